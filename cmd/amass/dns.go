@@ -6,8 +6,11 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"os/signal"
@@ -20,6 +23,7 @@ import (
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
 	"github.com/OWASP/Amass/v3/systems"
+	"github.com/OWASP/Amass/v3/wordlist"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/stringset"
 	"github.com/fatih/color"
@@ -35,9 +39,11 @@ type dnsArgs struct {
 	Domains       stringset.Set
 	MaxDNSQueries int
 	Names         stringset.Set
+	NSEC3Length   int
 	RecordTypes   stringset.Set
 	Resolvers     stringset.Set
 	Timeout       int
+	WordListMask  stringset.Set
 	Options       struct {
 		DemoMode            bool
 		IPs                 bool
@@ -55,8 +61,10 @@ type dnsArgs struct {
 		JSONOutput    string
 		LogFile       string
 		Names         format.ParseStrings
+		NSEC3         format.ParseStrings
 		Resolvers     format.ParseStrings
 		TermOut       string
+		Wordlist      format.ParseStrings
 	}
 }
 
@@ -64,9 +72,12 @@ func defineDNSArgumentFlags(dnsFlags *flag.FlagSet, args *dnsArgs) {
 	dnsFlags.Var(&args.Blacklist, "bl", "Blacklist of subdomain names that will not be investigated")
 	dnsFlags.Var(&args.Domains, "d", "Domain names separated by commas (can be used multiple times)")
 	dnsFlags.IntVar(&args.MaxDNSQueries, "max-dns-queries", 0, "Maximum number of concurrent DNS queries")
+	dnsFlags.IntVar(&args.NSEC3Length, "nsec3-len", 0,
+		fmt.Sprintf("Maximum label length brute forced when cracking the NSEC3 hashes (up to %d)", resolvers.MaxNSEC3BruteLength))
 	dnsFlags.Var(&args.RecordTypes, "t", "DNS record types to be queried for (can be used multiple times)")
 	dnsFlags.Var(&args.Resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
 	dnsFlags.IntVar(&args.Timeout, "timeout", 0, "Number of minutes to let enumeration run before quitting")
+	dnsFlags.Var(&args.WordListMask, "wm", "\"hashcat-style\" wordlist masks for cracking the NSEC3 hashes")
 }
 
 func defineDNSOptionFlags(dnsFlags *flag.FlagSet, args *dnsArgs) {
@@ -87,17 +98,20 @@ func defineDNSFilepathFlags(dnsFlags *flag.FlagSet, args *dnsArgs) {
 	dnsFlags.StringVar(&args.Filepaths.JSONOutput, "json", "", "Path to the JSON output file")
 	dnsFlags.StringVar(&args.Filepaths.LogFile, "log", "", "Path to the log file where errors will be written")
 	dnsFlags.Var(&args.Filepaths.Names, "nf", "Path to a file providing already known subdomain names (from other tools/sources)")
+	dnsFlags.Var(&args.Filepaths.NSEC3, "nsec3", "Path to a file of NSEC3 hashes saved during zone walks (can be used multiple times)")
 	dnsFlags.Var(&args.Filepaths.Resolvers, "rf", "Path to a file providing preferred DNS resolvers")
 	dnsFlags.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
+	dnsFlags.Var(&args.Filepaths.Wordlist, "w", "Path to a wordlist file for cracking the NSEC3 hashes")
 }
 
 func runDNSCommand(clArgs []string) {
 	args := &dnsArgs{
-		Blacklist:   stringset.New(),
-		Domains:     stringset.New(),
-		Names:       stringset.New(),
-		RecordTypes: stringset.New(),
-		Resolvers:   stringset.New(),
+		Blacklist:    stringset.New(),
+		Domains:      stringset.New(),
+		Names:        stringset.New(),
+		RecordTypes:  stringset.New(),
+		Resolvers:    stringset.New(),
+		WordListMask: stringset.New(),
 	}
 	var help1, help2 bool
 	dnsCommand := flag.NewFlagSet("dns", flag.ContinueOnError)
@@ -300,9 +314,13 @@ loop:
 				name := fmt.Sprintf("%-36s", req.Name)
 				tstr := fmt.Sprintf("%-4s", typeToName(uint16(rec.Type)))
 
-				if t := uint16(rec.Type); t == dns.TypeNS || t == dns.TypeSOA {
+				if t := uint16(rec.Type); t == dns.TypeNS {
 					pieces := strings.Split(rec.Data, ",")
 					rec.Data = pieces[len(pieces)-1]
+				} else if t == dns.TypeSOA {
+					if ns, mbox, serial, err := resolvers.ParseSOAData(rec.Data); err == nil {
+						rec.Data = fmt.Sprintf("%s %s %d", ns, mbox, serial)
+					}
 				}
 				rec.Data = resolvers.RemoveLastDot(rec.Data)
 
@@ -351,6 +369,82 @@ func processDNSInputFiles(args *dnsArgs) error {
 			args.Resolvers.InsertMany(list...)
 		}
 	}
+	if len(args.Filepaths.NSEC3) > 0 {
+		return crackNSEC3Files(args)
+	}
+	return nil
+}
+
+// Recover the names covered by the NSEC3 hashes saved during zone walks and resolve them
+func crackNSEC3Files(args *dnsArgs) error {
+	if args.NSEC3Length < 0 || args.NSEC3Length > resolvers.MaxNSEC3BruteLength {
+		return fmt.Errorf("The NSEC3 brute force length must be between 0 and %d", resolvers.MaxNSEC3BruteLength)
+	}
+
+	var words []string
+	for _, f := range args.Filepaths.Wordlist {
+		list, err := config.GetListFromFile(f)
+		if err != nil {
+			return fmt.Errorf("Failed to parse the wordlist file: %v", err)
+		}
+
+		words = append(words, list...)
+	}
+	if args.WordListMask.Len() > 0 {
+		masks, err := wordlist.ExpandMaskWordlist(args.WordListMask.Slice())
+		if err != nil {
+			return err
+		}
+
+		words = append(words, masks...)
+	}
+	if len(words) == 0 && args.NSEC3Length == 0 {
+		list, err := config.DefaultWordlist()
+		if err != nil {
+			return err
+		}
+
+		words = list
+	}
+
+	// The brute forcing can take a long time, so it stops when the user interrupts the program
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for _, f := range args.Filepaths.NSEC3 {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("Failed to read the NSEC3 hashes file: %v", err)
+		}
+
+		var zone resolvers.NSEC3Zone
+		if err := json.Unmarshal(data, &zone); err != nil {
+			return fmt.Errorf("Failed to parse the NSEC3 hashes file %s: %v", f, err)
+		}
+
+		names := stringset.New(zone.Crack(words)...)
+		if args.NSEC3Length > 0 {
+			names.InsertMany(zone.BruteForce(ctx, args.NSEC3Length)...)
+		}
+		if ctx.Err() != nil {
+			return errors.New("The NSEC3 hash cracking was interrupted")
+		}
+
+		fmt.Fprintf(color.Error, "Cracked %d of the %d NSEC3 hashes for %s\n", names.Len(), len(zone.Hashes), zone.Domain)
+		args.Names.Union(names)
+	}
 	return nil
 }
 
@@ -423,6 +517,14 @@ func typeToName(qtype uint16) string {
 		name = "SPF"
 	case dns.TypeSRV:
 		name = "SRV"
+	case dns.TypeCAA:
+		name = "CAA"
+	case dns.TypeDNAME:
+		name = "DNAME"
+	case dns.TypeNAPTR:
+		name = "NAPTR"
+	case dns.TypeTLSA:
+		name = "TLSA"
 	}
 
 	return name
@@ -450,6 +552,14 @@ func nameToType(t string) uint16 {
 		return dns.TypeSPF
 	case "SRV":
 		return dns.TypeSRV
+	case "CAA":
+		return dns.TypeCAA
+	case "DNAME":
+		return dns.TypeDNAME
+	case "NAPTR":
+		return dns.TypeNAPTR
+	case "TLSA":
+		return dns.TypeTLSA
 	}
 	return dns.TypeNone
 }
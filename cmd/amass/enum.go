@@ -19,18 +19,22 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/datasrcs"
 	"github.com/OWASP/Amass/v3/enum"
 	"github.com/OWASP/Amass/v3/format"
+	"github.com/OWASP/Amass/v3/handlers"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringfilter"
 	"github.com/OWASP/Amass/v3/systems"
+	"github.com/OWASP/Amass/v3/wordlist"
 	"github.com/caffix/stringset"
 	"github.com/fatih/color"
 )
@@ -51,20 +55,25 @@ type enumArgs struct {
 	Included          stringset.Set
 	Interface         string
 	MaxDNSQueries     int
+	MaxDepth          int
 	MinForRecursive   int
 	Names             stringset.Set
 	Ports             format.ParseInts
 	Resolvers         stringset.Set
+	SRVServices       stringset.Set
+	Trusted           stringset.Set
 	Timeout           int
 	Options           struct {
 		Active              bool
 		BruteForcing        bool
 		DemoMode            bool
+		Fresh               bool
 		IPs                 bool
 		IPv4                bool
 		IPv6                bool
 		ListSources         bool
 		MonitorResolverRate bool
+		NoResolvScore       bool
 		NoAlts              bool
 		NoColor             bool
 		NoLocalDatabase     bool
@@ -72,11 +81,17 @@ type enumArgs struct {
 		Passive             bool
 		Silent              bool
 		Sources             bool
+		Sweep               bool
 		Verbose             bool
+		ZoneWalk            bool
+		NoNSEC              bool
+		NoNSEC3             bool
+		NoZoneTransfers     bool
 	}
 	Filepaths struct {
 		AllFilePrefix    string
 		AltWordlist      format.ParseStrings
+		ArchiveDirectory string
 		Blacklist        string
 		BruteWordlist    format.ParseStrings
 		ConfigFile       string
@@ -89,6 +104,9 @@ type enumArgs struct {
 		Names            format.ParseStrings
 		Resolvers        format.ParseStrings
 		ScriptsDirectory string
+		SRVServices      format.ParseStrings
+		Template         string
+		TemplateOutput   string
 		TermOut          string
 	}
 }
@@ -105,9 +123,11 @@ func defineEnumArgumentFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.Var(&args.Included, "include", "Data source names separated by commas to be included")
 	enumFlags.StringVar(&args.Interface, "iface", "", "Provide the network interface to send traffic through")
 	enumFlags.IntVar(&args.MaxDNSQueries, "max-dns-queries", 0, "Maximum number of DNS queries per second")
+	enumFlags.IntVar(&args.MaxDepth, "max-depth", 0, "Maximum number of subdomain labels for brute forcing")
 	enumFlags.IntVar(&args.MinForRecursive, "min-for-recursive", 1, "Subdomain labels seen before recursive brute forcing (Default: 1)")
 	enumFlags.Var(&args.Ports, "p", "Ports separated by commas (default: 443)")
 	enumFlags.Var(&args.Resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
+	enumFlags.Var(&args.Trusted, "tr", "IP addresses of trusted DNS resolvers that verify the public resolver answers (can be used multiple times)")
 	enumFlags.IntVar(&args.Timeout, "timeout", 0, "Number of minutes to let enumeration run before quitting")
 }
 
@@ -115,24 +135,32 @@ func defineEnumOptionFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.BoolVar(&args.Options.Active, "active", false, "Attempt zone transfers and certificate name grabs")
 	enumFlags.BoolVar(&args.Options.BruteForcing, "brute", false, "Execute brute forcing after searches")
 	enumFlags.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
+	enumFlags.BoolVar(&args.Options.Fresh, "fresh", false, "Ignore the cached data source responses")
 	enumFlags.BoolVar(&args.Options.IPs, "ip", false, "Show the IP addresses for discovered names")
 	enumFlags.BoolVar(&args.Options.IPv4, "ipv4", false, "Show the IPv4 addresses for discovered names")
 	enumFlags.BoolVar(&args.Options.IPv6, "ipv6", false, "Show the IPv6 addresses for discovered names")
 	enumFlags.BoolVar(&args.Options.ListSources, "list", false, "Print the names of all available data sources")
 	enumFlags.BoolVar(&args.Options.MonitorResolverRate, "noresolvrate", true, "Disable resolver rate monitoring")
+	enumFlags.BoolVar(&args.Options.NoResolvScore, "noresolvscore", false, "Disable resolver reliability scoring")
 	enumFlags.BoolVar(&args.Options.NoAlts, "noalts", false, "Disable generation of altered names")
 	enumFlags.BoolVar(&args.Options.NoColor, "nocolor", false, "Disable colorized output")
+	enumFlags.BoolVar(&args.Options.NoNSEC, "nonsec", false, "Disable NSEC walking during zone walks")
+	enumFlags.BoolVar(&args.Options.NoNSEC3, "nonsec3", false, "Disable NSEC3 hash cracking during zone walks")
 	enumFlags.BoolVar(&args.Options.NoLocalDatabase, "nolocaldb", false, "Disable saving data into a local database")
+	enumFlags.BoolVar(&args.Options.NoZoneTransfers, "noxfr", false, "Disable zone transfers from the discovered nameservers during active enumeration")
 	enumFlags.BoolVar(&args.Options.NoRecursive, "norecursive", false, "Turn off recursive brute forcing")
 	enumFlags.BoolVar(&args.Options.Passive, "passive", false, "Disable DNS resolution of names and dependent features")
 	enumFlags.BoolVar(&args.Options.Silent, "silent", false, "Disable all output during execution")
 	enumFlags.BoolVar(&args.Options.Sources, "src", false, "Print data sources for the discovered names")
+	enumFlags.BoolVar(&args.Options.Sweep, "sweep", false, "Perform reverse DNS sweeps of the netblocks learned for the discovered addresses")
 	enumFlags.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
+	enumFlags.BoolVar(&args.Options.ZoneWalk, "zonewalk", false, "Walk DNSSEC signed zones using NSEC and NSEC3 records")
 }
 
 func defineEnumFilepathFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.StringVar(&args.Filepaths.AllFilePrefix, "oA", "", "Path prefix used for naming all output files")
 	enumFlags.Var(&args.Filepaths.AltWordlist, "aw", "Path to a different wordlist file for alterations")
+	enumFlags.StringVar(&args.Filepaths.ArchiveDirectory, "archive", "", "Path to the directory where raw data source responses are saved")
 	enumFlags.StringVar(&args.Filepaths.Blacklist, "blf", "", "Path to a file providing blacklisted subdomains")
 	enumFlags.Var(&args.Filepaths.BruteWordlist, "w", "Path to a different wordlist file for brute forcing")
 	enumFlags.StringVar(&args.Filepaths.ConfigFile, "config", "", "Path to the INI configuration file. Additional details below")
@@ -145,6 +173,9 @@ func defineEnumFilepathFlags(enumFlags *flag.FlagSet, args *enumArgs) {
 	enumFlags.Var(&args.Filepaths.Names, "nf", "Path to a file providing already known subdomain names (from other tools/sources)")
 	enumFlags.Var(&args.Filepaths.Resolvers, "rf", "Path to a file providing preferred DNS resolvers")
 	enumFlags.StringVar(&args.Filepaths.ScriptsDirectory, "scripts", "", "Path to a directory containing ADS scripts")
+	enumFlags.Var(&args.Filepaths.SRVServices, "srvf", "Path to a file providing additional SRV service names, e.g. _ldap._tcp")
+	enumFlags.StringVar(&args.Filepaths.Template, "template", "", "Path to a Go text/template file executed for each output record")
+	enumFlags.StringVar(&args.Filepaths.TemplateOutput, "otemplate", "", "Path to the file containing the template output")
 	enumFlags.StringVar(&args.Filepaths.TermOut, "o", "", "Path to the text file containing terminal stdout/stderr")
 }
 
@@ -177,7 +208,13 @@ func runEnumCommand(clArgs []string) {
 		os.Exit(1)
 	}
 	defer sys.Shutdown()
-	sys.SetDataSources(datasrcs.GetAllSources(sys))
+
+	srcs, err := datasrcs.GetAllSources(sys)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	sys.SetDataSources(srcs)
 	// Expand data source category names into the associated source names
 	cfg.SourceFilter.Sources = expandCategoryNames(cfg.SourceFilter.Sources, generateCategoryMap(sys))
 
@@ -212,6 +249,34 @@ func runEnumCommand(clArgs []string) {
 	go saveJSONOutput(e, args, jsonOutChan, &wg)
 	outChans = append(outChans, jsonOutChan)
 
+	if cfg.OutputTemplate != "" {
+		tmpl, err := format.ParseOutputTemplate(cfg.OutputTemplate)
+		if err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+
+		wg.Add(1)
+		// This goroutine will handle saving the output using the template
+		tmplOutChan := make(chan *requests.Output, 10)
+		go saveTemplateOutput(e, args, tmpl, tmplOutChan, &wg)
+		outChans = append(outChans, tmplOutChan)
+	}
+
+	for _, db := range cfg.OutputStreams {
+		stream, err := handlers.NewOutputStream(db)
+		if err != nil {
+			r.Fprintf(color.Error, "Failed to setup the %s output stream: %v\n", db.System, err)
+			os.Exit(1)
+		}
+
+		wg.Add(1)
+		// This goroutine will handle publishing the output to the message broker
+		streamOutChan := make(chan *requests.Output, 10)
+		go streamOutput(stream, streamOutChan, &wg)
+		outChans = append(outChans, streamOutChan)
+	}
+
 	wg.Add(1)
 	go processOutput(e, outChans, done, &wg)
 
@@ -246,6 +311,12 @@ func runEnumCommand(clArgs []string) {
 	close(done)
 	wg.Wait()
 
+	if args.Options.Verbose {
+		printSourceStats(e.Stats())
+	}
+	printRelatedDomains(e.RelatedDomains())
+	printInterceptions(e.Interceptions())
+
 	//e.Graph.DumpGraph()
 	// If necessary, handle graph database migration
 	if !cfg.Passive && len(e.Sys.GraphDatabases()) > 0 {
@@ -256,7 +327,7 @@ func runEnumCommand(clArgs []string) {
 			fmt.Fprintf(color.Error, "%s%s%s\n",
 				yellow("Discoveries are being migrated into the "), yellow(g.String()), yellow(" database"))
 
-			if err := e.Graph.MigrateEvents(g, e.Config.UUID.String()); err != nil {
+			if err := e.MigrateEvents(g); err != nil {
 				fmt.Fprintf(color.Error, "%s%s%s%s\n",
 					red("The database migration to "), red(g.String()), red(" failed: "), red(err.Error()))
 			}
@@ -264,6 +335,51 @@ func runEnumCommand(clArgs []string) {
 	}
 }
 
+func printSourceStats(stats map[string]datasrcs.SourceStats) {
+	var names []string
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := stats[name]
+
+		line := fmt.Sprintf("%s: %d queries in %s", name, s.Queries, s.Elapsed.Round(time.Millisecond))
+		if s.BudgetExceeded {
+			line += fmt.Sprintf(", time budget exceeded with %d queries skipped", s.Skipped)
+		} else if s.RequestLimitReached {
+			line += fmt.Sprintf(", request limit reached with %d queries skipped", s.Skipped)
+		} else if s.Unhealthy {
+			line += fmt.Sprintf(", unhealthy after %d failures with %d queries skipped", s.Failures, s.Skipped)
+		}
+		fgY.Fprintln(color.Error, line)
+	}
+}
+
+func printRelatedDomains(related []*requests.RelatedDomain) {
+	if len(related) == 0 {
+		return
+	}
+
+	fmt.Fprintf(color.Error, "\n%s\n", green("Related domains discovered through reverse DNS"))
+	for _, rd := range related {
+		fmt.Fprintf(color.Error, "%s %s\n", green(rd.Domain),
+			yellow(fmt.Sprintf("(%s has the PTR record %s in AS%d - %s)", rd.Address, rd.Name, rd.ASN, rd.Description)))
+	}
+}
+
+func printInterceptions(msgs []string) {
+	if len(msgs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(color.Error, "\n%s\n", red("Resolvers suspected of DNS interception"))
+	for _, msg := range msgs {
+		fmt.Fprintf(color.Error, "%s\n", yellow(msg))
+	}
+}
+
 func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 	args := enumArgs{
 		AltWordList:       stringset.New(),
@@ -276,6 +392,8 @@ func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 		Included:          stringset.New(),
 		Names:             stringset.New(),
 		Resolvers:         stringset.New(),
+		SRVServices:       stringset.New(),
+		Trusted:           stringset.New(),
 	}
 	var help1, help2 bool
 	enumCommand := flag.NewFlagSet("enum", flag.ContinueOnError)
@@ -320,6 +438,12 @@ func argsAndConfig(clArgs []string) (*config.Config, *enumArgs) {
 		color.Output = ioutil.Discard
 		color.Error = ioutil.Discard
 	}
+	for _, mask := range append(args.AltWordListMask.Slice(), args.BruteWordListMask.Slice()...) {
+		if err := wordlist.CheckMask(mask); err != nil {
+			r.Fprintf(color.Error, "%v\n", err)
+			os.Exit(1)
+		}
+	}
 	if args.AltWordListMask.Len() > 0 {
 		args.AltWordList.Union(args.AltWordListMask)
 	}
@@ -491,6 +615,70 @@ func saveJSONOutput(e *enum.Enumeration, args *enumArgs, output chan *requests.O
 		// Handle encoding the result as JSON
 		enc.Encode(out)
 	}
+	// Record how the results were produced
+	enc.Encode(struct {
+		Metadata *enum.Metadata `json:"metadata"`
+	}{Metadata: e.Metadata()})
+}
+
+func saveTemplateOutput(e *enum.Enumeration, args *enumArgs, tmpl *template.Template, output chan *requests.Output, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	dir := config.OutputDirectory(e.Config.Dir)
+	tmplfile := filepath.Join(dir, "amass_template.txt")
+	if e.Config.TemplateOutput != "" {
+		tmplfile = e.Config.TemplateOutput
+	}
+	if args.Filepaths.AllFilePrefix != "" {
+		tmplfile = args.Filepaths.AllFilePrefix + "_template.txt"
+	}
+
+	outptr, err := os.OpenFile(tmplfile, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		r.Fprintf(color.Error, "Failed to open the template output file: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		outptr.Sync()
+		outptr.Close()
+	}()
+
+	outptr.Truncate(0)
+	outptr.Seek(0, 0)
+	// Save all the output returned by the enumeration
+	for out := range output {
+		out.Addresses = format.DesiredAddrTypes(out.Addresses, args.Options.IPv4, args.Options.IPv6)
+		if !e.Config.Passive && len(out.Addresses) <= 0 {
+			continue
+		}
+
+		if err := format.WriteTemplateOutput(outptr, tmpl, out); err != nil {
+			r.Fprintf(color.Error, "Failed to execute the output template for %s: %v\n", out.Name, err)
+		}
+	}
+}
+
+func streamOutput(stream handlers.OutputStream, output chan *requests.Output, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	var failed int
+	// Publish all the output returned by the enumeration
+	for out := range output {
+		if err := stream.Publish(out); err != nil {
+			// Only the first failure is reported to avoid flooding the terminal
+			if failed == 0 {
+				r.Fprintf(color.Error, "Failed to publish the output to %s: %v\n", stream, err)
+			}
+			failed++
+		}
+	}
+
+	if err := stream.Close(); err != nil {
+		r.Fprintf(color.Error, "Failed to deliver the output to %s: %v\n", stream, err)
+	}
+	if failed > 1 {
+		r.Fprintf(color.Error, "Failed to publish %d outputs to %s\n", failed, stream)
+	}
 }
 
 func processOutput(e *enum.Enumeration, outputs []chan *requests.Output, done chan struct{}, wg *sync.WaitGroup) {
@@ -542,6 +730,7 @@ func writeLogsAndMessages(logs *io.PipeReader, logfile string, verbose bool) {
 	wildcard := regexp.MustCompile("DNS wildcard")
 	avg := regexp.MustCompile("Average DNS queries")
 	rScore := regexp.MustCompile("Resolver .* has a low score")
+	intercept := regexp.MustCompile("suspected DNS interception")
 	queries := regexp.MustCompile("Querying")
 
 	var filePtr *os.File
@@ -583,6 +772,10 @@ func writeLogsAndMessages(logs *io.PipeReader, logfile string, verbose bool) {
 		if rScore.FindString(line) != "" {
 			fgR.Fprintln(color.Error, line)
 		}
+		// Check if a DNS resolver returned wrong data for the canary names
+		if intercept.FindString(line) != "" {
+			fgR.Fprintln(color.Error, line)
+		}
 		// Check for Amass DNS wildcard messages
 		if verbose && wildcard.FindString(line) != "" {
 			fgR.Fprintln(color.Error, line)
@@ -647,6 +840,16 @@ func processEnumInputFiles(args *enumArgs) error {
 			args.Names.InsertMany(list...)
 		}
 	}
+	if len(args.Filepaths.SRVServices) > 0 {
+		for _, f := range args.Filepaths.SRVServices {
+			list, err := config.GetListFromFile(f)
+			if err != nil {
+				return fmt.Errorf("Failed to parse the SRV service names file: %v", err)
+			}
+
+			args.SRVServices.InsertMany(list...)
+		}
+	}
 	if len(args.Filepaths.Domains) > 0 {
 		for _, f := range args.Filepaths.Domains {
 			list, err := config.GetListFromFile(f)
@@ -690,6 +893,19 @@ func (e enumArgs) OverrideConfig(conf *config.Config) error {
 	if e.Filepaths.ScriptsDirectory != "" {
 		conf.ScriptsDirectory = e.Filepaths.ScriptsDirectory
 	}
+	if e.Filepaths.Template != "" {
+		data, err := ioutil.ReadFile(e.Filepaths.Template)
+		if err != nil {
+			return fmt.Errorf("Failed to read the output template file: %v", err)
+		}
+		conf.OutputTemplate = string(data)
+	}
+	if e.Filepaths.TemplateOutput != "" {
+		conf.TemplateOutput = e.Filepaths.TemplateOutput
+	}
+	if e.Filepaths.ArchiveDirectory != "" {
+		conf.ArchiveDirectory = e.Filepaths.ArchiveDirectory
+	}
 	if e.Names.Len() > 0 {
 		conf.ProvidedNames = e.Names.Slice()
 	}
@@ -714,27 +930,59 @@ func (e enumArgs) OverrideConfig(conf *config.Config) error {
 	if e.MinForRecursive != 1 {
 		conf.MinForRecursive = e.MinForRecursive
 	}
+	if e.MaxDepth > 0 {
+		conf.MaxDepth = e.MaxDepth
+	}
 	if e.Options.Active {
 		conf.Active = true
 	}
 	if e.Options.Passive {
 		conf.Passive = true
 	}
+	if e.Options.Fresh {
+		conf.FreshResponses = true
+	}
+	if e.Options.ZoneWalk {
+		conf.EnableZoneWalk = true
+	}
+	if e.Options.Sweep {
+		conf.EnableNetblockSweeps = true
+	}
+	if e.Options.NoNSEC {
+		conf.ZoneWalkNSEC = false
+	}
+	if e.Options.NoNSEC3 {
+		conf.ZoneWalkNSEC3 = false
+	}
+	if e.Options.NoZoneTransfers {
+		conf.ZoneTransfers = false
+	}
 	if e.Blacklist.Len() > 0 {
 		conf.Blacklist = e.Blacklist.Slice()
 	}
+	if e.SRVServices.Len() > 0 {
+		if err := conf.AddSRVServices(e.SRVServices.Slice()...); err != nil {
+			return err
+		}
+	}
 	if e.Options.Verbose {
 		conf.Verbose = true
 	}
 	if e.Resolvers.Len() > 0 {
 		conf.SetResolvers(e.Resolvers.Slice()...)
 	}
+	if e.Trusted.Len() > 0 {
+		conf.TrustedResolvers = e.Trusted.Slice()
+	}
 	if e.MaxDNSQueries > 0 {
 		conf.MaxDNSQueries = e.MaxDNSQueries
 	}
 	if !e.Options.MonitorResolverRate {
 		conf.MonitorResolverRate = false
 	}
+	if e.Options.NoResolvScore {
+		conf.ScoreResolvers = false
+	}
 
 	if len(e.Included) > 0 {
 		conf.SourceFilter.Include = true
@@ -15,6 +15,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -42,10 +43,12 @@ type intelArgs struct {
 	MaxDNSQueries    int
 	Ports            format.ParseInts
 	Resolvers        stringset.Set
+	Trusted          stringset.Set
 	Timeout          int
 	Options          struct {
 		Active              bool
 		DemoMode            bool
+		Fresh               bool
 		IPs                 bool
 		IPv4                bool
 		IPv6                bool
@@ -53,6 +56,7 @@ type intelArgs struct {
 		ReverseWhois        bool
 		Sources             bool
 		MonitorResolverRate bool
+		NoResolvScore       bool
 		Verbose             bool
 	}
 	Filepaths struct {
@@ -78,17 +82,20 @@ func defineIntelArgumentFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.IntVar(&args.MaxDNSQueries, "max-dns-queries", 0, "Maximum number of concurrent DNS queries")
 	intelFlags.Var(&args.Ports, "p", "Ports separated by commas (default: 443)")
 	intelFlags.Var(&args.Resolvers, "r", "IP addresses of preferred DNS resolvers (can be used multiple times)")
+	intelFlags.Var(&args.Trusted, "tr", "IP addresses of trusted DNS resolvers that verify the public resolver answers (can be used multiple times)")
 	intelFlags.IntVar(&args.Timeout, "timeout", 0, "Number of minutes to let enumeration run before quitting")
 }
 
 func defineIntelOptionFlags(intelFlags *flag.FlagSet, args *intelArgs) {
 	intelFlags.BoolVar(&args.Options.Active, "active", false, "Attempt certificate name grabs")
 	intelFlags.BoolVar(&args.Options.DemoMode, "demo", false, "Censor output to make it suitable for demonstrations")
+	intelFlags.BoolVar(&args.Options.Fresh, "fresh", false, "Ignore the cached data source responses")
 	intelFlags.BoolVar(&args.Options.IPs, "ip", false, "Show the IP addresses for discovered names")
 	intelFlags.BoolVar(&args.Options.IPv4, "ipv4", false, "Show the IPv4 addresses for discovered names")
 	intelFlags.BoolVar(&args.Options.IPv6, "ipv6", false, "Show the IPv6 addresses for discovered names")
 	intelFlags.BoolVar(&args.Options.ListSources, "list", false, "Print additional information")
 	intelFlags.BoolVar(&args.Options.MonitorResolverRate, "noresolvrate", true, "Disable resolver rate monitoring")
+	intelFlags.BoolVar(&args.Options.NoResolvScore, "noresolvscore", false, "Disable resolver reliability scoring")
 	intelFlags.BoolVar(&args.Options.ReverseWhois, "whois", false, "All provided domains are run through reverse whois")
 	intelFlags.BoolVar(&args.Options.Sources, "src", false, "Print data sources for the discovered names")
 	intelFlags.BoolVar(&args.Options.Verbose, "v", false, "Output status / debug / troubleshooting info")
@@ -111,6 +118,7 @@ func runIntelCommand(clArgs []string) {
 		Excluded:  stringset.New(),
 		Included:  stringset.New(),
 		Resolvers: stringset.New(),
+		Trusted:   stringset.New(),
 	}
 	var help1, help2 bool
 	intelCommand := flag.NewFlagSet("intel", flag.ContinueOnError)
@@ -196,13 +204,30 @@ func runIntelCommand(clArgs []string) {
 	if err != nil {
 		return
 	}
-	sys.SetDataSources(datasrcs.GetAllSources(sys))
+	defer sys.Shutdown()
+
+	srcs, err := datasrcs.GetAllSources(sys)
+	if err != nil {
+		r.Fprintf(color.Error, "%v\n", err)
+		os.Exit(1)
+	}
+	sys.SetDataSources(srcs)
 
 	if args.OrganizationName != "" {
 		asns, _, err := config.LookupASNsByName(args.OrganizationName)
 		if err != nil {
 			fmt.Printf("%v\n", err)
 		}
+		// The data sources are also searched for autonomous systems registered to the organization
+	loop:
+		for _, asn := range systems.PopulateCacheByOrganization(context.Background(), args.OrganizationName, sys) {
+			for _, known := range asns {
+				if asn == known {
+					continue loop
+				}
+			}
+			asns = append(asns, asn)
+		}
 		if len(asns) > 0 {
 			printNetblocks(asns, cfg, sys)
 		}
@@ -267,6 +292,13 @@ func printNetblocks(asns []int, cfg *config.Config, sys systems.System) {
 		}
 
 		fmt.Printf("%s%s %s %s\n", blue("ASN: "), yellow(strconv.Itoa(asn)), green("-"), green(d.Description))
+		if len(d.Upstreams) > 0 {
+			var ups []string
+			for _, up := range d.Upstreams {
+				ups = append(ups, "AS"+strconv.Itoa(up))
+			}
+			fmt.Printf("%s%s\n", blue("\tUpstreams: "), green(strings.Join(ups, ", ")))
+		}
 		for _, cidr := range d.Netblocks.Slice() {
 			fmt.Printf("%s\n", yellow(fmt.Sprintf("\t%s", cidr)))
 		}
@@ -376,15 +408,24 @@ func (i intelArgs) OverrideConfig(conf *config.Config) error {
 	if i.Options.Verbose {
 		conf.Verbose = true
 	}
+	if i.Options.Fresh {
+		conf.FreshResponses = true
+	}
 	if i.Resolvers.Len() > 0 {
 		conf.SetResolvers(i.Resolvers.Slice()...)
 	}
+	if i.Trusted.Len() > 0 {
+		conf.TrustedResolvers = i.Trusted.Slice()
+	}
 	if i.MaxDNSQueries > 0 {
 		conf.MaxDNSQueries = i.MaxDNSQueries
 	}
 	if !i.Options.MonitorResolverRate {
 		conf.MonitorResolverRate = false
 	}
+	if i.Options.NoResolvScore {
+		conf.ScoreResolvers = false
+	}
 
 	if len(i.Included) > 0 {
 		conf.SourceFilter.Include = true
@@ -147,7 +147,12 @@ func GetAllSourceInfo(cfg *config.Config) []string {
 	}
 	defer sys.Shutdown()
 
-	srcs := datasrcs.SelectedDataSources(cfg, datasrcs.GetAllSources(sys))
+	all, err := datasrcs.GetAllSources(sys)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	srcs := datasrcs.SelectedDataSources(cfg, all)
 	sys.SetDataSources(srcs)
 
 	return DataSourceInfo(srcs, sys)
@@ -364,9 +369,14 @@ func healASInfo(uuids []string, db *graph.Graph) bool {
 	if err != nil {
 		return false
 	}
-	sys.SetDataSources(datasrcs.GetAllSources(sys))
 	defer sys.Shutdown()
 
+	srcs, err := datasrcs.GetAllSources(sys)
+	if err != nil {
+		return false
+	}
+	sys.SetDataSources(srcs)
+
 	cache := sys.Cache()
 	for _, g := range sys.GraphDatabases() {
 		g.ASNCacheFill(cache)
@@ -383,7 +393,7 @@ func healASInfo(uuids []string, db *graph.Graph) bool {
 		for _, out := range db.EventOutput(uuid, nil, false, cache) {
 			for _, a := range out.Addresses {
 				if r := cache.AddrSearch(a.Address.String()); r != nil {
-					db.InsertInfrastructure(r.ASN, r.Description, r.Address, r.Prefix, r.Source, r.Tag, uuid)
+					insertInfrastructure(cfg, db, r, uuid)
 					continue
 				}
 
@@ -399,7 +409,7 @@ func healASInfo(uuids []string, db *graph.Graph) bool {
 				}
 
 				if r := cache.AddrSearch(a.Address.String()); r != nil {
-					db.InsertInfrastructure(r.ASN, r.Description, r.Address, r.Prefix, r.Source, r.Tag, uuid)
+					insertInfrastructure(cfg, db, r, uuid)
 				}
 
 				updated = true
@@ -410,6 +420,15 @@ func healASInfo(uuids []string, db *graph.Graph) bool {
 	return updated
 }
 
+// insertInfrastructure adds the infrastructure of the address and the content delivery network it belongs to.
+func insertInfrastructure(cfg *config.Config, db *graph.Graph, r *requests.ASNRequest, uuid string) {
+	_ = db.InsertInfrastructure(r.ASN, r.Description, r.Address, r.Prefix, r.Source, r.Tag, uuid)
+
+	if cdn := cfg.CDNProviderByAddr(net.ParseIP(r.Address), r.ASN); cdn != "" {
+		_ = db.InsertCDN(r.Address, cdn, r.Source, r.Tag, uuid)
+	}
+}
+
 func assignNetInterface(iface *net.Interface) error {
 	addrs, err := iface.Addrs()
 	if err != nil {
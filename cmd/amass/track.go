@@ -10,6 +10,8 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
@@ -231,6 +233,10 @@ func cumulativeOutput(uuids, domains []string, ea, la []time.Time, db *graph.Gra
 		updates = true
 		fmt.Fprintln(color.Output, d)
 	}
+	for _, d := range diffSOASerials(db, trackedZones(domains, out), uuids[:idx], uuids[idx:]) {
+		updates = true
+		fmt.Fprintln(color.Output, d)
+	}
 	if !updates {
 		g.Println("No differences discovered")
 	}
@@ -275,6 +281,10 @@ func completeHistoryOutput(uuids, domains []string, ea, la []time.Time, db *grap
 			updates = true
 			fmt.Fprintln(color.Output, d)
 		}
+		for _, d := range diffSOASerials(db, trackedZones(domains, out2), []string{prev}, []string{uuid}) {
+			updates = true
+			fmt.Fprintln(color.Output, d)
+		}
 		if !updates {
 			g.Println("No differences discovered")
 		}
@@ -326,6 +336,38 @@ func diffEnumOutput(older, newer []*requests.Output) []string {
 	return diff
 }
 
+// The zones are the root domain names and the subdomains discovered during the enumeration.
+func trackedZones(domains []string, out []*requests.Output) []string {
+	zones := stringset.New(domains...)
+
+	for _, o := range out {
+		zones.Insert(o.Name)
+	}
+	return zones.Slice()
+}
+
+// diffSOASerials reports the zones with serial numbers that changed between the enumerations, indicating zone activity.
+func diffSOASerials(db *graph.Graph, zones, older, newer []string) []string {
+	var diff []string
+
+	sort.Strings(zones)
+	for _, zone := range zones {
+		prev, found := db.SOASerial(zone, older...)
+		if !found {
+			continue
+		}
+
+		serial, found := db.SOASerial(zone, newer...)
+		if !found || serial == prev {
+			continue
+		}
+
+		diff = append(diff, fmt.Sprintf("%s%s %s%s%s", blue("Zone Updated: "), green(zone),
+			yellow(strconv.FormatUint(uint64(prev), 10)), blue(" -> "), yellow(strconv.FormatUint(uint64(serial), 10))))
+	}
+	return diff
+}
+
 func lineOfAddresses(addrs []requests.AddressInfo) string {
 	var line string
 
@@ -6,6 +6,7 @@ package config
 import (
 	"fmt"
 
+	"github.com/OWASP/Amass/v3/wordlist"
 	"github.com/caffix/stringset"
 	"github.com/go-ini/ini"
 )
@@ -23,6 +24,10 @@ func (c *Config) loadBruteForceSettings(cfg *ini.File) error {
 
 	c.Recursive = bruteforce.Key("recursive").MustBool(true)
 	c.MinForRecursive = bruteforce.Key("minimum_for_recursive").MustInt(0)
+	c.MaxDepth = bruteforce.Key("max_depth").MustInt(0)
+	if c.MaxDepth < 0 {
+		return fmt.Errorf("The bruteforce max_depth setting must not be negative: %d", c.MaxDepth)
+	}
 
 	if bruteforce.HasKey("wordlist_file") {
 		for _, wordlist := range bruteforce.Key("wordlist_file").ValueWithShadows() {
@@ -34,6 +39,16 @@ func (c *Config) loadBruteForceSettings(cfg *ini.File) error {
 		}
 	}
 
+	// The masks generate names that match the naming conventions observed in the organization
+	if bruteforce.HasKey("mask") {
+		for _, mask := range bruteforce.Key("mask").ValueWithShadows() {
+			if err := wordlist.CheckMask(mask); err != nil {
+				return fmt.Errorf("Unable to use the mask in the bruteforce mask setting: %v", err)
+			}
+			c.Wordlist = append(c.Wordlist, mask)
+		}
+	}
+
 	c.Wordlist = stringset.Deduplicate(c.Wordlist)
 	return nil
 }
@@ -53,8 +68,11 @@ func (c *Config) loadAlterationSettings(cfg *ini.File) error {
 	c.AddWords = alterations.Key("add_words").MustBool(true)
 	c.FlipNumbers = alterations.Key("flip_numbers").MustBool(true)
 	c.AddNumbers = alterations.Key("add_numbers").MustBool(true)
+	c.TransposeWords = alterations.Key("transpose_words").MustBool(true)
+	c.RemoveWords = alterations.Key("remove_words").MustBool(true)
 	c.MinForWordFlip = alterations.Key("minimum_for_word_flip").MustInt(2)
 	c.EditDistance = alterations.Key("edit_distance").MustInt(1)
+	c.MarkovGuesses = alterations.Key("markov_guesses").MustInt(100)
 
 	if alterations.HasKey("wordlist_file") {
 		for _, wordlist := range alterations.Key("wordlist_file").ValueWithShadows() {
@@ -0,0 +1,34 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadBruteForceMasks(t *testing.T) {
+	load := func(mask string) error {
+		cfg, _ := ini.LoadSources(
+			ini.LoadOptions{
+				Insensitive:  true,
+				AllowShadows: true,
+			},
+			[]byte("[bruteforce]\nenabled = true\nmask = "+mask+"\n"),
+		)
+
+		return NewConfig().loadBruteForceSettings(cfg)
+	}
+
+	if err := load("app?d?d"); err != nil {
+		t.Errorf("A proper mask was rejected: %v", err)
+	}
+	if err := load("app?x"); err == nil {
+		t.Errorf("An improper mask was accepted")
+	}
+	if err := load("?a?a?a?a?a"); err == nil {
+		t.Errorf("A mask exceeding the maximum expansion was accepted")
+	}
+}
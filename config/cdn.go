@@ -0,0 +1,122 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+// CDNProvider identifies the network infrastructure used by a content delivery network.
+type CDNProvider struct {
+	Name  string
+	ASNs  []int
+	CIDRs []*net.IPNet
+}
+
+// DefaultCDNProviders is the list of content delivery networks known to Amass by default.
+var DefaultCDNProviders = []*CDNProvider{
+	{Name: "Akamai", ASNs: []int{12222, 16625, 16702, 17204, 18680, 18717, 20189, 20940, 21342, 21357, 21399, 22207, 23454, 23455, 23903, 24319, 26008, 30675, 31107, 31108, 31109, 31110, 31377, 33047, 33905, 34164, 34850, 35204, 35993, 35994, 36183, 39836, 43639, 55409, 55770}},
+	{Name: "CDN77", ASNs: []int{60068}},
+	{Name: "CDNetworks", ASNs: []int{36408, 38107}},
+	{Name: "Cloudflare", ASNs: []int{13335, 209242}},
+	{Name: "Edgecast", ASNs: []int{15133}},
+	{Name: "Fastly", ASNs: []int{54113}},
+	{Name: "Imperva Incapsula", ASNs: []int{19551}},
+	{Name: "Limelight", ASNs: []int{22822}},
+	{Name: "StackPath", ASNs: []int{12989, 33438}},
+	{Name: "Sucuri", ASNs: []int{30148}},
+}
+
+func copyCDNProviders(providers []*CDNProvider) []*CDNProvider {
+	var results []*CDNProvider
+
+	for _, p := range providers {
+		results = append(results, &CDNProvider{
+			Name:  p.Name,
+			ASNs:  append([]int{}, p.ASNs...),
+			CIDRs: append([]*net.IPNet{}, p.CIDRs...),
+		})
+	}
+	return results
+}
+
+// CDNProviderByAddr returns the name of the content delivery network that the address
+// or the ASN belongs to, or an empty string when the infrastructure is not a known CDN.
+func (c *Config) CDNProviderByAddr(addr net.IP, asn int) string {
+	for _, cdn := range c.CDNProviders {
+		if asn != 0 {
+			for _, a := range cdn.ASNs {
+				if a == asn {
+					return cdn.Name
+				}
+			}
+		}
+		if addr != nil {
+			for _, cidr := range cdn.CIDRs {
+				if cidr.Contains(addr) {
+					return cdn.Name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func (c *Config) loadCDNSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("cdn")
+	if err != nil {
+		return nil
+	}
+
+	if !sec.Key("use_defaults").MustBool(true) {
+		c.CDNProviders = []*CDNProvider{}
+	}
+	c.NoteHiddenOrigin = sec.Key("note_hidden_origin").MustBool(c.NoteHiddenOrigin)
+
+	for _, child := range sec.ChildSections() {
+		name := strings.SplitN(child.Name(), ".", 2)[1]
+
+		cdn := &CDNProvider{Name: name}
+		if child.HasKey("asn") {
+			for _, a := range child.Key("asn").ValueWithShadows() {
+				var asn int
+
+				if _, err := fmt.Sscanf(strings.TrimSpace(a), "%d", &asn); err != nil || asn <= 0 {
+					return fmt.Errorf("Invalid ASN provided for the %s CDN provider: %s", name, a)
+				}
+				cdn.ASNs = append(cdn.ASNs, asn)
+			}
+		}
+		if child.HasKey("cidr") {
+			for _, cidr := range child.Key("cidr").ValueWithShadows() {
+				_, ipnet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+				if err != nil {
+					return fmt.Errorf("Invalid CIDR provided for the %s CDN provider: %s", name, cidr)
+				}
+				cdn.CIDRs = append(cdn.CIDRs, ipnet)
+			}
+		}
+
+		c.addCDNProvider(cdn)
+	}
+
+	return nil
+}
+
+// addCDNProvider merges the provider infrastructure with an existing entry of the same name.
+func (c *Config) addCDNProvider(cdn *CDNProvider) {
+	for _, p := range c.CDNProviders {
+		if strings.EqualFold(p.Name, cdn.Name) {
+			p.ASNs = append(p.ASNs, cdn.ASNs...)
+			p.CIDRs = append(p.CIDRs, cdn.CIDRs...)
+			return
+		}
+	}
+
+	c.CDNProviders = append(c.CDNProviders, cdn)
+}
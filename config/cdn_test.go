@@ -0,0 +1,54 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"net"
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestCDNProviderByAddr(t *testing.T) {
+	c := NewConfig()
+
+	if cdn := c.CDNProviderByAddr(net.ParseIP("104.16.1.1"), 13335); cdn != "Cloudflare" {
+		t.Errorf("CDNProviderByAddr returned %s instead of Cloudflare for the ASN", cdn)
+	}
+	if cdn := c.CDNProviderByAddr(net.ParseIP("192.168.1.1"), 26808); cdn != "" {
+		t.Errorf("CDNProviderByAddr returned %s for infrastructure that is not a CDN", cdn)
+	}
+}
+
+func TestLoadCDNSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[cdn]
+		use_defaults = false
+		note_hidden_origin = true
+		[cdn.example]
+		asn = 64512
+		cidr = 192.0.2.0/24
+		`),
+	)
+
+	if err := c.loadCDNSettings(cfg); err != nil {
+		t.Errorf("Failed to load the CDN settings: %v", err)
+	}
+	if len(c.CDNProviders) != 1 || !c.NoteHiddenOrigin {
+		t.Errorf("The CDN settings were not correctly assigned to the configuration")
+	}
+	if cdn := c.CDNProviderByAddr(net.ParseIP("192.0.2.10"), 0); cdn != "example" {
+		t.Errorf("CDNProviderByAddr failed to match the address within the configured CIDR")
+	}
+	if cdn := c.CDNProviderByAddr(nil, 13335); cdn != "" {
+		t.Errorf("CDNProviderByAddr matched a default provider after the defaults were disabled")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	_ "github.com/OWASP/Amass/v3/config/statik" // The content being embedded into the binary
 	"github.com/OWASP/Amass/v3/wordlist"
@@ -63,15 +64,33 @@ type Config struct {
 	// Alternative directory for scripts provided by the user
 	ScriptsDirectory string `ini:"scripts_directory"`
 
+	// The YAML file declaring the data sources that extract names from REST APIs
+	RESTSourcesFile string `ini:"rest_sources_file"`
+
 	// Use a local graph database
 	LocalDatabase bool
 
 	// The graph databases used by the system / enumerations
 	GraphDBs []*Database
 
+	// The databases that receive the data as it is inserted into the enumeration graph
+	DataHandlers []*Database
+
+	// The message brokers that receive each finalized output as JSON
+	OutputStreams []*Database
+
 	// The maximum number of concurrent DNS queries
 	MaxDNSQueries int `ini:"maximum_dns_queries"`
 
+	// Reduce the rate of DNS queries when the timeout and SERVFAIL rates spike
+	AdaptiveQueryRate bool `ini:"adaptive_query_rate"`
+
+	// The memory usage in megabytes that causes emitted names to be pruned from the in-memory graph
+	PruneMemoryThreshold int `ini:"prune_memory_threshold"`
+
+	// Keep the enumeration graph in an embedded store within the output directory instead of memory
+	PersistentGraph bool `ini:"persistent_graph"`
+
 	// Names provided to seed the enumeration
 	ProvidedNames []string
 
@@ -99,15 +118,22 @@ type Config struct {
 	// Minimum number of subdomain discoveries before performing recursive brute forcing
 	MinForRecursive int
 
+	// Maximum number of labels below the root domain for subdomains being brute forced (0 means no limit)
+	MaxDepth int
+
 	// Will discovered subdomain name alterations be generated?
 	Alterations    bool
 	FlipWords      bool
 	FlipNumbers    bool
 	AddWords       bool
 	AddNumbers     bool
+	TransposeWords bool
+	RemoveWords    bool
 	MinForWordFlip int
 	EditDistance   int
 	AltWordlist    []string
+	// The number of labels guessed by the Markov model trained on the discovered names (0 disables the model)
+	MarkovGuesses int
 
 	// Only access the data sources for names and return results?
 	Passive bool
@@ -115,9 +141,30 @@ type Config struct {
 	// Determines if zone transfers will be attempted
 	Active bool
 
+	// Will the nameservers discovered during active enumeration be asked for zone transfers?
+	ZoneTransfers bool `ini:"zone_transfers"`
+
+	// Will the nameservers discovered during active enumeration be fingerprinted?
+	NameserverFingerprints bool `ini:"nameserver_fingerprints"`
+
+	// Will NSEC and NSEC3 zone walking be performed on DNSSEC signed zones?
+	EnableZoneWalk     bool
+	ZoneWalkNSEC       bool
+	ZoneWalkNSEC3      bool
+	ZoneWalkMaxNames   int
+	ZoneWalkMaxQueries int
+
+	// Reverse DNS sweeps of the netblocks learned for the discovered addresses
+	EnableNetblockSweeps bool
+	NetblockSweepMaxSize int
+	NetblockSweepRate    int
+
 	// A blacklist of subdomain names that will not be investigated
 	Blacklist []string
 
+	// Names with a subdomain label shorter than this length are considered junk
+	MinLabelLength int
+
 	// A list of data sources that should not be utilized
 	SourceFilter struct {
 		Include bool // true = include, false = exclude
@@ -127,12 +174,80 @@ type Config struct {
 	// The minimum number of minutes that data source responses will be reused
 	MinimumTTL int
 
+	// The number of minutes after the first query of each data source before new queries are not started
+	TimeBudget int
+
+	// The number of seconds each data source request can take before it is abandoned
+	RequestTimeout int
+
+	// The number of consecutive failed requests before a data source is marked as unhealthy
+	SourceMaxFailures int
+
+	// The directory where raw data source responses are archived
+	ArchiveDirectory string
+
+	// Determines if URL paths discovered by the data sources are stored in the graph
+	StoreURLPaths bool
+
+	// The URL of the proxy used for the data source requests
+	Proxy string
+
+	// Determines if data source responses are cached in the graph databases for later enumerations
+	ResponseCache bool
+
+	// Determines if the cached data source responses are ignored and fresh responses are requested
+	FreshResponses bool
+
+	// Data sources declared in the REST sources file that extract names from REST APIs
+	RESTSources []*RESTSourceConfig
+
+	// Content delivery networks used to annotate the discovered names
+	CDNProviders []*CDNProvider
+
+	// Note names that only resolve into CDN infrastructure as having a hidden origin
+	NoteHiddenOrigin bool
+
 	// Type of DNS records to query for
 	RecordTypes []string
 
+	// The service names queried for SRV records under each discovered name, e.g. _ldap._tcp
+	SRVServices []string
+
+	// The resolution priority level assigned to each DNS record type
+	RecordPriorities map[string]int
+
 	// Resolver settings
 	Resolvers           []string
 	MonitorResolverRate bool
+	// The queries per second provided for specific resolvers
+	resolverRates map[string]int
+	// Score the public resolvers and evict those that perform poorly
+	ScoreResolvers bool
+	// Cache the DNS responses for the TTL of the records, and optionally keep them between enumerations
+	DNSCache        bool
+	PersistDNSCache bool
+	// The maximum duration that NXDOMAIN responses are cached, where zero disables negative caching
+	NegativeCacheTTL time.Duration
+	// The maximum number of responses cached before the least recently used are evicted, where zero selects the default
+	DNSCacheSize int
+	// The number of attempts made for each query, where zero selects the attempts based on priority
+	QueryRetries int
+	// The duration until an unanswered query is considered to have timed out
+	QueryTimeout time.Duration
+	// The netblock provided in the EDNS client-subnet option, which is zeroed out when empty
+	ClientSubnet string
+	// Names and their known addresses resolved through each resolver to detect poisoning and interception
+	CanaryRecords map[string][]string
+
+	// Resolvers that verify the answers obtained from the untrusted public resolvers
+	TrustedResolvers []string
+
+	// Resolvers inside the network that are compared with the public resolvers to detect split-horizon DNS
+	InternalResolvers []string
+
+	// Text of the template executed for each output record, and the file the results are written to
+	OutputTemplate string
+	TemplateOutput string
 
 	// Option for verbose logging and output
 	Verbose bool
@@ -155,6 +270,11 @@ func NewConfig() *Config {
 		Ports:               []int{443},
 		MinForRecursive:     1,
 		MonitorResolverRate: true,
+		ScoreResolvers:      true,
+		AdaptiveQueryRate:   true,
+		DNSCache:            true,
+		NegativeCacheTTL:    time.Hour,
+		QueryTimeout:        2 * time.Second,
 		LocalDatabase:       true,
 		// The following is enum-only, but intel will just ignore them anyway
 		Alterations:    true,
@@ -162,13 +282,32 @@ func NewConfig() *Config {
 		FlipNumbers:    true,
 		AddWords:       true,
 		AddNumbers:     true,
+		TransposeWords: true,
+		RemoveWords:    true,
 		MinForWordFlip: 2,
 		EditDistance:   1,
+		MarkovGuesses:  100,
 		Recursive:      true,
 		MinimumTTL:     1440,
+		CDNProviders:   copyCDNProviders(DefaultCDNProviders),
+		// Data sources that stop responding are no longer queried
+		SourceMaxFailures: 5,
+		// Record types that drive infrastructure discovery are resolved first
+		RecordPriorities: copyRecordPriorities(DefaultRecordPriorities),
+		SRVServices:      append([]string(nil), DefaultSRVServices...),
+		ZoneTransfers:    true,
+		// Nameservers are only fingerprinted during active enumeration
+		NameserverFingerprints: true,
+		// Zone walking is opt-in, but both techniques are used once enabled
+		ZoneWalkNSEC:       true,
+		ZoneWalkNSEC3:      true,
+		ZoneWalkMaxNames:   10000,
+		ZoneWalkMaxQueries: 500,
+		// Netblock sweeps are opt-in and limited to netblocks no larger than a /22
+		NetblockSweepMaxSize: 1024,
+		NetblockSweepRate:    20,
 	}
 
-	c.calcDNSQueriesMax()
 	return c
 }
 
@@ -194,6 +333,19 @@ func (c *Config) CheckSettings() error {
 	if c.Passive && c.Active {
 		return errors.New("Active enumeration cannot be performed without DNS resolution")
 	}
+	if c.Passive && c.EnableZoneWalk {
+		return errors.New("Zone walking cannot be performed without DNS resolution")
+	}
+	if c.Passive && c.EnableNetblockSweeps {
+		return errors.New("Netblock sweeps cannot be performed without DNS resolution")
+	}
+	if c.EnableZoneWalk && c.ZoneWalkNSEC3 && len(c.Wordlist) == 0 {
+		// The wordlist is used to crack the NSEC3 hashes
+		c.Wordlist, err = getWordlistByFS("/namelist.txt")
+		if err != nil {
+			return err
+		}
+	}
 	if c.Alterations {
 		if len(c.AltWordlist) == 0 {
 			c.AltWordlist, err = getWordlistByFS("/alterations.txt")
@@ -245,7 +397,16 @@ func (c *Config) LoadSettings(path string) error {
 		c.loadAlterationSettings,
 		c.loadBruteForceSettings,
 		c.loadDatabaseSettings,
+		c.loadDataHandlerSettings,
+		c.loadOutputStreamSettings,
 		c.loadDataSourceSettings,
+		c.loadRESTSourceSettings,
+		c.loadCDNSettings,
+		c.loadOutputSettings,
+		c.loadZoneWalkSettings,
+		c.loadNetblockSweepSettings,
+		c.loadSRVSettings,
+		c.loadRecordPrioritySettings,
 	}
 	for _, load := range loads {
 		if err := load(cfg); err != nil {
@@ -331,6 +492,11 @@ func GetListFromFile(path string) ([]string, error) {
 	return s, err
 }
 
+// DefaultWordlist returns the wordlist used for DNS brute forcing when a wordlist has not been provided.
+func DefaultWordlist() ([]string, error) {
+	return getWordlistByFS("/namelist.txt")
+}
+
 func getWordlistByFS(path string) ([]string, error) {
 	fsOnce.Do(openTheFS)
 
@@ -5,6 +5,7 @@ package config
 
 import (
 	"net"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
@@ -103,6 +104,23 @@ func TestBlacklist(t *testing.T) {
 	}
 }
 
+func TestMeetsMinimumLabelLength(t *testing.T) {
+	c := NewConfig()
+	c.AddDomain("owasp.org")
+
+	if !c.MeetsMinimumLabelLength("a.owasp.org") {
+		t.Errorf("Names were filtered without a minimum label length being configured")
+	}
+
+	c.MinLabelLength = 2
+	if c.MeetsMinimumLabelLength("a.owasp.org") || c.MeetsMinimumLabelLength("www.b.owasp.org") {
+		t.Errorf("Failed to filter a name with a label shorter than the minimum length")
+	}
+	if !c.MeetsMinimumLabelLength("www.owasp.org") || !c.MeetsMinimumLabelLength("owasp.org") {
+		t.Errorf("A name meeting the minimum label length was filtered")
+	}
+}
+
 func TestLoadSettings(t *testing.T) {
 	c := NewConfig()
 	path := "../examples/config.ini"
@@ -110,3 +128,17 @@ func TestLoadSettings(t *testing.T) {
 		t.Errorf("Config file failed to load.")
 	}
 }
+
+func TestEnumGraphDirectory(t *testing.T) {
+	c := NewConfig()
+	c.Dir = t.TempDir()
+
+	if dir := c.EnumGraphDirectory(); dir != "" {
+		t.Errorf("An enumeration graph directory was returned without the persistent graph enabled: %s", dir)
+	}
+
+	c.PersistentGraph = true
+	if dir := c.EnumGraphDirectory(); dir != filepath.Join(c.Dir, "enum_graphs") {
+		t.Errorf("The enumeration graph directory was not within the output directory: %s", dir)
+	}
+}
@@ -4,9 +4,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/caffix/stringset"
 	"github.com/go-ini/ini"
@@ -14,18 +17,40 @@ import (
 
 // DataSourceConfig contains the configurations specific to a data source.
 type DataSourceConfig struct {
-	Name  string
-	TTL   int `ini:"ttl"`
-	creds map[string]*Credentials
+	Name        string
+	TTL         int    `ini:"ttl"`
+	TimeBudget  int    `ini:"time_budget"`
+	Timeout     int    `ini:"timeout"`
+	RateLimit   int    `ini:"rate_limit"`
+	MaxRequests int    `ini:"max_requests"`
+	Proxy       string `ini:"proxy"`
+	UserAgent   string `ini:"user_agent"`
+	Database    bool   `ini:"database"`
+	// The server hosting the dataset of the data source, e.g. a dnsgrep server for FDNS
+	URL string `ini:"url"`
+	// The local copy of the dataset used by the data source
+	Dataset string `ini:"dataset"`
+	creds   map[string]*Credentials
 }
 
 // Credentials contains values required for authenticating with web APIs.
 type Credentials struct {
-	Name     string
-	Username string `ini:"username"`
-	Password string `ini:"password"`
-	Key      string `ini:"apikey"`
-	Secret   string `ini:"secret"`
+	Name         string
+	Username     string `ini:"username"`
+	Password     string `ini:"password"`
+	Key          string `ini:"apikey"`
+	Secret       string `ini:"secret"`
+	ClientID     string `ini:"client_id"`
+	Tenant       string `ini:"tenant_id"`
+	Subscription string `ini:"subscription_id"`
+	Project      string `ini:"project"`
+	KeyFile      string `ini:"key_file"`
+}
+
+// empty returns true when none of the values were provided with the Credentials.
+func (cr *Credentials) empty() bool {
+	return cr.Username == "" && cr.Password == "" && cr.Key == "" && cr.Secret == "" &&
+		cr.ClientID == "" && cr.Tenant == "" && cr.Subscription == "" && cr.Project == "" && cr.KeyFile == ""
 }
 
 // GetDataSourceConfig returns the DataSourceConfig associated with the data source name argument.
@@ -75,6 +100,117 @@ func (dsc *DataSourceConfig) GetCredentials() *Credentials {
 	return nil
 }
 
+// APIKey returns randomly selected Credentials configured for the named data source.
+// Nil is returned when the data source has no Credentials with values provided.
+func (c *Config) APIKey(source string) *Credentials {
+	dsc := c.GetDataSourceConfig(source)
+	if dsc == nil {
+		return nil
+	}
+
+	var creds []*Credentials
+	for _, cr := range dsc.AllCredentials() {
+		if !cr.empty() {
+			creds = append(creds, cr)
+		}
+	}
+
+	if num := len(creds); num > 0 {
+		return creds[rand.Intn(num)]
+	}
+	return nil
+}
+
+// SourceTimeBudget returns the soft time budget for the named data source, or zero when no budget applies.
+func (c *Config) SourceTimeBudget(source string) time.Duration {
+	budget := c.TimeBudget
+
+	if dsc := c.GetDataSourceConfig(source); dsc != nil && dsc.TimeBudget > 0 {
+		budget = dsc.TimeBudget
+	}
+	return time.Duration(budget) * time.Minute
+}
+
+// SourceRequestTimeout returns the deadline for each request sent by the named data source, or zero when none applies.
+func (c *Config) SourceRequestTimeout(source string) time.Duration {
+	timeout := c.RequestTimeout
+
+	if dsc := c.GetDataSourceConfig(source); dsc != nil && dsc.Timeout > 0 {
+		timeout = dsc.Timeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// SourceRequestInterval returns the minimum time between requests sent by the named data source.
+// Zero is returned when no rate limit has been configured for the data source.
+func (c *Config) SourceRequestInterval(source string) time.Duration {
+	if dsc := c.GetDataSourceConfig(source); dsc != nil && dsc.RateLimit > 0 {
+		return time.Minute / time.Duration(dsc.RateLimit)
+	}
+	return 0
+}
+
+// SourceMaxRequests returns the maximum number of requests the named data source can send, or zero when unlimited.
+func (c *Config) SourceMaxRequests(source string) int {
+	if dsc := c.GetDataSourceConfig(source); dsc != nil && dsc.MaxRequests > 0 {
+		return dsc.MaxRequests
+	}
+	return 0
+}
+
+// SourceProxy returns the URL of the proxy used for the named data source requests, or nil when none was configured.
+func (c *Config) SourceProxy(source string) *url.URL {
+	proxy := c.Proxy
+
+	if dsc := c.GetDataSourceConfig(source); dsc != nil && dsc.Proxy != "" {
+		proxy = dsc.Proxy
+	}
+	if proxy == "" {
+		return nil
+	}
+
+	u, err := parseProxyURL(proxy)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(proxy))
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("The proxy scheme %q is not supported", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("The proxy host was not provided")
+	}
+	return u, nil
+}
+
+// SourceUserAgent returns the user agent configured for the named data source, or an empty string when none was provided.
+func (c *Config) SourceUserAgent(source string) string {
+	if dsc := c.GetDataSourceConfig(source); dsc != nil {
+		return strings.TrimSpace(dsc.UserAgent)
+	}
+	return ""
+}
+
+// AllCredentials returns every set of Credentials associated with the receiver configuration.
+func (dsc *DataSourceConfig) AllCredentials() []*Credentials {
+	var creds []*Credentials
+
+	for _, c := range dsc.creds {
+		creds = append(creds, c)
+	}
+	return creds
+}
+
 func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 	sec, err := cfg.GetSection("data_sources")
 	if err != nil {
@@ -87,19 +223,73 @@ func (c *Config) loadDataSourceSettings(cfg *ini.File) error {
 		}
 	}
 
+	if sec.HasKey("time_budget") {
+		if budget, err := sec.Key("time_budget").Int(); err == nil {
+			c.TimeBudget = budget
+		}
+	}
+
+	if sec.HasKey("timeout") {
+		if timeout, err := sec.Key("timeout").Int(); err == nil {
+			c.RequestTimeout = timeout
+		}
+	}
+
+	if sec.HasKey("max_failures") {
+		if max, err := sec.Key("max_failures").Int(); err == nil {
+			c.SourceMaxFailures = max
+		}
+	}
+
+	if sec.HasKey("archive_directory") {
+		c.ArchiveDirectory = sec.Key("archive_directory").String()
+	}
+
+	if sec.HasKey("proxy") {
+		c.Proxy = strings.TrimSpace(sec.Key("proxy").String())
+	}
+
+	if sec.HasKey("response_cache") {
+		if cache, err := sec.Key("response_cache").Bool(); err == nil {
+			c.ResponseCache = cache
+		}
+	}
+
+	if sec.HasKey("store_url_paths") {
+		if store, err := sec.Key("store_url_paths").Bool(); err == nil {
+			c.StoreURLPaths = store
+		}
+	}
+
+	if c.Proxy != "" {
+		if _, err := parseProxyURL(c.Proxy); err != nil {
+			return fmt.Errorf("The data source proxy is invalid: %v", err)
+		}
+	}
+
+	var filter string
 	for _, child := range sec.ChildSections() {
 		name := strings.Split(child.Name(), ".")[1]
 
-		if name == "disabled" {
-			// Load up all the disabled data source names
+		if name == "disabled" || name == "included" {
+			if filter != "" && filter != name {
+				return errors.New("The data_sources.disabled and data_sources.included sections cannot both be provided")
+			}
+			filter = name
+			// Load up all the disabled or exclusively included data source names
 			c.SourceFilter.Sources = stringset.Deduplicate(child.Key("data_source").ValueWithShadows())
-			c.SourceFilter.Include = false
+			c.SourceFilter.Include = name == "included"
 			continue
 		}
 
 		dsc := c.GetDataSourceConfig(name)
 		// Parse the Database information and assign to the Config
 		child.MapTo(dsc)
+		if dsc.Proxy != "" {
+			if _, err := parseProxyURL(dsc.Proxy); err != nil {
+				return fmt.Errorf("The %s data source proxy is invalid: %v", name, err)
+			}
+		}
 		if c.MinimumTTL > dsc.TTL {
 			dsc.TTL = c.MinimumTTL
 		}
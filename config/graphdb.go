@@ -4,6 +4,7 @@
 package config
 
 import (
+	"path/filepath"
 	"strings"
 
 	"github.com/go-ini/ini"
@@ -68,3 +69,17 @@ func (c *Config) LocalDatabaseSettings(dbs []*Database) *Database {
 
 	return bolt
 }
+
+// EnumGraphDirectory returns the directory that keeps the embedded stores of the enumeration
+// graphs, or an empty string when the enumeration graphs are kept in memory.
+func (c *Config) EnumGraphDirectory() string {
+	if !c.PersistentGraph {
+		return ""
+	}
+
+	dir := OutputDirectory(c.Dir)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "enum_graphs")
+}
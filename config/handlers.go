@@ -0,0 +1,67 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+func (c *Config) loadDataHandlerSettings(cfg *ini.File) error {
+	dbs, err := childDatabases(cfg, "data_handlers", "data handler")
+	if err != nil {
+		return err
+	}
+	c.DataHandlers = append(c.DataHandlers, dbs...)
+
+	// The gremlin section is the original location of the Gremlin Server settings
+	if sec, err := cfg.GetSection("gremlin"); err == nil && sec.HasKey("url") {
+		db := &Database{System: "gremlin"}
+
+		if err := sec.MapTo(db); err != nil {
+			return fmt.Errorf("Failed to load the gremlin settings: %v", err)
+		}
+		c.DataHandlers = append(c.DataHandlers, db)
+	}
+	return nil
+}
+
+func (c *Config) loadOutputStreamSettings(cfg *ini.File) error {
+	dbs, err := childDatabases(cfg, "output_streams", "output stream")
+	if err != nil {
+		return err
+	}
+
+	c.OutputStreams = append(c.OutputStreams, dbs...)
+	return nil
+}
+
+// childDatabases returns the settings from the child sections of the parent, e.g. [data_handlers.neo4j],
+// where the name of the child section identifies the database system.
+func childDatabases(cfg *ini.File, parent, desc string) ([]*Database, error) {
+	var dbs []*Database
+
+	for _, child := range cfg.Sections() {
+		// The parent section is not required for the declarations
+		if !strings.HasPrefix(child.Name(), parent+".") {
+			continue
+		}
+
+		db := new(Database)
+		name := strings.SplitN(child.Name(), ".", 2)[1]
+
+		if err := child.MapTo(db); err != nil {
+			return nil, fmt.Errorf("Failed to load the %s %s settings: %v", name, desc, err)
+		}
+		if db.URL == "" {
+			return nil, fmt.Errorf("The %s %s requires the url of the server", name, desc)
+		}
+
+		db.System = name
+		dbs = append(dbs, db)
+	}
+	return dbs, nil
+}
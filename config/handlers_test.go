@@ -0,0 +1,72 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadDataHandlerSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.Load([]byte(`
+		[data_handlers.neo4j]
+		url = bolt://localhost:7687
+		username = neo4j
+		password = secret
+		`))
+	if err := c.loadDataHandlerSettings(cfg); err != nil {
+		t.Fatalf("Failed to load the data handler settings: %v", err)
+	}
+	if len(c.DataHandlers) != 1 {
+		t.Fatalf("Expected one data handler, got %d", len(c.DataHandlers))
+	}
+	if db := c.DataHandlers[0]; db.System != "neo4j" || db.URL != "bolt://localhost:7687" || db.Username != "neo4j" {
+		t.Errorf("The data handler settings were not loaded: %+v", db)
+	}
+
+	bad, _ := ini.Load([]byte(`
+		[data_handlers.neo4j]
+		username = neo4j
+		`))
+	if err := NewConfig().loadDataHandlerSettings(bad); err == nil {
+		t.Errorf("loadDataHandlerSettings accepted a data handler without a url")
+	}
+
+	legacy, _ := ini.Load([]byte(`
+		[gremlin]
+		url = ws://localhost:8182/gremlin
+		username = amass
+		`))
+	c = NewConfig()
+	if err := c.loadDataHandlerSettings(legacy); err != nil || len(c.DataHandlers) != 1 || c.DataHandlers[0].System != "gremlin" {
+		t.Errorf("The gremlin section was not loaded as a data handler: %v", err)
+	}
+}
+
+func TestLoadOutputStreamSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.Load([]byte(`
+		[output_streams.kafka]
+		url = kafka://broker1:9092,broker2:9092
+		options = topic=amass.output
+		`))
+	if err := c.loadOutputStreamSettings(cfg); err != nil {
+		t.Fatalf("Failed to load the output stream settings: %v", err)
+	}
+	if len(c.OutputStreams) != 1 || c.OutputStreams[0].System != "kafka" || c.OutputStreams[0].Options != "topic=amass.output" {
+		t.Errorf("The output stream settings were not loaded: %v", c.OutputStreams)
+	}
+
+	bad, _ := ini.Load([]byte(`
+		[output_streams.nats]
+		username = amass
+		`))
+	if err := NewConfig().loadOutputStreamSettings(bad); err == nil {
+		t.Errorf("loadOutputStreamSettings accepted an output stream without a url")
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/go-ini/ini"
+)
+
+func (c *Config) loadOutputSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("output")
+	if err != nil {
+		return nil
+	}
+
+	text := sec.Key("template").String()
+	if sec.HasKey("template_file") {
+		data, err := ioutil.ReadFile(sec.Key("template_file").String())
+		if err != nil {
+			return fmt.Errorf("Unable to load the file in the output template_file setting: %v", err)
+		}
+		text = string(data)
+	}
+	if text != "" {
+		c.OutputTemplate = text
+	}
+
+	c.TemplateOutput = sec.Key("template_output").String()
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadOutputSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[output]
+		template = {{.Name}},{{range .Addresses}}{{.Address}} {{end}}
+		template_output = out.txt
+		`),
+	)
+
+	if err := c.loadOutputSettings(cfg); err != nil {
+		t.Errorf("Failed to load a valid output template: %v", err)
+	}
+	// The template is only validated by the format package when the output is set up
+	if c.OutputTemplate != "{{.Name}},{{range .Addresses}}{{.Address}} {{end}}" || c.TemplateOutput != "out.txt" {
+		t.Errorf("The output settings were not correctly assigned to the configuration")
+	}
+}
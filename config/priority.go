@@ -0,0 +1,78 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+// The priority levels assigned to DNS record types during name resolution.
+const (
+	RecordPriorityLow int = iota
+	RecordPriorityNormal
+	RecordPriorityHigh
+	RecordPriorityCritical
+)
+
+var recordPriorityNames = map[string]int{
+	"low":      RecordPriorityLow,
+	"normal":   RecordPriorityNormal,
+	"high":     RecordPriorityHigh,
+	"critical": RecordPriorityCritical,
+}
+
+// DefaultRecordPriorities favors the record types that drive infrastructure discovery.
+var DefaultRecordPriorities = map[string]int{
+	"A":     RecordPriorityHigh,
+	"AAAA":  RecordPriorityHigh,
+	"CNAME": RecordPriorityHigh,
+	"NS":    RecordPriorityHigh,
+	"MX":    RecordPriorityNormal,
+	"SOA":   RecordPriorityNormal,
+	"SRV":   RecordPriorityNormal,
+	"PTR":   RecordPriorityLow,
+	"SPF":   RecordPriorityLow,
+	"TXT":   RecordPriorityLow,
+}
+
+func copyRecordPriorities(priorities map[string]int) map[string]int {
+	results := make(map[string]int, len(priorities))
+
+	for rtype, p := range priorities {
+		results[rtype] = p
+	}
+	return results
+}
+
+// RecordPriority returns the resolution priority level for the DNS record type.
+// Record types without an assigned priority are resolved with the low priority level.
+func (c *Config) RecordPriority(rtype string) int {
+	c.Lock()
+	defer c.Unlock()
+
+	if p, found := c.RecordPriorities[strings.ToUpper(rtype)]; found {
+		return p
+	}
+	return RecordPriorityLow
+}
+
+func (c *Config) loadRecordPrioritySettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("record_priorities")
+	if err != nil {
+		return nil
+	}
+
+	for _, key := range sec.Keys() {
+		p, found := recordPriorityNames[strings.ToLower(strings.TrimSpace(key.String()))]
+		if !found {
+			return fmt.Errorf("Invalid priority provided for the %s record type: %s", key.Name(), key.String())
+		}
+
+		c.RecordPriorities[strings.ToUpper(key.Name())] = p
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadRecordPrioritySettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[record_priorities]
+		txt = critical
+		aaaa = low
+		`),
+	)
+
+	if err := c.loadRecordPrioritySettings(cfg); err != nil {
+		t.Errorf("Failed to load the record priority settings: %v", err)
+	}
+	if p := c.RecordPriority("TXT"); p != RecordPriorityCritical {
+		t.Errorf("The TXT record type was assigned priority %d instead of critical", p)
+	}
+	if p := c.RecordPriority("aaaa"); p != RecordPriorityLow {
+		t.Errorf("The AAAA record type was assigned priority %d instead of low", p)
+	}
+	if p := c.RecordPriority("A"); p != RecordPriorityHigh {
+		t.Errorf("The A record type lost the default high priority")
+	}
+
+	bad, _ := ini.LoadSources(ini.LoadOptions{Insensitive: true}, []byte(`
+		[record_priorities]
+		a = urgent
+		`))
+	if err := c.loadRecordPrioritySettings(bad); err == nil {
+		t.Errorf("loadRecordPrioritySettings accepted an invalid priority level")
+	}
+}
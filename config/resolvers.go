@@ -9,8 +9,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	amasshttp "github.com/OWASP/Amass/v3/net/http"
 	"github.com/caffix/stringset"
@@ -65,10 +68,24 @@ loop:
 }
 
 // SetResolvers assigns the resolver names provided in the parameter to the list in the configuration.
+// Resolvers provided without a rate keep the rate previously assigned to them.
 func (c *Config) SetResolvers(resolvers ...string) {
+	prev := c.resolverRates
 	c.Resolvers = []string{}
+	c.resolverRates = nil
 
 	c.AddResolvers(resolvers...)
+	for _, r := range c.Resolvers {
+		if _, found := c.resolverRates[r]; found {
+			continue
+		}
+		if rate, found := prev[r]; found {
+			if c.resolverRates == nil {
+				c.resolverRates = make(map[string]int)
+			}
+			c.resolverRates[r] = rate
+		}
+	}
 }
 
 // AddResolvers appends the resolver names provided in the parameter to the list in the configuration.
@@ -79,18 +96,145 @@ func (c *Config) AddResolvers(resolvers ...string) {
 }
 
 // AddResolver appends the resolver name provided in the parameter to the list in the configuration.
+// The resolver can be followed by the number of queries per second to be sent, e.g. "8.8.8.8 50".
 func (c *Config) AddResolver(resolver string) {
 	c.Lock()
 	defer c.Unlock()
 
-	// Check that the domain string is not empty
-	r := strings.TrimSpace(resolver)
-	if r == "" {
+	r, rate, err := parseResolverEntry(resolver)
+	// Check that the resolver string is not empty
+	if err != nil || r == "" {
 		return
 	}
 
-	c.Resolvers = stringset.Deduplicate(append(c.Resolvers, resolver))
-	c.calcDNSQueriesMax()
+	// Keep the order the resolvers were provided in
+	var found bool
+	for _, existing := range c.Resolvers {
+		if existing == r {
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.Resolvers = append(c.Resolvers, r)
+	}
+
+	if rate > 0 {
+		if c.resolverRates == nil {
+			c.resolverRates = make(map[string]int)
+		}
+		c.resolverRates[r] = rate
+	}
+}
+
+// ResolverRates returns the number of queries per second to be sent to each resolver in the configuration.
+// The rates provided for specific resolvers are honored within the maximum number of DNS queries,
+// and the remaining queries are shared evenly by the other resolvers.
+func (c *Config) ResolverRates() map[string]int {
+	c.Lock()
+	defer c.Unlock()
+
+	rates := make(map[string]int)
+	if len(c.Resolvers) == 0 {
+		return rates
+	}
+
+	var specified, unrated int
+	for _, r := range c.Resolvers {
+		if qps, found := c.resolverRates[r]; found {
+			specified += qps
+		} else {
+			unrated++
+		}
+	}
+
+	max := c.MaxDNSQueries
+	if max == 0 {
+		max = specified + (unrated * DefaultQueriesPerBaselineResolver)
+	} else if max < len(c.Resolvers) {
+		max = len(c.Resolvers)
+	}
+
+	scale := 1.0
+	remaining := max - specified
+	// The provided rates are reduced when they exceed the maximum
+	if remaining < unrated {
+		scale = float64(max-unrated) / float64(specified)
+		remaining = unrated
+	}
+
+	var share int
+	if unrated > 0 {
+		share = remaining / unrated
+	}
+
+	for _, r := range c.Resolvers {
+		rate := share
+		if qps, found := c.resolverRates[r]; found {
+			rate = int(float64(qps) * scale)
+		}
+		if rate < 1 {
+			rate = 1
+		}
+		rates[r] = rate
+	}
+	return rates
+}
+
+// DNSCacheFile returns the path of the file where the DNS responses are kept between enumerations,
+// or an empty string when the cached responses are not persisted.
+func (c *Config) DNSCacheFile() string {
+	if !c.DNSCache || !c.PersistDNSCache {
+		return ""
+	}
+
+	dir := OutputDirectory(c.Dir)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "dns_cache.json")
+}
+
+// parseCanaryEntries extracts the names and known addresses from entries such as "a.root-servers.net 198.41.0.4".
+func parseCanaryEntries(entries []string) (map[string][]string, error) {
+	records := make(map[string][]string)
+
+	for _, entry := range entries {
+		fields := strings.Fields(strings.ReplaceAll(entry, ",", " "))
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("The canary entry %s must provide the name and known addresses", entry)
+		}
+
+		name := strings.ToLower(fields[0])
+		for _, addr := range fields[1:] {
+			if net.ParseIP(addr) == nil {
+				return nil, fmt.Errorf("The canary entry %s has an invalid address", entry)
+			}
+			records[name] = append(records[name], addr)
+		}
+	}
+	return records, nil
+}
+
+// parseResolverEntry extracts the resolver address and the optional queries per second from the entry.
+func parseResolverEntry(entry string) (string, int, error) {
+	fields := strings.Fields(strings.ReplaceAll(entry, ",", " "))
+	if len(fields) == 0 {
+		return "", 0, nil
+	}
+	if len(fields) > 2 {
+		return "", 0, fmt.Errorf("The resolver entry %s has too many fields", entry)
+	}
+
+	var rate int
+	if len(fields) == 2 {
+		r, err := strconv.Atoi(fields[1])
+		if err != nil || r < 1 {
+			return "", 0, fmt.Errorf("The resolver entry %s has an invalid queries per second value", entry)
+		}
+		rate = r
+	}
+	return fields[0], rate, nil
 }
 
 func (c *Config) loadResolverSettings(cfg *ini.File) error {
@@ -99,19 +243,74 @@ func (c *Config) loadResolverSettings(cfg *ini.File) error {
 		return nil
 	}
 
-	c.Resolvers = stringset.Deduplicate(sec.Key("resolver").ValueWithShadows())
+	c.Resolvers = []string{}
+	c.resolverRates = nil
+	for _, entry := range sec.Key("resolver").ValueWithShadows() {
+		if _, _, err := parseResolverEntry(entry); err != nil {
+			return err
+		}
+
+		c.AddResolver(entry)
+	}
 	if len(c.Resolvers) == 0 {
 		return errors.New("No resolver keys were found in the resolvers section")
 	}
 
 	c.MonitorResolverRate = sec.Key("monitor_resolver_rate").MustBool(true)
+	c.ScoreResolvers = sec.Key("score_resolvers").MustBool(true)
+	c.DNSCache = sec.Key("dns_cache").MustBool(true)
+	c.PersistDNSCache = sec.Key("persist_dns_cache").MustBool(false)
+	if sec.HasKey("negative_cache_ttl") {
+		ttl, err := sec.Key("negative_cache_ttl").Int()
+		if err != nil || ttl < 0 {
+			return errors.New("The negative_cache_ttl must be a number of seconds that is zero or greater")
+		}
+		c.NegativeCacheTTL = time.Duration(ttl) * time.Second
+	}
+	if sec.HasKey("dns_cache_size") {
+		size, err := sec.Key("dns_cache_size").Int()
+		if err != nil || size < 0 {
+			return errors.New("The dns_cache_size must be a number of responses that is zero or greater")
+		}
+		c.DNSCacheSize = size
+	}
+	if sec.HasKey("retries") {
+		retries, err := sec.Key("retries").Int()
+		if err != nil || retries < 0 {
+			return errors.New("The retries setting must be a number that is zero or greater")
+		}
+		c.QueryRetries = retries
+	}
+	if sec.HasKey("query_timeout") {
+		timeout, err := sec.Key("query_timeout").Int()
+		if err != nil || timeout <= 0 {
+			return errors.New("The query_timeout must be a number of milliseconds greater than zero")
+		}
+		c.QueryTimeout = time.Duration(timeout) * time.Millisecond
+	}
+	if sec.HasKey("canary") {
+		records, err := parseCanaryEntries(sec.Key("canary").ValueWithShadows())
+		if err != nil {
+			return err
+		}
+		c.CanaryRecords = records
+	}
+	if sec.HasKey("client_subnet") {
+		subnet := strings.TrimSpace(sec.Key("client_subnet").String())
+		if _, _, err := net.ParseCIDR(subnet); subnet != "" && err != nil {
+			return fmt.Errorf("The client_subnet %s is not a valid netblock", subnet)
+		}
+		c.ClientSubnet = subnet
+	}
+	if sec.HasKey("trusted_resolver") {
+		c.TrustedResolvers = stringset.Deduplicate(sec.Key("trusted_resolver").ValueWithShadows())
+	}
+	if sec.HasKey("internal_resolver") {
+		c.InternalResolvers = stringset.Deduplicate(sec.Key("internal_resolver").ValueWithShadows())
+	}
 	return nil
 }
 
-func (c *Config) calcDNSQueriesMax() {
-	c.MaxDNSQueries = len(c.Resolvers) * DefaultQueriesPerBaselineResolver
-}
-
 func getPublicDNSResolvers() ([]string, error) {
 	url := "https://public-dns.info/nameservers-all.csv"
 	page, err := amasshttp.RequestWebPage(context.Background(), url, nil, nil, nil)
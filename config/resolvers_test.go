@@ -0,0 +1,111 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadResolverSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[resolvers]
+		resolver = 8.8.8.8 50 ; Google
+		resolver = 1.1.1.1
+		trusted_resolver = 9.9.9.9
+		score_resolvers = false
+		client_subnet = 203.0.113.0/24
+		canary = a.root-servers.net 198.41.0.4
+		canary = dns.google 8.8.8.8,8.8.4.4
+		retries = 5
+		query_timeout = 1500
+		negative_cache_ttl = 60
+		dns_cache_size = 5000
+		`),
+	)
+
+	if err := c.loadResolverSettings(cfg); err != nil {
+		t.Errorf("Failed to load the resolver settings: %v", err)
+	}
+	if len(c.Resolvers) != 2 || c.Resolvers[0] != "8.8.8.8" {
+		t.Errorf("The resolver addresses were not extracted from the entries: %v", c.Resolvers)
+	}
+	if len(c.TrustedResolvers) != 1 || c.TrustedResolvers[0] != "9.9.9.9" {
+		t.Errorf("The trusted resolvers were not loaded: %v", c.TrustedResolvers)
+	}
+	if c.ScoreResolvers {
+		t.Errorf("The score_resolvers setting was ignored")
+	}
+	if len(c.CanaryRecords) != 2 || len(c.CanaryRecords["dns.google"]) != 2 {
+		t.Errorf("The canary records were not loaded: %v", c.CanaryRecords)
+	}
+	if c.QueryRetries != 5 || c.QueryTimeout != 1500*time.Millisecond || c.NegativeCacheTTL != time.Minute {
+		t.Errorf("The retry, timeout and negative caching settings were not loaded")
+	}
+	if c.DNSCacheSize != 5000 {
+		t.Errorf("The dns_cache_size setting was not loaded: %d", c.DNSCacheSize)
+	}
+	if c.ClientSubnet != "203.0.113.0/24" {
+		t.Errorf("The client_subnet setting was not loaded: %s", c.ClientSubnet)
+	}
+
+	rates := c.ResolverRates()
+	if rates["8.8.8.8"] != 50 || rates["1.1.1.1"] != DefaultQueriesPerBaselineResolver {
+		t.Errorf("The resolver rates were not assigned as expected: %v", rates)
+	}
+	// The command-line tools provide the configured resolvers again without the rates
+	c.SetResolvers(c.Resolvers...)
+	if rates := c.ResolverRates(); rates["8.8.8.8"] != 50 {
+		t.Errorf("SetResolvers discarded the rate provided for the resolver: %v", rates)
+	}
+
+	bad, _ := ini.LoadSources(ini.LoadOptions{AllowShadows: true}, []byte(`
+		[resolvers]
+		resolver = 8.8.8.8 fast
+		`))
+	if err := c.loadResolverSettings(bad); err == nil {
+		t.Errorf("loadResolverSettings accepted an invalid queries per second value")
+	}
+
+	bad, _ = ini.LoadSources(ini.LoadOptions{AllowShadows: true}, []byte(`
+		[resolvers]
+		resolver = 8.8.8.8
+		query_timeout = 0
+		`))
+	if err := c.loadResolverSettings(bad); err == nil {
+		t.Errorf("loadResolverSettings accepted a query timeout of zero")
+	}
+}
+
+func TestResolverRates(t *testing.T) {
+	c := NewConfig()
+	c.SetResolvers("8.8.8.8 60", "1.1.1.1,40", "9.9.9.9", "64.6.64.6")
+
+	// The remaining queries are shared by the resolvers without a rate
+	c.MaxDNSQueries = 200
+	rates := c.ResolverRates()
+	if rates["8.8.8.8"] != 60 || rates["1.1.1.1"] != 40 || rates["9.9.9.9"] != 50 || rates["64.6.64.6"] != 50 {
+		t.Errorf("The resolver rates did not honor the maximum: %v", rates)
+	}
+
+	// The provided rates are reduced to remain within the maximum
+	c.MaxDNSQueries = 52
+	rates = c.ResolverRates()
+	var total int
+	for _, rate := range rates {
+		total += rate
+	}
+	if total > 52 || rates["9.9.9.9"] < 1 || rates["8.8.8.8"] <= rates["1.1.1.1"] {
+		t.Errorf("The resolver rates exceeded the maximum: %v", rates)
+	}
+}
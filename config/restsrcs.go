@@ -0,0 +1,110 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/go-ini/ini"
+	"gopkg.in/yaml.v2"
+)
+
+// RESTSourceConfig declares a data source that sends GET requests to a REST API and extracts
+// the names from the JSON responses, so new API sources can be added without new code.
+type RESTSourceConfig struct {
+	Name string `yaml:"name"`
+	// The URL template supports the {domain}, {key}, {username}, {page} and {next} variables
+	URL string `yaml:"url"`
+	// The header used to provide the API key, e.g. X-API-Key
+	AuthHeader string `yaml:"auth_header"`
+	// The JSON paths to the names, e.g. data.*.hostname
+	Names []string `yaml:"names"`
+	// The JSON path to the token or URL for the next page
+	NextPath string `yaml:"next"`
+	// The URL template for the following pages, which defaults to the URL template
+	NextURL string `yaml:"next_url"`
+	// Labels in the response are appended to the domain name when true
+	AppendDomain bool `yaml:"append_domain"`
+	MaxPages     int  `yaml:"max_pages"`
+	RateLimit    int  `yaml:"rate_limit"`
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface, so the defaults apply to the omitted settings.
+func (rs *RESTSourceConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain RESTSourceConfig
+
+	p := plain{
+		MaxPages:  10,
+		RateLimit: 1,
+	}
+	if err := unmarshal(&p); err != nil {
+		return err
+	}
+
+	*rs = RESTSourceConfig(p)
+	return nil
+}
+
+func (c *Config) loadRESTSourceSettings(cfg *ini.File) error {
+	if c.RESTSourcesFile == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(c.RESTSourcesFile)
+	if err != nil {
+		return fmt.Errorf("Failed to read the REST sources file: %v", err)
+	}
+	return c.loadRESTSources(data)
+}
+
+func (c *Config) loadRESTSources(data []byte) error {
+	var decls struct {
+		Sources []*RESTSourceConfig `yaml:"sources"`
+	}
+
+	if err := yaml.UnmarshalStrict(data, &decls); err != nil {
+		return fmt.Errorf("Error parsing the REST source declarations: %v", err)
+	}
+
+	names := make(map[string]struct{})
+	for _, rs := range decls.Sources {
+		rs.Name = strings.TrimSpace(rs.Name)
+		if rs.Name == "" {
+			return fmt.Errorf("A REST source was declared without a name")
+		}
+
+		key := strings.ToLower(rs.Name)
+		if _, found := names[key]; found {
+			return fmt.Errorf("The %s REST source was declared more than once", rs.Name)
+		}
+		names[key] = struct{}{}
+
+		var paths []string
+		for _, path := range rs.Names {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+		rs.Names = paths
+
+		if rs.URL == "" || len(rs.Names) == 0 {
+			return fmt.Errorf("The %s REST source requires the url and names settings", rs.Name)
+		}
+		if rs.NextURL == "" {
+			rs.NextURL = rs.URL
+		}
+		if rs.MaxPages < 1 {
+			rs.MaxPages = 1
+		}
+		if rs.RateLimit < 0 {
+			rs.RateLimit = 0
+		}
+
+		c.RESTSources = append(c.RESTSources, rs)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadRESTSources(t *testing.T) {
+	c := NewConfig()
+
+	err := c.loadRESTSources([]byte(`
+sources:
+  - name: ExampleAPI
+    url: https://api.example.com/{domain}?page={page}
+    auth_header: X-API-Key
+    names:
+      - data.*.hostname
+      - data.*.aliases
+    next: meta.cursor
+  - name: OtherAPI
+    url: https://other.example.com/{domain}
+    names: [subdomains]
+    append_domain: true
+    max_pages: 0
+    rate_limit: 5
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse the REST source declarations: %v", err)
+	}
+	if len(c.RESTSources) != 2 {
+		t.Fatalf("Failed to load the REST sources")
+	}
+
+	rs := c.RESTSources[0]
+	if rs.Name != "ExampleAPI" || rs.AuthHeader != "X-API-Key" || rs.NextPath != "meta.cursor" {
+		t.Errorf("Failed to load the REST source settings")
+	}
+	if len(rs.Names) != 2 {
+		t.Errorf("Failed to load all the name paths for the REST source")
+	}
+	if rs.NextURL != rs.URL || rs.MaxPages != 10 || rs.RateLimit != 1 {
+		t.Errorf("Failed to apply the REST source defaults")
+	}
+
+	rs = c.RESTSources[1]
+	if !rs.AppendDomain || rs.MaxPages != 1 || rs.RateLimit != 5 {
+		t.Errorf("Failed to load the provided REST source settings")
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"missing name", "sources:\n  - url: https://api.example.com/{domain}\n    names: [hosts]\n"},
+		{"missing names", "sources:\n  - name: Broken\n    url: https://api.example.com/{domain}\n"},
+		{"missing url", "sources:\n  - name: Broken\n    names: [hosts]\n"},
+		{"unknown key", "sources:\n  - name: Broken\n    url: https://api.example.com/\n    names: [hosts]\n    method: POST\n"},
+		{"duplicate", "sources:\n  - name: Dup\n    url: https://a.example.com/\n    names: [hosts]\n  - name: dup\n    url: https://b.example.com/\n    names: [hosts]\n"},
+	}
+
+	for _, test := range tests {
+		if err := NewConfig().loadRESTSources([]byte(test.data)); err == nil {
+			t.Errorf("Failed to report the REST source declaration with the %s", test.name)
+		}
+	}
+}
+
+func TestLoadRESTSourceSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restsrcs")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rest_sources.yaml")
+	data := []byte("sources:\n  - name: ExampleAPI\n    url: https://api.example.com/{domain}\n    names: [hosts]\n")
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write the REST sources file: %v", err)
+	}
+
+	c := NewConfig()
+	c.RESTSourcesFile = path
+	if err := c.loadRESTSourceSettings(ini.Empty()); err != nil {
+		t.Fatalf("Failed to load the REST sources file: %v", err)
+	}
+	if len(c.RESTSources) != 1 || c.RESTSources[0].Name != "ExampleAPI" {
+		t.Errorf("Failed to load the REST source from the file")
+	}
+
+	c = NewConfig()
+	c.RESTSourcesFile = filepath.Join(dir, "missing.yaml")
+	if err := c.loadRESTSourceSettings(ini.Empty()); err == nil {
+		t.Errorf("Failed to report the missing REST sources file")
+	}
+}
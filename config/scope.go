@@ -152,6 +152,27 @@ func (c *Config) Blacklisted(name string) bool {
 	return false
 }
 
+// MeetsMinimumLabelLength returns true when every label of the subdomain portion of
+// the name is at least as long as the configured minimum label length.
+func (c *Config) MeetsMinimumLabelLength(name string) bool {
+	if c.MinLabelLength <= 0 {
+		return true
+	}
+
+	n := strings.ToLower(strings.TrimSpace(name))
+	domain := c.WhichDomain(n)
+	if domain == "" || n == domain {
+		return true
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(n, "."+domain), ".") {
+		if len(label) < c.MinLabelLength {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *Config) loadScopeSettings(cfg *ini.File) error {
 	scope, err := cfg.GetSection("scope")
 	if err != nil {
@@ -199,6 +220,8 @@ func (c *Config) loadScopeSettings(cfg *ini.File) error {
 		}
 	}
 
+	c.MinLabelLength = scope.Key("minimum_label_length").MustInt(c.MinLabelLength)
+
 	// Load up all the blacklisted subdomain names
 	if blacklisted, err := cfg.GetSection("scope.blacklisted"); err == nil {
 		c.Blacklist = stringset.Deduplicate(blacklisted.Key("subdomain").ValueWithShadows())
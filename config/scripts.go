@@ -13,20 +13,20 @@ import (
 )
 
 // AcquireScripts returns all the default and user provided scripts for data sources.
+// The user provided scripts follow the default scripts, so they can replace data sources with the same name.
 func (c *Config) AcquireScripts() ([]string, error) {
 	scripts := getDefaultScripts()
 
-	dir := OutputDirectory(c.Dir)
-	if dir == "" {
-		return scripts, nil
-	}
-
-	finfo, err := os.Stat(dir)
-	if os.IsNotExist(err) || !finfo.IsDir() {
-		return scripts, errors.New("The output directory does not exist or is not a directory")
+	var paths []string
+	var dirErr error
+	if dir := OutputDirectory(c.Dir); dir != "" {
+		if finfo, err := os.Stat(dir); os.IsNotExist(err) || !finfo.IsDir() {
+			dirErr = errors.New("The output directory does not exist or is not a directory")
+		} else {
+			paths = append(paths, filepath.Join(dir, "scripts"))
+		}
 	}
-
-	paths := []string{filepath.Join(dir, "scripts")}
+	// The scripts directory is still used when the output directory is not available
 	if c.ScriptsDirectory != "" {
 		paths = append(paths, c.ScriptsDirectory)
 	}
@@ -51,7 +51,7 @@ func (c *Config) AcquireScripts() ([]string, error) {
 		})
 	}
 
-	return scripts, nil
+	return scripts, dirErr
 }
 
 func getDefaultScripts() []string {
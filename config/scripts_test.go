@@ -0,0 +1,36 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquireScriptsDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scripts")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	custom := "name = \"Custom\"\ntype = \"api\"\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "custom.ads"), []byte(custom), 0644); err != nil {
+		t.Fatalf("Failed to write the script: %v", err)
+	}
+
+	c := NewConfig()
+	c.Dir = filepath.Join(dir, "missing")
+	c.ScriptsDirectory = dir
+
+	scripts, err := c.AcquireScripts()
+	if err == nil {
+		t.Errorf("AcquireScripts did not report the missing output directory")
+	}
+	if len(scripts) == 0 || scripts[len(scripts)-1] != custom {
+		t.Errorf("AcquireScripts did not provide the script from the scripts directory last")
+	}
+}
@@ -1,9 +1,18 @@
 // Copyright 2017 Jeff Foley. All rights reserved.
 // Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
 
-package enum
+package config
 
-var popularSRVRecords = []string{
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffix/stringset"
+	"github.com/go-ini/ini"
+)
+
+// DefaultSRVServices is the list of popular service names queried for SRV records under each discovered name.
+var DefaultSRVServices = []string{
 	"_caldav._tcp",
 	"_caldavs._tcp",
 	"_ceph._tcp",
@@ -108,3 +117,67 @@ var popularSRVRecords = []string{
 	"_nicname._udp",
 	"_collab-edge._tls",
 }
+
+// AddSRVServices appends the service names, e.g. _ldap._tcp, queried for SRV records under each discovered name.
+func (c *Config) AddSRVServices(services ...string) error {
+	for _, s := range services {
+		if s = strings.ToLower(strings.TrimSpace(s)); s == "" {
+			continue
+		}
+		if !validSRVService(s) {
+			return fmt.Errorf("The SRV service name %s is not valid", s)
+		}
+
+		c.SRVServices = stringset.Deduplicate(append(c.SRVServices, s))
+	}
+	return nil
+}
+
+// validSRVService checks that the name begins with the underscore labels of the service and protocol.
+func validSRVService(name string) bool {
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return false
+	}
+
+	for i, label := range labels {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		if i < 2 && (len(label) < 2 || !strings.HasPrefix(label, "_")) {
+			return false
+		}
+		if strings.Trim(label, "_-abcdefghijklmnopqrstuvwxyz0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) loadSRVSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("srv_services")
+	if err != nil {
+		return nil
+	}
+
+	if !sec.Key("include_defaults").MustBool(true) {
+		c.SRVServices = []string{}
+	}
+	if sec.HasKey("service") {
+		if err := c.AddSRVServices(sec.Key("service").ValueWithShadows()...); err != nil {
+			return err
+		}
+	}
+	if sec.HasKey("service_file") {
+		for _, path := range sec.Key("service_file").ValueWithShadows() {
+			list, err := GetListFromFile(path)
+			if err != nil {
+				return fmt.Errorf("Failed to load the SRV service names file: %v", err)
+			}
+			if err := c.AddSRVServices(list...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/caffix/stringset"
+	"github.com/go-ini/ini"
+)
+
+func TestLoadSRVSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "services.txt")
+	if err := ioutil.WriteFile(path, []byte("_kerberos._tcp\n_Collab._tcp\n"), 0644); err != nil {
+		t.Fatalf("Failed to write the service names file: %v", err)
+	}
+
+	c := NewConfig()
+	if len(c.SRVServices) != len(DefaultSRVServices) {
+		t.Errorf("The default SRV service names were not assigned")
+	}
+
+	cfg, _ := ini.LoadSources(ini.LoadOptions{AllowShadows: true}, []byte(`
+		[srv_services]
+		include_defaults = false
+		service = _ldap._tcp.dc._msdcs
+		service_file = `+path+`
+		`))
+	if err := c.loadSRVSettings(cfg); err != nil {
+		t.Errorf("Failed to load the SRV settings: %v", err)
+	}
+
+	expected := stringset.New("_ldap._tcp.dc._msdcs", "_kerberos._tcp", "_collab._tcp")
+	got := stringset.New(c.SRVServices...)
+	if len(c.SRVServices) != expected.Len() || got.Len() != expected.Len() {
+		t.Errorf("The SRV service names were not loaded: %v", c.SRVServices)
+	}
+	got.Subtract(expected)
+	if got.Len() != 0 {
+		t.Errorf("Unexpected SRV service names were loaded: %v", got.Slice())
+	}
+
+	for _, bad := range []string{"ldap._tcp", "_ldap", "_ldap._tcp.bad name", "_._tcp"} {
+		if err := c.AddSRVServices(bad); err == nil {
+			t.Errorf("The invalid SRV service name %s was accepted", bad)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"github.com/go-ini/ini"
+)
+
+func (c *Config) loadNetblockSweepSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("netblock_sweeps")
+	if err != nil {
+		return nil
+	}
+
+	c.EnableNetblockSweeps = sec.Key("enabled").MustBool(false)
+	c.NetblockSweepMaxSize = sec.Key("maximum_size").MustInt(c.NetblockSweepMaxSize)
+	c.NetblockSweepRate = sec.Key("queries_per_second").MustInt(c.NetblockSweepRate)
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadNetblockSweepSettings(t *testing.T) {
+	c := NewConfig()
+
+	cfg, _ := ini.Load([]byte("[netblock_sweeps]\nmaximum_size = 256\n"))
+	if err := c.loadNetblockSweepSettings(cfg); err != nil {
+		t.Fatalf("Failed to load the netblock sweep settings: %v", err)
+	}
+	if c.EnableNetblockSweeps {
+		t.Errorf("The netblock sweeps were enabled without the enabled setting")
+	}
+	if c.NetblockSweepMaxSize != 256 {
+		t.Errorf("The maximum_size setting was not loaded: %d", c.NetblockSweepMaxSize)
+	}
+}
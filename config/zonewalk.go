@@ -0,0 +1,22 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"github.com/go-ini/ini"
+)
+
+func (c *Config) loadZoneWalkSettings(cfg *ini.File) error {
+	sec, err := cfg.GetSection("zone_walk")
+	if err != nil {
+		return nil
+	}
+
+	c.EnableZoneWalk = sec.Key("enabled").MustBool(false)
+	c.ZoneWalkNSEC = sec.Key("nsec").MustBool(true)
+	c.ZoneWalkNSEC3 = sec.Key("nsec3").MustBool(true)
+	c.ZoneWalkMaxNames = sec.Key("maximum_names").MustInt(c.ZoneWalkMaxNames)
+	c.ZoneWalkMaxQueries = sec.Key("maximum_queries").MustInt(c.ZoneWalkMaxQueries)
+	return nil
+}
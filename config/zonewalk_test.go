@@ -0,0 +1,40 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-ini/ini"
+)
+
+func TestLoadZoneWalkSettings(t *testing.T) {
+	c := NewConfig()
+	cfg, _ := ini.LoadSources(
+		ini.LoadOptions{
+			Insensitive:  true,
+			AllowShadows: true,
+		},
+		[]byte(`
+		[zone_walk]
+		nsec3 = false
+		maximum_names = 50
+		`),
+	)
+
+	if err := c.loadZoneWalkSettings(cfg); err != nil {
+		t.Fatalf("Failed to parse the zone walk settings: %v", err)
+	}
+	if c.EnableZoneWalk {
+		t.Errorf("The zone walk was enabled without the enabled setting")
+	}
+	if !c.ZoneWalkNSEC || c.ZoneWalkNSEC3 || c.ZoneWalkMaxNames != 50 {
+		t.Errorf("Failed to load the zone walk settings")
+	}
+
+	cfg, _ = ini.LoadSources(ini.LoadOptions{Insensitive: true}, []byte("[zone_walk]\nenabled = true\n"))
+	if err := c.loadZoneWalkSettings(cfg); err != nil || !c.EnableZoneWalk {
+		t.Errorf("Failed to enable the zone walk: %v", err)
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"strings"
 
 	"github.com/OWASP/Amass/v3/config"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -31,6 +30,10 @@ type AlienVault struct {
 	creds      *config.Credentials
 }
 
+func init() {
+	RegisterSource("AlienVault", func(sys systems.System) service.Service { return NewAlienVault(sys) })
+}
+
 // NewAlienVault returns he object initialized, but not yet started.
 func NewAlienVault(sys systems.System) *AlienVault {
 	a := &AlienVault{
@@ -49,13 +52,16 @@ func (a *AlienVault) Description() string {
 
 // OnStart implements the Service interface.
 func (a *AlienVault) OnStart() error {
-	a.creds = a.sys.Config().GetDataSourceConfig(a.String()).GetCredentials()
+	a.creds = a.sys.Config().APIKey(a.String())
 
-	if a.creds == nil {
+	if a.creds == nil || a.creds.Key == "" {
 		a.sys.Config().Log.Printf("%s: API key data was not provided", a.String())
+		a.SetRateLimit(1)
+		return nil
 	}
 
-	a.SetRateLimit(1)
+	// Requests that provide the API key are allowed a higher rate limit
+	a.SetRateLimit(3)
 	return nil
 }
 
@@ -107,7 +113,7 @@ func (a *AlienVault) executeDNSQuery(ctx context.Context, req *requests.DNSReque
 	}
 
 	u := a.getURL(req.Domain) + "passive_dns"
-	page, err := http.RequestWebPage(ctx, u, nil, a.getHeaders(), nil)
+	page, err := requestWebPage(ctx, a, u, nil, a.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
 		return
@@ -139,6 +145,10 @@ func (a *AlienVault) executeDNSQuery(ctx context.Context, req *requests.DNSReque
 				ips.Insert(ip.String())
 			}
 		}
+		// CNAME records provide the target name in the address field
+		if target := strings.ToLower(sub.IP); net.ParseIP(target) == nil && re.MatchString(target) {
+			names.Insert(target)
+		}
 	}
 
 	for name := range names {
@@ -178,7 +188,7 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 
 	headers := a.getHeaders()
 	u := a.getURL(req.Domain) + "url_list"
-	page, err := http.RequestWebPage(ctx, u, nil, headers, nil)
+	page, err := requestWebPage(ctx, a, u, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
 		return
@@ -209,7 +219,7 @@ func (a *AlienVault) executeURLQuery(ctx context.Context, req *requests.DNSReque
 		for cur := m.PageNum + 1; cur <= pages; cur++ {
 			a.CheckRateLimit()
 			pageURL := u + "?page=" + strconv.Itoa(cur)
-			page, err = http.RequestWebPage(ctx, pageURL, nil, headers, nil)
+			page, err = requestWebPage(ctx, a, pageURL, nil, headers, nil)
 			if err != nil {
 				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 					fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
@@ -271,7 +281,7 @@ func (a *AlienVault) executeWhoisQuery(ctx context.Context, req *requests.WhoisR
 	headers := a.getHeaders()
 	for _, email := range emails {
 		pageURL := a.getReverseWhoisURL(email)
-		page, err := http.RequestWebPage(ctx, pageURL, nil, headers, nil)
+		page, err := requestWebPage(ctx, a, pageURL, nil, headers, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 				fmt.Sprintf("%s: %s: %v", a.String(), pageURL, err))
@@ -319,7 +329,7 @@ func (a *AlienVault) queryWhoisForEmails(ctx context.Context, req *requests.Whoi
 		return emails.Slice()
 	}
 
-	page, err := http.RequestWebPage(ctx, u, nil, a.getHeaders(), nil)
+	page, err := requestWebPage(ctx, a, u, nil, a.getHeaders(), nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), u, err))
 		return emails.Slice()
@@ -0,0 +1,290 @@
+// Copyright 2017 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/service"
+	"github.com/caffix/stringset"
+)
+
+const ldhChars = "_abcdefghijklmnopqrstuvwxyz0123456789-"
+
+var altNumberRE = regexp.MustCompile(`\d+`)
+
+// Alterations is the Service that generates alterations of the resolved names.
+type Alterations struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+}
+
+func init() {
+	RegisterSource("Alterations", func(sys systems.System) service.Service { return NewAlterations(sys) })
+}
+
+// NewAlterations returns he object initialized, but not yet started.
+func NewAlterations(sys systems.System) *Alterations {
+	a := &Alterations{
+		SourceType: requests.ALT,
+		sys:        sys,
+	}
+
+	a.BaseService = *service.NewBaseService(a, "Alterations")
+	return a
+}
+
+// Description implements the Service interface.
+func (a *Alterations) Description() string {
+	return a.SourceType
+}
+
+// OnRequest implements the Service interface.
+func (a *Alterations) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.ResolvedRequest); ok {
+		a.resolvedRequest(ctx, req)
+	}
+}
+
+func (a *Alterations) resolvedRequest(ctx context.Context, req *requests.ResolvedRequest) {
+	cfg, _, err := ContextConfigBus(ctx)
+	if err != nil || cfg.Passive || !cfg.Alterations || req == nil || req.Name == "" {
+		return
+	}
+	// Do not process resolved root domain names
+	if len(strings.Split(req.Name, ".")) <= len(strings.Split(req.Domain, ".")) {
+		return
+	}
+
+	for _, name := range AlteredNames(cfg, req.Name) {
+		genNewNameEvent(ctx, a.sys, a, name)
+	}
+}
+
+// AlteredNames returns the alterations of the name selected by the configuration.
+// The names are generated without being resolved or checked against the scope.
+func AlteredNames(cfg *config.Config, name string) []string {
+	parts := strings.SplitN(strings.ToLower(strings.TrimSpace(name)), ".", 2)
+	if len(parts) < 2 || parts[0] == "" {
+		return []string{}
+	}
+
+	hostname, rest := parts[0], parts[1]
+	words := cfg.AltWordlist
+
+	names := stringset.New()
+	if cfg.FlipWords {
+		names.InsertMany(flipWords(hostname, rest, words)...)
+	}
+	if cfg.FlipNumbers {
+		names.InsertMany(flipNumbers(hostname, rest)...)
+	}
+	if cfg.AddNumbers {
+		names.InsertMany(appendNumbers(hostname, rest)...)
+	}
+	if cfg.TransposeWords {
+		names.InsertMany(transposeWords(hostname, rest)...)
+	}
+	if cfg.RemoveWords {
+		names.InsertMany(removeWords(hostname, rest, words)...)
+	}
+	if cfg.AddWords {
+		names.InsertMany(addPrefixWords(hostname, rest, words)...)
+		names.InsertMany(addSuffixWords(hostname, rest, words)...)
+	}
+	if cfg.EditDistance > 0 {
+		names.InsertMany(fuzzyLabelSearches(hostname, rest, cfg.EditDistance)...)
+	}
+
+	names.Remove(hostname + "." + rest)
+	return names.Slice()
+}
+
+func flipWords(hostname, rest string, words []string) []string {
+	var names []string
+
+	parts := strings.Split(hostname, "-")
+	if len(parts) < 2 {
+		return names
+	}
+
+	post := strings.Join(parts[1:], "-")
+	pre := strings.Join(parts[:len(parts)-1], "-")
+	for _, word := range words {
+		names = append(names, word+"-"+post+"."+rest, pre+"-"+word+"."+rest)
+	}
+	return names
+}
+
+func flipNumbers(hostname, rest string) []string {
+	var names []string
+
+	for _, idx := range altNumberRE.FindAllStringIndex(hostname, -1) {
+		pre := hostname[:idx[0]]
+		post := hostname[idx[1]:]
+		// Create an entry with the number removed
+		if pre+post != "" {
+			names = append(names, pre+post+"."+rest)
+		}
+
+		num, err := strconv.Atoi(hostname[idx[0]:idx[1]])
+		if err != nil {
+			continue
+		}
+
+		start := num - 50
+		if start < 1 {
+			start = 1
+		}
+		for i := start; i <= num+50; i++ {
+			names = append(names, pre+strconv.Itoa(i)+post+"."+rest)
+		}
+		// Create an entry with the digits swapped, e.g. web12 -> web21
+		if digits := hostname[idx[0]:idx[1]]; len(digits) > 1 {
+			names = append(names, pre+reverseString(digits)+post+"."+rest)
+		}
+	}
+	return names
+}
+
+func appendNumbers(hostname, rest string) []string {
+	var names []string
+
+	for i := 0; i < 10; i++ {
+		n := strconv.Itoa(i)
+
+		names = append(names, hostname+n+"."+rest, hostname+"-"+n+"."+rest)
+	}
+	return names
+}
+
+// transposeWords swaps each pair of words within the hostname, e.g. api-dev -> dev-api.
+func transposeWords(hostname, rest string) []string {
+	var names []string
+
+	parts := strings.Split(hostname, "-")
+	for i := 0; i < len(parts)-1; i++ {
+		for j := i + 1; j < len(parts); j++ {
+			swapped := append([]string(nil), parts...)
+
+			swapped[i], swapped[j] = parts[j], parts[i]
+			names = append(names, strings.Join(swapped, "-")+"."+rest)
+		}
+	}
+	return names
+}
+
+// removeWords removes each hyphenated word, e.g. api-dev -> api,
+// and the common words used as prefixes and suffixes, e.g. devapi -> api.
+func removeWords(hostname, rest string, words []string) []string {
+	var names []string
+
+	if parts := strings.Split(hostname, "-"); len(parts) > 1 {
+		for i := range parts {
+			remaining := append(append([]string(nil), parts[:i]...), parts[i+1:]...)
+
+			names = append(names, strings.Join(remaining, "-")+"."+rest)
+		}
+	}
+
+	for _, word := range words {
+		if word == "" || len(word) >= len(hostname) {
+			continue
+		}
+
+		var label string
+		if strings.HasPrefix(hostname, word) {
+			label = strings.Trim(strings.TrimPrefix(hostname, word), "-")
+		} else if strings.HasSuffix(hostname, word) {
+			label = strings.Trim(strings.TrimSuffix(hostname, word), "-")
+		}
+		if label != "" {
+			names = append(names, label+"."+rest)
+		}
+	}
+	return names
+}
+
+func addPrefixWords(hostname, rest string, words []string) []string {
+	var names []string
+
+	for _, word := range words {
+		names = append(names, word+hostname+"."+rest, word+"-"+hostname+"."+rest)
+	}
+	return names
+}
+
+func addSuffixWords(hostname, rest string, words []string) []string {
+	var names []string
+
+	for _, word := range words {
+		names = append(names, hostname+word+"."+rest, hostname+"-"+word+"."+rest)
+	}
+	return names
+}
+
+func fuzzyLabelSearches(hostname, rest string, distance int) []string {
+	labels := stringset.New(hostname)
+
+	for i := 0; i < distance; i++ {
+		current := labels.Slice()
+
+		labels.InsertMany(labelAdditions(current)...)
+		labels.InsertMany(labelDeletions(current)...)
+		labels.InsertMany(labelSubstitutions(current)...)
+	}
+
+	var names []string
+	for _, label := range labels.Slice() {
+		if label != "" {
+			names = append(names, label+"."+rest)
+		}
+	}
+	return names
+}
+
+func labelAdditions(labels []string) []string {
+	var results []string
+
+	for _, label := range labels {
+		for i := 0; i < len(label); i++ {
+			for _, c := range ldhChars {
+				results = append(results, label[:i]+string(c)+label[i:])
+			}
+		}
+	}
+	return results
+}
+
+func labelDeletions(labels []string) []string {
+	var results []string
+
+	for _, label := range labels {
+		for i := 0; i < len(label); i++ {
+			results = append(results, label[:i]+label[i+1:])
+		}
+	}
+	return results
+}
+
+func labelSubstitutions(labels []string) []string {
+	var results []string
+
+	for _, label := range labels {
+		for i := 0; i < len(label); i++ {
+			for _, c := range ldhChars {
+				results = append(results, label[:i]+string(c)+label[i+1:])
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,181 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func sortedNames(names []string) []string {
+	sorted := append([]string{}, names...)
+
+	sort.Strings(sorted)
+	return sorted
+}
+
+func TestReverseString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"1", "1"},
+		{"12", "21"},
+		{"123", "321"},
+		{"moc.elpmaxe", "example.com"},
+	}
+
+	for _, test := range tests {
+		if got := reverseString(test.in); got != test.want {
+			t.Errorf("reverseString(%q) returned %q, expected %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestTransposeWords(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     []string
+	}{
+		{"www", []string{}},
+		{"api-dev", []string{"dev-api.example.com"}},
+		{"a-b-c", []string{"a-c-b.example.com", "b-a-c.example.com", "c-b-a.example.com"}},
+	}
+
+	for _, test := range tests {
+		if got := transposeWords(test.hostname, "example.com"); !reflect.DeepEqual(sortedNames(got), test.want) {
+			t.Errorf("transposeWords(%q) returned %v, expected %v", test.hostname, got, test.want)
+		}
+	}
+}
+
+func TestRemoveWords(t *testing.T) {
+	words := []string{"dev", "stage", "api"}
+	tests := []struct {
+		hostname string
+		want     []string
+	}{
+		{"www", []string{}},
+		{"dev", []string{}},
+		{"api-www", []string{"api.example.com", "www.example.com", "www.example.com"}},
+		{"devapi", []string{"api.example.com", "dev.example.com"}},
+		{"www-stage", []string{"stage.example.com", "www.example.com", "www.example.com"}},
+	}
+
+	for _, test := range tests {
+		if got := removeWords(test.hostname, "example.com", words); !reflect.DeepEqual(sortedNames(got), test.want) {
+			t.Errorf("removeWords(%q) returned %v, expected %v", test.hostname, got, test.want)
+		}
+	}
+}
+
+func TestFlipNumbers(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     []string
+		missing  []string
+	}{
+		{
+			hostname: "web12",
+			want:     []string{"web.example.com", "web1.example.com", "web62.example.com", "web21.example.com"},
+			missing:  []string{"web0.example.com", "web63.example.com"},
+		},
+		{
+			hostname: "7",
+			want:     []string{"1.example.com", "57.example.com"},
+			missing:  []string{".example.com", "0.example.com"},
+		},
+		{
+			hostname: "www",
+			missing:  []string{"www.example.com"},
+		},
+	}
+
+	for _, test := range tests {
+		got := make(map[string]bool)
+		for _, n := range flipNumbers(test.hostname, "example.com") {
+			got[n] = true
+		}
+
+		for _, n := range test.want {
+			if !got[n] {
+				t.Errorf("flipNumbers(%q) did not return %s", test.hostname, n)
+			}
+		}
+		for _, n := range test.missing {
+			if got[n] {
+				t.Errorf("flipNumbers(%q) returned %s", test.hostname, n)
+			}
+		}
+	}
+}
+
+func TestAlteredNames(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AltWordlist = []string{"dev"}
+	cfg.FlipWords = false
+	cfg.FlipNumbers = false
+	cfg.AddNumbers = false
+	cfg.AddWords = false
+	cfg.EditDistance = 0
+
+	tests := []struct {
+		name      string
+		transpose bool
+		remove    bool
+		want      []string
+	}{
+		{"api-dev.example.com", false, false, []string{}},
+		{"api-dev.example.com", true, false, []string{"dev-api.example.com"}},
+		{"api-dev.example.com", false, true, []string{"api.example.com", "dev.example.com"}},
+		{"API-Dev.Example.com", true, true, []string{"api.example.com", "dev-api.example.com", "dev.example.com"}},
+		{"example", true, true, []string{}},
+	}
+
+	for _, test := range tests {
+		cfg.TransposeWords = test.transpose
+		cfg.RemoveWords = test.remove
+
+		if got := sortedNames(AlteredNames(cfg, test.name)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("AlteredNames(%q) returned %v, expected %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAlterationsResolvedRequest(t *testing.T) {
+	sys, ctx, names := setupTestSource(t, "example.com")
+	cfg := sys.cfg
+	cfg.Alterations = true
+	cfg.AltWordlist = []string{"dev"}
+	cfg.FlipWords = false
+	cfg.FlipNumbers = false
+	cfg.AddNumbers = false
+	cfg.AddWords = false
+	cfg.EditDistance = 0
+
+	a := NewAlterations(sys)
+	a.resolvedRequest(ctx, &requests.ResolvedRequest{
+		Name:   "api-dev.example.com",
+		Domain: "example.com",
+	})
+
+	got := names.wait("dev-api.example.com", "api.example.com")
+	for _, name := range []string{"dev-api.example.com", "api.example.com"} {
+		if _, found := got[name]; !found {
+			t.Errorf("The alteration %s was not provided", name)
+		}
+	}
+	// Root domain names do not get altered
+	a.resolvedRequest(ctx, &requests.ResolvedRequest{
+		Name:   "www-dev.example.com",
+		Domain: "www-dev.example.com",
+	})
+	if _, found := names.wait("dev-www.example.com")["dev-www.example.com"]; found {
+		t.Errorf("The root domain name was altered")
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+)
+
+const (
+	redactedValue   = "REDACTED"
+	archiveNoDomain = "_nodomain"
+)
+
+var (
+	archiveLock   sync.Mutex
+	sensitiveRE   = regexp.MustCompile(`(?i)(key|token|secret|pass|auth|cookie|session|credential|signature)`)
+	archiveNameRE = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+)
+
+type archiveRecord struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Source    string            `json:"source"`
+	Domain    string            `json:"domain"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Cached    bool              `json:"cached,omitempty"`
+	Response  string            `json:"response"`
+}
+
+// requestWebPage performs the HTTP request for the data source and archives the raw response when configured.
+// The responses to idempotent requests are cached when configured, so later enumerations reuse them.
+func requestWebPage(ctx context.Context, srv service.Service, u string, body io.Reader,
+	headers map[string]string, auth *http.BasicAuth) (string, error) {
+	cfg, bus, cerr := ContextConfigBus(ctx)
+	cache := contextResponseCache(ctx)
+	key, cacheable := responseCacheKey(u, body, headers, auth)
+	if cache != nil && cacheable {
+		if page, found := cache.lookup(srv.String(), key); found {
+			// The responses reused from the cache are archived as well, so the archive covers the enumeration
+			if cerr == nil {
+				archiveSourceResponse(cfg, bus, srv, u, headers, auth, page, nil, true)
+			}
+			return page, nil
+		}
+	}
+
+	budgets := contextSourceBudgets(ctx)
+	if budgets != nil {
+		if budgets.Exceeded(srv.String()) {
+			return "", fmt.Errorf("%s is no longer being queried due to the budgets or failures", srv.String())
+		}
+		if err := budgets.Wait(ctx, srv.String()); err != nil {
+			return "", err
+		}
+	}
+
+	if cerr != nil {
+		return http.RequestWebPage(ctx, u, body, headers, auth)
+	}
+
+	rctx := ctx
+	if proxy := cfg.SourceProxy(srv.String()); proxy != nil {
+		rctx = http.WithProxy(rctx, proxy)
+	}
+	if ua := cfg.SourceUserAgent(srv.String()); ua != "" {
+		rctx = http.WithUserAgent(rctx, ua)
+	}
+	if timeout := cfg.SourceRequestTimeout(srv.String()); timeout > 0 {
+		var cancel context.CancelFunc
+
+		rctx, cancel = context.WithTimeout(rctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	page, err := http.RequestWebPage(rctx, u, body, headers, auth)
+	if budgets != nil && budgets.Add(srv.String(), time.Since(start), sourceFailure(ctx, err)) {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: Marked as unhealthy after %d consecutive failures",
+			srv.String(), cfg.SourceMaxFailures))
+	}
+
+	if err == nil && cache != nil && cacheable {
+		if cerr := cache.store(srv.String(), srv.Description(), key, page); cerr != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", srv.String(), cerr))
+		}
+	}
+
+	archiveSourceResponse(cfg, bus, srv, u, headers, auth, page, err, false)
+	return page, err
+}
+
+// archiveSourceResponse archives the response when configured, with the data source credentials redacted.
+func archiveSourceResponse(cfg *config.Config, bus *eventbus.EventBus, srv service.Service, u string,
+	headers map[string]string, auth *http.BasicAuth, page string, reqErr error, cached bool) {
+	if cfg.ArchiveDirectory == "" {
+		return
+	}
+
+	var secrets []string
+	if auth != nil {
+		secrets = append(secrets, auth.Username, auth.Password)
+	}
+	if dsc := cfg.GetDataSourceConfig(srv.String()); dsc != nil {
+		for _, creds := range dsc.AllCredentials() {
+			secrets = append(secrets, creds.Username, creds.Password, creds.Key, creds.Secret)
+		}
+	}
+
+	if err := archiveResponse(cfg, srv.String(), u, headers, page, reqErr, secrets, cached); err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", srv.String(), err))
+	}
+}
+
+// sourceFailure returns true when the request error indicates the data source is not responding properly.
+// Client error status codes are commonly returned when the data source has no results.
+func sourceFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var serr *http.StatusError
+	if errors.As(err, &serr) {
+		return serr.StatusCode < 400 || serr.StatusCode >= 500
+	}
+	return true
+}
+
+// archiveResponse appends the raw data source response to the per-source, per-domain archive file.
+func archiveResponse(cfg *config.Config, src, u string, headers map[string]string,
+	page string, reqErr error, secrets []string, cached bool) error {
+	domain := archiveDomain(cfg, u)
+	rec := &archiveRecord{
+		Timestamp: time.Now(),
+		Source:    src,
+		Domain:    domain,
+		URL:       redactURL(u, secrets),
+		Headers:   redactHeaders(headers, secrets),
+		Cached:    cached,
+		Response:  page,
+	}
+	if reqErr != nil {
+		rec.Error = redactString(reqErr.Error(), secrets)
+	}
+
+	dir := filepath.Join(cfg.ArchiveDirectory, archiveNameRE.ReplaceAllString(src, "_"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("Failed to create the archive directory %s: %v", dir, err)
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("Failed to encode the archive record for %s: %v", rec.URL, err)
+	}
+
+	archiveLock.Lock()
+	defer archiveLock.Unlock()
+
+	path := filepath.Join(dir, archiveNameRE.ReplaceAllString(domain, "_")+".jsonl")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to open the archive file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("Failed to write to the archive file %s: %v", path, err)
+	}
+	return nil
+}
+
+// archiveDomain returns the longest root domain name found within the URL.
+func archiveDomain(cfg *config.Config, u string) string {
+	var domain string
+
+	lower := strings.ToLower(u)
+	for _, d := range cfg.Domains() {
+		if strings.Contains(lower, d) && len(d) > len(domain) {
+			domain = d
+		}
+	}
+
+	if domain == "" {
+		return archiveNoDomain
+	}
+	return domain
+}
+
+func redactURL(u string, secrets []string) string {
+	p, err := url.Parse(u)
+	if err != nil {
+		return redactString(u, secrets)
+	}
+
+	if p.User != nil {
+		p.User = url.User(redactedValue)
+	}
+
+	q := p.Query()
+	for k := range q {
+		if sensitiveRE.MatchString(k) {
+			q.Set(k, redactedValue)
+		}
+	}
+	p.RawQuery = q.Encode()
+
+	return redactString(p.String(), secrets)
+}
+
+func redactHeaders(headers map[string]string, secrets []string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if sensitiveRE.MatchString(k) {
+			v = redactedValue
+		}
+		redacted[k] = redactString(v, secrets)
+	}
+	return redacted
+}
+
+func redactString(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redactedValue)
+			s = strings.ReplaceAll(s, url.QueryEscape(secret), redactedValue)
+		}
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/graph"
+	amasshttp "github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestSourceFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&amasshttp.StatusError{StatusCode: 404, Status: "404 Not Found"}, false},
+		{fmt.Errorf("request: %w", &amasshttp.StatusError{StatusCode: 403, Status: "403 Forbidden"}), false},
+		{&amasshttp.StatusError{StatusCode: 500, Status: "500 Internal Server Error"}, true},
+		{&amasshttp.StatusError{StatusCode: 503, Status: "503 Service Unavailable"}, true},
+		{errors.New("4 connection attempts failed"), true},
+	}
+
+	for _, test := range tests {
+		if got := sourceFailure(context.Background(), test.err); got != test.want {
+			t.Errorf("sourceFailure(%v) returned %t, expected %t", test.err, got, test.want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if sourceFailure(ctx, errors.New("context canceled")) {
+		t.Errorf("The cancelled request was considered a data source failure")
+	}
+}
+
+func TestRequestWebPageArchivesCachedResponse(t *testing.T) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		fmt.Fprint(w, "www.example.com")
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sys, ctx, _ := setupTestSource(t, "example.com")
+	sys.cfg.ArchiveDirectory = dir
+	sys.cfg.ResponseCache = true
+	sys.dbs = []*graph.Graph{graph.NewGraph(graph.NewCayleyGraphMemory())}
+	srv := NewFDNS(sys)
+	u := ts.URL + "/?domain=example.com"
+
+	// Each enumeration has its own response cache, while the responses are kept in the graph
+	for i := 0; i < 2; i++ {
+		cctx := context.WithValue(ctx, requests.ContextResponseCache, NewResponseCache(sys.cfg, sys.dbs))
+
+		if page, err := requestWebPage(cctx, srv, u, nil, nil, nil); err != nil || page != "www.example.com" {
+			t.Fatalf("Request %d returned %q and the error: %v", i+1, page, err)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("The server received %d requests, expected the cached response to be reused", n)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, srv.String(), "example.com.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read the archive file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("The archive contained %d records, expected 2", len(lines))
+	}
+	for i, line := range lines {
+		var rec archiveRecord
+
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("Failed to decode the archive record: %v", err)
+		}
+		if rec.Response != "www.example.com" || rec.Cached != (i == 1) {
+			t.Errorf("Archive record %d has the response %q and cached %t", i+1, rec.Response, rec.Cached)
+		}
+	}
+}
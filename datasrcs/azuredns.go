@@ -0,0 +1,255 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	azureLoginURL      = "https://login.microsoftonline.com"
+	azureManagementURL = "https://management.azure.com"
+	azureDNSAPIVersion = "2018-05-01"
+)
+
+// AzureDNS is the Service that handles access to the DNS zones hosted in Azure DNS.
+type AzureDNS struct {
+	service.BaseService
+
+	SourceType   string
+	sys          systems.System
+	subscription string
+	tokens       oauth2.TokenSource
+	loginURL     string
+	baseURL      string
+}
+
+type azureRecordSet struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Properties struct {
+		FQDN     string `json:"fqdn"`
+		ARecords []struct {
+			Address string `json:"ipv4Address"`
+		} `json:"ARecords"`
+		AAAARecords []struct {
+			Address string `json:"ipv6Address"`
+		} `json:"AAAARecords"`
+		CNAMERecord *struct {
+			CNAME string `json:"cname"`
+		} `json:"CNAMERecord"`
+		MXRecords []struct {
+			Exchange string `json:"exchange"`
+		} `json:"MXRecords"`
+		NSRecords []struct {
+			NSDName string `json:"nsdname"`
+		} `json:"NSRecords"`
+		TXTRecords []struct {
+			Value []string `json:"value"`
+		} `json:"TXTRecords"`
+		TargetResource *struct {
+			ID string `json:"id"`
+		} `json:"targetResource"`
+	} `json:"properties"`
+}
+
+func init() {
+	RegisterSource("AzureDNS", func(sys systems.System) service.Service { return NewAzureDNS(sys) })
+}
+
+// NewAzureDNS returns he object initialized, but not yet started.
+func NewAzureDNS(sys systems.System) *AzureDNS {
+	a := &AzureDNS{
+		SourceType: requests.API,
+		sys:        sys,
+		loginURL:   azureLoginURL,
+		baseURL:    azureManagementURL,
+	}
+
+	a.BaseService = *service.NewBaseService(a, "AzureDNS")
+	return a
+}
+
+// Description implements the Service interface.
+func (a *AzureDNS) Description() string {
+	return a.SourceType
+}
+
+// OnStart implements the Service interface.
+func (a *AzureDNS) OnStart() error {
+	creds := a.sys.Config().APIKey(a.String())
+
+	if creds == nil || creds.Tenant == "" || creds.Subscription == "" || creds.ClientID == "" || creds.Secret == "" {
+		a.sys.Config().Log.Printf("%s: API key data was not provided", a.String())
+	} else {
+		// The service principal authenticates using the client credentials flow of the tenant
+		cc := &clientcredentials.Config{
+			ClientID:     creds.ClientID,
+			ClientSecret: creds.Secret,
+			TokenURL:     a.loginURL + "/" + url.PathEscape(creds.Tenant) + "/oauth2/v2.0/token",
+			Scopes:       []string{azureManagementURL + "/.default"},
+		}
+
+		// The access tokens are requested through the proxy configured for the data source
+		client := http.ClientWithProxy(a.sys.Config().SourceProxy(a.String()))
+		a.subscription = creds.Subscription
+		a.tokens = cc.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, client))
+	}
+
+	a.SetRateLimit(5)
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (a *AzureDNS) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.DNSRequest); ok {
+		a.dnsRequest(ctx, req)
+	}
+}
+
+func (a *AzureDNS) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	if a.tokens == nil || !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for %s subdomains", a.String(), req.Domain))
+
+	u := a.baseURL + "/subscriptions/" + url.PathEscape(a.subscription) +
+		"/providers/Microsoft.Network/dnszones?api-version=" + azureDNSAPIVersion
+	for u != "" {
+		var zones struct {
+			Value []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+
+		if err := a.query(ctx, u, &zones); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", a.String(), err))
+			return
+		}
+
+		for _, zone := range zones.Value {
+			if zoneMatchesDomain(zone.Name, req.Domain) {
+				a.zoneRecords(ctx, req.Domain, zone.ID)
+			}
+		}
+		u = zones.NextLink
+	}
+}
+
+func (a *AzureDNS) zoneRecords(ctx context.Context, domain, id string) {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	u := a.baseURL + id + "/all?api-version=" + azureDNSAPIVersion
+	for u != "" {
+		var sets struct {
+			Value    []azureRecordSet `json:"value"`
+			NextLink string           `json:"nextLink"`
+		}
+
+		if err := a.query(ctx, u, &sets); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", a.String(), id, err))
+			return
+		}
+
+		var entries []zoneEntry
+		for _, set := range sets.Value {
+			entries = append(entries, a.setEntries(ctx, &set)...)
+		}
+		genZoneRecordEvents(ctx, a.sys, a, domain, entries)
+
+		u = sets.NextLink
+	}
+}
+
+func (a *AzureDNS) setEntries(ctx context.Context, set *azureRecordSet) []zoneEntry {
+	name := set.Properties.FQDN
+	// The record set type is provided as Microsoft.Network/dnszones/A
+	rtype := set.Type[strings.LastIndex(set.Type, "/")+1:]
+	props := &set.Properties
+
+	var values []string
+	switch rtype {
+	case "A":
+		for _, r := range props.ARecords {
+			values = append(values, r.Address)
+		}
+	case "AAAA":
+		for _, r := range props.AAAARecords {
+			values = append(values, r.Address)
+		}
+	case "CNAME":
+		if props.CNAMERecord != nil {
+			values = append(values, props.CNAMERecord.CNAME)
+		}
+	case "MX":
+		for _, r := range props.MXRecords {
+			values = append(values, r.Exchange)
+		}
+	case "NS":
+		for _, r := range props.NSRecords {
+			values = append(values, r.NSDName)
+		}
+	case "TXT":
+		for _, r := range props.TXTRecords {
+			values = append(values, strings.Join(r.Value, ""))
+		}
+	}
+
+	// Alias record sets refer to other Azure resources and need to be resolved
+	if len(values) == 0 && props.TargetResource != nil && props.TargetResource.ID != "" {
+		genNewNameEvent(ctx, a.sys, a, strings.TrimSuffix(name, "."))
+		return nil
+	}
+
+	// The record sets of other types still provide the name
+	if len(values) == 0 {
+		return []zoneEntry{{Name: name, Type: rtype}}
+	}
+
+	var entries []zoneEntry
+	for _, v := range values {
+		entries = append(entries, zoneEntry{Name: name, Type: rtype, Data: v})
+	}
+	return entries
+}
+
+func (a *AzureDNS) query(ctx context.Context, u string, v interface{}) error {
+	token, err := a.tokens.Token()
+	if err != nil {
+		return fmt.Errorf("Failed to obtain the access token: %v", err)
+	}
+
+	a.CheckRateLimit()
+	headers := map[string]string{"Authorization": "Bearer " + token.AccessToken}
+	page, err := requestWebPage(ctx, a, u, nil, headers, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %v", u, err)
+	}
+
+	return json.Unmarshal([]byte(page), v)
+}
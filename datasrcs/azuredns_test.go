@@ -0,0 +1,81 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/miekg/dns"
+)
+
+func TestAzureDNSZoneRecords(t *testing.T) {
+	var ts *httptest.Server
+
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/tenant/oauth2/v2.0/token" {
+			if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"token","token_type":"Bearer","expires_in":3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		zone := "/subscriptions/subscription/resourceGroups/rg/providers/Microsoft.Network/dnszones/example.com"
+		switch r.URL.Path {
+		case "/subscriptions/subscription/providers/Microsoft.Network/dnszones":
+			fmt.Fprintf(w, `{"value":[{"id":"%s","name":"example.com"}]}`, zone)
+		case zone + "/all":
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"value":[{"name":"www","type":"Microsoft.Network/dnszones/A",`+
+					`"properties":{"fqdn":"www.example.com.","ARecords":[{"ipv4Address":"192.0.2.1"}]}}],"nextLink":"%s"}`,
+					ts.URL+zone+"/all?page=2")
+				return
+			}
+			fmt.Fprint(w, `{"value":[{"name":"_sip._tcp","type":"Microsoft.Network/dnszones/SRV",`+
+				`"properties":{"fqdn":"_sip._tcp.example.com.","SRVRecords":[{"target":"sip.example.com"}]}}]}`)
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	sys.cfg.GetDataSourceConfig("AzureDNS").AddCredentials(&config.Credentials{
+		Name:         "account",
+		Tenant:       "tenant",
+		Subscription: "subscription",
+		ClientID:     "client",
+		Secret:       "secret",
+	})
+
+	a := NewAzureDNS(sys)
+	a.loginURL = ts.URL
+	a.baseURL = ts.URL
+	if err := a.OnStart(); err != nil || a.tokens == nil {
+		t.Fatalf("Failed to start the data source using the service principal")
+	}
+
+	a.dnsRequest(ctx, &requests.DNSRequest{Domain: "example.com"})
+	got := names.wait("www.example.com", "_sip._tcp.example.com")
+
+	if !checkTestRecords(got["www.example.com"], int(dns.TypeA), "192.0.2.1") {
+		t.Errorf("The record from the first page was not provided")
+	}
+	if _, found := got["_sip._tcp.example.com"]; !found {
+		t.Errorf("The name of the SRV record from the second page was not provided")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// SourceStats contains the statistics collected for a data source during an enumeration.
+// Elapsed is the time from the start of the first query to the end of the last completed query.
+type SourceStats struct {
+	Elapsed             time.Duration
+	Queries             int
+	Skipped             int
+	Failures            int
+	BudgetExceeded      bool
+	RequestLimitReached bool
+	Unhealthy           bool
+	consecutive         int
+	started             time.Time
+}
+
+// SourceBudgets tracks the time since each data source started querying and enforces the soft time budgets,
+// the request rate limits and the maximum number of requests. Data sources are also marked as
+// unhealthy after repeated failures.
+type SourceBudgets struct {
+	sync.Mutex
+	cfg   *config.Config
+	stats map[string]*SourceStats
+	next  map[string]time.Time
+}
+
+// NewSourceBudgets returns a SourceBudgets that enforces the budgets in the provided configuration.
+func NewSourceBudgets(cfg *config.Config) *SourceBudgets {
+	return &SourceBudgets{
+		cfg:   cfg,
+		stats: make(map[string]*SourceStats),
+		next:  make(map[string]time.Time),
+	}
+}
+
+func (sb *SourceBudgets) getStats(name string) *SourceStats {
+	s, found := sb.stats[name]
+	if !found {
+		s = new(SourceStats)
+		sb.stats[name] = s
+	}
+	return s
+}
+
+// checkBudget marks the data source once the time since its first query reaches the time budget.
+func (sb *SourceBudgets) checkBudget(name string, s *SourceStats) {
+	if budget := sb.cfg.SourceTimeBudget(name); budget > 0 && time.Since(s.started) >= budget {
+		s.BudgetExceeded = true
+	}
+}
+
+// Exceeded returns true when the named data source should not start new queries.
+// The skipped query is counted in the data source statistics.
+func (sb *SourceBudgets) Exceeded(name string) bool {
+	sb.Lock()
+	defer sb.Unlock()
+
+	s := sb.getStats(name)
+	// The time budget starts with the first query of the data source
+	if s.started.IsZero() {
+		s.started = time.Now()
+	}
+	sb.checkBudget(name, s)
+	if max := sb.cfg.SourceMaxRequests(name); max > 0 && s.Queries >= max {
+		s.RequestLimitReached = true
+	}
+
+	exceeded := s.BudgetExceeded || s.RequestLimitReached || s.Unhealthy
+	if exceeded {
+		s.Skipped++
+	}
+	return exceeded
+}
+
+// Wait blocks until the named data source is permitted to send another request by its rate limit.
+func (sb *SourceBudgets) Wait(ctx context.Context, name string) error {
+	interval := sb.cfg.SourceRequestInterval(name)
+	if interval == 0 {
+		return nil
+	}
+
+	sb.Lock()
+	now := time.Now()
+	// Reserve the next available slot so concurrent requests are spaced out
+	slot := sb.next[name]
+	if slot.Before(now) {
+		slot = now
+	}
+	sb.next[name] = slot.Add(interval)
+	sb.Unlock()
+
+	if delay := slot.Sub(now); delay > 0 {
+		t := time.NewTimer(delay)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return nil
+}
+
+// Add records a completed query for the named data source, which took the elapsed time, along with the query result.
+// True is returned when the failure caused the data source to be marked as unhealthy.
+func (sb *SourceBudgets) Add(name string, elapsed time.Duration, failed bool) bool {
+	sb.Lock()
+	defer sb.Unlock()
+
+	s := sb.getStats(name)
+	if start := time.Now().Add(-elapsed); s.started.IsZero() || start.Before(s.started) {
+		s.started = start
+	}
+	s.Elapsed = time.Since(s.started)
+	s.Queries++
+	sb.checkBudget(name, s)
+
+	if !failed {
+		s.consecutive = 0
+		return false
+	}
+
+	s.Failures++
+	s.consecutive++
+	if max := sb.cfg.SourceMaxFailures; max > 0 && !s.Unhealthy && s.consecutive >= max {
+		s.Unhealthy = true
+		return true
+	}
+	return false
+}
+
+// Stats returns a copy of the statistics collected for each data source.
+func (sb *SourceBudgets) Stats() map[string]SourceStats {
+	sb.Lock()
+	defer sb.Unlock()
+
+	stats := make(map[string]SourceStats, len(sb.stats))
+	for name, s := range sb.stats {
+		stats[name] = *s
+	}
+	return stats
+}
+
+func contextSourceBudgets(ctx context.Context) *SourceBudgets {
+	if b := ctx.Value(requests.ContextSourceBudgets); b != nil {
+		if budgets, ok := b.(*SourceBudgets); ok {
+			return budgets
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+func TestTimeBudgetConcurrentQueries(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TimeBudget = 1
+	sb := NewSourceBudgets(cfg)
+
+	// Concurrent queries adding up to more than the budget do not exceed it
+	for i := 0; i < 3; i++ {
+		sb.Add("test", 30*time.Second, false)
+	}
+	if sb.Exceeded("test") {
+		t.Errorf("The time budget was exceeded by concurrent queries within the budget")
+	}
+	if s := sb.Stats()["test"]; s.Elapsed < 30*time.Second || s.Elapsed >= time.Minute {
+		t.Errorf("The elapsed time %v was not measured from the start of the first query", s.Elapsed)
+	}
+}
+
+func TestTimeBudgetWallClock(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TimeBudget = 1
+	sb := NewSourceBudgets(cfg)
+
+	if sb.Exceeded("test") {
+		t.Fatalf("The time budget was exceeded before the first query")
+	}
+	// The budget runs from the first query, even without completed queries
+	sb.Lock()
+	sb.stats["test"].started = time.Now().Add(-2 * time.Minute)
+	sb.Unlock()
+
+	if !sb.Exceeded("test") {
+		t.Errorf("The time budget was not exceeded after the time since the first query")
+	}
+	if s := sb.Stats()["test"]; !s.BudgetExceeded || s.Skipped != 1 {
+		t.Errorf("The exceeded budget was not recorded: %+v", s)
+	}
+}
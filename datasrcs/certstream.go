@@ -0,0 +1,196 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	certStreamURL     = "wss://certstream.calidog.io/"
+	certStreamOrigin  = "https://certstream.calidog.io"
+	certStreamMaxWait = 2 * time.Minute
+)
+
+// CertStream is the Service that handles access to the CertStream data source.
+type CertStream struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+	url        string
+	origin     string
+
+	sync.Mutex
+	streams map[string]struct{}
+}
+
+type certStreamMessage struct {
+	Type string `json:"message_type"`
+	Data struct {
+		LeafCert struct {
+			AllDomains []string `json:"all_domains"`
+		} `json:"leaf_cert"`
+	} `json:"data"`
+}
+
+func init() {
+	RegisterSource("CertStream", func(sys systems.System) service.Service { return NewCertStream(sys) })
+}
+
+// NewCertStream returns he object initialized, but not yet started.
+func NewCertStream(sys systems.System) *CertStream {
+	c := &CertStream{
+		SourceType: requests.CERT,
+		sys:        sys,
+		url:        certStreamURL,
+		origin:     certStreamOrigin,
+		streams:    make(map[string]struct{}),
+	}
+
+	c.BaseService = *service.NewBaseService(c, "CertStream")
+	return c
+}
+
+// Description implements the Service interface.
+func (c *CertStream) Description() string {
+	return c.SourceType
+}
+
+// OnStart implements the Service interface.
+func (c *CertStream) OnStart() error {
+	c.SetRateLimit(1)
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (c *CertStream) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.DNSRequest); ok {
+		c.dnsRequest(ctx, req)
+	}
+}
+
+func (c *CertStream) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil || !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+
+	// Only one connection to the firehose is needed for each enumeration
+	uuid := cfg.UUID.String()
+	c.Lock()
+	if _, found := c.streams[uuid]; found {
+		c.Unlock()
+		return
+	}
+	c.streams[uuid] = struct{}{}
+	c.Unlock()
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Streaming %s certificate updates for the enumeration", c.String()))
+	go c.stream(ctx, uuid)
+}
+
+// stream keeps the connection to the CertStream firehose open until the enumeration completes.
+func (c *CertStream) stream(ctx context.Context, uuid string) {
+	defer func() {
+		c.Lock()
+		delete(c.streams, uuid)
+		c.Unlock()
+	}()
+
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	wait := time.Second
+	for {
+		start := time.Now()
+		if err := c.readStream(ctx); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("%s: %s: %v", c.String(), c.url, err))
+		}
+		// Reset the backoff after connections that were healthy for a while
+		if time.Since(start) > certStreamMaxWait {
+			wait = time.Second
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-c.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		if wait *= 2; wait > certStreamMaxWait {
+			wait = certStreamMaxWait
+		}
+	}
+}
+
+func (c *CertStream) readStream(ctx context.Context) error {
+	cfg, _, err := ContextConfigBus(ctx)
+	if err != nil {
+		return err
+	}
+
+	ws, err := http.DialWebsocket(ctx, c.url, c.origin, cfg.SourceProxy(c.String()))
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	// Closing the connection unblocks the receive when the enumeration completes
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-c.Done():
+		case <-done:
+		}
+		ws.Close()
+	}()
+
+	for {
+		var msg certStreamMessage
+
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-c.Done():
+				return nil
+			default:
+			}
+			return err
+		}
+		if msg.Type != "certificate_update" {
+			continue
+		}
+
+		for _, name := range msg.Data.LeafCert.AllDomains {
+			name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "*.")
+
+			if name != "" {
+				genNewNameEvent(ctx, c.sys, c, name)
+			}
+		}
+	}
+}
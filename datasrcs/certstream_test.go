@@ -0,0 +1,72 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"golang.org/x/net/websocket"
+)
+
+func TestCertStreamUpdates(t *testing.T) {
+	var conns int32
+	ts := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		atomic.AddInt32(&conns, 1)
+
+		_ = websocket.Message.Send(ws, `{"message_type": "heartbeat"}`)
+		_ = websocket.Message.Send(ws, `{"message_type": "certificate_update", "data": {"leaf_cert": `+
+			`{"all_domains": ["www.other.org", "", "*.www.example.com", " API.example.com"]}}}`)
+		// Keep the connection open until the enumeration completes
+		var msg string
+		_ = websocket.Message.Receive(ws, &msg)
+	}))
+	defer ts.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := NewCertStream(sys)
+	c.url = "ws" + strings.TrimPrefix(ts.URL, "http") + "/"
+	c.origin = ts.URL
+
+	req := &requests.DNSRequest{Domain: "example.com"}
+	c.dnsRequest(ctx, req)
+	// Only one connection to the firehose is opened for the enumeration
+	c.dnsRequest(ctx, req)
+
+	got := names.wait("www.example.com", "api.example.com")
+	for _, name := range []string{"www.example.com", "api.example.com"} {
+		if _, found := got[name]; !found {
+			t.Errorf("The certificate update did not provide %s", name)
+		}
+	}
+	if _, found := got["www.other.org"]; found {
+		t.Errorf("The certificate update provided a name out of scope")
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("The firehose received %d connections for the enumeration", n)
+	}
+
+	cancel()
+	// The stream is released once the enumeration completes
+	for i := 0; ; i++ {
+		c.Lock()
+		open := len(c.streams)
+		c.Unlock()
+
+		if open == 0 {
+			break
+		} else if i == 50 {
+			t.Fatalf("The stream was not closed after the enumeration completed")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
@@ -22,6 +23,11 @@ type Cloudflare struct {
 	SourceType string
 	sys        systems.System
 	creds      *config.Credentials
+	baseURL    string
+}
+
+func init() {
+	RegisterSource("Cloudflare", func(sys systems.System) service.Service { return NewCloudflare(sys) })
 }
 
 // NewCloudflare returns he object initialized, but not yet started.
@@ -42,7 +48,7 @@ func (c *Cloudflare) Description() string {
 
 // OnStart implements the Service interface.
 func (c *Cloudflare) OnStart() error {
-	c.creds = c.sys.Config().GetDataSourceConfig(c.String()).GetCredentials()
+	c.creds = c.sys.Config().APIKey(c.String())
 
 	if c.creds == nil || c.creds.Key == "" {
 		c.sys.Config().Log.Printf("%s: API key data was not provided", c.String())
@@ -76,41 +82,38 @@ func (c *Cloudflare) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 		fmt.Sprintf("Querying %s for %s subdomains", c.String(), req.Domain))
 
-	api, err := cloudflare.NewWithAPIToken(c.creds.Key)
+	api, err := cloudflare.NewWithAPIToken(c.creds.Key,
+		cloudflare.HTTPClient(http.ClientWithProxy(cfg.SourceProxy(c.String()))))
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
+		return
 	}
-
-	zones, err := api.ListZones(req.Domain)
+	if c.baseURL != "" {
+		api.BaseURL = c.baseURL
+	}
+	// List all the zones the token has access to, since owned zones can be parents or children of the domain
+	zones, err := api.ListZones()
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
+		return
 	}
 
 	for _, zone := range zones {
+		if !zoneMatchesDomain(zone.Name, req.Domain) {
+			continue
+		}
+
+		c.CheckRateLimit()
 		records, err := api.DNSRecords(zone.ID, cloudflare.DNSRecord{})
 		if err != nil {
-			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", c.String(), err))
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), zone.Name, err))
+			continue
 		}
 
+		var entries []zoneEntry
 		for _, record := range records {
-			if d := cfg.WhichDomain(record.Name); d != "" {
-				bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-					Name:   record.Name,
-					Domain: req.Domain,
-					Tag:    c.SourceType,
-					Source: c.String(),
-				})
-			}
-			if record.Type == "CNAME" {
-				if d := cfg.WhichDomain(record.Content); d != "" {
-					bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-						Name:   record.Content,
-						Domain: req.Domain,
-						Tag:    c.SourceType,
-						Source: c.String(),
-					})
-				}
-			}
+			entries = append(entries, zoneEntry{Name: record.Name, Type: record.Type, Data: record.Content})
 		}
+		genZoneRecordEvents(ctx, c.sys, c, req.Domain, entries)
 	}
 }
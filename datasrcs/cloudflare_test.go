@@ -0,0 +1,80 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/miekg/dns"
+)
+
+func TestCloudflareZoneRecords(t *testing.T) {
+	var lock sync.Mutex
+	var proxied []string
+	// The server acts as the proxy, so the requests for the API host show the proxy was used
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		proxied = append(proxied, r.URL.Host)
+		lock.Unlock()
+
+		if r.URL.Host != "api.cloudflare.test" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/client/v4/zones":
+			fmt.Fprint(w, `{"success": true, "result": [`+
+				`{"id": "z1", "name": "example.com"}, {"id": "z2", "name": "dev.example.com"}, {"id": "z3", "name": "example.org"}],`+
+				`"result_info": {"page": 1, "per_page": 50, "total_pages": 1, "count": 3, "total_count": 3}}`)
+		case "/client/v4/zones/z1/dns_records":
+			fmt.Fprint(w, `{"success": true, "result": [`+
+				`{"type": "A", "name": "www.example.com", "content": "93.184.216.34"},`+
+				`{"type": "CNAME", "name": "shop.example.com", "content": "shops.myshopify.com"}],`+
+				`"result_info": {"page": 1, "per_page": 100, "total_pages": 1, "count": 2, "total_count": 2}}`)
+		case "/client/v4/zones/z2/dns_records":
+			fmt.Fprint(w, `{"success": true, "result": [{"type": "A", "name": "api.dev.example.com", "content": "93.184.216.35"}],`+
+				`"result_info": {"page": 1, "per_page": 100, "total_pages": 1, "count": 1, "total_count": 1}}`)
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	sys.cfg.Proxy = ts.URL
+
+	c := NewCloudflare(sys)
+	c.creds = &config.Credentials{Key: "token"}
+	c.baseURL = "http://api.cloudflare.test/client/v4"
+
+	c.dnsRequest(ctx, &requests.DNSRequest{Domain: "example.com"})
+	got := names.wait("www.example.com", "shop.example.com", "api.dev.example.com")
+
+	if !checkTestRecords(got["www.example.com"], int(dns.TypeA), "93.184.216.34") {
+		t.Errorf("The A record was not provided")
+	}
+	if !checkTestRecords(got["shop.example.com"], int(dns.TypeCNAME), "shops.myshopify.com") {
+		t.Errorf("The CNAME record was not provided")
+	}
+	if _, found := got["api.dev.example.com"]; !found {
+		t.Errorf("The record from the child zone was not provided")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	// The zones are listed twice by the client, followed by the records of the two matching zones
+	if len(proxied) != 4 {
+		t.Errorf("The API requests were not all sent through the proxy: %v", proxied)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+
+	// The crt.sh certwatch database is accessed using PostgreSQL
+	"github.com/lib/pq"
+)
+
+const (
+	crtshDSN      = "host=crt.sh port=5432 user=guest dbname=certwatch sslmode=disable connect_timeout=30"
+	crtshPageSize = 10000
+	crtshMaxPages = 50
+)
+
+// The certificates are paged by identifier, since crt.sh truncates the results of large queries. Each page
+// holds a fixed number of distinct certificates, so the identities of a certificate never span two pages.
+const crtshQuery = `WITH certs AS (
+		SELECT DISTINCT cai.CERTIFICATE_ID
+		FROM certificate_and_identities cai
+		WHERE plainto_tsquery('certwatch', $1) @@ identities(cai.CERTIFICATE)
+			AND cai.CERTIFICATE_ID > $2
+		ORDER BY cai.CERTIFICATE_ID
+		LIMIT $3
+	)
+	SELECT cai.CERTIFICATE_ID, lower(cai.NAME_VALUE), x509_notBefore(cai.CERTIFICATE)
+	FROM certificate_and_identities cai
+	JOIN certs ON cai.CERTIFICATE_ID = certs.CERTIFICATE_ID
+	WHERE cai.NAME_TYPE IN ('2.5.4.3', 'san:dNSName')
+	ORDER BY cai.CERTIFICATE_ID`
+
+// Crtsh is the Service that handles access to the crt.sh certificate search.
+type Crtsh struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+}
+
+func init() {
+	RegisterSource("Crtsh", func(sys systems.System) service.Service { return NewCrtsh(sys) })
+}
+
+// NewCrtsh returns he object initialized, but not yet started.
+func NewCrtsh(sys systems.System) *Crtsh {
+	c := &Crtsh{
+		SourceType: requests.CERT,
+		sys:        sys,
+	}
+
+	c.BaseService = *service.NewBaseService(c, "Crtsh")
+	return c
+}
+
+// Description implements the Service interface.
+func (c *Crtsh) Description() string {
+	return c.SourceType
+}
+
+// OnStart implements the Service interface.
+func (c *Crtsh) OnStart() error {
+	c.SetRateLimit(1)
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (c *Crtsh) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.DNSRequest); ok {
+		c.dnsRequest(ctx, req)
+	}
+}
+
+func (c *Crtsh) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	if !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for %s subdomains", c.String(), req.Domain))
+
+	// The certwatch database is only queried directly when it was enabled in the configuration
+	if dsc := cfg.GetDataSourceConfig(c.String()); dsc != nil && dsc.Database {
+		c.CheckRateLimit()
+		err := c.queryDatabase(ctx, req.Domain)
+		if err == nil {
+			return
+		}
+		// The JSON interface is used when the database cannot be reached
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: certwatch database: %v", c.String(), err))
+	}
+
+	c.queryJSON(ctx, req.Domain)
+}
+
+func (c *Crtsh) queryDatabase(ctx context.Context, domain string) error {
+	cfg, _, err := ContextConfigBus(ctx)
+	if err != nil {
+		return err
+	}
+
+	db := sql.OpenDB(&crtshConnector{proxy: cfg.SourceProxy(c.String())})
+	defer db.Close()
+
+	var last int64
+	for page := 0; page < crtshMaxPages; page++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.Done():
+			return nil
+		default:
+		}
+
+		rows, err := db.QueryContext(ctx, crtshQuery, domain, last, crtshPageSize)
+		if err != nil {
+			return err
+		}
+
+		var certs int
+		for rows.Next() {
+			var id int64
+			var name string
+			var notBefore time.Time
+
+			if err := rows.Scan(&id, &name, &notBefore); err != nil {
+				continue
+			}
+
+			if id != last {
+				certs++
+				last = id
+			}
+			genNewNameEvidenceEvent(ctx, c.sys, c, crtshCleanName(name), notBefore, crtshEvidence(id))
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		if certs < crtshPageSize {
+			break
+		}
+	}
+	return nil
+}
+
+func (c *Crtsh) queryJSON(ctx context.Context, domain string) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	u := "https://crt.sh/?q=" + url.QueryEscape("%."+domain) + "&output=json"
+	// Check if the response data is in the graph database
+	page, err := c.cachedResponse(cfg, u)
+	if err != nil {
+		c.CheckRateLimit()
+		page, err = requestWebPage(ctx, c, u, nil, map[string]string{"Content-Type": "application/json"}, nil)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), u, err))
+			return
+		}
+		c.cacheResponse(cfg, u, page)
+	}
+
+	var results []struct {
+		ID        int64  `json:"id"`
+		NameValue string `json:"name_value"`
+		NotBefore string `json:"not_before"`
+	}
+	if err := json.Unmarshal([]byte(page), &results); err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", c.String(), u, err))
+		return
+	}
+
+	for _, r := range results {
+		notBefore, _ := time.Parse("2006-01-02T15:04:05", r.NotBefore)
+
+		for _, name := range strings.Split(r.NameValue, "\n") {
+			genNewNameEvidenceEvent(ctx, c.sys, c, crtshCleanName(name), notBefore, crtshEvidence(r.ID))
+		}
+	}
+}
+
+func (c *Crtsh) cachedResponse(cfg *config.Config, u string) (string, error) {
+	if dsc := cfg.GetDataSourceConfig(c.String()); !cfg.FreshResponses && dsc != nil && dsc.TTL > 0 {
+		for _, db := range c.sys.GraphDatabases() {
+			if page, err := db.GetSourceData(c.String(), u, dsc.TTL); err == nil {
+				return page, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("Failed to obtain a cached response for %s", u)
+}
+
+func (c *Crtsh) cacheResponse(cfg *config.Config, u, page string) {
+	if dsc := cfg.GetDataSourceConfig(c.String()); dsc != nil && dsc.TTL > 0 {
+		for _, db := range c.sys.GraphDatabases() {
+			_ = db.CacheSourceData(c.String(), c.SourceType, u, page)
+		}
+	}
+}
+
+func crtshEvidence(id int64) map[string]string {
+	return map[string]string{
+		"certificate_id":  strconv.FormatInt(id, 10),
+		"certificate_url": "https://crt.sh/?id=" + strconv.FormatInt(id, 10),
+	}
+}
+
+func crtshCleanName(name string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "*.")
+}
+
+// crtshConnector opens the certwatch database connections through the proxy when configured.
+type crtshConnector struct {
+	proxy *url.URL
+}
+
+// Connect implements the driver.Connector interface.
+func (c *crtshConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return pq.DialOpen(&crtshDialer{ctx: ctx, proxy: c.proxy}, crtshDSN)
+}
+
+// Driver implements the driver.Connector interface.
+func (c *crtshConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+type crtshDialer struct {
+	ctx   context.Context
+	proxy *url.URL
+}
+
+func (d *crtshDialer) Dial(network, address string) (net.Conn, error) {
+	return http.ProxyDialContext(d.ctx, d.proxy, network, address)
+}
+
+func (d *crtshDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, timeout)
+	defer cancel()
+
+	return http.ProxyDialContext(ctx, d.proxy, network, address)
+}
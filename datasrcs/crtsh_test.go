@@ -0,0 +1,29 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/graph"
+)
+
+func TestCrtshCachedResponseFresh(t *testing.T) {
+	sys, _, _ := setupTestSource(t, "example.com")
+	sys.dbs = []*graph.Graph{graph.NewGraph(graph.NewCayleyGraphMemory())}
+	sys.cfg.GetDataSourceConfig("Crtsh").TTL = 60
+
+	c := NewCrtsh(sys)
+	u := "https://crt.sh/?q=%25.example.com&output=json"
+	c.cacheResponse(sys.cfg, u, "[]")
+
+	if page, err := c.cachedResponse(sys.cfg, u); err != nil || page != "[]" {
+		t.Errorf("Failed to obtain the cached response")
+	}
+
+	sys.cfg.FreshResponses = true
+	if _, err := c.cachedResponse(sys.cfg, u); err == nil {
+		t.Errorf("The cached response was served when fresh responses were requested")
+	}
+}
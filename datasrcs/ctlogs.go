@@ -0,0 +1,415 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+)
+
+const (
+	ctLogBatchSize     = 256
+	ctLogRecentEntries = 8192
+)
+
+// ctLogListURL is the location of the log list maintained for the Chrome certificate transparency policy.
+var ctLogListURL = "https://www.gstatic.com/ct/log_list/v3/log_list.json"
+
+// ctLog is a certificate transparency log that is queried directly.
+type ctLog struct {
+	URL string
+	// The domain names used in CAA records for the certificate authority operating the log
+	Issuers []string
+}
+
+// The logs operated by these certificate authorities are only queried when the CAA records
+// authorize the authority, while the logs of other operators accept certificates from all the authorities.
+var ctLogOperatorIssuers = map[string][]string{
+	"let's encrypt": {"letsencrypt.org"},
+	"digicert":      {"digicert.com", "symantec.com", "geotrust.com", "thawte.com", "rapidssl.com"},
+	"sectigo":       {"sectigo.com", "comodoca.com", "comodo.com", "usertrust.com"},
+}
+
+type ctLogList struct {
+	Operators []struct {
+		Name string `json:"name"`
+		Logs []struct {
+			URL   string `json:"url"`
+			State map[string]struct {
+				Timestamp string `json:"timestamp"`
+			} `json:"state"`
+			TemporalInterval *struct {
+				StartInclusive time.Time `json:"start_inclusive"`
+				EndExclusive   time.Time `json:"end_exclusive"`
+			} `json:"temporal_interval"`
+		} `json:"logs"`
+	} `json:"operators"`
+}
+
+// GoogleCTLogs is the Service that handles access to the Google and certificate authority operated
+// certificate transparency logs.
+type GoogleCTLogs struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+
+	sync.Mutex
+	scanned map[string]struct{}
+}
+
+type ctLogEntry struct {
+	LeafInput string `json:"leaf_input"`
+	ExtraData string `json:"extra_data"`
+}
+
+func init() {
+	RegisterSource("GoogleCTLogs", func(sys systems.System) service.Service { return NewGoogleCTLogs(sys) })
+}
+
+// NewGoogleCTLogs returns he object initialized, but not yet started.
+func NewGoogleCTLogs(sys systems.System) *GoogleCTLogs {
+	g := &GoogleCTLogs{
+		SourceType: requests.CERT,
+		sys:        sys,
+		scanned:    make(map[string]struct{}),
+	}
+
+	g.BaseService = *service.NewBaseService(g, "GoogleCTLogs")
+	return g
+}
+
+// Description implements the Service interface.
+func (g *GoogleCTLogs) Description() string {
+	return g.SourceType
+}
+
+// OnStart implements the Service interface.
+func (g *GoogleCTLogs) OnStart() error {
+	g.SetRateLimit(5)
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (g *GoogleCTLogs) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.DNSRequest); ok {
+		g.dnsRequest(ctx, req)
+	}
+}
+
+func (g *GoogleCTLogs) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil || !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+
+	// The log entries cannot be searched by domain name, so the recent entries
+	// are only scanned once for all the domains in the enumeration
+	uuid := cfg.UUID.String()
+	g.Lock()
+	if _, found := g.scanned[uuid]; found {
+		g.Unlock()
+		return
+	}
+	g.scanned[uuid] = struct{}{}
+	g.Unlock()
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for the most recent certificate entries", g.String()))
+
+	logs, err := g.loadLogs(ctx)
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: Failed to obtain the log list: %v", g.String(), err))
+		return
+	}
+
+	// The logs accepting certificates from all the authorities are scanned first, so the CAA
+	// records stored during the enumeration are available when selecting the authority logs
+	g.scanLogs(ctx, orderCTLogs(logs, nil))
+	g.scanLogs(ctx, orderCTLogs(authorityCTLogs(logs), g.caaIssuers(ctx, cfg.Domains())))
+}
+
+func (g *GoogleCTLogs) scanLogs(ctx context.Context, urls []string) {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, logURL := range urls {
+		select {
+		case <-ctx.Done():
+			return
+		case <-g.Done():
+			return
+		default:
+		}
+
+		if err := g.scanLog(ctx, logURL); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", g.String(), logURL, err))
+		}
+	}
+}
+
+// caaIssuers returns the certificate authorities authorized by the CAA records stored for the domains
+// in the enumeration graph and the graph databases.
+func (g *GoogleCTLogs) caaIssuers(ctx context.Context, domains []string) []string {
+	dbs := g.sys.GraphDatabases()
+	if eg, ok := ctx.Value(requests.ContextGraph).(*graph.Graph); ok && eg != nil {
+		dbs = append([]*graph.Graph{eg}, dbs...)
+	}
+
+	var records []string
+	for _, domain := range domains {
+		for _, db := range dbs {
+			records = append(records, db.ReadCAA(domain)...)
+		}
+	}
+	return resolvers.CAAIssuers(records)
+}
+
+// authorityCTLogs returns the logs operated by certificate authorities.
+func authorityCTLogs(logs []*ctLog) []*ctLog {
+	var results []*ctLog
+
+	for _, l := range logs {
+		if len(l.Issuers) > 0 {
+			results = append(results, l)
+		}
+	}
+	return results
+}
+
+// loadLogs returns the logs from the log list that are currently accepting or serving certificates.
+func (g *GoogleCTLogs) loadLogs(ctx context.Context) ([]*ctLog, error) {
+	page, err := requestWebPage(ctx, g, ctLogListURL, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCTLogList([]byte(page), time.Now())
+}
+
+// parseCTLogList returns the logs in the list that are qualified, usable or read-only and whose
+// temporal interval has not ended. The retired and rejected logs are skipped.
+func parseCTLogList(data []byte, now time.Time) ([]*ctLog, error) {
+	var list ctLogList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	var logs []*ctLog
+	for _, op := range list.Operators {
+		issuers := ctLogOperatorIssuers[strings.ToLower(strings.TrimSpace(op.Name))]
+
+		for _, l := range op.Logs {
+			var current bool
+			for _, state := range []string{"qualified", "usable", "readonly"} {
+				if _, found := l.State[state]; found {
+					current = true
+					break
+				}
+			}
+			if !current || l.URL == "" {
+				continue
+			}
+			// The shards only accept certificates expiring within the temporal interval
+			if ti := l.TemporalInterval; ti != nil && !ti.EndExclusive.IsZero() && !now.Before(ti.EndExclusive) {
+				continue
+			}
+
+			u := l.URL
+			if !strings.HasSuffix(u, "/") {
+				u += "/"
+			}
+			logs = append(logs, &ctLog{URL: u, Issuers: issuers})
+		}
+	}
+
+	if len(logs) == 0 {
+		return nil, errors.New("The log list did not contain any usable logs")
+	}
+	return logs, nil
+}
+
+// orderCTLogs returns the URLs of the logs operated by the authorized certificate authorities,
+// followed by the URLs of the logs that accept certificates from all the authorities.
+func orderCTLogs(logs []*ctLog, issuers []string) []string {
+	var first, last []string
+
+	for _, l := range logs {
+		if len(l.Issuers) == 0 {
+			last = append(last, l.URL)
+			continue
+		}
+
+	loop:
+		for _, issuer := range issuers {
+			for _, ca := range l.Issuers {
+				if issuer == ca || strings.HasSuffix(issuer, "."+ca) {
+					first = append(first, l.URL)
+					break loop
+				}
+			}
+		}
+	}
+	return append(first, last...)
+}
+
+func (g *GoogleCTLogs) scanLog(ctx context.Context, logURL string) error {
+	g.CheckRateLimit()
+	page, err := requestWebPage(ctx, g, logURL+"ct/v1/get-sth", nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var sth struct {
+		TreeSize int64 `json:"tree_size"`
+	}
+	if err := json.Unmarshal([]byte(page), &sth); err != nil {
+		return err
+	}
+	if sth.TreeSize == 0 {
+		return errors.New("The log is empty")
+	}
+
+	start := sth.TreeSize - g.scanEntries(ctx)
+	if start < 0 {
+		start = 0
+	}
+
+	for start < sth.TreeSize {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-g.Done():
+			return nil
+		default:
+		}
+
+		end := start + ctLogBatchSize - 1
+		if end >= sth.TreeSize {
+			end = sth.TreeSize - 1
+		}
+
+		g.CheckRateLimit()
+		u := logURL + "ct/v1/get-entries?start=" + strconv.FormatInt(start, 10) + "&end=" + strconv.FormatInt(end, 10)
+		page, err := requestWebPage(ctx, g, u, nil, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		var resp struct {
+			Entries []ctLogEntry `json:"entries"`
+		}
+		if err := json.Unmarshal([]byte(page), &resp); err != nil {
+			return err
+		}
+		// The log may return fewer entries than requested
+		if len(resp.Entries) == 0 {
+			return nil
+		}
+
+		for i, entry := range resp.Entries {
+			names, notBefore := ctLogEntryNames(&entry)
+			evidence := map[string]string{
+				"log":       logURL,
+				"log_index": strconv.FormatInt(start+int64(i), 10),
+			}
+
+			for _, name := range names {
+				genNewNameEvidenceEvent(ctx, g.sys, g, name, notBefore, evidence)
+			}
+		}
+		start += int64(len(resp.Entries))
+	}
+	return nil
+}
+
+// scanEntries returns the number of recent entries read from each log. When the data source has
+// a maximum number of requests configured, the scan reaches as far back as the requests permit.
+func (g *GoogleCTLogs) scanEntries(ctx context.Context) int64 {
+	entries := int64(ctLogRecentEntries)
+
+	if cfg, _, err := ContextConfigBus(ctx); err == nil {
+		if max := cfg.SourceMaxRequests(g.String()); max > 0 {
+			entries = int64(max) * ctLogBatchSize
+		}
+	}
+	return entries
+}
+
+// ctLogEntryNames parses the X.509 certificate or precertificate from the log entry and returns
+// the names along with the start of the certificate validity period.
+func ctLogEntryNames(entry *ctLogEntry) ([]string, time.Time) {
+	leaf, err := base64.StdEncoding.DecodeString(entry.LeafInput)
+	// Version, leaf type, timestamp and entry type precede the signed entry
+	if err != nil || len(leaf) < 12 {
+		return nil, time.Time{}
+	}
+
+	var der []byte
+	switch binary.BigEndian.Uint16(leaf[10:12]) {
+	case 0: // x509_entry
+		der = ctLogReadCert(leaf[12:])
+	case 1: // precert_entry
+		// The precertificate is provided as the first element of the extra data
+		extra, err := base64.StdEncoding.DecodeString(entry.ExtraData)
+		if err != nil {
+			return nil, time.Time{}
+		}
+		der = ctLogReadCert(extra)
+	}
+	if der == nil {
+		return nil, time.Time{}
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, time.Time{}
+	}
+
+	names := cert.DNSNames
+	if cn := cert.Subject.CommonName; cn != "" {
+		names = append(names, cn)
+	}
+
+	var results []string
+	for _, name := range names {
+		name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "*.")
+
+		if name != "" {
+			results = append(results, name)
+		}
+	}
+	return results, cert.NotBefore
+}
+
+// ctLogReadCert returns the ASN.1 certificate that follows the 24-bit length prefix.
+func ctLogReadCert(data []byte) []byte {
+	if len(data) < 3 {
+		return nil
+	}
+
+	length := int(data[0])<<16 | int(data[1])<<8 | int(data[2])
+	if length == 0 || len(data) < 3+length {
+		return nil
+	}
+	return data[3 : 3+length]
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+const ctLogTestList = `{"operators":[
+	{"name":"Google","logs":[
+		{"url":"https://ct.googleapis.com/logs/argon2021/","state":{"retired":{"timestamp":"2021-01-01T00:00:00Z"}}},
+		{"url":"https://ct.googleapis.com/logs/argon2025h1/","state":{"usable":{"timestamp":"2024-01-01T00:00:00Z"}},
+			"temporal_interval":{"start_inclusive":"2025-01-01T00:00:00Z","end_exclusive":"2025-07-01T00:00:00Z"}},
+		{"url":"https://ct.googleapis.com/logs/argon2026h1","state":{"usable":{"timestamp":"2025-01-01T00:00:00Z"}},
+			"temporal_interval":{"start_inclusive":"2026-01-01T00:00:00Z","end_exclusive":"2026-07-01T00:00:00Z"}}]},
+	{"name":"Let's Encrypt","logs":[
+		{"url":"https://oak.ct.letsencrypt.org/2026h1/","state":{"qualified":{"timestamp":"2025-01-01T00:00:00Z"}}},
+		{"url":"https://sapling.ct.letsencrypt.org/","state":{"rejected":{"timestamp":"2025-01-01T00:00:00Z"}}}]},
+	{"name":"DigiCert","logs":[
+		{"url":"https://wyvern.ct.digicert.com/2026h1/","state":{"readonly":{"timestamp":"2025-01-01T00:00:00Z"}}}]}
+]}`
+
+func TestParseCTLogList(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	logs, err := parseCTLogList([]byte(ctLogTestList), now)
+	if err != nil {
+		t.Fatalf("Failed to parse the log list: %v", err)
+	}
+
+	var urls []string
+	for _, l := range logs {
+		urls = append(urls, l.URL)
+	}
+	want := []string{
+		"https://ct.googleapis.com/logs/argon2026h1/",
+		"https://oak.ct.letsencrypt.org/2026h1/",
+		"https://wyvern.ct.digicert.com/2026h1/",
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("The retired, rejected or expired logs were not skipped: %v", urls)
+	}
+
+	if _, err := parseCTLogList([]byte(`{"operators":[]}`), now); err == nil {
+		t.Errorf("No error was returned for a log list without usable logs")
+	}
+}
+
+func TestOrderCTLogs(t *testing.T) {
+	logs, err := parseCTLogList([]byte(ctLogTestList), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to parse the log list: %v", err)
+	}
+
+	got := orderCTLogs(logs, []string{"letsencrypt.org"})
+	want := []string{
+		"https://oak.ct.letsencrypt.org/2026h1/",
+		"https://ct.googleapis.com/logs/argon2026h1/",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("orderCTLogs returned %v, expected %v", got, want)
+	}
+}
+
+func TestGoogleCTLogsCAAIssuers(t *testing.T) {
+	sys, ctx, _ := setupTestSource(t, "example.com", "example.org")
+
+	eg := graph.NewGraph(graph.NewCayleyGraphMemory())
+	if err := eg.InsertCAA("example.com", "issue letsencrypt.org"); err != nil {
+		t.Fatalf("Failed to insert the CAA record into the enumeration graph: %v", err)
+	}
+	db := graph.NewGraph(graph.NewCayleyGraphMemory())
+	if err := db.InsertCAA("example.org", "issuewild digicert.com; cansignhttpexchanges=yes"); err != nil {
+		t.Fatalf("Failed to insert the CAA record into the graph database: %v", err)
+	}
+	sys.dbs = []*graph.Graph{db}
+	ctx = context.WithValue(ctx, requests.ContextGraph, eg)
+
+	g := NewGoogleCTLogs(sys)
+	got := g.caaIssuers(ctx, []string{"example.com", "example.org"})
+	if want := []string{"letsencrypt.org", "digicert.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("caaIssuers returned %v, expected %v", got, want)
+	}
+}
+
+func TestGoogleCTLogsScanLog(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate the private key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		DNSNames:     []string{"*.api.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("Failed to create the certificate: %v", err)
+	}
+
+	// Version, leaf type, timestamp and the x509_entry type precede the certificate
+	leaf := append(make([]byte, 12), byte(len(der)>>16), byte(len(der)>>8), byte(len(der)))
+	leaf = append(leaf, der...)
+	entry := base64.StdEncoding.EncodeToString(leaf)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/log/ct/v1/get-sth":
+			fmt.Fprint(w, `{"tree_size":1}`)
+		case "/log/ct/v1/get-entries":
+			if r.URL.Query().Get("start") != "0" || r.URL.Query().Get("end") != "0" {
+				t.Errorf("Unexpected range of entries requested: %s", r.URL.RawQuery)
+			}
+			fmt.Fprintf(w, `{"entries":[{"leaf_input":"%s","extra_data":""}]}`, entry)
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	g := NewGoogleCTLogs(sys)
+
+	if err := g.scanLog(ctx, ts.URL+"/log/"); err != nil {
+		t.Fatalf("Failed to scan the log: %v", err)
+	}
+
+	got := names.wait("www.example.com", "api.example.com")
+	for _, name := range []string{"www.example.com", "api.example.com"} {
+		if _, found := got[name]; !found {
+			t.Errorf("The name %s from the certificate was not provided", name)
+		}
+	}
+}
+
+func TestGoogleCTLogsScanEntries(t *testing.T) {
+	sys, ctx, _ := setupTestSource(t, "example.com")
+	g := NewGoogleCTLogs(sys)
+
+	if got := g.scanEntries(ctx); got != ctLogRecentEntries {
+		t.Errorf("scanEntries returned %d without a request limit, expected %d", got, ctLogRecentEntries)
+	}
+
+	sys.cfg.GetDataSourceConfig(g.String()).MaxRequests = 100
+	if got := g.scanEntries(ctx); got != 100*ctLogBatchSize {
+		t.Errorf("scanEntries returned %d with the request limit, expected %d", got, 100*ctLogBatchSize)
+	}
+}
@@ -10,14 +10,14 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/OWASP/Amass/v3/config"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/service"
-	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
 )
 
 // DNSDB is the Service that handles access to the DNSDB data source.
@@ -29,6 +29,10 @@ type DNSDB struct {
 	creds      *config.Credentials
 }
 
+func init() {
+	RegisterSource("DNSDB", func(sys systems.System) service.Service { return NewDNSDB(sys) })
+}
+
 // NewDNSDB returns he object initialized, but not yet started.
 func NewDNSDB(sys systems.System) *DNSDB {
 	d := &DNSDB{
@@ -47,7 +51,7 @@ func (d *DNSDB) Description() string {
 
 // OnStart implements the Service interface.
 func (d *DNSDB) OnStart() error {
-	d.creds = d.sys.Config().GetDataSourceConfig(d.String()).GetCredentials()
+	d.creds = d.sys.Config().APIKey(d.String())
 
 	if d.creds == nil || d.creds.Key == "" {
 		d.sys.Config().Log.Printf("%s: API key data was not provided", d.String())
@@ -58,7 +62,7 @@ func (d *DNSDB) OnStart() error {
 }
 
 func (d *DNSDB) checkConfig() error {
-	creds := d.sys.Config().GetDataSourceConfig(d.String()).GetCredentials()
+	creds := d.sys.Config().APIKey(d.String())
 
 	if creds == nil || creds.Key == "" {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", d.String())
@@ -101,14 +105,14 @@ func (d *DNSDB) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	url := d.getURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, d, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), url, err))
 		return
 	}
 
-	for _, name := range d.parse(ctx, page, req.Domain) {
-		genNewNameEvent(ctx, d.sys, d, name)
+	for name, records := range d.parse(ctx, page, req.Domain) {
+		genNewNameRecordsEvent(ctx, d.sys, d, name, records)
 	}
 }
 
@@ -116,18 +120,24 @@ func (d *DNSDB) getURL(domain string) string {
 	return fmt.Sprintf("https://api.dnsdb.info/lookup/rrset/name/*.%s?limit=10000000", domain)
 }
 
-func (d *DNSDB) parse(ctx context.Context, page, domain string) []string {
+// dnsdbFreshness is how recently the rrset must have been observed for the records to be provided.
+// The names of stale rrsets are still provided, so their current records are obtained by resolving them.
+const dnsdbFreshness = 30 * 24 * time.Hour
+
+// parse returns the names discovered in the rrset results and the A, AAAA and CNAME records for each name.
+func (d *DNSDB) parse(ctx context.Context, page, domain string) map[string][]requests.DNSAnswer {
+	results := make(map[string][]requests.DNSAnswer)
+
 	cfg, _, err := ContextConfigBus(ctx)
 	if err != nil {
-		return []string{}
+		return results
 	}
 
 	re := cfg.DomainRegex(domain)
 	if re == nil {
-		return []string{}
+		return results
 	}
 
-	unique := stringset.New()
 	scanner := bufio.NewScanner(strings.NewReader(page))
 	for scanner.Scan() {
 		// Get the next line of JSON
@@ -137,16 +147,66 @@ func (d *DNSDB) parse(ctx context.Context, page, domain string) []string {
 		}
 
 		var j struct {
-			Name string `json:"rrname"`
+			Name         string          `json:"rrname"`
+			Type         string          `json:"rrtype"`
+			RData        json.RawMessage `json:"rdata"`
+			TimeLast     int64           `json:"time_last"`
+			ZoneTimeLast int64           `json:"zone_time_last"`
 		}
 		err := json.Unmarshal([]byte(line), &j)
 		if err != nil {
 			continue
 		}
-		if re.MatchString(j.Name) {
-			unique.Insert(j.Name)
+
+		name := strings.ToLower(strings.TrimSuffix(j.Name, "."))
+		if !re.MatchString(name) {
+			continue
+		}
+		if _, found := results[name]; !found {
+			results[name] = []requests.DNSAnswer{}
+		}
+
+		rrtype, found := dnsdbRecordTypes[strings.ToUpper(j.Type)]
+		if !found {
+			continue
+		}
+		// Records that have not been observed recently could be out of date
+		last := j.TimeLast
+		if j.ZoneTimeLast > last {
+			last = j.ZoneTimeLast
+		}
+		if time.Since(time.Unix(last, 0)) > dnsdbFreshness {
+			continue
+		}
+		for _, data := range dnsdbRData(j.RData) {
+			results[name] = append(results[name], requests.DNSAnswer{
+				Name: name,
+				Type: rrtype,
+				Data: strings.ToLower(strings.TrimSuffix(data, ".")),
+			})
 		}
 	}
 
-	return unique.Slice()
+	return results
+}
+
+// The DNSDB record types that are provided to the enumeration along with the names.
+var dnsdbRecordTypes = map[string]int{
+	"A":     int(dns.TypeA),
+	"CNAME": int(dns.TypeCNAME),
+	"AAAA":  int(dns.TypeAAAA),
+}
+
+// dnsdbRData handles rdata values provided as either a string or an array of strings.
+func dnsdbRData(raw json.RawMessage) []string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil && single != "" {
+		return []string{single}
+	}
+	return nil
 }
@@ -0,0 +1,45 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestDNSDBParse(t *testing.T) {
+	sys, ctx, _ := setupTestSource(t, "example.com")
+	d := NewDNSDB(sys)
+
+	recent := time.Now().Add(-24 * time.Hour).Unix()
+	stale := time.Now().Add(-2 * dnsdbFreshness).Unix()
+	page := strings.Join([]string{
+		fmt.Sprintf(`{"rrname": "www.example.com.", "rrtype": "A", "rdata": ["93.184.216.34"], "time_last": %d}`, recent),
+		fmt.Sprintf(`{"rrname": "old.example.com.", "rrtype": "A", "rdata": ["93.184.216.35"], "time_last": %d}`, stale),
+		fmt.Sprintf(`{"rrname": "zone.example.com.", "rrtype": "CNAME", "rdata": "www.example.com.", "time_last": %d, "zone_time_last": %d}`, stale, recent),
+		fmt.Sprintf(`{"rrname": "www.example.com.", "rrtype": "TXT", "rdata": ["v=spf1 -all"], "time_last": %d}`, recent),
+		fmt.Sprintf(`{"rrname": "www.example.org.", "rrtype": "A", "rdata": ["93.184.216.36"], "time_last": %d}`, recent),
+		"not json",
+	}, "\n")
+
+	results := d.parse(ctx, page, "example.com")
+	if len(results) != 3 {
+		t.Fatalf("The parse returned %d names instead of the expected 3: %v", len(results), results)
+	}
+
+	if recs := results["www.example.com"]; len(recs) != 1 || recs[0].Type != int(dns.TypeA) || recs[0].Data != "93.184.216.34" {
+		t.Errorf("The recent A record was not provided: %v", recs)
+	}
+	if recs := results["zone.example.com"]; len(recs) != 1 || recs[0].Type != int(dns.TypeCNAME) || recs[0].Data != "www.example.com" {
+		t.Errorf("The CNAME record recently observed in the zone file was not provided: %v", recs)
+	}
+	// The name of the stale rrset is still provided, so it will be resolved
+	if recs, found := results["old.example.com"]; !found || len(recs) != 0 {
+		t.Errorf("The stale record was provided or the name was dropped: %v", recs)
+	}
+}
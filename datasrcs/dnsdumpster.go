@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strings"
 
+	amassnet "github.com/OWASP/Amass/v3/net"
 	amasshttp "github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
@@ -19,6 +20,11 @@ import (
 	"github.com/caffix/service"
 )
 
+var (
+	dumpsterRowRE  = regexp.MustCompile(`(?s)<tr>.*?</tr>`)
+	dumpsterAddrRE = regexp.MustCompile(amassnet.IPv4RE)
+)
+
 // DNSDumpster is the Service that handles access to the DNSDumpster data source.
 type DNSDumpster struct {
 	service.BaseService
@@ -27,6 +33,10 @@ type DNSDumpster struct {
 	sys        systems.System
 }
 
+func init() {
+	RegisterSource("DNSDumpster", func(sys systems.System) service.Service { return NewDNSDumpster(sys) })
+}
+
 // NewDNSDumpster returns he object initialized, but not yet started.
 func NewDNSDumpster(sys systems.System) *DNSDumpster {
 	d := &DNSDumpster{
@@ -71,7 +81,7 @@ func (d *DNSDumpster) dnsRequest(ctx context.Context, req *requests.DNSRequest)
 		fmt.Sprintf("Querying %s for %s subdomains", d.String(), req.Domain))
 
 	u := "https://dnsdumpster.com/"
-	page, err := amasshttp.RequestWebPage(ctx, u, nil, nil, nil)
+	page, err := requestWebPage(ctx, d, u, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", d.String(), u, err))
 		return
@@ -94,6 +104,22 @@ func (d *DNSDumpster) dnsRequest(ctx context.Context, req *requests.DNSRequest)
 	for _, sd := range re.FindAllString(page, -1) {
 		genNewNameEvent(ctx, d.sys, d, amasshttp.CleanName(sd))
 	}
+
+	// Each row of the results tables provides the addresses for the name
+	for _, row := range dumpsterRowRE.FindAllString(page, -1) {
+		if re.FindString(row) == "" {
+			continue
+		}
+
+		for _, addr := range dumpsterAddrRE.FindAllString(row, -1) {
+			bus.Publish(requests.NewAddrTopic, eventbus.PriorityHigh, &requests.AddrRequest{
+				Address: addr,
+				Domain:  req.Domain,
+				Tag:     d.SourceType,
+				Source:  d.String(),
+			})
+		}
+	}
 }
 
 func (d *DNSDumpster) getCSRFToken(page string) string {
@@ -106,10 +132,13 @@ func (d *DNSDumpster) getCSRFToken(page string) string {
 }
 
 func (d *DNSDumpster) postForm(ctx context.Context, token, domain string) (string, error) {
-	_, bus, err := ContextConfigBus(ctx)
+	cfg, bus, err := ContextConfigBus(ctx)
 	if err != nil {
 		return "", fmt.Errorf("%s failed to obtain the EventBus from Context", d.String())
 	}
+	if proxy := cfg.SourceProxy(d.String()); proxy != nil {
+		ctx = amasshttp.WithProxy(ctx, proxy)
+	}
 
 	params := url.Values{
 		"csrfmiddlewaretoken": {token},
@@ -130,9 +159,7 @@ func (d *DNSDumpster) postForm(ctx context.Context, token, domain string) (strin
 	}
 	req.AddCookie(cookie)
 
-	req.Header.Set("User-Agent", amasshttp.UserAgent)
-	req.Header.Set("Accept", amasshttp.Accept)
-	req.Header.Set("Accept-Language", amasshttp.AcceptLang)
+	amasshttp.SetBrowserHeaders(req)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Referer", "https://dnsdumpster.com")
 	req.Header.Set("X-CSRF-Token", token)
@@ -0,0 +1,251 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+)
+
+// FDNS is the Service that handles access to mirrors of the Rapid7 Forward DNS dataset.
+type FDNS struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+	api        string
+	path       string
+}
+
+func init() {
+	RegisterSource("FDNS", func(sys systems.System) service.Service { return NewFDNS(sys) })
+}
+
+// NewFDNS returns he object initialized, but not yet started.
+func NewFDNS(sys systems.System) *FDNS {
+	f := &FDNS{
+		SourceType: requests.API,
+		sys:        sys,
+	}
+
+	f.BaseService = *service.NewBaseService(f, "FDNS")
+	return f
+}
+
+// Description implements the Service interface.
+func (f *FDNS) Description() string {
+	return f.SourceType
+}
+
+// OnStart implements the Service interface.
+func (f *FDNS) OnStart() error {
+	if dsc := f.sys.Config().GetDataSourceConfig(f.String()); dsc != nil {
+		f.api = strings.TrimSuffix(strings.TrimSpace(dsc.URL), "/")
+		f.path = strings.TrimSpace(dsc.Dataset)
+	}
+
+	if f.api == "" && f.path == "" {
+		f.sys.Config().Log.Printf("%s: The dataset location was not provided", f.String())
+	}
+
+	f.SetRateLimit(10)
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (f *FDNS) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.DNSRequest); ok {
+		f.dnsRequest(ctx, req)
+	}
+}
+
+func (f *FDNS) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	if (f.api == "" && f.path == "") || !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for %s subdomains", f.String(), req.Domain))
+
+	if f.path != "" {
+		if err := f.searchFile(ctx, req.Domain); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", f.String(), f.path, err))
+		}
+	}
+	if f.api != "" {
+		f.queryAPI(ctx, req.Domain)
+	}
+}
+
+// queryAPI obtains the records from a dnsgrep server hosting the dataset.
+func (f *FDNS) queryAPI(ctx context.Context, domain string) {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	f.CheckRateLimit()
+	u := f.api + "/dns?q=" + url.QueryEscape("."+domain)
+	page, err := requestWebPage(ctx, f, u, nil, nil, nil)
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", f.String(), u, err))
+		return
+	}
+
+	var m struct {
+		FDNS []string `json:"FDNS_A"`
+		RDNS []string `json:"RDNS"`
+	}
+	if err := json.Unmarshal([]byte(page), &m); err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", f.String(), u, err))
+		return
+	}
+
+	// Each entry is provided as the value followed by the name
+	for _, entry := range append(m.FDNS, m.RDNS...) {
+		if parts := strings.SplitN(entry, ",", 2); len(parts) == 2 {
+			f.processRecord(ctx, domain, parts[1], parts[0])
+		}
+	}
+}
+
+// searchFile performs a binary search of the sorted dataset file prepared for dnsgrep.
+// Each line of the file holds the value and name separated by a comma, with all the characters reversed.
+func (f *FDNS) searchFile(ctx context.Context, domain string) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// All the names belonging to the domain share the reversed domain as a prefix
+	prefix := reverseString(domain)
+	offset, err := fdnsFirstLine(file, info.Size(), prefix)
+	if err != nil {
+		return err
+	}
+
+	r, err := fdnsReaderAt(file, offset)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-f.Done():
+			return nil
+		default:
+		}
+
+		line, err := r.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line != "" && line > prefix && !strings.HasPrefix(line, prefix) {
+			break
+		}
+
+		if parts := strings.SplitN(line, ",", 2); len(parts) == 2 {
+			f.processRecord(ctx, domain, reverseString(parts[0]), reverseString(parts[1]))
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FDNS) processRecord(ctx context.Context, domain, name, value string) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != domain && !strings.HasSuffix(name, "."+domain) {
+		return
+	}
+
+	genNewNameEvent(ctx, f.sys, f, name)
+	// Values from the CNAME dataset can reveal additional names
+	if value = strings.ToLower(strings.TrimSpace(value)); net.ParseIP(value) == nil {
+		genNewNameEvent(ctx, f.sys, f, value)
+	}
+}
+
+// fdnsFirstLine returns the offset of the first line in the sorted file that is not less than the prefix.
+func fdnsFirstLine(file *os.File, size int64, prefix string) (int64, error) {
+	lo, hi := int64(0), size
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		r, err := fdnsReaderAt(file, mid)
+		if err != nil {
+			return 0, err
+		}
+
+		line, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		if line == "" || strings.TrimSpace(line) >= prefix {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// fdnsReaderAt returns a reader positioned at the first line starting at or after the offset.
+func fdnsReaderAt(file *os.File, offset int64) (*bufio.Reader, error) {
+	if offset == 0 {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return bufio.NewReader(file), nil
+	}
+
+	if _, err := file.Seek(offset-1, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(file)
+	// Discard the remainder of the line that the offset falls within
+	if _, err := r.ReadString('\n'); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return r, nil
+}
+
+func reverseString(s string) string {
+	runes := []rune(s)
+
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// writeTestDataset writes the records as the sorted dataset prepared for dnsgrep and returns the lines.
+func writeTestDataset(t *testing.T, records [][2]string) (string, []string) {
+	var lines []string
+	for _, rec := range records {
+		lines = append(lines, reverseString(rec[1]+","+rec[0]))
+	}
+	sort.Strings(lines)
+
+	dir, err := ioutil.TempDir("", "fdns")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "fdns_a.sort.txt")
+	if err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write the dataset: %v", err)
+	}
+	return path, lines
+}
+
+func testDatasetRecords() [][2]string {
+	records := [][2]string{
+		{"www.example.com", "93.184.216.34"},
+		{"api.example.com", "93.184.216.35"},
+		{"mail.example.com", "mx.provider.net"},
+		{"notexample.com", "93.184.216.36"},
+		{"www.example.org", "93.184.216.37"},
+	}
+	// Lines of varying lengths surround the records of interest
+	for i := 0; i < 200; i++ {
+		records = append(records, [2]string{
+			fmt.Sprintf("host%d.%s.com", i, strings.Repeat("z", i%17)),
+			fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+		})
+		records = append(records, [2]string{fmt.Sprintf("a%d.net", i), "93.184.216.1"})
+	}
+	return records
+}
+
+func TestFDNSFirstLine(t *testing.T) {
+	path, lines := writeTestDataset(t, testDatasetRecords())
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open the dataset: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatalf("Failed to stat the dataset: %v", err)
+	}
+
+	prefixes := []string{"", "0", "moc.elpmaxe", "moc.elpmaxe.", "gro.elpmaxe", "ten.", "moc", lines[0], lines[len(lines)-1], "~"}
+	for _, line := range lines[:20] {
+		prefixes = append(prefixes, line)
+	}
+
+	for _, prefix := range prefixes {
+		// The expected line is the first one in the file that is not less than the prefix
+		var want string
+		for _, line := range lines {
+			if line >= prefix {
+				want = line
+				break
+			}
+		}
+
+		offset, err := fdnsFirstLine(file, info.Size(), prefix)
+		if err != nil {
+			t.Fatalf("fdnsFirstLine(%q) returned an error: %v", prefix, err)
+		}
+
+		r, err := fdnsReaderAt(file, offset)
+		if err != nil {
+			t.Fatalf("fdnsReaderAt(%d) returned an error: %v", offset, err)
+		}
+		got, _ := r.ReadString('\n')
+		if got = strings.TrimSpace(got); got != want {
+			t.Errorf("fdnsFirstLine(%q) was positioned at %q, expected %q", prefix, got, want)
+		}
+	}
+}
+
+func TestFDNSSearchFile(t *testing.T) {
+	path, _ := writeTestDataset(t, testDatasetRecords())
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	f := NewFDNS(sys)
+	f.path = path
+
+	if err := f.searchFile(ctx, "example.com"); err != nil {
+		t.Fatalf("The dataset search failed: %v", err)
+	}
+
+	expected := []string{"www.example.com", "api.example.com", "mail.example.com"}
+	got := names.wait(expected...)
+	for _, name := range expected {
+		if _, found := got[name]; !found {
+			t.Errorf("The dataset search did not provide %s", name)
+		}
+	}
+	for _, name := range []string{"notexample.com", "www.example.org"} {
+		if _, found := got[name]; found {
+			t.Errorf("The dataset search provided %s", name)
+		}
+	}
+
+	f.path = filepath.Join(filepath.Dir(path), "missing.txt")
+	if err := f.searchFile(ctx, "example.com"); err == nil {
+		t.Errorf("The missing dataset was not reported")
+	}
+}
+
+func TestFDNSQueryAPI(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+
+		fmt.Fprint(w, `{"FDNS_A": ["93.184.216.34,www.example.com", "93.184.216.36,notexample.com"],`+
+			`"RDNS": ["93.184.216.35,api.example.com"]}`)
+	}))
+	defer ts.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	sys.cfg.GetDataSourceConfig("FDNS").URL = ts.URL + "/"
+
+	f := NewFDNS(sys)
+	if err := f.OnStart(); err != nil {
+		t.Fatalf("The data source failed to start: %v", err)
+	}
+	if f.api != ts.URL || f.path != "" {
+		t.Fatalf("The dataset settings were not loaded from the configuration")
+	}
+
+	f.dnsRequest(ctx, &requests.DNSRequest{Domain: "example.com"})
+	if query != ".example.com" {
+		t.Errorf("The dnsgrep server was sent the query %q", query)
+	}
+
+	got := names.wait("www.example.com", "api.example.com")
+	for _, name := range []string{"www.example.com", "api.example.com"} {
+		if _, found := got[name]; !found {
+			t.Errorf("The dnsgrep response did not provide %s", name)
+		}
+	}
+	if _, found := got["notexample.com"]; found {
+		t.Errorf("The dnsgrep response provided a name out of scope")
+	}
+}
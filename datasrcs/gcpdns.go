@@ -0,0 +1,212 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	gcpDNSBaseURL = "https://dns.googleapis.com/dns/v1/projects/"
+	gcpDNSScope   = "https://www.googleapis.com/auth/ndev.clouddns.readonly"
+)
+
+// GoogleCloudDNS is the Service that handles access to the managed zones in Google Cloud DNS.
+type GoogleCloudDNS struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+	project    string
+	tokens     oauth2.TokenSource
+	baseURL    string
+}
+
+func init() {
+	RegisterSource("GoogleCloudDNS", func(sys systems.System) service.Service { return NewGoogleCloudDNS(sys) })
+}
+
+// NewGoogleCloudDNS returns he object initialized, but not yet started.
+func NewGoogleCloudDNS(sys systems.System) *GoogleCloudDNS {
+	g := &GoogleCloudDNS{
+		SourceType: requests.API,
+		sys:        sys,
+		baseURL:    gcpDNSBaseURL,
+	}
+
+	g.BaseService = *service.NewBaseService(g, "GoogleCloudDNS")
+	return g
+}
+
+// Description implements the Service interface.
+func (g *GoogleCloudDNS) Description() string {
+	return g.SourceType
+}
+
+// OnStart implements the Service interface.
+func (g *GoogleCloudDNS) OnStart() error {
+	creds := g.sys.Config().APIKey(g.String())
+
+	if creds == nil || creds.KeyFile == "" {
+		g.sys.Config().Log.Printf("%s: API key data was not provided", g.String())
+	} else if gcreds, err := g.credentials(creds.KeyFile); err != nil {
+		g.sys.Config().Log.Printf("%s: %v", g.String(), err)
+	} else {
+		// The project of the service account is used when another was not configured
+		g.project = creds.Project
+		if g.project == "" {
+			g.project = gcreds.ProjectID
+		}
+		if g.project == "" {
+			g.sys.Config().Log.Printf("%s: The project was not provided", g.String())
+		} else {
+			g.tokens = gcreds.TokenSource
+		}
+	}
+
+	g.SetRateLimit(5)
+	return nil
+}
+
+// credentials returns the Google credentials obtained from the service account key file at the provided path.
+func (g *GoogleCloudDNS) credentials(path string) (*google.Credentials, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the service account key file: %v", err)
+	}
+
+	// The access tokens are requested through the proxy configured for the data source
+	client := http.ClientWithProxy(g.sys.Config().SourceProxy(g.String()))
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+
+	creds, err := google.CredentialsFromJSON(ctx, data, gcpDNSScope)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse the service account key file: %v", err)
+	}
+	return creds, nil
+}
+
+// OnRequest implements the Service interface.
+func (g *GoogleCloudDNS) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.DNSRequest); ok {
+		g.dnsRequest(ctx, req)
+	}
+}
+
+func (g *GoogleCloudDNS) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	if g.tokens == nil || !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for %s subdomains", g.String(), req.Domain))
+
+	var token string
+	for {
+		var zones struct {
+			ManagedZones []struct {
+				Name    string `json:"name"`
+				DNSName string `json:"dnsName"`
+			} `json:"managedZones"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+
+		u := g.baseURL + url.PathEscape(g.project) + "/managedZones"
+		if err := g.query(ctx, u, token, &zones); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", g.String(), err))
+			return
+		}
+
+		for _, zone := range zones.ManagedZones {
+			if zoneMatchesDomain(zone.DNSName, req.Domain) {
+				g.zoneRecords(ctx, req.Domain, zone.Name)
+			}
+		}
+
+		if zones.NextPageToken == "" {
+			break
+		}
+		token = zones.NextPageToken
+	}
+}
+
+func (g *GoogleCloudDNS) zoneRecords(ctx context.Context, domain, zone string) {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	var token string
+	for {
+		var sets struct {
+			RRSets []struct {
+				Name    string   `json:"name"`
+				Type    string   `json:"type"`
+				RRDatas []string `json:"rrdatas"`
+			} `json:"rrsets"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+
+		u := g.baseURL + url.PathEscape(g.project) + "/managedZones/" + url.PathEscape(zone) + "/rrsets"
+		if err := g.query(ctx, u, token, &sets); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", g.String(), zone, err))
+			return
+		}
+
+		var entries []zoneEntry
+		for _, set := range sets.RRSets {
+			entry := zoneEntry{Name: set.Name, Type: set.Type}
+			if len(set.RRDatas) == 0 {
+				entries = append(entries, entry)
+			}
+			for _, data := range set.RRDatas {
+				entry.Data = data
+				entries = append(entries, entry)
+			}
+		}
+		genZoneRecordEvents(ctx, g.sys, g, domain, entries)
+
+		if sets.NextPageToken == "" {
+			break
+		}
+		token = sets.NextPageToken
+	}
+}
+
+func (g *GoogleCloudDNS) query(ctx context.Context, u, pageToken string, v interface{}) error {
+	if pageToken != "" {
+		u += "?pageToken=" + url.QueryEscape(pageToken)
+	}
+
+	token, err := g.tokens.Token()
+	if err != nil {
+		return fmt.Errorf("Failed to obtain the access token: %v", err)
+	}
+
+	g.CheckRateLimit()
+	headers := map[string]string{"Authorization": "Bearer " + token.AccessToken}
+	page, err := requestWebPage(ctx, g, u, nil, headers, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %v", u, err)
+	}
+
+	return json.Unmarshal([]byte(page), v)
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/miekg/dns"
+)
+
+func TestGoogleCloudDNSZoneRecords(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/token" {
+			fmt.Fprint(w, `{"access_token":"token","token_type":"Bearer","expires_in":3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/amass-project/managedZones":
+			fmt.Fprint(w, `{"managedZones":[{"name":"zone1","dnsName":"example.com."},{"name":"zone2","dnsName":"example.org."}]}`)
+		case "/amass-project/managedZones/zone1/rrsets":
+			if r.URL.Query().Get("pageToken") == "" {
+				fmt.Fprint(w, `{"rrsets":[{"name":"www.example.com.","type":"A","rrdatas":["192.0.2.1"]}],"nextPageToken":"next"}`)
+				return
+			}
+			fmt.Fprint(w, `{"rrsets":[{"name":"example.com.","type":"CAA","rrdatas":["0 issue \"ca.example.net\""]}]}`)
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	sys.cfg.GetDataSourceConfig("GoogleCloudDNS").AddCredentials(&config.Credentials{
+		Name:    "account",
+		KeyFile: gcpTestKeyFile(t, ts.URL+"/token"),
+	})
+
+	g := NewGoogleCloudDNS(sys)
+	g.baseURL = ts.URL + "/"
+	if err := g.OnStart(); err != nil || g.tokens == nil {
+		t.Fatalf("Failed to start the data source using the service account key file")
+	}
+	if g.project != "amass-project" {
+		t.Errorf("The project was not obtained from the service account key file")
+	}
+
+	g.dnsRequest(ctx, &requests.DNSRequest{Domain: "example.com"})
+	got := names.wait("www.example.com", "example.com")
+
+	if !checkTestRecords(got["www.example.com"], int(dns.TypeA), "192.0.2.1") {
+		t.Errorf("The record from the first page was not provided")
+	}
+	if _, found := got["example.com"]; !found {
+		t.Errorf("The name of the CAA record from the second page was not provided")
+	}
+}
+
+func gcpTestKeyFile(t *testing.T, tokenURL string) string {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate the private key: %v", err)
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "amass-project",
+		"private_key_id": "1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})),
+		"client_email":   "amass@amass-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode the service account key: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write the service account key file: %v", err)
+	}
+	return path
+}
@@ -4,17 +4,22 @@
 package datasrcs
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 
-	"github.com/OWASP/Amass/v3/net/http"
+	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/service"
 )
 
+var pasteAddrRE = regexp.MustCompile(amassnet.IPv4RE)
+
 // Pastebin is the Service that handles access to the Pastebin data source.
 type Pastebin struct {
 	service.BaseService
@@ -23,6 +28,10 @@ type Pastebin struct {
 	sys        systems.System
 }
 
+func init() {
+	RegisterSource("Pastebin", func(sys systems.System) service.Service { return NewPastebin(sys) })
+}
+
 // NewPastebin returns he object initialized, but not yet started.
 func NewPastebin(sys systems.System) *Pastebin {
 	p := &Pastebin{
@@ -75,45 +84,85 @@ func (p *Pastebin) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	for _, id := range ids {
+		select {
+		case <-p.Done():
+			return
+		default:
+		}
+
+		p.CheckRateLimit()
 		url := p.webURLDumpData(id)
-		page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+		page, err := requestWebPage(ctx, p, url, nil, nil, nil)
 		if err != nil {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", p.String(), url, err))
-			return
+			continue
+		}
+
+		// The dump content is provided within the JSON response
+		var dump struct {
+			Content string `json:"content"`
+		}
+		if err := json.Unmarshal([]byte(page), &dump); err == nil && dump.Content != "" {
+			page = dump.Content
+		}
+
+		p.processContent(ctx, req.Domain, re, page)
+	}
+}
+
+// processContent extracts the in-scope names and the addresses appearing alongside them in the paste.
+func (p *Pastebin) processContent(ctx context.Context, domain string, re *regexp.Regexp, content string) {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		names := re.FindAllString(line, -1)
+		if len(names) == 0 {
+			continue
 		}
 
-		for _, name := range re.FindAllString(page, -1) {
+		for _, name := range names {
 			genNewNameEvent(ctx, p.sys, p, name)
 		}
+
+		for _, addr := range pasteAddrRE.FindAllString(line, -1) {
+			bus.Publish(requests.NewAddrTopic, eventbus.PriorityHigh, &requests.AddrRequest{
+				Address: addr,
+				Domain:  domain,
+				Tag:     p.SourceType,
+				Source:  p.String(),
+			})
+		}
 	}
 }
 
 // Extract the IDs from the pastebin Web response.
 func (p *Pastebin) extractIDs(ctx context.Context, domain string) ([]string, error) {
 	url := p.webURLDumpIDs(domain)
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := requestWebPage(ctx, p, url, nil, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	// Extract the response given by pastebin
-	var d struct {
-		Search string `json:"search"`
-		Count  int    `json:"count"`
-		Items  []struct {
-			ID   string `json:"id"`
-			Tags string `json:"tags"`
-			Time string `json:"time"`
-		} `json:"data"`
+	var items []struct {
+		ID string `json:"id"`
 	}
-	err = json.Unmarshal([]byte(page), &d)
-	if err != nil {
+	if err := json.Unmarshal([]byte(page), &items); err != nil {
 		return nil, err
 	}
 
 	var ids []string
-	for _, item := range d.Items {
-		ids = append(ids, item.ID)
+	for _, item := range items {
+		if item.ID != "" {
+			ids = append(ids, item.ID)
+		}
 	}
 
 	return ids, nil
@@ -121,10 +170,10 @@ func (p *Pastebin) extractIDs(ctx context.Context, domain string) ([]string, err
 
 // Returns the Web URL to fetch all dump ids for a given doamin.
 func (p *Pastebin) webURLDumpIDs(domain string) string {
-	return fmt.Sprintf("https://psbdmp.ws/api/search/%s", domain)
+	return fmt.Sprintf("https://psbdmp.ws/api/v3/search/%s", domain)
 }
 
 // Returns the Web URL to get all dumps for a given doamin.
 func (p *Pastebin) webURLDumpData(id string) string {
-	return fmt.Sprintf("https://psbdmp.ws/api/dump/get/%s", id)
+	return fmt.Sprintf("https://psbdmp.ws/api/v3/dump/%s", id)
 }
@@ -12,7 +12,6 @@ import (
 	"strings"
 	"time"
 
-	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
@@ -37,6 +36,10 @@ type RADb struct {
 	addr       string
 }
 
+func init() {
+	RegisterSource("RADb", func(sys systems.System) service.Service { return NewRADb(sys) })
+}
+
 // NewRADb returns he object initialized, but not yet started.
 func NewRADb(sys systems.System) *RADb {
 	r := &RADb{
@@ -117,7 +120,7 @@ func (r *RADb) executeASNAddrQuery(ctx context.Context, addr string) {
 
 	url := r.getIPURL("arin", addr)
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, r, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return
@@ -178,7 +181,7 @@ func (r *RADb) executeASNQuery(ctx context.Context, asn int, addr, prefix string
 	numRateLimitChecks(r, 2)
 	url := r.getASNURL("arin", strconv.Itoa(asn))
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, r, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return
@@ -261,7 +264,7 @@ func (r *RADb) netblocks(ctx context.Context, asn int) stringset.Set {
 	numRateLimitChecks(r, 2)
 	url := r.getNetblocksURL(strconv.Itoa(asn))
 	headers := map[string]string{"Content-Type": "application/json"}
-	page, err := http.RequestWebPage(ctx, url, nil, headers, nil)
+	page, err := requestWebPage(ctx, r, url, nil, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return netblocks
@@ -353,7 +356,7 @@ func (r *RADb) ipToASN(ctx context.Context, cidr string) int {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	conn, err := amassnet.DialContext(ctx, "tcp", r.addr+":43")
+	conn, err := http.ProxyDialContext(ctx, r.sys.Config().SourceProxy(r.String()), "tcp", r.addr+":43")
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", r.String(), err))
 		return 0
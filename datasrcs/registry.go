@@ -0,0 +1,62 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/service"
+)
+
+// SourceConstructor returns a data source service initialized, but not yet started.
+type SourceConstructor func(sys systems.System) service.Service
+
+var registry = struct {
+	sync.Mutex
+	sources map[string]SourceConstructor
+}{sources: make(map[string]SourceConstructor)}
+
+// RegisterSource makes the data source constructor available by name.
+// Registering a constructor with a name already in use replaces the previous constructor.
+func RegisterSource(name string, fn SourceConstructor) {
+	name = strings.TrimSpace(name)
+	if name == "" || fn == nil {
+		return
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.sources[name] = fn
+}
+
+// RegisteredSourceNames returns the sorted names of the registered data sources.
+func RegisteredSourceNames() []string {
+	registry.Lock()
+	defer registry.Unlock()
+
+	var names []string
+	for name := range registry.sources {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// NewRegisteredSource returns the named data source initialized, but not yet started.
+// Nil is returned when no data source has been registered with the name.
+func NewRegisteredSource(name string, sys systems.System) service.Service {
+	registry.Lock()
+	fn, found := registry.sources[strings.TrimSpace(name)]
+	registry.Unlock()
+
+	if !found {
+		return nil
+	}
+	return fn(sys)
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// Paths of the APIs that are polled until the results are ready, which must never be served from the cache
+var pollingPathRE = regexp.MustCompile(`(?i)/(result|status)(/|$)`)
+
+// ResponseCache reuses the data source responses kept by the graph databases from earlier enumerations.
+// The responses are kept for the minimum TTL of the data sources, and a request made more than once during
+// the same enumeration is always sent, so the APIs polled for results receive every request.
+type ResponseCache struct {
+	sync.Mutex
+	cfg  *config.Config
+	dbs  []*graph.Graph
+	sent map[string]struct{}
+}
+
+// NewResponseCache returns the ResponseCache for the graph databases, or nil when the responses are not cached.
+func NewResponseCache(cfg *config.Config, dbs []*graph.Graph) *ResponseCache {
+	if !cfg.ResponseCache || len(dbs) == 0 {
+		return nil
+	}
+
+	return &ResponseCache{
+		cfg:  cfg,
+		dbs:  dbs,
+		sent: make(map[string]struct{}),
+	}
+}
+
+// lookup returns the cached response to the request from an earlier enumeration.
+func (rc *ResponseCache) lookup(src, key string) (string, bool) {
+	// The requests already sent during this enumeration are never served from the cache
+	rc.Lock()
+	_, sent := rc.sent[src+" "+key]
+	rc.sent[src+" "+key] = struct{}{}
+	rc.Unlock()
+
+	if sent || rc.cfg.FreshResponses {
+		return "", false
+	}
+
+	ttl := rc.cfg.MinimumTTL
+	if dsc := rc.cfg.GetDataSourceConfig(src); dsc != nil && dsc.TTL > ttl {
+		ttl = dsc.TTL
+	}
+
+	for _, db := range rc.dbs {
+		if page, err := db.GetSourceData(src, key, ttl); err == nil {
+			return page, true
+		}
+	}
+	return "", false
+}
+
+// store keeps the response to the request for the later enumerations.
+func (rc *ResponseCache) store(src, tag, key, page string) error {
+	var err error
+
+	for _, db := range rc.dbs {
+		if e := db.CacheSourceData(src, tag, key, page); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// responseCacheKey returns the cache key of the request, or false when the request must not be cached.
+// The key includes the headers and credentials, so responses are only reused for the same auth context.
+func responseCacheKey(u string, body io.Reader, headers map[string]string, auth *http.BasicAuth) (string, bool) {
+	// Only the idempotent requests are cached
+	if body != nil {
+		return "", false
+	}
+
+	parsed, err := url.Parse(u)
+	if err != nil || pollingPathRE.MatchString(parsed.Path) {
+		return "", false
+	}
+
+	var parts []string
+	for k, v := range headers {
+		parts = append(parts, strings.ToLower(k)+":"+v)
+	}
+	if auth != nil {
+		parts = append(parts, auth.Username+":"+auth.Password)
+	}
+	return cacheKey(parsed, parts), true
+}
+
+// cacheKey returns the cache key of the URL without the credentials it contains. The credentials and
+// other parts provided are replaced by their digest, so the keys kept in the graph do not reveal them.
+func cacheKey(u *url.URL, parts []string) string {
+	key := *u
+
+	if key.User != nil {
+		parts = append(parts, "userinfo:"+key.User.String())
+		key.User = nil
+	}
+
+	q := key.Query()
+	var removed bool
+	for k, values := range q {
+		if !sensitiveRE.MatchString(k) {
+			continue
+		}
+
+		for _, v := range values {
+			parts = append(parts, "query:"+k+"="+v)
+		}
+		q.Del(k)
+		removed = true
+	}
+	if removed {
+		key.RawQuery = q.Encode()
+	}
+
+	if len(parts) == 0 {
+		return key.String()
+	}
+
+	sort.Strings(parts)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return key.String() + "#" + hex.EncodeToString(sum[:8])
+}
+
+// urlCacheKey returns the cache key for the responses that data sources cache themselves.
+func urlCacheKey(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		sum := sha256.Sum256([]byte(u))
+		return hex.EncodeToString(sum[:])
+	}
+	return cacheKey(parsed, nil)
+}
+
+func contextResponseCache(ctx context.Context) *ResponseCache {
+	if c := ctx.Value(requests.ContextResponseCache); c != nil {
+		if cache, ok := c.(*ResponseCache); ok {
+			return cache
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+)
+
+// RESTSource is the Service that handles access to a REST API declared in the configuration.
+type RESTSource struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+	decl       *config.RESTSourceConfig
+	creds      *config.Credentials
+}
+
+// NewRESTSource returns he object initialized, but not yet started.
+func NewRESTSource(decl *config.RESTSourceConfig, sys systems.System) *RESTSource {
+	r := &RESTSource{
+		SourceType: requests.API,
+		sys:        sys,
+		decl:       decl,
+	}
+
+	r.BaseService = *service.NewBaseService(r, decl.Name)
+	return r
+}
+
+// Description implements the Service interface.
+func (r *RESTSource) Description() string {
+	return r.SourceType
+}
+
+// OnStart implements the Service interface.
+func (r *RESTSource) OnStart() error {
+	r.creds = r.sys.Config().APIKey(r.String())
+
+	if r.decl.AuthHeader != "" || strings.Contains(r.decl.URL, "{key}") {
+		if r.creds == nil || r.creds.Key == "" {
+			r.sys.Config().Log.Printf("%s: API key data was not provided", r.String())
+		}
+	}
+
+	r.SetRateLimit(r.decl.RateLimit)
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (r *RESTSource) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.DNSRequest); ok {
+		r.dnsRequest(ctx, req)
+	}
+}
+
+func (r *RESTSource) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	if !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+	// Sources that require an API key are skipped when the key is missing
+	if (r.decl.AuthHeader != "" || strings.Contains(r.decl.URL, "{key}")) && (r.creds == nil || r.creds.Key == "") {
+		return
+	}
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for %s subdomains", r.String(), req.Domain))
+
+	headers := make(map[string]string)
+	if r.decl.AuthHeader != "" {
+		headers[r.decl.AuthHeader] = r.creds.Key
+	}
+
+	u := r.expandURL(r.decl.URL, req.Domain, 1, "")
+	host := urlHost(u)
+	for page := 1; u != "" && page <= r.decl.MaxPages; page++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.Done():
+			return
+		default:
+		}
+
+		// The API key is not disclosed to the other hosts provided as the next page
+		hdrs := headers
+		if urlHost(u) != host {
+			hdrs = nil
+		}
+
+		r.CheckRateLimit()
+		resp, err := requestWebPage(ctx, r, u, nil, hdrs, nil)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), u, err))
+			return
+		}
+
+		var v interface{}
+		if err := json.Unmarshal([]byte(resp), &v); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), u, err))
+			return
+		}
+
+		var count int
+		for _, path := range r.decl.Names {
+			for _, name := range jsonPathValues(v, path) {
+				name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "*."))
+				if name == "" {
+					continue
+				}
+				if r.decl.AppendDomain && name != req.Domain && !strings.HasSuffix(name, "."+req.Domain) {
+					name = name + "." + req.Domain
+				}
+
+				genNewNameEvent(ctx, r.sys, r, name)
+				count++
+			}
+		}
+
+		u = ""
+		// Pagination stops when the page is empty or the next page is not identified
+		if count == 0 {
+			break
+		}
+		if r.decl.NextPath != "" {
+			if next := jsonPathValues(v, r.decl.NextPath); len(next) > 0 && next[0] != "" {
+				if strings.HasPrefix(next[0], "http://") || strings.HasPrefix(next[0], "https://") {
+					u = next[0]
+				} else {
+					u = r.expandURL(r.decl.NextURL, req.Domain, page+1, next[0])
+				}
+			}
+		} else if strings.Contains(r.decl.NextURL, "{page}") {
+			u = r.expandURL(r.decl.NextURL, req.Domain, page+1, "")
+		}
+	}
+}
+
+func (r *RESTSource) expandURL(tmpl, domain string, page int, next string) string {
+	var key, username string
+	if r.creds != nil {
+		key = r.creds.Key
+		username = r.creds.Username
+	}
+
+	return strings.NewReplacer(
+		"{domain}", url.QueryEscape(domain),
+		"{key}", url.QueryEscape(key),
+		"{username}", url.QueryEscape(username),
+		"{page}", strconv.Itoa(page),
+		"{next}", url.QueryEscape(next),
+	).Replace(tmpl)
+}
+
+func urlHost(u string) string {
+	if parsed, err := url.Parse(u); err == nil {
+		return strings.ToLower(parsed.Host)
+	}
+	return ""
+}
+
+// jsonPathValues returns the string values found at the dotted path within the decoded JSON.
+// The * element matches all the values of an array or object, and arrays are traversed implicitly.
+func jsonPathValues(v interface{}, path string) []string {
+	if path = strings.Trim(strings.TrimSpace(path), "."); path == "" {
+		switch val := v.(type) {
+		case string:
+			return []string{val}
+		case float64:
+			return []string{strconv.FormatFloat(val, 'f', -1, 64)}
+		case []interface{}:
+			var results []string
+			for _, elem := range val {
+				results = append(results, jsonPathValues(elem, "")...)
+			}
+			return results
+		}
+		return nil
+	}
+
+	field, rest := path, ""
+	if i := strings.Index(path, "."); i != -1 {
+		field, rest = path[:i], path[i+1:]
+	}
+
+	var results []string
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if field == "*" {
+			for _, elem := range val {
+				results = append(results, jsonPathValues(elem, rest)...)
+			}
+		} else if elem, found := val[field]; found {
+			results = jsonPathValues(elem, rest)
+		}
+	case []interface{}:
+		if field == "*" {
+			for _, elem := range val {
+				results = append(results, jsonPathValues(elem, rest)...)
+			}
+		} else if i, err := strconv.Atoi(field); err == nil {
+			if i >= 0 && i < len(val) {
+				results = jsonPathValues(val[i], rest)
+			}
+		} else {
+			for _, elem := range val {
+				results = append(results, jsonPathValues(elem, path)...)
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestJSONPathValues(t *testing.T) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(`{
+		"data": [
+			{"hostname": "www.example.com", "aliases": ["a.example.com", "b.example.com"]},
+			{"hostname": "api.example.com", "port": 443}
+		],
+		"meta": {"cursor": "abc", "count": 2, "hosts": {"x": "x.example.com"}},
+		"list": ["one.example.com", "two.example.com"]
+	}`), &v); err != nil {
+		t.Fatalf("Failed to decode the JSON: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"data.*.hostname", []string{"api.example.com", "www.example.com"}},
+		{"data.hostname", []string{"api.example.com", "www.example.com"}},
+		{"data.*.aliases", []string{"a.example.com", "b.example.com"}},
+		{"data.0.hostname", []string{"www.example.com"}},
+		{"data.5.hostname", nil},
+		{"data.*.port", []string{"443"}},
+		{"meta.cursor", []string{"abc"}},
+		{" .meta.count. ", []string{"2"}},
+		{"meta.hosts.*", []string{"x.example.com"}},
+		{"meta.missing", nil},
+		{"list", []string{"one.example.com", "two.example.com"}},
+		{"meta", nil},
+	}
+
+	for _, test := range tests {
+		got := jsonPathValues(v, test.path)
+		if got != nil {
+			got = sortedNames(got)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("jsonPathValues(%q) returned %v, expected %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestRESTSourceForeignNextURL(t *testing.T) {
+	var lock sync.Mutex
+	keys := make(map[string]string)
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		keys["other"] = r.Header.Get("X-API-Key")
+		lock.Unlock()
+
+		fmt.Fprint(w, `{"hosts": ["mail.example.com"]}`)
+	}))
+	defer other.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		keys[r.URL.Query().Get("page")] = r.Header.Get("X-API-Key")
+		lock.Unlock()
+
+		if r.URL.Query().Get("page") == "1" {
+			fmt.Fprint(w, `{"hosts": ["www.example.com"], "next": "2"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"hosts": ["api.example.com"], "next": "%s/more"}`, other.URL)
+	}))
+	defer api.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	r := NewRESTSource(&config.RESTSourceConfig{
+		Name:       "TestREST",
+		URL:        api.URL + "/?page={page}",
+		AuthHeader: "X-API-Key",
+		Names:      []string{"hosts"},
+		NextPath:   "next",
+		NextURL:    api.URL + "/?page={next}",
+		MaxPages:   5,
+	}, sys)
+	r.creds = &config.Credentials{Key: "secretkey"}
+
+	r.dnsRequest(ctx, &requests.DNSRequest{Domain: "example.com"})
+
+	got := names.wait("www.example.com", "api.example.com", "mail.example.com")
+	for _, name := range []string{"www.example.com", "api.example.com", "mail.example.com"} {
+		if _, found := got[name]; !found {
+			t.Errorf("The name %s was not provided", name)
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if keys["1"] != "secretkey" || keys["2"] != "secretkey" {
+		t.Errorf("The API key was not sent to the API host: %v", keys)
+	}
+	if key, found := keys["other"]; !found || key != "" {
+		t.Errorf("The API key was sent to the host of the next URL: %v", keys)
+	}
+}
+
+func TestGetAllSourcesRESTCollision(t *testing.T) {
+	sys, _, _ := setupTestSource(t, "example.com")
+	sys.cfg.RESTSources = []*config.RESTSourceConfig{{
+		Name:  "alterations",
+		URL:   "https://api.example.com/{domain}",
+		Names: []string{"hosts"},
+	}}
+
+	if _, err := GetAllSources(sys); err == nil {
+		t.Errorf("The REST source with the name of another data source was accepted")
+	}
+
+	sys.cfg.RESTSources[0].Name = "TestREST"
+	srcs, err := GetAllSources(sys)
+	if err != nil {
+		t.Fatalf("The REST source was rejected: %v", err)
+	}
+
+	var found bool
+	for _, s := range srcs {
+		if s.String() == "TestREST" {
+			found = true
+		}
+		if script, ok := s.(*Script); ok {
+			script.close()
+		}
+	}
+	if !found {
+		t.Errorf("The REST source was not provided")
+	}
+}
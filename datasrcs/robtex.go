@@ -13,12 +13,12 @@ import (
 	"strings"
 
 	amassnet "github.com/OWASP/Amass/v3/net"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/service"
 	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
 )
 
 // Robtex is the Service that handles access to the Robtex data source.
@@ -29,12 +29,22 @@ type Robtex struct {
 	sys        systems.System
 }
 
+var robtexRecordTypes = map[string]int{
+	"A":     int(dns.TypeA),
+	"AAAA":  int(dns.TypeAAAA),
+	"CNAME": int(dns.TypeCNAME),
+}
+
 type robtexJSON struct {
 	Name string `json:"rrname"`
 	Data string `json:"rrdata"`
 	Type string `json:"rrtype"`
 }
 
+func init() {
+	RegisterSource("Robtex", func(sys systems.System) service.Service { return NewRobtex(sys) })
+}
+
 // NewRobtex returns he object initialized, but not yet started.
 func NewRobtex(sys systems.System) *Robtex {
 	r := &Robtex{
@@ -92,61 +102,90 @@ func (r *Robtex) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		return
 	}
 
-	numRateLimitChecks(r, 5)
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 		fmt.Sprintf("Querying %s for %s subdomains", r.String(), req.Domain))
 
-	url := "https://freeapi.robtex.com/pdns/forward/" + req.Domain
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
-	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
-		return
-	}
-
 	ips := stringset.New()
-	for _, line := range r.parseDNSJSON(page) {
-		if line.Type == "A" {
-			ips.Insert(line.Data)
-		} else if line.Type == "NS" || line.Type == "MX" {
-			name := strings.Trim(line.Data, ".")
-
-			if cfg.IsDomainInScope(name) {
-				genNewNameEvent(ctx, r.sys, r, name)
+	records := make(map[string][]requests.DNSAnswer)
+	for _, line := range r.forward(ctx, req.Domain) {
+		name := strings.Trim(line.Name, ".")
+		data := strings.Trim(line.Data, ".")
+
+		switch line.Type {
+		case "A", "AAAA":
+			ips.Insert(data)
+		case "NS", "MX":
+			if cfg.IsDomainInScope(data) {
+				genNewNameEvent(ctx, r.sys, r, data)
 			}
+			continue
+		case "CNAME":
+		default:
+			continue
 		}
+
+		if cfg.IsDomainInScope(name) {
+			records[name] = append(records[name], requests.DNSAnswer{
+				Name: name,
+				Type: robtexRecordTypes[line.Type],
+				Data: data,
+			})
+		}
+	}
+
+	for name, answers := range records {
+		genNewNameRecordsEvent(ctx, r.sys, r, name, answers)
 	}
 
-loop:
 	for ip := range ips {
 		select {
 		case <-r.Done():
 			return
 		default:
-			numRateLimitChecks(r, 6)
-			url = "https://freeapi.robtex.com/pdns/reverse/" + ip
-			pdns, err := http.RequestWebPage(ctx, url, nil, nil, nil)
-			if err != nil {
-				bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-					fmt.Sprintf("%s: %s: %v", r.String(), url, err))
-				continue loop
-			}
+			r.reverse(ctx, ip)
+		}
+	}
+}
 
-			for _, line := range r.parseDNSJSON(pdns) {
-				name := strings.Trim(line.Name, ".")
+// forward returns the passive DNS records for the name from the Robtex forward lookup API.
+func (r *Robtex) forward(ctx context.Context, name string) []robtexJSON {
+	numRateLimitChecks(r, 5)
+	return r.pdnsQuery(ctx, "https://freeapi.robtex.com/pdns/forward/"+name)
+}
 
-				genNewNameEvent(ctx, r.sys, r, name)
-			}
+// reverse publishes the names found by the Robtex reverse lookup API for the IP address.
+func (r *Robtex) reverse(ctx context.Context, addr string) {
+	numRateLimitChecks(r, 6)
+	for _, line := range r.pdnsQuery(ctx, "https://freeapi.robtex.com/pdns/reverse/"+addr) {
+		if name := strings.Trim(line.Name, "."); name != "" {
+			genNewNameEvent(ctx, r.sys, r, name)
 		}
 	}
 }
 
+func (r *Robtex) pdnsQuery(ctx context.Context, url string) []robtexJSON {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return nil
+	}
+
+	page, err := requestWebPage(ctx, r, url, nil, nil, nil)
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
+		return nil
+	}
+	return r.parseDNSJSON(page)
+}
+
+// parseDNSJSON extracts the records from the newline delimited JSON returned by the pdns API.
 func (r *Robtex) parseDNSJSON(page string) []robtexJSON {
 	var lines []robtexJSON
 
 	scanner := bufio.NewScanner(strings.NewReader(page))
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), 1024*1024)
 	for scanner.Scan() {
 		// Get the next line of JSON
-		line := scanner.Text()
+		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
 		}
@@ -200,6 +239,8 @@ func (r *Robtex) executeASNAddrQuery(ctx context.Context, addr string) {
 
 	req.Netblocks.Union(r.netblocks(ctx, req.ASN))
 	bus.Publish(requests.NewASNTopic, eventbus.PriorityHigh, req)
+	// Discover additional names that have pointed at the address
+	r.reverse(ctx, addr)
 }
 
 func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
@@ -214,7 +255,7 @@ func (r *Robtex) origin(ctx context.Context, addr string) *requests.ASNRequest {
 
 	numRateLimitChecks(r, 6)
 	url := "https://freeapi.robtex.com/ipquery/" + addr
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := requestWebPage(ctx, r, url, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return nil
@@ -296,7 +337,7 @@ func (r *Robtex) netblocks(ctx context.Context, asn int) stringset.Set {
 
 	numRateLimitChecks(r, 6)
 	url := "https://freeapi.robtex.com/asquery/" + strconv.Itoa(asn)
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := requestWebPage(ctx, r, url, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), url, err))
 		return netblocks
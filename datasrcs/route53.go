@@ -0,0 +1,180 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/net/http"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/service"
+)
+
+// Route 53 is a global service with the API endpoint located in this region.
+const route53Region = "us-east-1"
+
+// Route53 is the Service that handles access to the hosted zones in AWS Route 53.
+type Route53 struct {
+	service.BaseService
+
+	SourceType string
+	sys        systems.System
+	creds      *config.Credentials
+	// The API endpoint used in place of the default, so the requests can be sent elsewhere
+	endpoint string
+}
+
+func init() {
+	RegisterSource("Route53", func(sys systems.System) service.Service { return NewRoute53(sys) })
+}
+
+// NewRoute53 returns he object initialized, but not yet started.
+func NewRoute53(sys systems.System) *Route53 {
+	r := &Route53{
+		SourceType: requests.API,
+		sys:        sys,
+	}
+
+	r.BaseService = *service.NewBaseService(r, "Route53")
+	return r
+}
+
+// Description implements the Service interface.
+func (r *Route53) Description() string {
+	return r.SourceType
+}
+
+// OnStart implements the Service interface.
+func (r *Route53) OnStart() error {
+	r.creds = r.sys.Config().APIKey(r.String())
+
+	if r.creds == nil || r.creds.Key == "" || r.creds.Secret == "" {
+		r.sys.Config().Log.Printf("%s: API key data was not provided", r.String())
+	}
+
+	r.SetRateLimit(5)
+	return nil
+}
+
+// OnRequest implements the Service interface.
+func (r *Route53) OnRequest(ctx context.Context, args service.Args) {
+	if req, ok := args.(*requests.DNSRequest); ok {
+		r.dnsRequest(ctx, req)
+	}
+}
+
+func (r *Route53) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	if r.creds == nil || r.creds.Key == "" || r.creds.Secret == "" {
+		return
+	}
+
+	if !cfg.IsDomainInScope(req.Domain) {
+		return
+	}
+
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for %s subdomains", r.String(), req.Domain))
+
+	api, err := r.client(cfg)
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", r.String(), err))
+		return
+	}
+
+	var zones []*route53.HostedZone
+	r.CheckRateLimit()
+	err = api.ListHostedZonesPagesWithContext(ctx, &route53.ListHostedZonesInput{},
+		func(page *route53.ListHostedZonesOutput, last bool) bool {
+			for _, zone := range page.HostedZones {
+				if zoneMatchesDomain(aws.StringValue(zone.Name), req.Domain) {
+					zones = append(zones, zone)
+				}
+			}
+			if !last {
+				r.CheckRateLimit()
+			}
+			return true
+		})
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", r.String(), err))
+		return
+	}
+
+	for _, zone := range zones {
+		r.zoneRecords(ctx, api, req.Domain, aws.StringValue(zone.Id))
+	}
+}
+
+func (r *Route53) zoneRecords(ctx context.Context, api *route53.Route53, domain, id string) {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	r.CheckRateLimit()
+	err = api.ListResourceRecordSetsPagesWithContext(ctx, &route53.ListResourceRecordSetsInput{HostedZoneId: aws.String(id)},
+		func(page *route53.ListResourceRecordSetsOutput, last bool) bool {
+			var entries []zoneEntry
+
+			for _, set := range page.ResourceRecordSets {
+				name := strings.TrimSuffix(aws.StringValue(set.Name), ".")
+				// Alias records point at other AWS resources by name and need to be resolved
+				if set.AliasTarget != nil && aws.StringValue(set.AliasTarget.DNSName) != "" {
+					genNewNameEvent(ctx, r.sys, r, name)
+					genNewNameEvent(ctx, r.sys, r, strings.TrimSuffix(aws.StringValue(set.AliasTarget.DNSName), "."))
+					continue
+				}
+
+				entry := zoneEntry{Name: name, Type: aws.StringValue(set.Type)}
+				if len(set.ResourceRecords) == 0 {
+					entries = append(entries, entry)
+				}
+				for _, rr := range set.ResourceRecords {
+					entry.Data = aws.StringValue(rr.Value)
+					entries = append(entries, entry)
+				}
+			}
+			genZoneRecordEvents(ctx, r.sys, r, domain, entries)
+
+			if !last {
+				r.CheckRateLimit()
+			}
+			return true
+		})
+	if err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", r.String(), id, err))
+	}
+}
+
+// client returns the Route 53 API client that signs the requests using the configured access key.
+func (r *Route53) client(cfg *config.Config) (*route53.Route53, error) {
+	awscfg := &aws.Config{
+		Credentials: credentials.NewStaticCredentials(r.creds.Key, r.creds.Secret, ""),
+		Region:      aws.String(route53Region),
+		HTTPClient:  http.ClientWithProxy(cfg.SourceProxy(r.String())),
+	}
+	if r.endpoint != "" {
+		awscfg.Endpoint = aws.String(r.endpoint)
+	}
+
+	sess, err := session.NewSession(awscfg)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create the AWS session: %v", err)
+	}
+	return route53.New(sess), nil
+}
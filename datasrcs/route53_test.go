@@ -0,0 +1,76 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/miekg/dns"
+)
+
+const route53TestNS = "https://route53.amazonaws.com/doc/2013-04-01/"
+
+func route53TestRecordSet(name, rtype, value string) string {
+	return fmt.Sprintf("<ResourceRecordSet><Name>%s</Name><Type>%s</Type><TTL>300</TTL>"+
+		"<ResourceRecords><ResourceRecord><Value>%s</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>", name, rtype, value)
+}
+
+func TestRoute53ZoneRecords(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		switch r.URL.Path {
+		case "/2013-04-01/hostedzone":
+			fmt.Fprintf(w, `<ListHostedZonesResponse xmlns="%s"><HostedZones>`+
+				`<HostedZone><Id>/hostedzone/Z1</Id><Name>example.com.</Name><CallerReference>a</CallerReference></HostedZone>`+
+				`<HostedZone><Id>/hostedzone/Z2</Id><Name>example.org.</Name><CallerReference>b</CallerReference></HostedZone>`+
+				`</HostedZones><IsTruncated>false</IsTruncated><MaxItems>100</MaxItems></ListHostedZonesResponse>`, route53TestNS)
+		case "/2013-04-01/hostedzone/Z1/rrset":
+			if r.URL.Query().Get("name") == "" {
+				fmt.Fprintf(w, `<ListResourceRecordSetsResponse xmlns="%s"><ResourceRecordSets>%s%s</ResourceRecordSets>`+
+					`<IsTruncated>true</IsTruncated><NextRecordName>www.example.com.</NextRecordName><NextRecordType>A</NextRecordType>`+
+					`<MaxItems>2</MaxItems></ListResourceRecordSetsResponse>`, route53TestNS,
+					route53TestRecordSet("_sip._tcp.example.com.", "SRV", "10 5 5060 sip.example.com."),
+					`<ResourceRecordSet><Name>cdn.example.com.</Name><Type>A</Type><AliasTarget><HostedZoneId>Z3</HostedZoneId>`+
+						`<DNSName>d111111abcdef8.cloudfront.net.</DNSName><EvaluateTargetHealth>false</EvaluateTargetHealth></AliasTarget></ResourceRecordSet>`)
+				return
+			}
+			fmt.Fprintf(w, `<ListResourceRecordSetsResponse xmlns="%s"><ResourceRecordSets>%s</ResourceRecordSets>`+
+				`<IsTruncated>false</IsTruncated><MaxItems>2</MaxItems></ListResourceRecordSetsResponse>`, route53TestNS,
+				route53TestRecordSet("www.example.com.", "A", "192.0.2.1"))
+		default:
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	r := NewRoute53(sys)
+	r.creds = &config.Credentials{Key: "AKIDEXAMPLE", Secret: "secret"}
+	r.endpoint = ts.URL
+
+	r.dnsRequest(ctx, &requests.DNSRequest{Domain: "example.com"})
+	got := names.wait("www.example.com", "_sip._tcp.example.com", "cdn.example.com")
+
+	if !checkTestRecords(got["www.example.com"], int(dns.TypeA), "192.0.2.1") {
+		t.Errorf("The record from the second page was not provided")
+	}
+	if _, found := got["_sip._tcp.example.com"]; !found {
+		t.Errorf("The name of the SRV record was not provided")
+	}
+	if _, found := got["cdn.example.com"]; !found {
+		t.Errorf("The name of the alias record was not provided")
+	}
+}
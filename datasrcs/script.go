@@ -110,6 +110,7 @@ func (s *Script) newLuaState(cfg *config.Config) *lua.LState {
 	L.SetGlobal("submatch", L.NewFunction(s.submatch))
 	L.SetGlobal("newname", L.NewFunction(s.newName))
 	L.SetGlobal("newaddr", L.NewFunction(s.newAddr))
+	L.SetGlobal("newurl", L.NewFunction(s.newURL))
 	L.SetGlobal("newasn", L.NewFunction(s.newASN))
 	L.SetGlobal("associated", L.NewFunction(s.associated))
 	L.SetGlobal("inscope", L.NewFunction(s.inScope))
@@ -140,6 +141,11 @@ func (s *Script) getScriptCallbacks() {
 	s.subdomain = L.GetGlobal("subdomain")
 }
 
+// AcceptsAddresses returns true when the script implements the address callback.
+func (s *Script) AcceptsAddresses() bool {
+	return s.address.Type() != lua.LTNil
+}
+
 // Acquires the script name of the script by accessing the global variable.
 func (s *Script) scriptName() (string, error) {
 	L := s.luaState
@@ -198,12 +204,15 @@ func (s *Script) OnStart() error {
 	return s.checkConfig()
 }
 
+// close releases the resources held by the script.
+func (s *Script) close() {
+	s.cancel()
+	s.luaState.Close()
+}
+
 // OnStop implements the Service interface.
 func (s *Script) OnStop() error {
-	defer func() {
-		s.cancel()
-		s.luaState.Close()
-	}()
+	defer s.close()
 
 	L := s.luaState
 	if s.stop.Type() == lua.LTNil {
@@ -259,6 +268,11 @@ func (s *Script) checkConfig() error {
 
 // OnRequest implements the Service interface.
 func (s *Script) OnRequest(ctx context.Context, args service.Args) {
+	// Do not start new queries once the data source has exceeded its time budget
+	if b := contextSourceBudgets(ctx); b != nil && b.Exceeded(s.String()) {
+		return
+	}
+
 	switch req := args.(type) {
 	case *requests.DNSRequest:
 		s.dnsRequest(ctx, req)
@@ -391,7 +405,7 @@ func (s *Script) addrRequest(ctx context.Context, req *requests.AddrRequest) {
 func (s *Script) asnRequest(ctx context.Context, req *requests.ASNRequest) {
 	L := s.luaState
 
-	if s.asn.Type() == lua.LTNil || req == nil || (req.Address == "" && req.ASN == 0) {
+	if s.asn.Type() == lua.LTNil || req == nil || (req.Address == "" && req.ASN == 0 && req.Organization == "") {
 		return
 	}
 
@@ -405,7 +419,7 @@ func (s *Script) asnRequest(ctx context.Context, req *requests.ASNRequest) {
 		Fn:      s.asn,
 		NRet:    0,
 		Protect: true,
-	}, s.contextToUserData(ctx), lua.LString(req.Address), lua.LNumber(req.ASN))
+	}, s.contextToUserData(ctx), lua.LString(req.Address), lua.LNumber(req.ASN), lua.LString(req.Organization))
 
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
@@ -439,8 +453,12 @@ func (s *Script) whoisRequest(ctx context.Context, req *requests.WhoisRequest) {
 }
 
 func (s *Script) getCachedResponse(url string, ttl int) (string, error) {
+	if s.sys.Config().FreshResponses {
+		return "", fmt.Errorf("Fresh responses were requested for %s", s.String())
+	}
+
 	for _, db := range s.sys.GraphDatabases() {
-		if resp, err := db.GetSourceData(s.String(), url, ttl); err == nil {
+		if resp, err := db.GetSourceData(s.String(), urlCacheKey(url), ttl); err == nil {
 			return resp, err
 		}
 	}
@@ -449,7 +467,7 @@ func (s *Script) getCachedResponse(url string, ttl int) (string, error) {
 
 func (s *Script) setCachedResponse(url, resp string) error {
 	for _, db := range s.sys.GraphDatabases() {
-		db.CacheSourceData(s.String(), s.SourceType, url, resp)
+		db.CacheSourceData(s.String(), s.SourceType, urlCacheKey(url), resp)
 	}
 	return nil
 }
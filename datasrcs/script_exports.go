@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
@@ -107,6 +108,7 @@ func (s *Script) config(L *lua.LState) int {
 	tb.RawSetString("active", lua.LBool(cfg.BruteForcing))
 	tb.RawSetString("recursive", lua.LBool(cfg.Recursive))
 	tb.RawSetString("min_for_recursive", lua.LNumber(cfg.MinForRecursive))
+	tb.RawSetString("max_depth", lua.LNumber(cfg.MaxDepth))
 	r.RawSetString("brute_forcing", tb)
 
 	tb = L.NewTable()
@@ -115,7 +117,10 @@ func (s *Script) config(L *lua.LState) int {
 	tb.RawSetString("flip_numbers", lua.LBool(cfg.FlipNumbers))
 	tb.RawSetString("add_words", lua.LBool(cfg.AddWords))
 	tb.RawSetString("add_numbers", lua.LBool(cfg.AddNumbers))
+	tb.RawSetString("transpose_words", lua.LBool(cfg.TransposeWords))
+	tb.RawSetString("remove_words", lua.LBool(cfg.RemoveWords))
 	tb.RawSetString("edit_distance", lua.LNumber(cfg.EditDistance))
+	tb.RawSetString("markov_guesses", lua.LNumber(cfg.MarkovGuesses))
 	r.RawSetString("alterations", tb)
 
 	L.Push(r)
@@ -135,7 +140,7 @@ func (s *Script) dataSourceConfig(L *lua.LState) int {
 		tb.RawSetString("ttl", lua.LNumber(cfg.TTL))
 	}
 
-	if creds := cfg.GetCredentials(); creds != nil {
+	if creds := s.sys.Config().APIKey(s.String()); creds != nil {
 		c := L.NewTable()
 
 		c.RawSetString("name", lua.LString(creds.Name))
@@ -264,6 +269,44 @@ func (s *Script) newName(L *lua.LState) int {
 	return 0
 }
 
+// Wrapper so that scripts can send discovered URLs to Amass.
+// The URL path is stored in the graph databases when configured.
+func (s *Script) newURL(L *lua.LState) int {
+	c := L.CheckUserData(1).Value.(*contextWrapper)
+	cfg, _, err := ContextConfigBus(c.Ctx)
+	if err != nil {
+		return 0
+	}
+
+	lv := L.Get(2)
+	raw, ok := lv.(lua.LString)
+	if !ok {
+		return 0
+	}
+
+	u, err := url.Parse(strings.TrimSpace(string(raw)))
+	if err != nil || u.Hostname() == "" {
+		return 0
+	}
+
+	name := http.CleanName(u.Hostname())
+	if cfg.WhichDomain(name) == "" {
+		return 0
+	}
+
+	genNewNameEvent(c.Ctx, s.sys, s, name)
+	if !cfg.StoreURLPaths || u.Path == "" || u.Path == "/" {
+		return 0
+	}
+
+	for _, g := range s.sys.GraphDatabases() {
+		if err := g.InsertURLPath(name, u.Path); err != nil && cfg.Verbose {
+			cfg.Log.Printf("%s: %v", s.String(), err)
+		}
+	}
+	return 0
+}
+
 // Wrapper so that scripts can send discovered IP addresses to Amass.
 func (s *Script) newAddr(L *lua.LState) int {
 	c := L.CheckUserData(1).Value.(*contextWrapper)
@@ -347,6 +390,16 @@ func (s *Script) newASN(L *lua.LState) int {
 		})
 	}
 
+	var upstreams []int
+	lv = L.GetField(params, "upstreams")
+	if tbl, ok := lv.(*lua.LTable); ok {
+		tbl.ForEach(func(_, v lua.LValue) {
+			if n, ok := v.(lua.LNumber); ok && n > 0 {
+				upstreams = append(upstreams, int(n))
+			}
+		})
+	}
+
 	bus.Publish(requests.NewASNTopic, eventbus.PriorityHigh, &requests.ASNRequest{
 		Address:        ip.String(),
 		ASN:            int(asn),
@@ -356,6 +409,7 @@ func (s *Script) newASN(L *lua.LState) int {
 		AllocationDate: time.Now(),
 		Description:    desc,
 		Netblocks:      netblocks,
+		Upstreams:      upstreams,
 		Tag:            s.SourceType,
 		Source:         s.String(),
 	})
@@ -531,7 +585,7 @@ func (s *Script) request(L *lua.LState) int {
 	id, _ := getStringField(L, opt, "id")
 	pass, _ := getStringField(L, opt, "pass")
 
-	page, err := http.RequestWebPage(c.Ctx, url, body, headers,
+	page, err := requestWebPage(c.Ctx, s, url, body, headers,
 		&http.BasicAuth{
 			Username: id,
 			Password: pass,
@@ -587,7 +641,7 @@ func (s *Script) scrape(L *lua.LState) int {
 	}
 
 	if resp == "" {
-		resp, err = http.RequestWebPage(c.Ctx, url, nil, headers,
+		resp, err = requestWebPage(c.Ctx, s, url, nil, headers,
 			&http.BasicAuth{
 				Username: id,
 				Password: pass,
@@ -642,7 +696,12 @@ func (s *Script) crawl(L *lua.LState) int {
 		return 0
 	}
 
-	names, err := http.Crawl(c.Ctx, string(u), cfg.Domains(), int(max), nil)
+	ctx := c.Ctx
+	if proxy := cfg.SourceProxy(s.String()); proxy != nil {
+		ctx = http.WithProxy(ctx, proxy)
+	}
+
+	names, err := http.Crawl(ctx, string(u), cfg.Domains(), int(max), nil)
 	if err != nil {
 		if cfg.Verbose {
 			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", s.String(), u, err))
@@ -0,0 +1,179 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestAcceptsAddresses(t *testing.T) {
+	sys, _, _ := setupTestSource(t, "example.com")
+
+	s := NewScript(`
+name = "AddressScript"
+type = "api"
+
+function address(ctx, addr)
+end
+`, sys)
+	if s == nil || !AcceptsAddresses(s) {
+		t.Errorf("The script implementing the address callback does not accept the addresses")
+	}
+
+	s = NewScript(`
+name = "VerticalScript"
+type = "api"
+
+function vertical(ctx, domain)
+end
+`, sys)
+	if s == nil || AcceptsAddresses(s) {
+		t.Errorf("The script without the address callback accepts the addresses")
+	}
+
+	if AcceptsAddresses(NewIPAPI(sys)) {
+		t.Errorf("The data source that did not declare the address input accepts the addresses")
+	}
+}
+
+func TestScriptASNOrganization(t *testing.T) {
+	sys, ctx, _ := setupTestSource(t, "example.com")
+
+	s := NewScript(`
+name = "OrgScript"
+type = "api"
+
+function asn(ctx, addr, asn, org)
+    if org ~= "Example Org" then
+        return
+    end
+
+    newasn(ctx, {
+        ['addr']="93.184.216.34",
+        ['asn']=64496,
+        ['prefix']="93.184.216.0/24",
+        ['desc']="EXAMPLE-ORG",
+        ['upstreams']={64497, 64498},
+    })
+end
+`, sys)
+	if s == nil {
+		t.Fatalf("Failed to create the script")
+	}
+	results := subscribeTestTopic(ctx, t, requests.NewASNTopic, &requests.ASNRequest{})
+
+	s.asnRequest(ctx, &requests.ASNRequest{Organization: "Example Org"})
+
+	select {
+	case v := <-results:
+		req, ok := v.(*requests.ASNRequest)
+		if !ok || req.ASN != 64496 {
+			t.Fatalf("An unexpected event was published: %v", v)
+		}
+		if req.Description != "EXAMPLE-ORG" {
+			t.Errorf("The upstreams were not kept out of the description: %s", req.Description)
+		}
+		if want := []int{64497, 64498}; !reflect.DeepEqual(req.Upstreams, want) {
+			t.Errorf("The upstreams were %v, expected %v", req.Upstreams, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("The script did not search for the organization")
+	}
+}
+
+// bundledScript returns the script provided with Amass that implements the named data source.
+func bundledScript(t *testing.T, sys *testSystem, name string) string {
+	scripts, err := sys.cfg.AcquireScripts()
+	if err != nil && len(scripts) == 0 {
+		t.Fatalf("Failed to acquire the scripts: %v", err)
+	}
+
+	for _, script := range scripts {
+		if strings.Contains(script, "name = \""+name+"\"") {
+			return script
+		}
+	}
+
+	t.Fatalf("The %s script was not found", name)
+	return ""
+}
+
+func TestPassiveTotalWithoutSubdomains(t *testing.T) {
+	var lock sync.Mutex
+	var queries []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		queries = append(queries, r.URL.Path+"?"+r.URL.RawQuery)
+		lock.Unlock()
+
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/subdomains":
+			fmt.Fprint(w, `{"success": true, "subdomains": []}`)
+		case "/passive":
+			fmt.Fprint(w, `{"results": [{"resolve": "mail.example.com", "resolveType": "domain"},`+
+				`{"resolve": "93.184.216.34", "resolveType": "ip"}]}`)
+		}
+	}))
+	defer ts.Close()
+
+	sys, ctx, names := setupTestSource(t, "example.com")
+	sys.cfg.GetDataSourceConfig("PassiveTotal").AddCredentials(&config.Credentials{
+		Name:     "account",
+		Username: "user",
+		Key:      "secret",
+	})
+
+	// The API endpoints are replaced with the test server
+	s := NewScript(bundledScript(t, sys, "PassiveTotal")+fmt.Sprintf(`
+function buildurl(domain)
+    return "%[1]s/subdomains?query=" .. domain
+end
+
+function passiveurl(domain)
+    return "%[1]s/passive?query=" .. domain
+end
+`, ts.URL), sys)
+	if s == nil {
+		t.Fatalf("Failed to create the script")
+	}
+	defer s.close()
+	addrs := subscribeTestTopic(ctx, t, requests.NewAddrTopic, &requests.AddrRequest{})
+
+	s.dnsRequest(ctx, &requests.DNSRequest{Domain: "example.com"})
+	if _, found := names.wait("mail.example.com")["mail.example.com"]; !found {
+		t.Errorf("The name from the passive DNS records was not provided")
+	}
+
+	select {
+	case v := <-addrs:
+		if req, ok := v.(*requests.AddrRequest); !ok || req.Address != "93.184.216.34" {
+			t.Errorf("An unexpected event was published: %v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("The address from the passive DNS records was not provided")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if len(queries) != 2 || queries[1] != "/passive?query=example.com" {
+		t.Errorf("The passive DNS records of the root domain were not queried: %v", queries)
+	}
+}
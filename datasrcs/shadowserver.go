@@ -14,6 +14,7 @@ import (
 
 	amassnet "github.com/OWASP/Amass/v3/net"
 	amassdns "github.com/OWASP/Amass/v3/net/dns"
+	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
 	"github.com/OWASP/Amass/v3/systems"
@@ -37,6 +38,10 @@ type ShadowServer struct {
 	addr       string
 }
 
+func init() {
+	RegisterSource("ShadowServer", func(sys systems.System) service.Service { return NewShadowServer(sys) })
+}
+
 // NewShadowServer returns he object initialized, but not yet started.
 func NewShadowServer(sys systems.System) *ShadowServer {
 	s := &ShadowServer{
@@ -223,7 +228,7 @@ func (s *ShadowServer) netblocks(ctx context.Context, asn int) stringset.Set {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	conn, err := amassnet.DialContext(ctx, "tcp", s.addr+":43")
+	conn, err := http.ProxyDialContext(ctx, s.sys.Config().SourceProxy(s.String()), "tcp", s.addr+":43")
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", s.String(), err))
 		return netblocks
@@ -6,7 +6,10 @@ package datasrcs
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/requests"
@@ -17,36 +20,75 @@ import (
 )
 
 // GetAllSources returns a slice of all data source services, initialized and ready.
-func GetAllSources(sys systems.System) []service.Service {
-	srvs := []service.Service{
-		NewAlienVault(sys),
-		NewCloudflare(sys),
-		NewDNSDB(sys),
-		NewDNSDumpster(sys),
-		NewNetworksDB(sys),
-		NewPastebin(sys),
-		NewRADb(sys),
-		NewRobtex(sys),
-		NewShadowServer(sys),
-		NewTeamCymru(sys),
-		NewTwitter(sys),
-		NewUmbrella(sys),
-		NewURLScan(sys),
-		NewWhoisXML(sys),
-	}
-
-	if scripts, err := sys.Config().AcquireScripts(); err == nil {
-		for _, script := range scripts {
-			if s := NewScript(script, sys); s != nil {
-				srvs = append(srvs, s)
+// Scripts replace the data sources implemented with the same name, so sources can be fixed without recompiling.
+// An error is returned when a REST source declared in the configuration has the name of another data source.
+func GetAllSources(sys systems.System) ([]service.Service, error) {
+	srvs := make(map[string]service.Service)
+	for _, name := range RegisteredSourceNames() {
+		if s := NewRegisteredSource(name, sys); s != nil {
+			srvs[s.String()] = s
+		}
+	}
+
+	rest := make(map[string]struct{})
+	for _, decl := range sys.Config().RESTSources {
+		s := NewRESTSource(decl, sys)
+		if sourceNameTaken(srvs, s.String()) {
+			return nil, fmt.Errorf("The %s REST source has the same name as another data source", s.String())
+		}
+
+		srvs[s.String()] = s
+		rest[strings.ToLower(s.String())] = struct{}{}
+	}
+
+	// The scripts are still provided when the output directory is unavailable
+	scripts, err := sys.Config().AcquireScripts()
+	if err != nil {
+		sys.Config().Log.Printf("Failed to acquire all the scripts: %v", err)
+	}
+	for _, script := range scripts {
+		if s := NewScript(script, sys); s != nil {
+			if _, found := rest[strings.ToLower(s.String())]; found {
+				s.close()
+				closeScripts(srvs)
+				return nil, fmt.Errorf("The %s script has the same name as a REST source", s.String())
 			}
+			if prev, found := srvs[s.String()]; found {
+				if ps, ok := prev.(*Script); ok {
+					ps.close()
+				}
+				sys.Config().Log.Printf("The %s script replaced the data source with the same name", s.String())
+			}
+			srvs[s.String()] = s
 		}
 	}
 
-	sort.Slice(srvs, func(i, j int) bool {
-		return srvs[i].String() < srvs[j].String()
+	var results []service.Service
+	for _, s := range srvs {
+		results = append(results, s)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].String() < results[j].String()
 	})
-	return srvs
+	return results, nil
+}
+
+func sourceNameTaken(srvs map[string]service.Service, name string) bool {
+	for n := range srvs {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func closeScripts(srvs map[string]service.Service) {
+	for _, s := range srvs {
+		if script, ok := s.(*Script); ok {
+			script.close()
+		}
+	}
 }
 
 // SelectedDataSources uses the config and available data sources to return the selected data sources.
@@ -78,6 +120,20 @@ func SelectedDataSources(cfg *config.Config, avail []service.Service) []service.
 	return results
 }
 
+// addressSource is implemented by the data sources that can collect information about individual addresses.
+type addressSource interface {
+	AcceptsAddresses() bool
+}
+
+// AcceptsAddresses returns true when the data source declared that it handles the discovered addresses.
+// Many data sources send a request for each address, so the addresses are only provided to those that ask for them.
+func AcceptsAddresses(srv service.Service) bool {
+	if as, ok := srv.(addressSource); ok {
+		return as.AcceptsAddresses()
+	}
+	return false
+}
+
 // ContextConfigBus extracts the Config and EventBus references from the Context argument.
 func ContextConfigBus(ctx context.Context) (*config.Config, *eventbus.EventBus, error) {
 	var ok bool
@@ -106,19 +162,51 @@ func ContextConfigBus(ctx context.Context) (*config.Config, *eventbus.EventBus,
 }
 
 func genNewNameEvent(ctx context.Context, sys systems.System, srv service.Service, name string) {
+	genNewNameRecordsEvent(ctx, sys, srv, name, nil)
+}
+
+// genNewNameRecordsEvent publishes the name along with DNS records already known by the data source.
+func genNewNameRecordsEvent(ctx context.Context, sys systems.System, srv service.Service, name string, records []requests.DNSAnswer) {
+	publishName(ctx, srv, name, records, nil)
+}
+
+// genNewNameEvidenceEvent publishes the name along with the time the data source observed it and the raw evidence.
+func genNewNameEvidenceEvent(ctx context.Context, sys systems.System, srv service.Service, name string, seen time.Time, evidence map[string]string) {
+	publishName(ctx, srv, name, nil, &requests.Provenance{
+		Seen:     seen,
+		Evidence: evidence,
+	})
+}
+
+func publishName(ctx context.Context, srv service.Service, name string, records []requests.DNSAnswer, prov *requests.Provenance) {
 	cfg, bus, err := ContextConfigBus(ctx)
 	if err != nil {
 		return
 	}
 
-	if domain := cfg.WhichDomain(name); domain != "" {
-		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
-			Name:   name,
-			Domain: domain,
-			Tag:    srv.Description(),
-			Source: srv.String(),
-		})
+	if !cfg.MeetsMinimumLabelLength(name) {
+		return
+	}
+
+	domain := cfg.WhichDomain(name)
+	if domain == "" {
+		return
 	}
+
+	if prov == nil {
+		prov = new(requests.Provenance)
+	}
+	prov.Source = srv.String()
+	prov.Timestamp = time.Now()
+
+	bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{
+		Name:       name,
+		Domain:     domain,
+		Records:    records,
+		Tag:        srv.Description(),
+		Source:     srv.String(),
+		Provenance: prov,
+	})
 }
 
 func numRateLimitChecks(srv service.Service, num int) {
@@ -0,0 +1,114 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/eventbus"
+)
+
+// testSystem provides the configuration to the data sources tested without starting a complete System.
+type testSystem struct {
+	systems.System
+	cfg *config.Config
+	dbs []*graph.Graph
+}
+
+func (s *testSystem) Config() *config.Config {
+	return s.cfg
+}
+
+func (s *testSystem) GraphDatabases() []*graph.Graph {
+	return s.dbs
+}
+
+// testNames collects the names published by the data sources.
+type testNames struct {
+	sync.Mutex
+	reqs map[string]*requests.DNSRequest
+}
+
+func (n *testNames) add(req *requests.DNSRequest) {
+	n.Lock()
+	defer n.Unlock()
+
+	n.reqs[req.Name] = req
+}
+
+// wait returns the collected names once all the expected names were published or the time ran out.
+func (n *testNames) wait(names ...string) map[string]*requests.DNSRequest {
+	for i := 0; i < 50; i++ {
+		n.Lock()
+		var missing bool
+		for _, name := range names {
+			if _, found := n.reqs[name]; !found {
+				missing = true
+				break
+			}
+		}
+		n.Unlock()
+
+		if !missing {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	n.Lock()
+	defer n.Unlock()
+
+	results := make(map[string]*requests.DNSRequest, len(n.reqs))
+	for name, req := range n.reqs {
+		results[name] = req
+	}
+	return results
+}
+
+// setupTestSource returns the System and context used by the data sources, and the names they publish.
+func setupTestSource(t *testing.T, domains ...string) (*testSystem, context.Context, *testNames) {
+	cfg := config.NewConfig()
+	cfg.AddDomains(domains...)
+
+	bus := eventbus.NewEventBus()
+	t.Cleanup(bus.Stop)
+
+	names := &testNames{reqs: make(map[string]*requests.DNSRequest)}
+	bus.Subscribe(requests.NewNameTopic, names.add)
+	// The subscription is registered asynchronously, so wait for a probe to be received
+	for ready := false; !ready; {
+		bus.Publish(requests.NewNameTopic, eventbus.PriorityHigh, &requests.DNSRequest{})
+		time.Sleep(10 * time.Millisecond)
+
+		names.Lock()
+		_, ready = names.reqs[""]
+		delete(names.reqs, "")
+		names.Unlock()
+	}
+
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, cfg)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
+	return &testSystem{cfg: cfg}, ctx, names
+}
+
+// checkTestRecords returns true when the request contains a record of the type with the data.
+func checkTestRecords(req *requests.DNSRequest, rtype int, data string) bool {
+	if req == nil {
+		return false
+	}
+
+	for _, rec := range req.Records {
+		if rec.Type == rtype && rec.Data == data {
+			return true
+		}
+	}
+	return false
+}
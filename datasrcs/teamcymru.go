@@ -30,6 +30,10 @@ type TeamCymru struct {
 	sys        systems.System
 }
 
+func init() {
+	RegisterSource("TeamCymru", func(sys systems.System) service.Service { return NewTeamCymru(sys) })
+}
+
 // NewTeamCymru returns he object initialized, but not yet started.
 func NewTeamCymru(sys systems.System) *TeamCymru {
 	t := &TeamCymru{
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/OWASP/Amass/v3/config"
@@ -30,6 +31,10 @@ type Twitter struct {
 	client     *twitter.Client
 }
 
+func init() {
+	RegisterSource("Twitter", func(sys systems.System) service.Service { return NewTwitter(sys) })
+}
+
 // NewTwitter returns he object initialized, but not yet started.
 func NewTwitter(sys systems.System) *Twitter {
 	t := &Twitter{
@@ -48,16 +53,20 @@ func (t *Twitter) Description() string {
 
 // OnStart implements the Service interface.
 func (t *Twitter) OnStart() error {
-	t.creds = t.sys.Config().GetDataSourceConfig(t.String()).GetCredentials()
+	t.creds = t.sys.Config().APIKey(t.String())
 
 	if t.creds == nil || t.creds.Key == "" || t.creds.Secret == "" {
 		t.sys.Config().Log.Printf("%s: API key data was not provided", t.String())
 	} else {
-		if bearer, err := t.getBearerToken(); err == nil {
+		proxy := t.sys.Config().SourceProxy(t.String())
+
+		if bearer, err := t.getBearerToken(proxy); err == nil {
 			config := &oauth2.Config{}
 			token := &oauth2.Token{AccessToken: bearer}
+			// The OAuth2 client sends the requests through the proxy when configured
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, http.ClientWithProxy(proxy))
 			// OAuth2 http.Client will automatically authorize Requests
-			httpClient := config.Client(context.Background(), token)
+			httpClient := config.Client(ctx, token)
 			// Twitter client
 			t.client = twitter.NewClient(httpClient)
 		}
@@ -69,7 +78,7 @@ func (t *Twitter) OnStart() error {
 
 // CheckConfig implements the Service interface.
 func (t *Twitter) checkConfig() error {
-	creds := t.sys.Config().GetDataSourceConfig(t.String()).GetCredentials()
+	creds := t.sys.Config().APIKey(t.String())
 
 	if creds == nil || creds.Key == "" || creds.Secret == "" {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", t.String())
@@ -127,9 +136,14 @@ func (t *Twitter) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 }
 
-func (t *Twitter) getBearerToken() (string, error) {
+func (t *Twitter) getBearerToken(proxy *url.URL) (string, error) {
+	ctx := context.Background()
+	if proxy != nil {
+		ctx = http.WithProxy(ctx, proxy)
+	}
+
 	headers := map[string]string{"Content-Type": "application/x-www-form-urlencoded;charset=UTF-8"}
-	page, err := http.RequestWebPage(context.Background(), "https://api.twitter.com/oauth2/token",
+	page, err := http.RequestWebPage(ctx, "https://api.twitter.com/oauth2/token",
 		strings.NewReader("grant_type=client_credentials"), headers,
 		&http.BasicAuth{
 			Username: t.creds.Key,
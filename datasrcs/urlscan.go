@@ -28,6 +28,10 @@ type URLScan struct {
 	creds      *config.Credentials
 }
 
+func init() {
+	RegisterSource("URLScan", func(sys systems.System) service.Service { return NewURLScan(sys) })
+}
+
 // NewURLScan returns he object initialized, but not yet started.
 func NewURLScan(sys systems.System) *URLScan {
 	u := &URLScan{
@@ -46,7 +50,7 @@ func (u *URLScan) Description() string {
 
 // OnStart implements the Service interface.
 func (u *URLScan) OnStart() error {
-	u.creds = u.sys.Config().GetDataSourceConfig(u.String()).GetCredentials()
+	u.creds = u.sys.Config().APIKey(u.String())
 
 	if u.creds == nil || u.creds.Key == "" {
 		u.sys.Config().Log.Printf("%s: API key data was not provided", u.String())
@@ -79,7 +83,7 @@ func (u *URLScan) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		fmt.Sprintf("Querying %s for %s subdomains", u.String(), req.Domain))
 
 	url := u.searchURL(req.Domain)
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := requestWebPage(ctx, u, url, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return
@@ -87,7 +91,15 @@ func (u *URLScan) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	// Extract the subdomain names from the REST API results
 	var results struct {
 		Results []struct {
-			ID string `json:"_id"`
+			ID   string `json:"_id"`
+			Page struct {
+				Domain string `json:"domain"`
+				URL    string `json:"url"`
+			} `json:"page"`
+			Task struct {
+				Domain string `json:"domain"`
+				URL    string `json:"url"`
+			} `json:"task"`
 		} `json:"results"`
 		Total int `json:"total"`
 	}
@@ -96,9 +108,14 @@ func (u *URLScan) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	var ids []string
+	subs := stringset.New()
 	if results.Total > 0 {
 		for _, result := range results.Results {
 			ids = append(ids, result.ID)
+			// The search results already provide the names for the page and the task
+			subs.InsertMany(result.Page.Domain, result.Task.Domain)
+			subs.InsertMany(re.FindAllString(result.Page.URL, -1)...)
+			subs.InsertMany(re.FindAllString(result.Task.URL, -1)...)
 		}
 	} else {
 		if id := u.attemptSubmission(ctx, req.Domain); id != "" {
@@ -106,7 +123,6 @@ func (u *URLScan) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 		}
 	}
 
-	subs := stringset.New()
 	for _, id := range ids {
 		set, err := u.getSubsFromResult(ctx, id)
 		if err != nil {
@@ -117,7 +133,7 @@ func (u *URLScan) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	}
 
 	for name := range subs {
-		if re.MatchString(name) {
+		if name = strings.ToLower(name); re.MatchString(name) {
 			genNewNameEvent(ctx, u.sys, u, name)
 		}
 	}
@@ -133,7 +149,7 @@ func (u *URLScan) getSubsFromResult(ctx context.Context, id string) (stringset.S
 
 	numRateLimitChecks(u, 2)
 	url := u.resultURL(id)
-	page, err := http.RequestWebPage(ctx, url, nil, nil, nil)
+	page, err := requestWebPage(ctx, u, url, nil, nil, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return subs, errors.New("HTTP request failed")
@@ -169,7 +185,7 @@ func (u *URLScan) attemptSubmission(ctx context.Context, domain string) string {
 	}
 	url := "https://urlscan.io/api/v1/scan/"
 	body := strings.NewReader(u.submitBody(domain))
-	page, err := http.RequestWebPage(ctx, url, body, headers, nil)
+	page, err := requestWebPage(ctx, u, url, body, headers, nil)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", u.String(), url, err))
 		return ""
@@ -190,7 +206,7 @@ func (u *URLScan) attemptSubmission(ctx context.Context, domain string) string {
 
 	// Keep this data source active while waiting for the scan to complete
 	for {
-		_, err = http.RequestWebPage(ctx, result.API, nil, nil, nil)
+		_, err = requestWebPage(ctx, u, result.API, nil, nil, nil)
 		if err == nil || err.Error() != "404 Not Found" {
 			break
 		}
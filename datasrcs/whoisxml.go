@@ -9,16 +9,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/OWASP/Amass/v3/config"
-	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/systems"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/service"
 )
 
+// The maximum number of reverse WHOIS result pages requested for each domain.
+const whoisXMLMaxPages = 10
+
 // WhoisXML is the Service that handles access to the WhoisXML data source.
 type WhoisXML struct {
 	service.BaseService
@@ -26,12 +29,15 @@ type WhoisXML struct {
 	SourceType string
 	sys        systems.System
 	creds      *config.Credentials
+	subsURL    string
+	whoisURL   string
 }
 
 // WhoisXMLResponse handles WhoisXML response json.
 type WhoisXMLResponse struct {
-	Found int      `json:"domainsCount"`
-	List  []string `json:"domainsList"`
+	Found     int      `json:"domainsCount"`
+	List      []string `json:"domainsList"`
+	NextAfter *int64   `json:"nextPageSearchAfter"`
 }
 
 // WhoisXMLAdvanceSearchTerms are variables for the api's query with specific fields in mind.
@@ -57,6 +63,11 @@ type WhoisXMLBasicRequest struct {
 	Search      string                   `json:"searchType"`
 	Mode        string                   `json:"mode"`
 	SearchTerms WhoisXMLBasicSearchTerms `json:"basicSearchTerms"`
+	SearchAfter *int64                   `json:"searchAfter,omitempty"`
+}
+
+func init() {
+	RegisterSource("WhoisXML", func(sys systems.System) service.Service { return NewWhoisXML(sys) })
 }
 
 // NewWhoisXML returns the object initialized, but not yet started.
@@ -64,6 +75,8 @@ func NewWhoisXML(sys systems.System) *WhoisXML {
 	w := &WhoisXML{
 		SourceType: requests.API,
 		sys:        sys,
+		subsURL:    "https://subdomains.whoisxmlapi.com/api/v1",
+		whoisURL:   "https://reverse-whois-api.whoisxmlapi.com/api/v2",
 	}
 
 	w.BaseService = *service.NewBaseService(w, "WhoisXML")
@@ -77,7 +90,7 @@ func (w *WhoisXML) Description() string {
 
 // OnStart implements the Service interface.
 func (w *WhoisXML) OnStart() error {
-	w.creds = w.sys.Config().GetDataSourceConfig(w.String()).GetCredentials()
+	w.creds = w.sys.Config().APIKey(w.String())
 
 	if w.creds == nil || w.creds.Key == "" {
 		w.sys.Config().Log.Printf("%s: API key data was not provided", w.String())
@@ -89,7 +102,7 @@ func (w *WhoisXML) OnStart() error {
 
 // CheckConfig implements the Service interface.
 func (w *WhoisXML) checkConfig() error {
-	creds := w.sys.Config().GetDataSourceConfig(w.String()).GetCredentials()
+	creds := w.sys.Config().APIKey(w.String())
 
 	if creds == nil || creds.Key == "" {
 		estr := fmt.Sprintf("%s: check callback failed for the configuration", w.String())
@@ -102,12 +115,15 @@ func (w *WhoisXML) checkConfig() error {
 
 // OnRequest implements the Service interface.
 func (w *WhoisXML) OnRequest(ctx context.Context, args service.Args) {
-	if req, ok := args.(*requests.WhoisRequest); ok {
+	switch req := args.(type) {
+	case *requests.DNSRequest:
+		w.dnsRequest(ctx, req)
+	case *requests.WhoisRequest:
 		w.whoisRequest(ctx, req)
 	}
 }
 
-func (w *WhoisXML) whoisRequest(ctx context.Context, req *requests.WhoisRequest) {
+func (w *WhoisXML) dnsRequest(ctx context.Context, req *requests.DNSRequest) {
 	cfg, bus, err := ContextConfigBus(ctx)
 	if err != nil {
 		return
@@ -115,49 +131,119 @@ func (w *WhoisXML) whoisRequest(ctx context.Context, req *requests.WhoisRequest)
 	if w.creds == nil || w.creds.Key == "" {
 		return
 	}
-	if !cfg.IsDomainInScope(req.Domain) {
+
+	re := cfg.DomainRegex(req.Domain)
+	if re == nil {
 		return
 	}
 
 	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 		fmt.Sprintf("Querying %s for %s subdomains", w.String(), req.Domain))
 
-	numRateLimitChecks(w, 9)
-	u := w.getReverseWhoisURL(req.Domain)
-	headers := map[string]string{"X-Authentication-Token": w.creds.Key}
-
-	var r = WhoisXMLBasicRequest{
-		Search: "historic",
-		Mode:   "purchase",
+	w.CheckRateLimit()
+	u := w.getSubdomainsURL(req.Domain)
+	page, err := requestWebPage(ctx, w, u, nil, nil, nil)
+	if err != nil {
+		// The URL contains the API key, so only the error is logged
+		if ue, ok := err.(*url.Error); ok {
+			err = ue.Err
+		}
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", w.String(), err))
+		return
 	}
-	r.SearchTerms.Include = append(r.SearchTerms.Include, req.Domain)
-	jr, _ := json.Marshal(r)
 
-	page, err := http.RequestWebPage(ctx, u, bytes.NewReader(jr), headers, nil)
-	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %s: %v", w.String(), u, err))
+	var m struct {
+		Result struct {
+			Count   int `json:"count"`
+			Records []struct {
+				Domain string `json:"domain"`
+			} `json:"records"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(page), &m); err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", w.String(), err))
 		return
 	}
 
-	var q WhoisXMLResponse
-	// Pull the table we need from the page content
-	err = json.NewDecoder(strings.NewReader(page)).Decode(&q)
+	for _, rec := range m.Result.Records {
+		if name := strings.ToLower(rec.Domain); re.MatchString(name) {
+			genNewNameEvent(ctx, w.sys, w, name)
+		}
+	}
+}
+
+func (w *WhoisXML) whoisRequest(ctx context.Context, req *requests.WhoisRequest) {
+	cfg, bus, err := ContextConfigBus(ctx)
 	if err != nil {
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
-			fmt.Sprintf("Failed to decode json in WhoisXML.\nErr:%s", err))
+		return
+	}
+	if w.creds == nil || w.creds.Key == "" {
+		return
+	}
+	if !cfg.IsDomainInScope(req.Domain) {
 		return
 	}
 
-	if q.Found > 0 {
+	bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Querying %s for %s subdomains", w.String(), req.Domain))
+
+	var domains []string
+	var after *int64
+	headers := map[string]string{"X-Authentication-Token": w.creds.Key}
+	// Each page of results provides the value used to request the following page
+	for i := 0; i < whoisXMLMaxPages; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.Done():
+			return
+		default:
+		}
+
+		r := WhoisXMLBasicRequest{
+			Search:      "historic",
+			Mode:        "purchase",
+			SearchAfter: after,
+		}
+		r.SearchTerms.Include = append(r.SearchTerms.Include, req.Domain)
+		jr, _ := json.Marshal(r)
+
+		numRateLimitChecks(w, 9)
+		page, err := requestWebPage(ctx, w, w.getReverseWhoisURL(req.Domain), bytes.NewReader(jr), headers, nil)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", w.String(), err))
+			break
+		}
+
+		var q WhoisXMLResponse
+		// Pull the table we need from the page content
+		if err := json.NewDecoder(strings.NewReader(page)).Decode(&q); err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("Failed to decode json in WhoisXML.\nErr:%s", err))
+			break
+		}
+
+		domains = append(domains, q.List...)
+		if q.NextAfter == nil || len(q.List) == 0 {
+			break
+		}
+		after = q.NextAfter
+	}
+
+	if len(domains) > 0 {
 		bus.Publish(requests.NewWhoisTopic, eventbus.PriorityHigh, &requests.WhoisRequest{
 			Domain:     req.Domain,
-			NewDomains: q.List,
+			NewDomains: domains,
 			Tag:        w.SourceType,
 			Source:     w.String(),
 		})
 	}
 }
 
+func (w *WhoisXML) getSubdomainsURL(domain string) string {
+	return w.subsURL + "?apiKey=" + w.creds.Key + "&domainName=" + domain
+}
+
 func (w *WhoisXML) getReverseWhoisURL(domain string) string {
-	return "https://reverse-whois-api.whoisxmlapi.com/api/v2"
+	return w.whoisURL
 }
@@ -0,0 +1,138 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+)
+
+// subscribeTestTopic returns a channel receiving the events published on the topic.
+func subscribeTestTopic(ctx context.Context, t *testing.T, topic string, probe interface{}) chan interface{} {
+	_, bus, err := ContextConfigBus(ctx)
+	if err != nil {
+		t.Fatalf("The context did not contain the event bus")
+	}
+
+	ch := make(chan interface{}, 100)
+	bus.Subscribe(topic, func(v interface{}) { ch <- v })
+	// The subscription is registered asynchronously, so wait for a probe to be received
+	for ready := false; !ready; {
+		bus.Publish(topic, eventbus.PriorityHigh, probe)
+
+		select {
+		case <-ch:
+			ready = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	// Drain the additional probes
+	for {
+		select {
+		case <-ch:
+		case <-time.After(50 * time.Millisecond):
+			return ch
+		}
+	}
+}
+
+func setupTestWhoisXML(t *testing.T, ts *httptest.Server) (*WhoisXML, context.Context) {
+	sys, ctx, _ := setupTestSource(t, "example.com")
+	sys.cfg.GetDataSourceConfig("WhoisXML").AddCredentials(&config.Credentials{
+		Name: "account",
+		Key:  "secretkey",
+	})
+
+	w := NewWhoisXML(sys)
+	w.subsURL = ts.URL + "/subdomains"
+	w.whoisURL = ts.URL + "/whois"
+	w.creds = sys.cfg.APIKey(w.String())
+	return w, ctx
+}
+
+func TestWhoisXMLErrorsOmitKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	w, ctx := setupTestWhoisXML(t, ts)
+	msgs := subscribeTestTopic(ctx, t, requests.LogTopic, "probe")
+
+	w.dnsRequest(ctx, &requests.DNSRequest{Domain: "example.com"})
+
+	var failed bool
+	for done := false; !done; {
+		select {
+		case v := <-msgs:
+			msg, _ := v.(string)
+			if strings.Contains(msg, "secretkey") {
+				t.Errorf("The API key was written to the log: %s", msg)
+			}
+			if strings.Contains(msg, "500") {
+				failed = true
+			}
+		case <-time.After(500 * time.Millisecond):
+			done = true
+		}
+	}
+	if !failed {
+		t.Errorf("The failed request was not logged")
+	}
+}
+
+func TestWhoisXMLReverseWhoisPages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/whois" || r.Header.Get("X-Authentication-Token") != "secretkey" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req WhoisXMLBasicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.SearchAfter == nil {
+			fmt.Fprint(w, `{"domainsCount":3,"domainsList":["example.net","example.org"],"nextPageSearchAfter":1600000000}`)
+			return
+		}
+		if *req.SearchAfter != 1600000000 {
+			t.Errorf("The search did not continue after the previous page: %d", *req.SearchAfter)
+		}
+		fmt.Fprint(w, `{"domainsCount":3,"domainsList":["example.info"],"nextPageSearchAfter":null}`)
+	}))
+	defer ts.Close()
+
+	w, ctx := setupTestWhoisXML(t, ts)
+	results := subscribeTestTopic(ctx, t, requests.NewWhoisTopic, &requests.WhoisRequest{})
+
+	w.whoisRequest(ctx, &requests.WhoisRequest{Domain: "example.com"})
+
+	select {
+	case v := <-results:
+		req, ok := v.(*requests.WhoisRequest)
+		if !ok {
+			t.Fatalf("An unexpected event was published")
+		}
+		if want := []string{"example.net", "example.org", "example.info"}; !reflect.DeepEqual(req.NewDomains, want) {
+			t.Errorf("The domains from every page were not provided: %v", req.NewDomains)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("The reverse WHOIS results were not published")
+	}
+}
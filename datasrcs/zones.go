@@ -0,0 +1,104 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/service"
+	"github.com/miekg/dns"
+)
+
+// zoneEntry is a resource record obtained from a DNS zone.
+type zoneEntry struct {
+	Name string
+	Type string
+	Data string
+}
+
+// The record types obtained from the DNS zones that are provided with the names.
+var zoneRecordTypes = map[string]struct{}{
+	"A":     {},
+	"AAAA":  {},
+	"CNAME": {},
+	"MX":    {},
+	"NS":    {},
+	"TXT":   {},
+}
+
+// zoneMatchesDomain returns true when the zone is the root domain, a child of it or a parent of it.
+func zoneMatchesDomain(zone, domain string) bool {
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+
+	return zone == domain || strings.HasSuffix(zone, "."+domain) || strings.HasSuffix(domain, "."+zone)
+}
+
+// zoneRecord returns the DNS answer for the record obtained from a DNS zone.
+// False is returned when the record type is not provided with the names.
+func zoneRecord(entry zoneEntry) (requests.DNSAnswer, bool) {
+	rtype := strings.ToUpper(entry.Type)
+	if _, found := zoneRecordTypes[rtype]; !found {
+		return requests.DNSAnswer{}, false
+	}
+
+	data := strings.TrimSpace(entry.Data)
+	switch rtype {
+	case "MX":
+		// Remove the preference value preceding the mail exchange
+		if parts := strings.Fields(data); len(parts) > 0 {
+			data = parts[len(parts)-1]
+		}
+	case "TXT":
+		data = strings.Trim(data, "\"")
+	}
+
+	return requests.DNSAnswer{
+		Name: zoneEntryName(entry),
+		Type: int(dns.StringToType[rtype]),
+		Data: strings.TrimSuffix(data, "."),
+	}, true
+}
+
+func zoneEntryName(entry zoneEntry) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(entry.Name), "."))
+}
+
+// genZoneRecordEvents publishes every name from the DNS zone that belongs to the root domain.
+// The records of the provided types are published along with the names.
+func genZoneRecordEvents(ctx context.Context, sys systems.System, srv service.Service, domain string, entries []zoneEntry) {
+	cfg, _, err := ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+
+	answers := make(map[string][]requests.DNSAnswer)
+	for _, entry := range entries {
+		name := zoneEntryName(entry)
+		if name == "" || cfg.WhichDomain(name) != domain {
+			continue
+		}
+
+		// Names are kept even when none of their records are of the types provided
+		if _, found := answers[name]; !found {
+			answers[name] = nil
+		}
+
+		rec, ok := zoneRecord(entry)
+		if !ok {
+			continue
+		}
+
+		answers[name] = append(answers[name], rec)
+		if uint16(rec.Type) == dns.TypeCNAME {
+			genNewNameEvent(ctx, sys, srv, rec.Data)
+		}
+	}
+	// The zone contents are the ground truth, so the records are provided with the names
+	for name, ans := range answers {
+		genNewNameRecordsEvent(ctx, sys, srv, name, ans)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package datasrcs
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestZoneMatchesDomain(t *testing.T) {
+	tests := []struct {
+		zone     string
+		expected bool
+	}{
+		{"example.com.", true},
+		{"Dev.Example.com", true},
+		{"com", true},
+		{"notexample.com", false},
+		{"example.org", false},
+	}
+
+	for _, test := range tests {
+		if got := zoneMatchesDomain(test.zone, "example.com"); got != test.expected {
+			t.Errorf("zoneMatchesDomain(%q) returned %t instead of %t", test.zone, got, test.expected)
+		}
+	}
+}
+
+func TestZoneRecord(t *testing.T) {
+	tests := []struct {
+		entry    zoneEntry
+		rtype    int
+		data     string
+		provided bool
+	}{
+		{zoneEntry{Name: "www.example.com.", Type: "a", Data: "192.0.2.1"}, int(dns.TypeA), "192.0.2.1", true},
+		{zoneEntry{Name: "example.com", Type: "MX", Data: "10 mail.example.com."}, int(dns.TypeMX), "mail.example.com", true},
+		{zoneEntry{Name: "example.com", Type: "TXT", Data: "\"v=spf1 -all\""}, int(dns.TypeTXT), "v=spf1 -all", true},
+		{zoneEntry{Name: "_sip._tcp.example.com", Type: "SRV", Data: "10 5 5060 sip.example.com."}, 0, "", false},
+	}
+
+	for _, test := range tests {
+		ans, ok := zoneRecord(test.entry)
+		if ok != test.provided {
+			t.Errorf("zoneRecord returned %t for the %s record", ok, test.entry.Type)
+			continue
+		}
+		if ok && (ans.Type != test.rtype || ans.Data != test.data) {
+			t.Errorf("zoneRecord returned type %d and data %q for the %s record", ans.Type, ans.Data, test.entry.Type)
+		}
+	}
+}
+
+func TestGenZoneRecordEvents(t *testing.T) {
+	sys, ctx, names := setupTestSource(t, "example.com")
+	srv := NewRoute53(sys)
+
+	genZoneRecordEvents(ctx, sys, srv, "example.com", []zoneEntry{
+		{Name: "www.example.com.", Type: "A", Data: "192.0.2.1"},
+		{Name: "www.example.com.", Type: "AAAA", Data: "2001:db8::1"},
+		{Name: "_sip._tcp.example.com.", Type: "SRV", Data: "10 5 5060 sip.example.com."},
+		{Name: "ftp.example.com.", Type: "CNAME", Data: "files.example.com."},
+		{Name: "www.example.org.", Type: "A", Data: "192.0.2.2"},
+	})
+
+	got := names.wait("www.example.com", "_sip._tcp.example.com", "ftp.example.com", "files.example.com")
+	if req := got["www.example.com"]; !checkTestRecords(req, int(dns.TypeA), "192.0.2.1") ||
+		!checkTestRecords(req, int(dns.TypeAAAA), "2001:db8::1") {
+		t.Errorf("The records were not provided with the name")
+	}
+	if req, found := got["_sip._tcp.example.com"]; !found || len(req.Records) != 0 {
+		t.Errorf("The name with only records of other types was not provided without records")
+	}
+	if _, found := got["files.example.com"]; !found {
+		t.Errorf("The CNAME target was not provided")
+	}
+	if _, found := got["www.example.org"]; found {
+		t.Errorf("The name outside the root domain was provided")
+	}
+}
@@ -5,14 +5,23 @@ package enum
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/datasrcs"
+	"github.com/OWASP/Amass/v3/graph"
 	"github.com/OWASP/Amass/v3/net/http"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/stringfilter"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/pipeline"
 	"github.com/caffix/queue"
@@ -21,9 +30,14 @@ import (
 
 // activeTask is the task that handles all requests related to active enumeration within the pipeline.
 type activeTask struct {
-	enum      *Enumeration
-	queue     queue.Queue
-	tokenPool chan struct{}
+	enum       *Enumeration
+	queue      queue.Queue
+	tokenPool  chan struct{}
+	walkFilter stringfilter.Filter
+	walkLock   sync.Mutex
+	walking    map[string]struct{}
+	xfrFilter  stringfilter.Filter
+	nsFilter   stringfilter.Filter
 }
 
 type taskArgs struct {
@@ -44,9 +58,13 @@ func newActiveTask(e *Enumeration, max int) *activeTask {
 	}
 
 	a := &activeTask{
-		enum:      e,
-		queue:     queue.NewQueue(),
-		tokenPool: tokenPool,
+		enum:       e,
+		queue:      queue.NewQueue(),
+		tokenPool:  tokenPool,
+		walkFilter: stringfilter.NewStringFilter(),
+		walking:    make(map[string]struct{}),
+		xfrFilter:  stringfilter.NewStringFilter(),
+		nsFilter:   stringfilter.NewStringFilter(),
 	}
 
 	go a.processQueue()
@@ -105,17 +123,47 @@ func (a *activeTask) processTask() {
 		}
 
 		args := element.(*taskArgs)
+		active := a.enum.Config.Active
 		switch v := args.Data.(type) {
 		case *requests.DNSRequest:
-			go a.crawlName(args.Ctx, v, args.Params)
+			if active {
+				go a.crawlName(args.Ctx, v, args.Params)
+				return
+			}
 		case *requests.AddrRequest:
-			if v.InScope {
+			if active && v.InScope {
 				go a.certEnumeration(args.Ctx, v, args.Params)
+				return
 			}
 		case *requests.ZoneXFRRequest:
-			go a.zoneTransfer(args.Ctx, v, args.Params)
-			go a.zoneWalk(args.Ctx, v, args.Params)
+			tasks := []func(context.Context, *requests.ZoneXFRRequest, pipeline.TaskParams){a.zoneWalk}
+			if active && a.enum.Config.ZoneTransfers {
+				tasks = append(tasks, a.zoneTransfer)
+			}
+			if active && a.enum.Config.NameserverFingerprints {
+				tasks = append(tasks, a.fingerprintNameserver)
+			}
+
+			for i, task := range tasks {
+				// The first task uses the token already taken, and each other task waits for its own
+				if i > 0 && !a.takeToken() {
+					return
+				}
+				go task(args.Ctx, v, args.Params)
+			}
+			return
 		}
+		a.tokenPool <- struct{}{}
+	}
+}
+
+// takeToken waits for a token from the pool, and returns false when the enumeration is done.
+func (a *activeTask) takeToken() bool {
+	select {
+	case <-a.enum.done:
+		return false
+	case <-a.tokenPool:
+		return true
 	}
 }
 
@@ -131,6 +179,11 @@ func (a *activeTask) crawlName(ctx context.Context, req *requests.DNSRequest, tp
 	defer func() { tp.ProcessedData() <- req }()
 
 	cfg := a.enum.Config
+	// The crawls are sent through the global proxy when configured
+	if proxy := cfg.SourceProxy(""); proxy != nil {
+		ctx = http.WithProxy(ctx, proxy)
+	}
+
 	for _, port := range cfg.Ports {
 		u := "https://" + req.Name
 		if port != 443 {
@@ -186,10 +239,16 @@ func (a *activeTask) certEnumeration(ctx context.Context, req *requests.AddrRequ
 }
 
 func (a *activeTask) zoneTransfer(ctx context.Context, req *requests.ZoneXFRRequest, tp pipeline.TaskParams) {
+	defer func() { a.tokenPool <- struct{}{} }()
+
 	_, bus, err := datasrcs.ContextConfigBus(ctx)
 	if err != nil {
 		return
 	}
+	// Each nameserver is only asked once for the zone
+	if a.xfrFilter.Duplicate(req.Name + "@" + strings.ToLower(req.Server)) {
+		return
+	}
 
 	// Hold the pipeline during slow activities
 	tp.NewData() <- req
@@ -205,7 +264,6 @@ func (a *activeTask) zoneTransfer(ctx context.Context, req *requests.ZoneXFRRequ
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 			fmt.Sprintf("DNS: Zone XFR failed: %s: %v", req.Server, err))
-		return
 	}
 
 	for _, req := range reqs {
@@ -213,6 +271,55 @@ func (a *activeTask) zoneTransfer(ctx context.Context, req *requests.ZoneXFRRequ
 	}
 }
 
+func (a *activeTask) fingerprintNameserver(ctx context.Context, req *requests.ZoneXFRRequest, tp pipeline.TaskParams) {
+	defer func() { a.tokenPool <- struct{}{} }()
+
+	cfg, bus, err := datasrcs.ContextConfigBus(ctx)
+	if err != nil {
+		return
+	}
+	// Each nameserver is only fingerprinted once, regardless of the number of zones it serves
+	server := strings.ToLower(resolvers.RemoveLastDot(req.Server))
+	if a.nsFilter.Duplicate(server) {
+		return
+	}
+
+	// Hold the pipeline during slow activities
+	tp.NewData() <- req
+	defer func() { tp.ProcessedData() <- req }()
+
+	addr, err := a.nameserverAddr(ctx, server)
+	if addr == "" {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("DNS: Nameserver fingerprint failed: %v", err))
+		return
+	}
+
+	r := resolvers.NewBaseResolver(addr, 10, cfg.Log)
+	if r == nil {
+		return
+	}
+	defer r.Stop()
+
+	fp := resolvers.FingerprintNameserver(ctx, r, resolvers.PriorityHigh)
+	if fp.Recursive {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("DNS: Nameserver %s (%s) provides open recursion", server, addr))
+	}
+
+	if err := a.enum.Graph.InsertNameserverFingerprint(server, &graph.NameserverFingerprint{
+		EventID:   cfg.UUID.String(),
+		Address:   addr,
+		Version:   fp.Version,
+		Hostname:  fp.Hostname,
+		ServerID:  fp.ServerID,
+		Software:  fp.Software,
+		Recursive: fp.Recursive,
+	}); err != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("%s failed to insert the nameserver fingerprint: %v", a.enum.Graph, err))
+	}
+}
+
 func (a *activeTask) zoneWalk(ctx context.Context, req *requests.ZoneXFRRequest, tp pipeline.TaskParams) {
 	defer func() { a.tokenPool <- struct{}{} }()
 
@@ -221,6 +328,14 @@ func (a *activeTask) zoneWalk(ctx context.Context, req *requests.ZoneXFRRequest,
 		return
 	}
 
+	// Each zone only needs to be walked once, regardless of the number of nameservers
+	if !cfg.EnableZoneWalk || !a.startWalk(req.Name) {
+		return
+	}
+	// Zones are only filtered after a completed walk, so the other nameservers can be tried after a failure
+	var completed bool
+	defer func() { a.endWalk(req.Name, completed) }()
+
 	// Hold the pipeline during slow activities
 	tp.NewData() <- req
 	defer func() { tp.ProcessedData() <- req }()
@@ -237,12 +352,51 @@ func (a *activeTask) zoneWalk(ctx context.Context, req *requests.ZoneXFRRequest,
 	}
 	defer r.Stop()
 
-	names, _, err := resolvers.NsecTraversal(ctx, r, req.Name, resolvers.PriorityHigh)
+	// Only zones signed with DNSSEC can be walked
+	denial, err := resolvers.ZoneDenialType(ctx, r, req.Name, resolvers.PriorityHigh)
 	if err != nil {
 		bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
 			fmt.Sprintf("DNS: Zone Walk failed: %s: %v", req.Name, err))
 		return
 	}
+	if denial == 0 {
+		completed = true
+		return
+	}
+
+	var names []string
+	source := "NSEC Walk"
+	completed = true
+	if denial == dns.TypeNSEC && cfg.ZoneWalkNSEC {
+		names, _, err = resolvers.BoundedNsecTraversal(ctx, r, req.Name, resolvers.PriorityHigh, cfg.ZoneWalkMaxNames)
+		if err != nil {
+			completed = false
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("DNS: Zone Walk failed: %s: %v", req.Name, err))
+		}
+	}
+	// Attempt to crack the NSEC3 hashes, since the hashed chain cannot be followed
+	if denial == dns.TypeNSEC3 && cfg.ZoneWalkNSEC3 {
+		source = "NSEC3 Walk"
+
+		zone, err := resolvers.Nsec3Hashes(ctx, r, req.Name, resolvers.PriorityHigh, cfg.ZoneWalkMaxQueries)
+		if err != nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("DNS: NSEC3 Walk failed: %s: %v", req.Name, err))
+			completed = false
+			return
+		}
+		// The hashes are saved for cracking offline using the dns subcommand
+		if path, err := saveNSEC3Zone(cfg, zone); err == nil {
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("DNS: NSEC3 Walk: %s: Saved %d hashes to %s", req.Name, len(zone.Hashes), path))
+		}
+
+		names = zone.Crack(cfg.Wordlist)
+		if max := cfg.ZoneWalkMaxNames; max > 0 && len(names) > max {
+			names = names[:max]
+		}
+	}
 
 	for _, name := range names {
 		if domain := cfg.WhichDomain(name); domain != "" {
@@ -250,12 +404,56 @@ func (a *activeTask) zoneWalk(ctx context.Context, req *requests.ZoneXFRRequest,
 				Name:   name,
 				Domain: domain,
 				Tag:    requests.DNS,
-				Source: "NSEC Walk",
+				Source: source,
 			}, tp)
 		}
 	}
 }
 
+// startWalk returns true when the zone has not been walked and no walk of the zone is in progress.
+func (a *activeTask) startWalk(zone string) bool {
+	a.walkLock.Lock()
+	defer a.walkLock.Unlock()
+
+	if _, found := a.walking[zone]; found || a.walkFilter.Has(zone) {
+		return false
+	}
+
+	a.walking[zone] = struct{}{}
+	return true
+}
+
+// endWalk releases the zone, and prevents it from being walked again when the walk was completed.
+func (a *activeTask) endWalk(zone string, completed bool) {
+	a.walkLock.Lock()
+	defer a.walkLock.Unlock()
+
+	delete(a.walking, zone)
+	if completed {
+		a.walkFilter.Duplicate(zone)
+	}
+}
+
+func saveNSEC3Zone(cfg *config.Config, zone *resolvers.NSEC3Zone) (string, error) {
+	dir := config.OutputDirectory(cfg.Dir)
+	if dir == "" {
+		return "", errors.New("The output directory could not be identified")
+	}
+
+	dir = filepath.Join(dir, "nsec3")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(zone)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, zone.Domain+".json")
+	return path, ioutil.WriteFile(path, data, 0644)
+}
+
 func (a *activeTask) nameserverAddr(ctx context.Context, server string) (string, error) {
 	var err error
 	var found bool
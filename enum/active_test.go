@@ -0,0 +1,107 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/stringfilter"
+	"github.com/caffix/pipeline"
+	"github.com/caffix/queue"
+	"github.com/miekg/dns"
+)
+
+func TestZoneWalkFilter(t *testing.T) {
+	_, e := setupTestEnum(t, "example.com")
+	a := newActiveTask(e, 1)
+
+	if !a.startWalk("example.com") {
+		t.Fatalf("The zone walk was not started")
+	}
+	if a.startWalk("example.com") {
+		t.Errorf("The zone was walked twice at the same time")
+	}
+
+	// The zone can be walked using another nameserver after a failure
+	a.endWalk("example.com", false)
+	if !a.startWalk("example.com") {
+		t.Fatalf("The zone walk was not retried after the failure")
+	}
+
+	a.endWalk("example.com", true)
+	if a.startWalk("example.com") {
+		t.Errorf("The zone was walked again after a completed walk")
+	}
+}
+
+// failResolver fails every query, so the nameserver addresses are never found.
+type failResolver struct {
+	testResolver
+}
+
+func (r *failResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolvers.Retry) (*dns.Msg, error) {
+	return nil, errors.New("query failed")
+}
+
+func TestZoneXFRRequestTokens(t *testing.T) {
+	ctx, e := setupTestEnum(t, "example.com")
+	e.Config.Active = true
+	e.Config.ZoneTransfers = true
+	e.Config.NameserverFingerprints = true
+	e.Sys = &testSystem{pool: &failResolver{}}
+
+	max := 3
+	tokenPool := make(chan struct{}, max)
+	for i := 0; i < max; i++ {
+		tokenPool <- struct{}{}
+	}
+	// The queue is processed by the test, instead of the goroutine started by newActiveTask
+	a := &activeTask{
+		enum:       e,
+		queue:      queue.NewQueue(),
+		tokenPool:  tokenPool,
+		walkFilter: stringfilter.NewStringFilter(),
+		walking:    make(map[string]struct{}),
+		xfrFilter:  stringfilter.NewStringFilter(),
+		nsFilter:   stringfilter.NewStringFilter(),
+	}
+
+	// The zone transfer and the fingerprint wait for the pipeline while holding their tokens
+	tp := &testTaskParams{
+		newdata:   make(chan pipeline.Data),
+		processed: make(chan pipeline.Data, 10),
+	}
+	a.queue.Append(&taskArgs{
+		Ctx: ctx,
+		Data: &requests.ZoneXFRRequest{
+			Name:   "example.com",
+			Domain: "example.com",
+			Server: "ns1.example.com",
+		},
+		Params: tp,
+	})
+	a.processTask()
+
+	// The zone walk is not enabled, so only its token is returned
+	waitForTokens := func(num int) int {
+		for i := 0; i < 100 && len(a.tokenPool) != num; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		return len(a.tokenPool)
+	}
+	if got := waitForTokens(1); got != 1 {
+		t.Errorf("The zone transfer and fingerprint goroutines did not take tokens: %d of %d available", got, max)
+	}
+
+	<-tp.newdata
+	<-tp.newdata
+	if got := waitForTokens(max); got != max {
+		t.Errorf("The tokens were not returned after the goroutines finished: %d of %d available", got, max)
+	}
+}
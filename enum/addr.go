@@ -5,19 +5,25 @@ package enum
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"strconv"
 
 	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resolvers"
 	"github.com/OWASP/Amass/v3/stringfilter"
+	"github.com/caffix/eventbus"
 	"github.com/caffix/pipeline"
+	"go.uber.org/ratelimit"
 )
 
 const (
 	defaultSweepSize = 100
 	activeSweepSize  = 200
 	filterSize       = 20
+	// The maximum number of queries used to nibble walk each IPv6 /64 netblock
+	nibbleWalkMaxQueries = 2000
 )
 
 // addrTask handles the investigation of addresses associated with newly resolved FQDNs.
@@ -25,13 +31,21 @@ type addrTask struct {
 	enum        *Enumeration
 	filter      stringfilter.Filter
 	sweepFilter stringfilter.Filter
+	// Rate limiter for the reverse DNS sweeps of complete netblocks
+	sweepLimit ratelimit.Limiter
 }
 
 func newAddressTask(e *Enumeration) *addrTask {
+	rate := e.Config.NetblockSweepRate
+	if rate <= 0 {
+		rate = 1
+	}
+
 	return &addrTask{
 		enum:        e,
 		filter:      stringfilter.NewBloomFilter(1 << filterSize),
 		sweepFilter: stringfilter.NewBloomFilter(1 << filterSize),
+		sweepLimit:  ratelimit.New(rate, ratelimit.WithoutSlack),
 	}
 }
 
@@ -51,6 +65,8 @@ func (r *addrTask) Process(ctx context.Context, data pipeline.Data, tp pipeline.
 	if req == nil || !req.Valid() {
 		return nil, nil
 	}
+	// IPv6 addresses can be written in several forms
+	req.Address = net.ParseIP(req.Address).String()
 	// Does the address fall into a reserved address range?
 	if yes, _ := amassnet.IsReservedAddress(req.Address); yes {
 		return nil, nil
@@ -68,6 +84,12 @@ func (r *addrTask) Process(ctx context.Context, data pipeline.Data, tp pipeline.
 }
 
 func (r *addrTask) genSweepAddrs(ctx context.Context, req *requests.AddrRequest, tp pipeline.TaskParams) {
+	// Sweeping the enormous IPv6 netblocks is replaced by walking the ip6.arpa tree
+	if ip := net.ParseIP(req.Address); amassnet.IsIPv6(ip) {
+		r.nibbleWalk(ctx, req, ip, tp)
+		return
+	}
+
 	size := defaultSweepSize
 	if r.enum.Config.Active {
 		size = activeSweepSize
@@ -75,18 +97,84 @@ func (r *addrTask) genSweepAddrs(ctx context.Context, req *requests.AddrRequest,
 
 	cidr := r.getAddrCIDR(req.Address)
 	// Get information about nearby IP addresses
-	ips := amassnet.CIDRSubset(cidr, req.Address, size)
+	go r.sendSweepAddrs(ctx, req, amassnet.CIDRSubset(cidr, req.Address, size), nil, tp)
+}
+
+// sweepNetblock sends each address of the netblock learned for the address to the DNS stage
+// for a reverse DNS query. Netblocks larger than the configured maximum size are not swept.
+func (r *addrTask) sweepNetblock(ctx context.Context, req *requests.AddrRequest, prefix string, tp pipeline.TaskParams) {
+	_, cidr, err := net.ParseCIDR(prefix)
+	// The IPv6 netblocks are nibble walked instead
+	if err != nil || cidr.IP.To4() == nil || r.sweepFilter.Duplicate(cidr.String()) {
+		return
+	}
+
+	ones, bits := cidr.Mask.Size()
+	if host := bits - ones; host >= 31 || 1<<host > r.enum.Config.NetblockSweepMaxSize {
+		r.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+			fmt.Sprintf("The netblock %s exceeds the maximum sweep size and was not swept", cidr.String()))
+		return
+	}
 
+	go r.sendSweepAddrs(ctx, req, amassnet.AllHosts(cidr), r.sweepLimit, tp)
+}
+
+// sendSweepAddrs sends the addresses that have not been swept already to the DNS stage.
+func (r *addrTask) sendSweepAddrs(ctx context.Context, req *requests.AddrRequest,
+	ips []net.IP, limit ratelimit.Limiter, tp pipeline.TaskParams) {
 	for _, ip := range ips {
-		if a := ip.String(); !r.sweepFilter.Duplicate(a) {
-			go pipeline.SendData(ctx, "dns", &requests.AddrRequest{
-				Address: a,
-				Domain:  req.Domain,
-				Tag:     req.Tag,
-				Source:  req.Source,
-			}, tp)
+		a := ip.String()
+		if r.sweepFilter.Duplicate(a) {
+			continue
+		}
+		if limit != nil {
+			limit.Take()
 		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		pipeline.SendData(ctx, "dns", &requests.AddrRequest{
+			Address: a,
+			Domain:  req.Domain,
+			Tag:     req.Tag,
+			Source:  req.Source,
+		}, tp)
+	}
+}
+
+// nibbleWalk discovers the addresses with PTR records in the /64 netblock of the IPv6 address.
+func (r *addrTask) nibbleWalk(ctx context.Context, req *requests.AddrRequest, ip net.IP, tp pipeline.TaskParams) {
+	mask := net.CIDRMask(64, 128)
+	cidr := &net.IPNet{
+		IP:   ip.Mask(mask),
+		Mask: mask,
+	}
+	if r.sweepFilter.Duplicate(cidr.String()) {
+		return
 	}
+
+	go func() {
+		addrs, err := resolvers.NibbleWalk(ctx, r.enum.Sys.Pool(), cidr, resolvers.PriorityLow, nibbleWalkMaxQueries)
+		if err != nil {
+			r.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("The nibble walk of %s stopped early: %v", cidr.String(), err))
+		}
+
+		for _, a := range addrs {
+			if !r.sweepFilter.Duplicate(a) {
+				pipeline.SendData(ctx, "dns", &requests.AddrRequest{
+					Address: a,
+					Domain:  req.Domain,
+					Tag:     req.Tag,
+					Source:  req.Source,
+				}, tp)
+			}
+		}
+	}()
 }
 
 func (r *addrTask) getAddrCIDR(addr string) *net.IPNet {
@@ -0,0 +1,59 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/stringset"
+)
+
+// addrs returns the addresses of the requests sent by the tasks within the time allowed.
+func (tp *testTaskParams) addrs(d time.Duration) stringset.Set {
+	addrs := stringset.New()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			return addrs
+		case data := <-tp.processed:
+			if req, ok := data.(*requests.AddrRequest); ok {
+				addrs.Insert(req.Address)
+			}
+		}
+	}
+}
+
+func TestSweepNetblock(t *testing.T) {
+	ctx, e := setupTestEnum(t, "example.com")
+	e.Config.NetblockSweepMaxSize = 16
+	e.Config.NetblockSweepRate = 1000
+	r := newAddressTask(e)
+	tp := newTestTaskParams()
+
+	req := &requests.AddrRequest{
+		Address: "192.0.2.1",
+		Domain:  "example.com",
+		Tag:     requests.DNS,
+		Source:  "DNS",
+	}
+	// The addresses swept already are not sent again
+	r.sweepFilter.Duplicate("192.0.2.2")
+
+	r.sweepNetblock(ctx, req, "192.0.2.0/28", tp)
+	r.sweepNetblock(ctx, req, "192.0.2.0/28", tp)
+	r.sweepNetblock(ctx, req, "198.51.100.0/24", tp)
+
+	got := tp.addrs(500 * time.Millisecond)
+	if got.Len() != 13 || got.Has("192.0.2.2") || !got.Has("192.0.2.14") {
+		t.Errorf("The netblock sweep sent the unexpected addresses: %v", got.Slice())
+	}
+	if r.sweepFilter.Has("198.51.100.1") {
+		t.Errorf("The netblock exceeding the maximum sweep size was swept")
+	}
+}
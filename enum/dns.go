@@ -5,14 +5,19 @@ package enum
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/datasrcs"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/stringfilter"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/pipeline"
+	"github.com/caffix/stringset"
 	"github.com/miekg/dns"
 	"golang.org/x/net/publicsuffix"
 )
@@ -24,14 +29,29 @@ var InitialQueryTypes = []uint16{
 	dns.TypeAAAA,
 }
 
+// ServiceQueryTypes include the DNS record types that are queried for a discovered service name, e.g. _sip._udp.example.com.
+var ServiceQueryTypes = []uint16{
+	dns.TypeCNAME,
+	dns.TypeSRV,
+}
+
+// tlsaMailPorts are the ports of the mail services commonly pinned using TLSA records,
+// which are queried in addition to the ports configured for pulling certificates.
+var tlsaMailPorts = []int{25, 465, 587}
+
 // dNSTask is the task that handles all DNS name resolution requests within the pipeline.
 type dNSTask struct {
-	enum *Enumeration
+	enum     *Enumeration
+	internal resolvers.Resolver
+	related  stringfilter.Filter
 }
 
 // newDNSTask returns a dNSTask specific to the provided Enumeration.
 func newDNSTask(e *Enumeration) *dNSTask {
-	return &dNSTask{enum: e}
+	return &dNSTask{
+		enum:    e,
+		related: stringfilter.NewStringFilter(),
+	}
 }
 
 func (dt *dNSTask) makeBlacklistTaskFunc() pipeline.TaskFunc {
@@ -133,8 +153,52 @@ func (dt *dNSTask) processDNSRequest(ctx context.Context, req *requests.DNSReque
 	if req == nil || !req.Valid() {
 		return nil, nil
 	}
+
+	internal, err := dt.resolveName(ctx, req, tp)
+	if len(internal) > 0 {
+		// Records only provided by the internal resolvers are kept separate from the public results.
+		// The name is already passing through the filter, so the records are sent straight to the store
+		go pipeline.SendData(ctx, "store", &requests.DNSRequest{
+			Name:    req.Name,
+			Domain:  req.Domain,
+			Records: internal,
+			Tag:     requests.DNS,
+			Source:  "Internal DNS",
+		}, tp)
+	}
+
+	if errors.Is(err, errWildcardMatch) {
+		// Records provided by data sources are not kept for names matching a wildcard
+		if dt.enum.Config.Verbose {
+			dt.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityLow,
+				fmt.Sprintf("DNS: %s: %s: %v", req.Source, req.Name, err))
+		}
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(req.Records) > 0 {
+		return req, nil
+	}
+	return nil, nil
+}
+
+// errWildcardMatch is returned when the answers for a name provided by a data source match a DNS wildcard.
+var errWildcardMatch = errors.New("The DNS answers matched a wildcard")
+
+// resolveName appends the answers for the initial query types to the request, and returns the
+// answers only provided by the internal resolvers. The errWildcardMatch error is returned when
+// the name was provided by a data source and the answers matched a DNS wildcard.
+func (dt *dNSTask) resolveName(ctx context.Context, req *requests.DNSRequest, tp pipeline.TaskParams) ([]requests.DNSAnswer, error) {
+	var internal []requests.DNSAnswer
+
+	qtypes := InitialQueryTypes
+	if resolvers.IsServiceName(req.Name) {
+		qtypes = ServiceQueryTypes
+	}
 loop:
-	for _, t := range InitialQueryTypes {
+	for _, t := range qtypes {
 		select {
 		case <-ctx.Done():
 			break loop
@@ -143,7 +207,7 @@ loop:
 
 		var nxdomain bool
 		msg := resolvers.QueryMsg(req.Name, t)
-		resp, err := dt.enum.Sys.Pool().Query(ctx, msg, resolvers.PriorityLow, func(times, priority int, m *dns.Msg) bool {
+		resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(req.Name, t), func(times, priority int, m *dns.Msg) bool {
 			// Try one more time if we receive NXDOMAIN
 			if m.Rcode == dns.RcodeNameError && !nxdomain {
 				nxdomain = true
@@ -152,16 +216,24 @@ loop:
 			return resolvers.PoolRetryPolicy(times, priority, m)
 		})
 
+		if dt.internal != nil {
+			internal = append(internal, dt.splitHorizonAnswers(ctx, msg, t, resp)...)
+		}
+
 		if err == nil && resp != nil && len(resp.Answer) > 0 {
 			if !requests.TrustedTag(req.Tag) &&
 				dt.enum.Sys.Pool().WildcardType(ctx, resp, req.Domain) != resolvers.WildcardTypeNone {
-				break
+				return internal, errWildcardMatch
 			}
 
 			ans := resolvers.ExtractAnswers(resp)
 			if len(ans) == 0 {
 				continue
 			}
+			// The DNAME records that synthesized the answer alias a subtree of names
+			if dname := resolvers.AnswersByType(ans, dns.TypeDNAME); len(dname) > 0 {
+				dt.sendDNAMERecords(ctx, dname, tp)
+			}
 
 			rr := resolvers.AnswersByType(ans, t)
 			if len(rr) == 0 {
@@ -174,16 +246,71 @@ loop:
 			}
 		} else {
 			if err != nil && err.Error() == "All resolvers have been stopped" {
-				return nil, err
+				return internal, err
 			}
 			dt.handleResolverError(ctx, err)
 		}
 	}
+	return internal, nil
+}
 
-	if len(req.Records) > 0 {
-		return req, nil
+func (dt *dNSTask) sendDNAMERecords(ctx context.Context, rr []*resolvers.ExtractedAnswer, tp pipeline.TaskParams) {
+	for _, a := range rr {
+		domain := dt.enum.Config.WhichDomain(a.Name)
+		if domain == "" {
+			continue
+		}
+
+		// The owner name has normally been resolved already, so the record is sent straight to the store
+		go pipeline.SendData(ctx, "store", &requests.DNSRequest{
+			Name:    a.Name,
+			Domain:  domain,
+			Records: convertAnswers([]*resolvers.ExtractedAnswer{a}),
+			Tag:     requests.DNS,
+			Source:  "DNS",
+		}, tp)
 	}
-	return nil, nil
+}
+
+// queryPriority returns the resolver priority level for the record type configured for the enumeration.
+// Names outside of the enumeration scope are always resolved with the lowest priority.
+func (dt *dNSTask) queryPriority(name string, qtype uint16) int {
+	if name != "" && !dt.enum.Config.IsDomainInScope(name) {
+		return resolvers.PriorityLow
+	}
+
+	switch dt.enum.Config.RecordPriority(dns.TypeToString[qtype]) {
+	case config.RecordPriorityCritical:
+		return resolvers.PriorityCritical
+	case config.RecordPriorityHigh:
+		return resolvers.PriorityHigh
+	case config.RecordPriorityNormal:
+		return resolvers.PriorityNormal
+	}
+	return resolvers.PriorityLow
+}
+
+// splitHorizonAnswers returns the answers provided by the internal resolvers that were not in the public response.
+func (dt *dNSTask) splitHorizonAnswers(ctx context.Context, msg *dns.Msg, qtype uint16, public *dns.Msg) []requests.DNSAnswer {
+	resp, err := dt.internal.Query(ctx, msg.Copy(), dt.queryPriority("", qtype), resolvers.PoolRetryPolicy)
+	if err != nil || resp == nil || len(resp.Answer) == 0 {
+		return nil
+	}
+
+	known := stringset.New()
+	if public != nil {
+		for _, a := range resolvers.ExtractAnswers(public) {
+			known.Insert(strings.ToLower(a.Name + "," + a.Data))
+		}
+	}
+
+	var diff []*resolvers.ExtractedAnswer
+	for _, a := range resolvers.AnswersByType(resolvers.ExtractAnswers(resp), qtype) {
+		if !known.Has(strings.ToLower(a.Name + "," + a.Data)) {
+			diff = append(diff, a)
+		}
+	}
+	return convertAnswers(diff)
 }
 
 func (dt *dNSTask) handleResolverError(ctx context.Context, e error) {
@@ -208,7 +335,8 @@ func (dt *dNSTask) handleResolverError(ctx context.Context, e error) {
 func (dt *dNSTask) subdomainQueries(ctx context.Context, req *requests.DNSRequest, tp pipeline.TaskParams) {
 	msg := resolvers.QueryMsg(req.Name, dns.TypeNS)
 	// Obtain the DNS answers for the NS records related to the domain
-	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, resolvers.PriorityHigh, resolvers.PoolRetryPolicy); err == nil {
+	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(req.Name, dns.TypeNS),
+		resolvers.PoolRetryPolicy); err == nil {
 		ans := resolvers.ExtractAnswers(resp)
 		rr := resolvers.AnswersByType(ans, dns.TypeNS)
 
@@ -229,7 +357,8 @@ func (dt *dNSTask) subdomainQueries(ctx context.Context, req *requests.DNSReques
 
 	msg = resolvers.QueryMsg(req.Name, dns.TypeMX)
 	// Obtain the DNS answers for the MX records related to the domain
-	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, resolvers.PriorityHigh, resolvers.PoolRetryPolicy); err == nil {
+	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(req.Name, dns.TypeMX),
+		resolvers.PoolRetryPolicy); err == nil {
 		ans := resolvers.ExtractAnswers(resp)
 		rr := resolvers.AnswersByType(ans, dns.TypeMX)
 
@@ -240,23 +369,20 @@ func (dt *dNSTask) subdomainQueries(ctx context.Context, req *requests.DNSReques
 
 	msg = resolvers.QueryMsg(req.Name, dns.TypeSOA)
 	// Obtain the DNS answers for the SOA records related to the domain
-	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, resolvers.PriorityHigh, resolvers.PoolRetryPolicy); err == nil {
+	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(req.Name, dns.TypeSOA),
+		resolvers.PoolRetryPolicy); err == nil {
 		ans := resolvers.ExtractAnswers(resp)
 		rr := resolvers.AnswersByType(ans, dns.TypeSOA)
 
-		for _, a := range rr {
-			pieces := strings.Split(a.Data, ",")
-			a.Data = pieces[len(pieces)-1]
-
-			req.Records = append(req.Records, convertAnswers([]*resolvers.ExtractedAnswer{a})...)
-		}
+		req.Records = append(req.Records, convertAnswers(rr)...)
 	} else {
 		dt.handleResolverError(ctx, err)
 	}
 
 	msg = resolvers.QueryMsg(req.Name, dns.TypeSPF)
 	// Obtain the DNS answers for the SPF records related to the domain
-	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, resolvers.PriorityHigh, resolvers.PoolRetryPolicy); err == nil {
+	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(req.Name, dns.TypeSPF),
+		resolvers.PoolRetryPolicy); err == nil {
 		ans := resolvers.ExtractAnswers(resp)
 		rr := resolvers.AnswersByType(ans, dns.TypeSPF)
 
@@ -265,17 +391,71 @@ func (dt *dNSTask) subdomainQueries(ctx context.Context, req *requests.DNSReques
 		dt.handleResolverError(ctx, err)
 	}
 
+	msg = resolvers.QueryMsg(req.Name, dns.TypeNAPTR)
+	// Obtain the DNS answers for the NAPTR records that identify the services offered by the domain
+	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(req.Name, dns.TypeNAPTR),
+		resolvers.PoolRetryPolicy); err == nil {
+		ans := resolvers.ExtractAnswers(resp)
+		rr := resolvers.AnswersByType(ans, dns.TypeNAPTR)
+
+		req.Records = append(req.Records, convertAnswers(rr)...)
+	} else {
+		dt.handleResolverError(ctx, err)
+	}
+
+	for _, tlsa := range tlsaNames(req.Name, dt.enum.Config.Ports) {
+		msg = resolvers.QueryMsg(tlsa, dns.TypeTLSA)
+		// Obtain the DNS answers for the TLSA records that pin the certificates of the TLS services
+		if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(tlsa, dns.TypeTLSA),
+			resolvers.PoolRetryPolicy); err == nil {
+			ans := resolvers.ExtractAnswers(resp)
+			rr := resolvers.AnswersByType(ans, dns.TypeTLSA)
+
+			req.Records = append(req.Records, convertAnswers(rr)...)
+		} else {
+			dt.handleResolverError(ctx, err)
+		}
+	}
+
+	msg = resolvers.QueryMsg(req.Name, dns.TypeCAA)
+	// Obtain the DNS answers for the CAA records that identify the authorized certificate authorities
+	if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(req.Name, dns.TypeCAA),
+		resolvers.PoolRetryPolicy); err == nil {
+		ans := resolvers.ExtractAnswers(resp)
+		rr := resolvers.AnswersByType(ans, dns.TypeCAA)
+
+		req.Records = append(req.Records, convertAnswers(rr)...)
+	} else {
+		dt.handleResolverError(ctx, err)
+	}
+
 	if req.Valid() && len(req.Records) > 0 {
 		go pipeline.SendData(ctx, "store", req, tp)
 	}
 }
 
+// tlsaNames returns the owner names of the TLSA records for the configured ports and the mail ports.
+func tlsaNames(name string, ports []int) []string {
+	var names []string
+
+	seen := make(map[int]struct{})
+	for _, port := range append(append([]int(nil), ports...), tlsaMailPorts...) {
+		if _, found := seen[port]; found || port <= 0 {
+			continue
+		}
+
+		seen[port] = struct{}{}
+		names = append(names, "_"+strconv.Itoa(port)+"._tcp."+name)
+	}
+	return names
+}
+
 func (dt *dNSTask) queryServiceNames(ctx context.Context, req *requests.DNSRequest, tp pipeline.TaskParams) {
-	for _, name := range popularSRVRecords {
+	for _, name := range dt.enum.Config.SRVServices {
 		srvName := name + "." + req.Name
 
 		msg := resolvers.QueryMsg(srvName, dns.TypeSRV)
-		if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, resolvers.PriorityHigh,
+		if resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority(srvName, dns.TypeSRV),
 			resolvers.PoolRetryPolicy); err == nil && len(resp.Answer) > 0 {
 			ans := resolvers.ExtractAnswers(resp)
 			if len(ans) == 0 {
@@ -314,7 +494,7 @@ func (dt *dNSTask) reverseDNSQuery(ctx context.Context, addr string, tp pipeline
 	}
 
 	var nxdomain bool
-	resp, err := dt.enum.Sys.Pool().Query(ctx, msg, resolvers.PriorityLow, func(times, priority int, m *dns.Msg) bool {
+	resp, err := dt.enum.Sys.Pool().Query(ctx, msg, dt.queryPriority("", dns.TypePTR), func(times, priority int, m *dns.Msg) bool {
 		// Try one more time if we receive NXDOMAIN
 		if m.Rcode == dns.RcodeNameError && !nxdomain {
 			nxdomain = true
@@ -343,6 +523,7 @@ func (dt *dNSTask) reverseDNSQuery(ctx context.Context, addr string, tp pipeline
 
 	// Check that the name discovered is in scope
 	if dt.enum.Config.WhichDomain(answer) == "" {
+		dt.relatedDomain(addr, answer)
 		return false
 	}
 
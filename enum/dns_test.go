@@ -0,0 +1,159 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/miekg/dns"
+)
+
+// testResolver answers the A queries with the same address, 93.184.216.34 unless another is configured,
+// and reports the configured wildcard type.
+type testResolver struct {
+	wildcard int
+	addr     string
+}
+
+func (r *testResolver) String() string { return "test" }
+func (r *testResolver) Stop()          {}
+func (r *testResolver) Stopped() bool  { return false }
+
+func (r *testResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry resolvers.Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	addr := r.addr
+	if addr == "" {
+		addr = "93.184.216.34"
+	}
+
+	if q := msg.Question[0]; q.Qtype == dns.TypeA {
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300},
+			A:   net.ParseIP(addr),
+		})
+	}
+	return resp, nil
+}
+
+func (r *testResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return r.wildcard
+}
+
+// testSystem provides the resolver pool to the tasks tested without starting a complete System.
+type testSystem struct {
+	systems.System
+	pool resolvers.Resolver
+}
+
+func (s *testSystem) Pool() resolvers.Resolver {
+	return s.pool
+}
+
+func TestProcessDNSRequestWildcard(t *testing.T) {
+	ctx, e := setupTestEnum(t, "example.com")
+	pool := &testResolver{wildcard: resolvers.WildcardTypeStatic}
+	e.Sys = &testSystem{pool: pool}
+	dt := newDNSTask(e)
+	tp := newTestTaskParams()
+
+	newReq := func(tag string) *requests.DNSRequest {
+		return &requests.DNSRequest{
+			Name:   "www.example.com",
+			Domain: "example.com",
+			Records: []requests.DNSAnswer{{
+				Name: "www.example.com",
+				Type: int(dns.TypeA),
+				Data: "93.184.216.35",
+			}},
+			Tag:    tag,
+			Source: "Test",
+		}
+	}
+
+	if _, err := dt.resolveName(ctx, newReq(requests.API), tp); !errors.Is(err, errWildcardMatch) {
+		t.Errorf("The wildcard match was not reported: %v", err)
+	}
+	// The records provided by the data source are discarded without stopping the pipeline
+	if data, err := dt.processDNSRequest(ctx, newReq(requests.API), tp); data != nil || err != nil {
+		t.Errorf("The name matching the wildcard was kept: %v %v", data, err)
+	}
+
+	data, err := dt.processDNSRequest(ctx, newReq(requests.DNS), tp)
+	if err != nil {
+		t.Fatalf("The name provided by a trusted source failed: %v", err)
+	}
+	if req, ok := data.(*requests.DNSRequest); !ok || len(req.Records) != 2 {
+		t.Errorf("The records of the name provided by a trusted source were not kept: %v", data)
+	}
+
+	pool.wildcard = resolvers.WildcardTypeNone
+	if data, err := dt.processDNSRequest(ctx, newReq(requests.API), tp); err != nil || data == nil {
+		t.Errorf("The name without a wildcard was dropped: %v", err)
+	}
+}
+
+func TestProcessDNSRequestSplitHorizon(t *testing.T) {
+	ctx, e := setupTestEnum(t, "example.com")
+	e.Sys = &testSystem{pool: &testResolver{wildcard: resolvers.WildcardTypeNone}}
+	dt := newDNSTask(e)
+	tp := newTestTaskParams()
+
+	// The internal resolvers provide the same answer as the public resolvers
+	dt.internal = &testResolver{wildcard: resolvers.WildcardTypeNone}
+	req := &requests.DNSRequest{Name: "www.example.com", Domain: "example.com", Tag: requests.DNS, Source: "Test"}
+	if internal, err := dt.resolveName(ctx, req, tp); err != nil || len(internal) != 0 {
+		t.Errorf("The answers provided by both resolvers were reported as split-horizon: %v", internal)
+	}
+
+	dt.internal = &testResolver{wildcard: resolvers.WildcardTypeNone, addr: "10.1.1.1"}
+	req = &requests.DNSRequest{Name: "www.example.com", Domain: "example.com", Tag: requests.DNS, Source: "Test"}
+	data, err := dt.processDNSRequest(ctx, req, tp)
+	if err != nil {
+		t.Fatalf("The name failed to resolve: %v", err)
+	}
+	// The public results do not contain the internal records
+	if r, ok := data.(*requests.DNSRequest); !ok || len(r.Records) != 1 || r.Records[0].Data != "93.184.216.34" {
+		t.Errorf("The public records of the name were %v", data)
+	}
+
+	select {
+	case d := <-tp.processed:
+		r, ok := d.(*requests.DNSRequest)
+		if !ok || r.Name != "www.example.com" || r.Source != "Internal DNS" {
+			t.Fatalf("The internal records were sent as %v", d)
+		}
+		if len(r.Records) != 1 || r.Records[0].Data != "10.1.1.1" || r.Records[0].Type != int(dns.TypeA) {
+			t.Errorf("The internal records were %v", r.Records)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("The records only provided by the internal resolvers were not sent to the store")
+	}
+}
+
+func TestTLSANames(t *testing.T) {
+	tests := []struct {
+		ports []int
+		want  []string
+	}{
+		{nil, []string{"_25._tcp.example.com", "_465._tcp.example.com", "_587._tcp.example.com"}},
+		{[]int{443, 8443, 25, 0}, []string{"_443._tcp.example.com", "_8443._tcp.example.com",
+			"_25._tcp.example.com", "_465._tcp.example.com", "_587._tcp.example.com"}},
+	}
+
+	for _, test := range tests {
+		if got := tlsaNames("example.com", test.ports); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tlsaNames(%v) returned %v, expected %v", test.ports, got, test.want)
+		}
+	}
+}
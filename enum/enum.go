@@ -5,7 +5,14 @@ package enum
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/datasrcs"
@@ -28,17 +35,28 @@ type Enumeration struct {
 	Bus            *eventbus.EventBus
 	Sys            systems.System
 	Graph          *graph.Graph
+	graphPath      string
+	migrated       map[*graph.Graph]struct{}
 	closedOnce     sync.Once
 	logQueue       queue.Queue
 	ctx            context.Context
 	srcs           []service.Service
+	registered     []service.Service
 	done           chan struct{}
 	doneOnce       sync.Once
+	srcBudgets     *datasrcs.SourceBudgets
 	resolvedFilter stringfilter.Filter
 	crawlFilter    stringfilter.Filter
+	emitted        stringfilter.Filter
+	related        []*requests.RelatedDomain
+	interceptions  []string
 	nameSrc        *enumSource
 	subTask        *subdomainTask
 	dnsTask        *dNSTask
+	addrTask       *addrTask
+	metaLock       sync.Mutex
+	started        time.Time
+	finished       time.Time
 }
 
 // NewEnumeration returns an initialized Enumeration that has not been started yet.
@@ -47,12 +65,20 @@ func NewEnumeration(cfg *config.Config, sys systems.System) *Enumeration {
 		Config:         cfg,
 		Sys:            sys,
 		Bus:            eventbus.NewEventBus(),
-		Graph:          graph.NewGraph(graph.NewCayleyGraphMemory()),
 		srcs:           datasrcs.SelectedDataSources(cfg, sys.DataSources()),
+		srcBudgets:     datasrcs.NewSourceBudgets(cfg),
 		logQueue:       queue.NewQueue(),
 		done:           make(chan struct{}),
 		resolvedFilter: stringfilter.NewBloomFilter(filterMaxSize),
 		crawlFilter:    stringfilter.NewStringFilter(),
+		emitted:        stringfilter.NewStringFilter(),
+		migrated:       make(map[*graph.Graph]struct{}),
+	}
+
+	e.Graph, e.graphPath = newEnumGraph(cfg)
+	// The data handlers receive the findings as they are inserted into the graph
+	for _, h := range sys.DataHandlers() {
+		e.Graph.AddDataHandler(h, cfg.Log)
 	}
 
 	if cfg.Passive {
@@ -61,6 +87,7 @@ func NewEnumeration(cfg *config.Config, sys systems.System) *Enumeration {
 
 	e.dnsTask = newDNSTask(e)
 	e.subTask = newSubdomainTask(e)
+	e.addrTask = newAddressTask(e)
 	return e
 }
 
@@ -68,7 +95,184 @@ func NewEnumeration(cfg *config.Config, sys systems.System) *Enumeration {
 func (e *Enumeration) Close() {
 	e.closedOnce.Do(func() {
 		e.Graph.Close()
+		// The embedded store is the only durable copy of the findings until they are
+		// migrated, so the store is otherwise left for the system to recover later
+		if e.graphPath != "" && e.findingsMigrated() {
+			os.RemoveAll(e.graphPath)
+		}
+	})
+}
+
+// MigrateEvents copies the findings of the enumeration into the provided graph database.
+func (e *Enumeration) MigrateEvents(g *graph.Graph) error {
+	if err := e.Graph.MigrateEvents(g, e.Config.UUID.String()); err != nil {
+		return err
+	}
+
+	e.metaLock.Lock()
+	e.migrated[g] = struct{}{}
+	e.metaLock.Unlock()
+	return nil
+}
+
+// findingsMigrated returns true when the findings were migrated into every system graph database.
+func (e *Enumeration) findingsMigrated() bool {
+	e.metaLock.Lock()
+	defer e.metaLock.Unlock()
+
+	dbs := e.Sys.GraphDatabases()
+	if len(dbs) == 0 {
+		return false
+	}
+
+	for _, g := range dbs {
+		if _, found := e.migrated[g]; !found {
+			return false
+		}
+	}
+	return true
+}
+
+// newEnumGraph returns the graph for the enumeration findings and the path of the embedded store
+// keeping the graph. The graph is kept in memory when the store is not configured or not available.
+func newEnumGraph(cfg *config.Config) (*graph.Graph, string) {
+	if dir := cfg.EnumGraphDirectory(); dir != "" {
+		path := filepath.Join(dir, cfg.UUID.String())
+
+		if err := os.MkdirAll(path, 0755); err == nil {
+			if db := graph.NewCayleyGraph("local", path, "nosync=true"); db != nil {
+				return graph.NewGraph(db), path
+			}
+		}
+		cfg.Log.Printf("Failed to create the embedded store at %s, so the enumeration graph is kept in memory", path)
+	}
+	return graph.NewGraph(graph.NewCayleyGraphMemory()), ""
+}
+
+// Stats returns the statistics collected for each data source used by the enumeration.
+func (e *Enumeration) Stats() map[string]datasrcs.SourceStats {
+	return e.srcBudgets.Stats()
+}
+
+// RelatedDomains returns the out of scope domains that appear to belong to the target organization,
+// discovered through the PTR records of addresses announced by the target ASNs.
+func (e *Enumeration) RelatedDomains() []*requests.RelatedDomain {
+	e.metaLock.Lock()
+	defer e.metaLock.Unlock()
+
+	related := make([]*requests.RelatedDomain, len(e.related))
+	copy(related, e.related)
+	return related
+}
+
+// Interceptions returns the messages about resolvers suspected of DNS interception during the enumeration.
+func (e *Enumeration) Interceptions() []string {
+	e.metaLock.Lock()
+	defer e.metaLock.Unlock()
+
+	return append([]string(nil), e.interceptions...)
+}
+
+// watchInterception registers the enumeration to receive the resolvers suspected of DNS
+// interception by the pool, and returns the function removing the registration.
+func (e *Enumeration) watchInterception(pool resolvers.Resolver) func() {
+	ir, ok := pool.(resolvers.InterceptionReporter)
+	if !ok {
+		return func() {}
+	}
+
+	return ir.AddInterceptionHandler(e.addInterception)
+}
+
+func (e *Enumeration) addInterception(msg string) {
+	// Resolvers suspected of DNS interception are reported with the enumeration logs
+	e.Bus.Publish(requests.LogTopic, eventbus.PriorityCritical, msg)
+
+	e.metaLock.Lock()
+	defer e.metaLock.Unlock()
+
+	for _, m := range e.interceptions {
+		if m == msg {
+			return
+		}
+	}
+	e.interceptions = append(e.interceptions, msg)
+}
+
+func (e *Enumeration) addRelatedDomain(rd *requests.RelatedDomain) {
+	e.metaLock.Lock()
+	defer e.metaLock.Unlock()
+
+	e.related = append(e.related, rd)
+}
+
+// RegisterSource adds a data source implemented outside of the datasrcs package to the enumeration.
+// The source must be registered before the enumeration is started, and it will be started and stopped
+// along with the enumeration. The source receives the DNSRequest for each root domain name and an
+// ASNRequest for each ASN provided, and obtains the configuration and event bus from the request
+// context using datasrcs.ContextConfigBus. Discovered names are published on requests.NewNameTopic.
+func (e *Enumeration) RegisterSource(src service.Service) error {
+	if src == nil || src.String() == "" {
+		return errors.New("The data source must be named")
+	}
+
+	e.metaLock.Lock()
+	defer e.metaLock.Unlock()
+
+	if !e.started.IsZero() {
+		return fmt.Errorf("The %s data source was registered after the enumeration was started", src.String())
+	}
+	for _, s := range e.srcs {
+		if strings.EqualFold(s.String(), src.String()) {
+			return fmt.Errorf("A data source named %s has already been registered", src.String())
+		}
+	}
+	// The source filter in the configuration applies to the registered data sources as well
+	if len(datasrcs.SelectedDataSources(e.Config, []service.Service{src})) == 0 {
+		return nil
+	}
+
+	e.srcs = append(e.srcs, src)
+	e.registered = append(e.registered, src)
+	sort.Slice(e.srcs, func(i, j int) bool {
+		return e.srcs[i].String() < e.srcs[j].String()
 	})
+	return nil
+}
+
+// internalResolverSetup returns a pool of the resolvers inside the network used to detect split-horizon DNS.
+func internalResolverSetup(cfg *config.Config) resolvers.Resolver {
+	var internal []resolvers.Resolver
+
+	for _, addr := range cfg.InternalResolvers {
+		if r := resolvers.NewBaseResolver(addr, config.DefaultQueriesPerBaselineResolver, cfg.Log); r != nil {
+			internal = append(internal, r)
+		}
+	}
+
+	return resolvers.NewResolverPool(internal, 2*time.Second, nil, cfg.Log)
+}
+
+func (e *Enumeration) startRegisteredSources() {
+	e.metaLock.Lock()
+	srcs := e.registered
+	e.metaLock.Unlock()
+
+	for _, src := range srcs {
+		if err := src.Start(); err != nil {
+			e.Config.Log.Printf("Failed to start the %s data source: %v", src.String(), err)
+		}
+	}
+}
+
+func (e *Enumeration) stopRegisteredSources() {
+	e.metaLock.Lock()
+	srcs := e.registered
+	e.metaLock.Unlock()
+
+	for _, src := range srcs {
+		src.Stop()
+	}
 }
 
 func (e *Enumeration) stop() {
@@ -83,6 +287,29 @@ func (e *Enumeration) Start(ctx context.Context) error {
 		return err
 	}
 
+	e.metaLock.Lock()
+	e.started = time.Now()
+	e.metaLock.Unlock()
+	defer func() {
+		e.metaLock.Lock()
+		e.finished = time.Now()
+		e.metaLock.Unlock()
+	}()
+
+	e.startRegisteredSources()
+	defer e.stopRegisteredSources()
+
+	if e.dnsTask != nil && len(e.Config.InternalResolvers) > 0 {
+		r := internalResolverSetup(e.Config)
+		if r == nil {
+			return errors.New("Failed to setup the internal DNS resolvers")
+		}
+
+		e.dnsTask.internal = r
+		defer r.Stop()
+		defer e.watchInterception(r)()
+	}
+
 	max := e.Config.MaxDNSQueries * int(resolvers.QueryTimeout.Seconds())
 	// The pipeline input source will receive all the names
 	source := newEnumSource(e, max)
@@ -91,11 +318,13 @@ func (e *Enumeration) Start(ctx context.Context) error {
 	defer e.Bus.Unsubscribe(requests.NewNameTopic, source.InputName)
 	sink := e.makeOutputSink()
 
+	var fqdn *fqdnFilter
 	var stages []pipeline.Stage
 	if !e.Config.Passive {
+		fqdn = newFQDNFilter(e)
 		// Task that performs initial filtering for new FQDNs and IP addresses
 		stages = append(stages, pipeline.FixedPool("new",
-			e.makeNewDataTaskFunc(newFQDNFilter(e), newAddressTask(e)), 50))
+			e.makeNewDataTaskFunc(fqdn, e.addrTask), 50))
 		stages = append(stages, pipeline.FixedPool("", e.dnsTask.makeBlacklistTaskFunc(), 50))
 		// Task that performs DNS queries for root domain names
 		stages = append(stages, pipeline.DynamicPool("root", e.dnsTask.makeRootTaskFunc(), max))
@@ -103,13 +332,13 @@ func (e *Enumeration) Start(ctx context.Context) error {
 		stages = append(stages, pipeline.DynamicPool("dns", e.dnsTask, max))
 	}
 
-	stages = append(stages, pipeline.FIFO("filter", e.makeFilterTaskFunc()))
+	stages = append(stages, pipeline.FIFO("filter", e.makeFilterTaskFunc(fqdn)))
 
 	if !e.Config.Passive {
 		stages = append(stages, pipeline.FIFO("store", newDataManager(e)))
 		stages = append(stages, pipeline.FIFO("", e.subTask))
 	}
-	if e.Config.Active {
+	if e.Config.Active || e.Config.EnableZoneWalk {
 		stages = append(stages, pipeline.FIFO("active", newActiveTask(e, 50)))
 	}
 
@@ -119,11 +348,14 @@ func (e *Enumeration) Start(ctx context.Context) error {
 	 */
 	e.Bus.Subscribe(requests.LogTopic, e.queueLog)
 	defer e.Bus.Unsubscribe(requests.LogTopic, e.queueLog)
+	defer e.watchInterception(e.Sys.Pool())()
 	if !e.Config.Passive {
 		e.Bus.Subscribe(requests.NewAddrTopic, source.InputAddress)
 		defer e.Bus.Unsubscribe(requests.NewAddrTopic, source.InputAddress)
 		e.Bus.Subscribe(requests.NewASNTopic, e.Sys.Cache().Update)
 		defer e.Bus.Unsubscribe(requests.NewASNTopic, e.Sys.Cache().Update)
+		e.Bus.Subscribe(requests.RelatedDomainTopic, e.addRelatedDomain)
+		defer e.Bus.Unsubscribe(requests.RelatedDomainTopic, e.addRelatedDomain)
 	}
 
 	/*
@@ -145,6 +377,11 @@ func (e *Enumeration) Start(ctx context.Context) error {
 	ctx, cancel = context.WithCancel(ctx)
 	ctx = context.WithValue(ctx, requests.ContextConfig, e.Config)
 	ctx = context.WithValue(ctx, requests.ContextEventBus, e.Bus)
+	ctx = context.WithValue(ctx, requests.ContextSourceBudgets, e.srcBudgets)
+	ctx = context.WithValue(ctx, requests.ContextGraph, e.Graph)
+	if cache := datasrcs.NewResponseCache(e.Config, e.Sys.GraphDatabases()); cache != nil {
+		ctx = context.WithValue(ctx, requests.ContextResponseCache, cache)
+	}
 	e.ctx = ctx
 
 	// Monitor for termination of the enumeration
@@ -155,6 +392,9 @@ func (e *Enumeration) Start(ctx context.Context) error {
 	defer e.stop()
 
 	go e.periodicLogging()
+	if e.Config.PruneMemoryThreshold > 0 {
+		go e.periodicPruning()
+	}
 	defer e.writeLogs(true)
 
 	if !e.Config.Passive {
@@ -233,7 +473,7 @@ func (e *Enumeration) makeNewDataTaskFunc(fqdn *fqdnFilter, addrs *addrTask) pip
 	})
 }
 
-func (e *Enumeration) makeFilterTaskFunc() pipeline.TaskFunc {
+func (e *Enumeration) makeFilterTaskFunc(fqdn *fqdnFilter) pipeline.TaskFunc {
 	return pipeline.TaskFunc(func(ctx context.Context, data pipeline.Data, tp pipeline.TaskParams) (pipeline.Data, error) {
 		select {
 		case <-ctx.Done():
@@ -258,6 +498,10 @@ func (e *Enumeration) makeFilterTaskFunc() pipeline.TaskFunc {
 		if name != "" && !e.resolvedFilter.Duplicate(name) {
 			return data, nil
 		}
+		// The other sources that provided the resolved name are still shown in the graph
+		if req, ok := data.(*requests.DNSRequest); ok && fqdn != nil && req.Source != "" {
+			fqdn.queue.Append(req)
+		}
 		return nil, nil
 	})
 }
@@ -0,0 +1,139 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/stringfilter"
+	"github.com/OWASP/Amass/v3/systems"
+	"github.com/caffix/queue"
+	"github.com/caffix/service"
+)
+
+// testSource is a data source implemented outside of the datasrcs package.
+type testSource struct {
+	service.BaseService
+	started bool
+	stopped bool
+}
+
+func newTestSource(name string) *testSource {
+	s := new(testSource)
+
+	s.BaseService = *service.NewBaseService(s, name)
+	return s
+}
+
+func (s *testSource) OnStart() error {
+	s.started = true
+	return nil
+}
+
+func (s *testSource) OnStop() error {
+	s.stopped = true
+	return nil
+}
+
+func TestRegisterSource(t *testing.T) {
+	_, e := setupTestEnum(t, "example.com")
+	e.Config.SourceFilter.Sources = []string{"Excluded"}
+
+	if err := e.RegisterSource(nil); err == nil {
+		t.Errorf("The missing data source was registered")
+	}
+	if err := e.RegisterSource(newTestSource("")); err == nil {
+		t.Errorf("The data source without a name was registered")
+	}
+
+	second, first := newTestSource("Second"), newTestSource("First")
+	for _, src := range []*testSource{second, first} {
+		if err := e.RegisterSource(src); err != nil {
+			t.Errorf("Failed to register the %s data source: %v", src.String(), err)
+		}
+	}
+	if err := e.RegisterSource(newTestSource("first")); err == nil {
+		t.Errorf("The data source with the name of a registered data source was accepted")
+	}
+	// The data sources excluded by the configuration are ignored
+	if err := e.RegisterSource(newTestSource("Excluded")); err != nil {
+		t.Errorf("The excluded data source returned an error: %v", err)
+	}
+
+	if len(e.srcs) != 2 || e.srcs[0] != first || e.srcs[1] != second {
+		t.Fatalf("The enumeration has the data sources %v", e.srcs)
+	}
+	if names := e.Metadata().Sources; len(names) != 2 || names[0] != "First" {
+		t.Errorf("The metadata did not include the registered data sources: %v", names)
+	}
+
+	e.startRegisteredSources()
+	if !first.started || !second.started {
+		t.Errorf("The registered data sources were not started")
+	}
+	e.stopRegisteredSources()
+	if !first.stopped || !second.stopped {
+		t.Errorf("The registered data sources were not stopped")
+	}
+
+	e.metaLock.Lock()
+	e.started = time.Now()
+	e.metaLock.Unlock()
+	if err := e.RegisterSource(newTestSource("Late")); err == nil {
+		t.Errorf("The data source was registered after the enumeration was started")
+	}
+}
+
+// pruneSystem reports memory usage above any pruning threshold and has no graph databases.
+type pruneSystem struct {
+	systems.System
+}
+
+func (s *pruneSystem) GetMemoryUsage() uint64 {
+	return ^uint64(0)
+}
+
+func (s *pruneSystem) GraphDatabases() []*graph.Graph {
+	return nil
+}
+
+func TestPeriodicPruningContinuesAfterFailure(t *testing.T) {
+	_, e := setupTestEnum(t, "example.com")
+	e.Sys = &pruneSystem{}
+	e.Config.PruneMemoryThreshold = 1
+	e.emitted = stringfilter.NewStringFilter()
+	e.logQueue = queue.NewQueue()
+	e.done = make(chan struct{})
+
+	interval := pruneInterval
+	pruneInterval = 10 * time.Millisecond
+	defer func() { pruneInterval = interval }()
+
+	finished := make(chan struct{})
+	go func() {
+		e.periodicPruning()
+		close(finished)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	e.stop()
+	<-finished
+
+	var failures int
+	for {
+		msg, ok := e.logQueue.Next()
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(msg.(string), "Graph pruning failed") {
+			failures++
+		}
+	}
+	// Without a persistent graph every attempt fails, and each attempt must be logged
+	if failures < 2 {
+		t.Errorf("The pruning was not attempted again after the failure: %d attempts logged", failures)
+	}
+}
@@ -4,19 +4,48 @@
 package enum
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/OWASP/Amass/v3/graph"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/stringfilter"
 )
 
 // ExtractOutput is a convenience method for obtaining new discoveries made by the enumeration process.
 func (e *Enumeration) ExtractOutput(filter stringfilter.Filter, asinfo bool) []*requests.Output {
+	var output []*requests.Output
+
 	if e.Config.Passive {
-		return e.Graph.EventNames(e.Config.UUID.String(), filter)
+		output = e.Graph.EventNames(e.Config.UUID.String(), filter)
+	} else {
+		output = e.Graph.EventOutput(e.Config.UUID.String(), filter, asinfo, e.Sys.Cache())
 	}
 
-	return e.Graph.EventOutput(e.Config.UUID.String(), filter, asinfo, e.Sys.Cache())
+	for _, o := range output {
+		if !e.Config.Passive {
+			e.annotateCDN(o)
+		}
+		// Names that have been emitted are candidates for graph pruning
+		e.emitted.Duplicate(o.Name)
+	}
+	return output
+}
+
+// annotateCDN marks the output of names that only resolve into content delivery networks.
+// The graph provides the CDN of each address, which is recorded as the infrastructure is inserted.
+func (e *Enumeration) annotateCDN(o *requests.Output) {
+	if !e.Config.NoteHiddenOrigin || len(o.Addresses) == 0 {
+		return
+	}
+
+	for _, a := range o.Addresses {
+		if a.CDN == "" {
+			return
+		}
+	}
+	// When all the addresses belong to CDNs, the origin infrastructure is likely hidden
+	o.OriginHidden = true
 }
 
 func (e *Enumeration) submitKnownNames() {
@@ -68,6 +97,40 @@ func (e *Enumeration) submitProvidedNames() {
 	}
 }
 
+// How often the memory usage is checked against the pruning threshold
+var pruneInterval = time.Minute
+
+// periodicPruning removes emitted names from the in-memory graph once the memory threshold is exceeded.
+func (e *Enumeration) periodicPruning() {
+	t := time.NewTicker(pruneInterval)
+	defer t.Stop()
+
+	threshold := uint64(e.Config.PruneMemoryThreshold) * 1024 * 1024
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-t.C:
+			if e.Sys.GetMemoryUsage() < threshold {
+				continue
+			}
+
+			num, err := e.Graph.Prune(&graph.PrunePolicy{
+				Event:   e.Config.UUID.String(),
+				InScope: e.Config.IsDomainInScope,
+				Emitted: e.emitted.Has,
+				Persist: e.Sys.GraphDatabases(),
+			})
+			if err != nil {
+				// The pruning is attempted again at the next interval
+				e.queueLog(fmt.Sprintf("Graph pruning failed: %v", err))
+				continue
+			}
+			e.queueLog(fmt.Sprintf("Pruned %d names from the in-memory graph", num))
+		}
+	}
+}
+
 func (e *Enumeration) queueLog(msg string) {
 	e.logQueue.Append(msg)
 }
@@ -0,0 +1,102 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/OWASP/Amass/v3/wordlist"
+	"github.com/caffix/pipeline"
+)
+
+const (
+	markovOrder = 2
+	// The number of labels required before the model starts guessing names
+	markovMinLabels = 20
+	// The number of newly trained labels that causes another round of guesses
+	markovRoundSize = 50
+)
+
+// markovGuesser trains a Markov model on the labels of the names discovered during the
+// enumeration and guesses likely names within the subdomains those names were found in.
+type markovGuesser struct {
+	sync.Mutex
+	enum    *Enumeration
+	model   *wordlist.MarkovModel
+	count   int
+	subs    map[string]string
+	guessed map[string]struct{}
+}
+
+func newMarkovGuesser(e *Enumeration) *markovGuesser {
+	return &markovGuesser{
+		enum:    e,
+		model:   wordlist.NewMarkovModel(markovOrder),
+		subs:    make(map[string]string),
+		guessed: make(map[string]struct{}),
+	}
+}
+
+// addName trains the model on the name and sends the guesses once enough new labels have been seen.
+func (m *markovGuesser) addName(ctx context.Context, req *requests.DNSRequest, tp pipeline.TaskParams) {
+	labels := strings.Split(req.Name, ".")
+	// Root domain names are not used to train the model
+	if len(labels) <= len(strings.Split(req.Domain, ".")) {
+		return
+	}
+
+	sub := strings.Join(labels[1:], ".")
+	if !m.model.Train(labels[0]) {
+		return
+	}
+
+	m.Lock()
+	m.count++
+	m.subs[sub] = req.Domain
+	if m.model.Len() < markovMinLabels || m.count < markovRoundSize {
+		m.Unlock()
+		return
+	}
+
+	subs := m.subs
+	m.count = 0
+	m.subs = make(map[string]string)
+	m.Unlock()
+
+	guesses := m.model.Generate(m.enum.Config.MarkovGuesses)
+	go m.sendGuesses(ctx, guesses, subs, tp)
+}
+
+func (m *markovGuesser) sendGuesses(ctx context.Context, guesses []string, subs map[string]string, tp pipeline.TaskParams) {
+	for sub, domain := range subs {
+		for _, label := range guesses {
+			name := label + "." + sub
+
+			m.Lock()
+			_, found := m.guessed[name]
+			m.guessed[name] = struct{}{}
+			m.Unlock()
+
+			if found || !m.enum.Config.IsDomainInScope(name) {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			pipeline.SendData(ctx, "new", &requests.DNSRequest{
+				Name:   name,
+				Domain: domain,
+				Tag:    requests.GUESS,
+				Source: "Markov Model",
+			}, tp)
+		}
+	}
+}
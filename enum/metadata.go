@@ -0,0 +1,117 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/OWASP/Amass/v3/format"
+)
+
+var dsnCredsRE = regexp.MustCompile(`^([^:@/]+):[^@]*@`)
+
+// Metadata describes how the results of an enumeration were produced.
+type Metadata struct {
+	Version  string           `json:"version"`
+	UUID     string           `json:"uuid"`
+	Start    time.Time        `json:"start"`
+	End      time.Time        `json:"end,omitempty"`
+	Domains  []string         `json:"domains"`
+	Sources  []string         `json:"sources"`
+	Settings MetadataSettings `json:"settings"`
+	// Messages about the resolvers suspected of DNS interception
+	Interceptions []string `json:"interceptions,omitempty"`
+}
+
+// MetadataSettings contains the key configuration settings of an enumeration, without secrets.
+type MetadataSettings struct {
+	Mode            string   `json:"mode"`
+	BruteForcing    bool     `json:"brute_forcing"`
+	Recursive       bool     `json:"recursive"`
+	MinForRecursive int      `json:"min_for_recursive"`
+	MaxDepth        int      `json:"max_depth"`
+	Alterations     bool     `json:"alterations"`
+	ZoneWalk        bool     `json:"zone_walk"`
+	NetblockSweeps  bool     `json:"netblock_sweeps"`
+	MaxDNSQueries   int      `json:"max_dns_queries"`
+	Resolvers       []string `json:"resolvers,omitempty"`
+	Blacklist       []string `json:"blacklist,omitempty"`
+	MinLabelLength  int      `json:"min_label_length,omitempty"`
+	TimeBudget      int      `json:"time_budget,omitempty"`
+	GraphDBs        []string `json:"graph_databases,omitempty"`
+	DataHandlers    []string `json:"data_handlers,omitempty"`
+}
+
+// Metadata returns the structured information describing how the enumeration was performed.
+func (e *Enumeration) Metadata() *Metadata {
+	cfg := e.Config
+
+	mode := "normal"
+	if cfg.Active {
+		mode = "active"
+	} else if cfg.Passive {
+		mode = "passive"
+	}
+
+	var srcs []string
+	for _, src := range e.srcs {
+		srcs = append(srcs, src.String())
+	}
+
+	var dbs []string
+	for _, db := range cfg.GraphDBs {
+		dbs = append(dbs, db.System+": "+redactDBURL(db.URL))
+	}
+
+	var handlers []string
+	for _, db := range cfg.DataHandlers {
+		handlers = append(handlers, db.System+": "+redactDBURL(db.URL))
+	}
+
+	e.metaLock.Lock()
+	start, end := e.started, e.finished
+	interceptions := append([]string(nil), e.interceptions...)
+	e.metaLock.Unlock()
+
+	return &Metadata{
+		Version: format.Version,
+		UUID:    cfg.UUID.String(),
+		Start:   start,
+		End:     end,
+		Domains: cfg.Domains(),
+		Sources: srcs,
+		Settings: MetadataSettings{
+			Mode:            mode,
+			BruteForcing:    cfg.BruteForcing,
+			Recursive:       cfg.Recursive,
+			MinForRecursive: cfg.MinForRecursive,
+			MaxDepth:        cfg.MaxDepth,
+			Alterations:     cfg.Alterations,
+			ZoneWalk:        cfg.EnableZoneWalk,
+			NetblockSweeps:  cfg.EnableNetblockSweeps,
+			MaxDNSQueries:   cfg.MaxDNSQueries,
+			Resolvers:       cfg.Resolvers,
+			Blacklist:       cfg.Blacklist,
+			MinLabelLength:  cfg.MinLabelLength,
+			TimeBudget:      cfg.TimeBudget,
+			GraphDBs:        dbs,
+			DataHandlers:    handlers,
+		},
+		Interceptions: interceptions,
+	}
+}
+
+// redactDBURL removes the credentials from the graph database connection strings.
+func redactDBURL(u string) string {
+	if p, err := url.Parse(u); err == nil && p.User != nil {
+		if _, set := p.User.Password(); set {
+			p.User = url.UserPassword(p.User.Username(), "REDACTED")
+		}
+		return p.String()
+	}
+
+	return dsnCredsRE.ReplaceAllString(u, "$1:REDACTED@")
+}
@@ -92,20 +92,36 @@ func (f *fqdnFilter) processDupNames() {
 	uuid := f.enum.Config.UUID.String()
 
 	type altsource struct {
-		Name      string
-		Source    string
-		Tag       string
-		Timestamp time.Time
+		Name       string
+		Source     string
+		Tag        string
+		Provenance *requests.Provenance
+		Timestamp  time.Time
 	}
+	insert := func(a *altsource) {
+		node, err := f.enum.Graph.ReadNode(a.Name, "fqdn")
+		if err != nil {
+			return
+		}
+
+		// The name is already in the graph, so only the source and event edges are added
+		_ = f.enum.Graph.AddNodeToEvent(node, a.Source, a.Tag, uuid)
+		// Keep the discovery timestamps and evidence provided by each source
+		if a.Provenance != nil {
+			_ = f.enum.Graph.InsertProvenance(a.Name, uuid, a.Provenance)
+		}
+	}
+
 	var pending []*altsource
 	each := func(element interface{}) {
 		req := element.(*requests.DNSRequest)
 
 		pending = append(pending, &altsource{
-			Name:      req.Name,
-			Source:    req.Source,
-			Tag:       req.Tag,
-			Timestamp: time.Now(),
+			Name:       req.Name,
+			Source:     req.Source,
+			Tag:        req.Tag,
+			Provenance: req.Provenance,
+			Timestamp:  time.Now(),
 		})
 	}
 	t := time.NewTicker(5 * time.Second)
@@ -123,9 +139,7 @@ loop:
 				if now.Before(a.Timestamp.Add(10 * time.Minute)) {
 					break
 				}
-				if _, err := f.enum.Graph.ReadNode(a.Name, "fqdn"); err == nil {
-					f.enum.Graph.InsertFQDN(a.Name, a.Source, a.Tag, uuid)
-				}
+				insert(a)
 				count++
 			}
 			pending = pending[count:]
@@ -134,15 +148,14 @@ loop:
 
 	f.queue.Process(each)
 	for _, a := range pending {
-		if _, err := f.enum.Graph.ReadNode(a.Name, "fqdn"); err == nil {
-			f.enum.Graph.InsertFQDN(a.Name, a.Source, a.Tag, uuid)
-		}
+		insert(a)
 	}
 }
 
 // subdomainTask handles newly discovered proper subdomain names in the enumeration.
 type subdomainTask struct {
 	enum      *Enumeration
+	markov    *markovGuesser
 	queue     queue.Queue
 	timesChan chan *timesReq
 	done      chan struct{}
@@ -157,6 +170,10 @@ func newSubdomainTask(e *Enumeration) *subdomainTask {
 		done:      make(chan struct{}, 2),
 	}
 
+	if e.Config.Alterations && e.Config.MarkovGuesses > 0 {
+		r.markov = newMarkovGuesser(e)
+	}
+
 	go r.timesManager()
 	return r
 }
@@ -195,6 +212,9 @@ func (r *subdomainTask) Process(ctx context.Context, data pipeline.Data, tp pipe
 		Source:  req.Source,
 	})
 
+	if r.markov != nil {
+		r.markov.addName(ctx, req, tp)
+	}
 	return r.checkForSubdomains(ctx, req, tp)
 }
 
@@ -0,0 +1,73 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"golang.org/x/net/publicsuffix"
+)
+
+var nonAlphaNumRE = regexp.MustCompile(`[^a-z0-9]+`)
+
+// relatedDomain correlates an out of scope name found in a PTR record with the ASN announcing
+// the address, and emits a RelatedDomain event when the ASN appears to belong to the target.
+func (dt *dNSTask) relatedDomain(addr, name string) {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return
+	}
+
+	asn := dt.enum.Sys.Cache().AddrSearch(addr)
+	if asn == nil || asn.ASN == 0 || !dt.ownedASN(asn) {
+		return
+	}
+	// Only report each related domain once
+	if dt.related.Duplicate(domain) {
+		return
+	}
+
+	dt.enum.Bus.Publish(requests.RelatedDomainTopic, eventbus.PriorityHigh, &requests.RelatedDomain{
+		Domain:      domain,
+		Name:        name,
+		Address:     addr,
+		ASN:         asn.ASN,
+		Description: asn.Description,
+		Tag:         requests.DNS,
+		Source:      "Reverse DNS",
+	})
+	dt.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+		fmt.Sprintf("Related domain %s: %s has the PTR record %s in AS%d - %s", domain, addr, name, asn.ASN, asn.Description))
+}
+
+// ownedASN returns true when the ASN was provided as in scope, or the ASN description
+// mentions the organization name of one of the target domains.
+func (dt *dNSTask) ownedASN(asn *requests.ASNRequest) bool {
+	for _, n := range dt.enum.Config.ASNs {
+		if n == asn.ASN {
+			return true
+		}
+	}
+
+	words := make(map[string]struct{})
+	for _, w := range nonAlphaNumRE.Split(strings.ToLower(asn.Description), -1) {
+		words[w] = struct{}{}
+	}
+
+	for _, d := range dt.enum.Config.Domains() {
+		label := strings.ToLower(strings.Split(d, ".")[0])
+		// Short labels match too many unrelated descriptions
+		if len(label) < 3 {
+			continue
+		}
+		if _, found := words[label]; found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+)
+
+func TestOwnedASN(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AddDomain("owasp.org")
+	cfg.ASNs = []int{64496}
+	dt := &dNSTask{enum: &Enumeration{Config: cfg}}
+
+	tests := []struct {
+		asn  *requests.ASNRequest
+		want bool
+	}{
+		{&requests.ASNRequest{ASN: 64496, Description: "EXAMPLE-AS"}, true},
+		{&requests.ASNRequest{ASN: 64497, Description: "OWASP-FOUNDATION - The OWASP Foundation"}, true},
+		{&requests.ASNRequest{ASN: 64498, Description: "EXAMPLE-AS - Example Networks"}, false},
+		// The related domain must not match the ASN description on its own name
+		{&requests.ASNRequest{ASN: 64499, Description: "UNRELATED - Unrelated Hosting"}, false},
+	}
+
+	for _, test := range tests {
+		if got := dt.ownedASN(test.asn); got != test.want {
+			t.Errorf("ownedASN returned %t for AS%d - %s", got, test.asn.ASN, test.asn.Description)
+		}
+	}
+}
+
+func TestRelatedDomains(t *testing.T) {
+	e := &Enumeration{Bus: eventbus.NewEventBus()}
+	defer e.Bus.Stop()
+
+	e.Bus.Subscribe(requests.RelatedDomainTopic, e.addRelatedDomain)
+	defer e.Bus.Unsubscribe(requests.RelatedDomainTopic, e.addRelatedDomain)
+
+	rd := &requests.RelatedDomain{
+		Domain:  "owasp-mail.net",
+		Name:    "mx1.owasp-mail.net",
+		Address: "192.0.2.1",
+		ASN:     64496,
+	}
+	// The subscription is processed asynchronously by the event bus
+	time.Sleep(100 * time.Millisecond)
+	e.Bus.Publish(requests.RelatedDomainTopic, eventbus.PriorityHigh, rd)
+
+	for i := 0; i < 100 && len(e.RelatedDomains()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	related := e.RelatedDomains()
+	if len(related) != 1 || related[0].Domain != "owasp-mail.net" {
+		t.Errorf("The related domain was not collected by the enumeration: %v", related)
+	}
+}
+
+func TestInterceptions(t *testing.T) {
+	e := &Enumeration{Bus: eventbus.NewEventBus()}
+	defer e.Bus.Stop()
+
+	msg := "Resolver 192.0.2.53 returned wrong data for the canary name a.root-servers.net and was quarantined: suspected DNS interception"
+	e.addInterception(msg)
+	e.addInterception(msg)
+
+	if got := e.Interceptions(); len(got) != 1 || got[0] != msg {
+		t.Errorf("The suspected interception was not collected by the enumeration: %v", got)
+	}
+}
@@ -10,27 +10,39 @@ import (
 	"net"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/OWASP/Amass/v3/datasrcs"
+	"github.com/OWASP/Amass/v3/graph"
 	amassnet "github.com/OWASP/Amass/v3/net"
 	amassdns "github.com/OWASP/Amass/v3/net/dns"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/OWASP/Amass/v3/stringfilter"
 	"github.com/caffix/eventbus"
 	"github.com/caffix/pipeline"
+	"github.com/caffix/stringset"
 	"github.com/miekg/dns"
 	"golang.org/x/net/publicsuffix"
 )
 
 // DataManager is the OutputSink that handles all data processed by the pipeline.
 type dataManager struct {
-	enum *Enumeration
+	enum        *Enumeration
+	dnameFilter stringfilter.Filter
+	// The subtrees aliased by DNAME records, from the owner name to the target name
+	dnameLock sync.Mutex
+	dnames    map[string]string
 }
 
 // newDataManager returns a dataManager specific to the provided Enumeration.
 func newDataManager(e *Enumeration) *dataManager {
-	return &dataManager{enum: e}
+	return &dataManager{
+		enum:        e,
+		dnameFilter: stringfilter.NewStringFilter(),
+		dnames:      make(map[string]string),
+	}
 }
 
 // Process implements the pipeline Task interface.
@@ -58,11 +70,22 @@ func (dm *dataManager) Process(ctx context.Context, data pipeline.Data, tp pipel
 }
 
 func (dm *dataManager) dnsRequest(ctx context.Context, req *requests.DNSRequest, tp pipeline.TaskParams) error {
-	// Check for CNAME records first
+	// Keep the discovery timestamps and evidence provided by the data source
+	if req.Provenance != nil && len(req.Records) > 0 {
+		if err := dm.enum.Graph.InsertProvenance(req.Name, dm.enum.Config.UUID.String(), req.Provenance); err != nil {
+			dm.enum.Bus.Publish(requests.LogTopic, eventbus.PriorityHigh,
+				fmt.Sprintf("%s failed to insert provenance: %v", dm.enum.Graph, err))
+		}
+	}
+
 	for i, r := range req.Records {
 		req.Records[i].Name = strings.Trim(strings.ToLower(r.Name), ".")
 		req.Records[i].Data = strings.Trim(strings.ToLower(r.Data), ".")
-
+	}
+	// Names stored under a subtree aliased by a DNAME record are also resolved under the target subtree
+	dm.aliasedNames(ctx, req.Name, tp)
+	// Check for CNAME records first
+	for i, r := range req.Records {
 		if uint16(r.Type) == dns.TypeCNAME {
 			// Do not enter more than the CNAME record
 			return dm.insertCNAME(ctx, req, i, tp)
@@ -90,6 +113,14 @@ func (dm *dataManager) dnsRequest(ctx context.Context, req *requests.DNSRequest,
 			err = dm.insertSOA(ctx, req, i, tp)
 		case dns.TypeSPF:
 			err = dm.insertSPF(ctx, req, i, tp)
+		case dns.TypeCAA:
+			err = dm.insertCAA(ctx, req, i, tp)
+		case dns.TypeDNAME:
+			err = dm.insertDNAME(ctx, req, i, tp)
+		case dns.TypeNAPTR:
+			err = dm.insertNAPTR(ctx, req, i, tp)
+		case dns.TypeTLSA:
+			err = dm.insertTLSA(ctx, req, i, tp)
 		}
 		if err != nil {
 			break
@@ -104,11 +135,25 @@ func (dm *dataManager) insertCNAME(ctx context.Context, req *requests.DNSRequest
 		return errors.New("The context did not contain the expected values")
 	}
 
-	target := resolvers.RemoveLastDot(req.Records[recidx].Data)
-	if target == "" {
+	chain, loop := cnameChain(req.Records[recidx].Name, req.Records)
+	if len(chain) == 0 {
 		return errors.New("Failed to extract a FQDN from the DNS answer data")
 	}
+	// Store every record in the chain, not only the first hop
+	for _, rec := range chain {
+		if err := dm.enum.Graph.InsertCNAME(rec.Name, rec.Data, req.Source, req.Tag, cfg.UUID.String()); err != nil {
+			msg := fmt.Sprintf("%s failed to insert CNAME: %v", dm.enum.Graph, err)
+
+			bus.Publish(requests.LogTopic, eventbus.PriorityHigh, msg)
+			return errors.New(msg)
+		}
+	}
+	if loop != nil {
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, fmt.Sprintf("%s: %v", req.Name, loop))
+		return nil
+	}
 
+	target := chain[len(chain)-1].Data
 	domain, err := publicsuffix.EffectiveTLDPlusOne(target)
 	if err != nil {
 		return errors.New("Failed to extract a domain name from the FQDN")
@@ -119,13 +164,6 @@ func (dm *dataManager) insertCNAME(ctx context.Context, req *requests.DNSRequest
 		return errors.New("The request did not contain a domain name")
 	}
 
-	if err := dm.enum.Graph.InsertCNAME(req.Name, target, req.Source, req.Tag, cfg.UUID.String()); err != nil {
-		msg := fmt.Sprintf("%s failed to insert CNAME: %v", dm.enum.Graph, err)
-
-		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, msg)
-		return errors.New(msg)
-	}
-
 	// Important - Allows chained CNAME records to be resolved until an A/AAAA record
 	go pipeline.SendData(ctx, "new", &requests.DNSRequest{
 		Name:   target,
@@ -136,6 +174,39 @@ func (dm *dataManager) insertCNAME(ctx context.Context, req *requests.DNSRequest
 	return nil
 }
 
+// cnameChain follows the CNAME records starting at the provided name, and returns the records in
+// the order they were followed. An error is returned when the chain loops or is too long.
+func cnameChain(name string, records []requests.DNSAnswer) ([]requests.DNSAnswer, error) {
+	var chain []requests.DNSAnswer
+	seen := stringset.New(name)
+
+	for {
+		var next *requests.DNSAnswer
+		for i, rec := range records {
+			if uint16(rec.Type) == dns.TypeCNAME && rec.Name == name && rec.Data != "" {
+				next = &records[i]
+				break
+			}
+		}
+		if next == nil {
+			return chain, nil
+		}
+
+		// The same limit as Graph.CNAMEChain, so the stored chains can be followed in the graph
+		if len(chain) >= graph.MaxCNAMEChainLength {
+			return chain, fmt.Errorf("The CNAME chain exceeds %d records", graph.MaxCNAMEChainLength)
+		}
+
+		chain = append(chain, *next)
+		if seen.Has(next.Data) {
+			return chain, fmt.Errorf("The CNAME chain loops back to %s", next.Data)
+		}
+
+		seen.Insert(next.Data)
+		name = next.Data
+	}
+}
+
 func (dm *dataManager) insertA(ctx context.Context, req *requests.DNSRequest, recidx int, tp pipeline.TaskParams) error {
 	cfg, bus, err := datasrcs.ContextConfigBus(ctx)
 	if err != nil {
@@ -292,6 +363,16 @@ func (dm *dataManager) insertNS(ctx context.Context, req *requests.DNSRequest, r
 			Source: "DNS",
 		}, tp)
 	}
+	// Attempt a zone transfer from each nameserver recorded for the zone
+	if cfg.Active && cfg.ZoneTransfers && cfg.IsDomainInScope(req.Name) {
+		go pipeline.SendData(ctx, "active", &requests.ZoneXFRRequest{
+			Name:   req.Name,
+			Domain: req.Domain,
+			Server: target,
+			Tag:    requests.DNS,
+			Source: "DNS",
+		}, tp)
+	}
 	return nil
 }
 
@@ -349,7 +430,7 @@ func (dm *dataManager) insertTXT(ctx context.Context, req *requests.DNSRequest,
 }
 
 func (dm *dataManager) insertSOA(ctx context.Context, req *requests.DNSRequest, recidx int, tp pipeline.TaskParams) error {
-	cfg, _, err := datasrcs.ContextConfigBus(ctx)
+	cfg, bus, err := datasrcs.ContextConfigBus(ctx)
 	if err != nil {
 		return errors.New("The context did not contain the expected values")
 	}
@@ -358,10 +439,42 @@ func (dm *dataManager) insertSOA(ctx context.Context, req *requests.DNSRequest,
 		return nil
 	}
 
-	dm.findNamesAndAddresses(ctx, req.Records[recidx].Data, req.Domain, tp)
+	data := req.Records[recidx].Data
+	dm.findNamesAndAddresses(ctx, data, req.Domain, tp)
+
+	ns, mbox, serial, err := resolvers.ParseSOAData(data)
+	if err != nil {
+		return nil
+	}
+	// The zone serial numbers are tracked across the enumerations to detect zone activity
+	if err := dm.enum.Graph.InsertSOA(req.Name, &graph.SOARecord{
+		EventID:   cfg.UUID.String(),
+		PrimaryNS: ns,
+		Contact:   soaContact(mbox),
+		Serial:    serial,
+	}); err != nil {
+		msg := fmt.Sprintf("%s failed to insert SOA record: %v", dm.enum.Graph, err)
+
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, msg)
+		return errors.New(msg)
+	}
 	return nil
 }
 
+// soaContact converts the responsible mailbox of the SOA record into an email address.
+func soaContact(mbox string) string {
+	for i := 0; i < len(mbox); i++ {
+		if mbox[i] == '\\' {
+			i++
+			continue
+		}
+		if mbox[i] == '.' {
+			return strings.ReplaceAll(mbox[:i], "\\", "") + "@" + mbox[i+1:]
+		}
+	}
+	return mbox
+}
+
 func (dm *dataManager) insertSPF(ctx context.Context, req *requests.DNSRequest, recidx int, tp pipeline.TaskParams) error {
 	cfg, _, err := datasrcs.ContextConfigBus(ctx)
 	if err != nil {
@@ -376,9 +489,147 @@ func (dm *dataManager) insertSPF(ctx context.Context, req *requests.DNSRequest,
 	return nil
 }
 
+func (dm *dataManager) insertCAA(ctx context.Context, req *requests.DNSRequest, recidx int, tp pipeline.TaskParams) error {
+	cfg, bus, err := datasrcs.ContextConfigBus(ctx)
+	if err != nil {
+		return errors.New("The context did not contain the expected values")
+	}
+
+	if !cfg.IsDomainInScope(req.Name) {
+		return nil
+	}
+
+	if err := dm.enum.Graph.InsertCAA(req.Name, req.Records[recidx].Data); err != nil {
+		msg := fmt.Sprintf("%s failed to insert CAA record: %v", dm.enum.Graph, err)
+
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, msg)
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (dm *dataManager) insertDNAME(ctx context.Context, req *requests.DNSRequest, recidx int, tp pipeline.TaskParams) error {
+	cfg, bus, err := datasrcs.ContextConfigBus(ctx)
+	if err != nil {
+		return errors.New("The context did not contain the expected values")
+	}
+
+	owner := resolvers.RemoveLastDot(req.Records[recidx].Name)
+	target := resolvers.RemoveLastDot(req.Records[recidx].Data)
+	if owner == "" || target == "" {
+		return errors.New("Failed to extract DNAME info from the DNS answer data")
+	}
+	// Each subtree only needs to be rewritten once
+	if dm.dnameFilter.Duplicate(owner + " " + target) {
+		return nil
+	}
+
+	if err := dm.enum.Graph.InsertDNAME(owner, target, req.Source, req.Tag, cfg.UUID.String()); err != nil {
+		msg := fmt.Sprintf("%s failed to insert DNAME record: %v", dm.enum.Graph, err)
+
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, msg)
+		return errors.New(msg)
+	}
+
+	// The names stored from now on under the old subtree are resolved again under the target subtree
+	dm.dnameLock.Lock()
+	dm.dnames[owner] = target
+	dm.dnameLock.Unlock()
+
+	// Names already discovered under the old subtree are rewritten and resolved under the target subtree
+	names := append(dm.enum.Graph.EventFQDNs(cfg.UUID.String()), owner, req.Name)
+	for _, name := range stringset.Deduplicate(names) {
+		if name == owner || strings.HasSuffix(name, "."+owner) {
+			dm.sendAliasedName(ctx, strings.TrimSuffix(name, owner)+target, tp)
+		}
+	}
+	return nil
+}
+
+// aliasedNames sends the names obtained by rewriting the name under the target subtrees of the DNAME records.
+func (dm *dataManager) aliasedNames(ctx context.Context, name string, tp pipeline.TaskParams) {
+	var names []string
+
+	dm.dnameLock.Lock()
+	for owner, target := range dm.dnames {
+		if name == owner || strings.HasSuffix(name, "."+owner) {
+			names = append(names, strings.TrimSuffix(name, owner)+target)
+		}
+	}
+	dm.dnameLock.Unlock()
+
+	for _, n := range names {
+		dm.sendAliasedName(ctx, n, tp)
+	}
+}
+
+func (dm *dataManager) sendAliasedName(ctx context.Context, name string, tp pipeline.TaskParams) {
+	if domain := dm.enum.Config.WhichDomain(name); domain != "" {
+		go pipeline.SendData(ctx, "new", &requests.DNSRequest{
+			Name:   name,
+			Domain: domain,
+			Tag:    requests.DNS,
+			Source: "DNS",
+		}, tp)
+	}
+}
+
+func (dm *dataManager) insertNAPTR(ctx context.Context, req *requests.DNSRequest, recidx int, tp pipeline.TaskParams) error {
+	cfg, bus, err := datasrcs.ContextConfigBus(ctx)
+	if err != nil {
+		return errors.New("The context did not contain the expected values")
+	}
+
+	target := resolvers.RemoveLastDot(req.Records[recidx].Data)
+	if target == "" {
+		return errors.New("Failed to extract NAPTR info from the DNS answer data")
+	}
+
+	if err := dm.enum.Graph.InsertNAPTR(req.Name, target, req.Source, req.Tag, cfg.UUID.String()); err != nil {
+		msg := fmt.Sprintf("%s failed to insert NAPTR record: %v", dm.enum.Graph, err)
+
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, msg)
+		return errors.New(msg)
+	}
+
+	domain := cfg.WhichDomain(target)
+	if domain == "" {
+		return nil
+	}
+	// Replacement fields often identify service names, which have their SRV records resolved by the DNS task
+	go pipeline.SendData(ctx, "new", &requests.DNSRequest{
+		Name:   target,
+		Domain: domain,
+		Tag:    requests.DNS,
+		Source: "DNS",
+	}, tp)
+	return nil
+}
+
+func (dm *dataManager) insertTLSA(ctx context.Context, req *requests.DNSRequest, recidx int, tp pipeline.TaskParams) error {
+	cfg, bus, err := datasrcs.ContextConfigBus(ctx)
+	if err != nil {
+		return errors.New("The context did not contain the expected values")
+	}
+	// The TLSA records are kept with the host name, e.g. www.example.com for _443._tcp.www.example.com
+	host := resolvers.TLSAHost(req.Records[recidx].Name)
+	if host == "" || !cfg.IsDomainInScope(host) {
+		return nil
+	}
+
+	if err := dm.enum.Graph.InsertTLSA(host, req.Records[recidx].Data); err != nil {
+		msg := fmt.Sprintf("%s failed to insert TLSA record: %v", dm.enum.Graph, err)
+
+		bus.Publish(requests.LogTopic, eventbus.PriorityHigh, msg)
+		return errors.New(msg)
+	}
+	return nil
+}
+
 func (dm *dataManager) findNamesAndAddresses(ctx context.Context, data, domain string, tp pipeline.TaskParams) {
 	ipre := regexp.MustCompile(amassnet.IPv4RE)
-	for _, ip := range ipre.FindAllString(data, -1) {
+	addrs := append(ipre.FindAllString(data, -1), amassnet.FindIPv6Addrs(data)...)
+	for _, ip := range addrs {
 		go pipeline.SendData(ctx, "new", &requests.AddrRequest{
 			Address: ip,
 			Domain:  domain,
@@ -415,9 +666,16 @@ func (dm *dataManager) addrRequest(ctx context.Context, req *requests.AddrReques
 	if req == nil || !req.InScope || graph == nil || uuid == "" {
 		return nil
 	}
+	// Provide the address to the data sources that collect information about addresses
+	for _, src := range dm.enum.srcs {
+		if datasrcs.AcceptsAddresses(src) {
+			src.Request(ctx, req.Clone().(*requests.AddrRequest))
+		}
+	}
 
 	if r := dm.enum.Sys.Cache().AddrSearch(req.Address); r != nil {
-		graph.InsertInfrastructure(r.ASN, r.Description, r.Address, r.Prefix, r.Source, r.Tag, uuid)
+		dm.insertInfrastructure(r.ASN, r.Description, r.Address, r.Prefix, r.Source, r.Tag)
+		dm.sweepNetblock(ctx, req, r.Prefix, tp)
 		return nil
 	}
 
@@ -435,7 +693,8 @@ loop:
 			return nil
 		case <-t.C:
 			if r := dm.enum.Sys.Cache().AddrSearch(req.Address); r != nil {
-				graph.InsertInfrastructure(r.ASN, r.Description, r.Address, r.Prefix, r.Source, r.Tag, uuid)
+				dm.insertInfrastructure(r.ASN, r.Description, r.Address, r.Prefix, r.Source, r.Tag)
+				dm.sweepNetblock(ctx, req, r.Prefix, tp)
 				found = true
 				break loop
 			}
@@ -443,12 +702,30 @@ loop:
 	}
 
 	if !found {
-		graph.InsertInfrastructure(0, "Unknown", req.Address, fakePrefix(req.Address), "RIR", requests.RIR, uuid)
+		dm.insertInfrastructure(0, "Unknown", req.Address, fakePrefix(req.Address), "RIR", requests.RIR)
 	}
 	graph.HealAddressNodes(dm.enum.Sys.Cache(), uuid)
 	return nil
 }
 
+// insertInfrastructure adds the infrastructure of the address to the graph, along with the
+// content delivery network that the address belongs to.
+func (dm *dataManager) insertInfrastructure(asn int, desc, addr, cidr, source, tag string) {
+	uuid := dm.enum.Config.UUID.String()
+
+	_ = dm.enum.Graph.InsertInfrastructure(asn, desc, addr, cidr, source, tag, uuid)
+	if cdn := dm.enum.Config.CDNProviderByAddr(net.ParseIP(addr), asn); cdn != "" {
+		_ = dm.enum.Graph.InsertCDN(addr, cdn, source, tag, uuid)
+	}
+}
+
+// sweepNetblock performs the reverse DNS sweep of the netblock learned for the address, when enabled.
+func (dm *dataManager) sweepNetblock(ctx context.Context, req *requests.AddrRequest, prefix string, tp pipeline.TaskParams) {
+	if dm.enum.Config.EnableNetblockSweeps && dm.enum.addrTask != nil {
+		dm.enum.addrTask.sweepNetblock(ctx, req, prefix, tp)
+	}
+}
+
 func fakePrefix(addr string) string {
 	bits := 24
 	total := 32
@@ -0,0 +1,242 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/caffix/eventbus"
+	"github.com/caffix/pipeline"
+	"github.com/caffix/stringset"
+	"github.com/miekg/dns"
+)
+
+// testTaskParams collects the data sent to the pipeline stages by the tasks.
+type testTaskParams struct {
+	newdata   chan pipeline.Data
+	processed chan pipeline.Data
+}
+
+func newTestTaskParams() *testTaskParams {
+	tp := &testTaskParams{
+		newdata:   make(chan pipeline.Data, 100),
+		processed: make(chan pipeline.Data, 100),
+	}
+
+	// The new data signals are not needed by the tests
+	go func() {
+		for range tp.newdata {
+		}
+	}()
+	return tp
+}
+
+func (tp *testTaskParams) NewData() chan<- pipeline.Data       { return tp.newdata }
+func (tp *testTaskParams) ProcessedData() chan<- pipeline.Data { return tp.processed }
+func (tp *testTaskParams) Registry() pipeline.StageRegistry    { return nil }
+
+// names returns the names of the DNS requests sent by the tasks within the time allowed.
+func (tp *testTaskParams) names(d time.Duration) stringset.Set {
+	names := stringset.New()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			return names
+		case data := <-tp.processed:
+			if req, ok := data.(*requests.DNSRequest); ok {
+				names.Insert(req.Name)
+			}
+		}
+	}
+}
+
+func setupTestEnum(t *testing.T, domains ...string) (context.Context, *Enumeration) {
+	cfg := config.NewConfig()
+	cfg.AddDomains(domains...)
+
+	bus := eventbus.NewEventBus()
+	t.Cleanup(bus.Stop)
+
+	e := &Enumeration{
+		Config: cfg,
+		Bus:    bus,
+		Graph:  graph.NewGraph(graph.NewCayleyGraphMemory()),
+	}
+
+	ctx := context.WithValue(context.Background(), requests.ContextConfig, cfg)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
+	return ctx, e
+}
+
+func TestInsertNAPTR(t *testing.T) {
+	ctx, e := setupTestEnum(t, "example.com")
+	dm := newDataManager(e)
+	tp := newTestTaskParams()
+
+	// The enumeration has no System, so the store stage must not resolve the replacements itself
+	err := dm.dnsRequest(ctx, &requests.DNSRequest{
+		Name:   "example.com",
+		Domain: "example.com",
+		Records: []requests.DNSAnswer{
+			{Name: "example.com", Type: int(dns.TypeNAPTR), Data: "_sip._udp.example.com."},
+			{Name: "example.com", Type: int(dns.TypeNAPTR), Data: "sipgw.example.com"},
+			{Name: "example.com", Type: int(dns.TypeNAPTR), Data: "sip.example.org"},
+		},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	}, tp)
+	if err != nil {
+		t.Fatalf("Failed to store the NAPTR records: %v", err)
+	}
+
+	names := tp.names(500 * time.Millisecond)
+	for _, name := range []string{"_sip._udp.example.com", "sipgw.example.com"} {
+		if !names.Has(name) {
+			t.Errorf("The replacement %s was not sent to be resolved", name)
+		}
+	}
+	if names.Has("sip.example.org") {
+		t.Errorf("The replacement out of scope was sent to be resolved")
+	}
+}
+
+func TestDNAMESubtreeRewrite(t *testing.T) {
+	ctx, e := setupTestEnum(t, "example.com")
+	dm := newDataManager(e)
+	tp := newTestTaskParams()
+
+	if _, err := e.Graph.InsertFQDN("www.old.example.com", "DNS", requests.DNS, e.Config.UUID.String()); err != nil {
+		t.Fatalf("Failed to insert the name discovered before the DNAME record: %v", err)
+	}
+
+	err := dm.dnsRequest(ctx, &requests.DNSRequest{
+		Name:   "old.example.com",
+		Domain: "example.com",
+		Records: []requests.DNSAnswer{{
+			Name: "old.example.com",
+			Type: int(dns.TypeDNAME),
+			Data: "new.example.com.",
+		}},
+		Tag:    requests.DNS,
+		Source: "DNS",
+	}, tp)
+	if err != nil {
+		t.Fatalf("Failed to store the DNAME record: %v", err)
+	}
+
+	// Names stored after the DNAME record are rewritten as well
+	_ = dm.dnsRequest(ctx, &requests.DNSRequest{
+		Name:   "mail.old.example.com",
+		Domain: "example.com",
+		Tag:    requests.DNS,
+		Source: "DNS",
+	}, tp)
+
+	names := tp.names(500 * time.Millisecond)
+	for _, name := range []string{"new.example.com", "www.new.example.com", "mail.new.example.com"} {
+		if !names.Has(name) {
+			t.Errorf("The name %s was not sent to be resolved under the target subtree", name)
+		}
+	}
+	if names.Has("www.old.example.com") {
+		t.Errorf("The name under the old subtree was sent again")
+	}
+}
+
+func TestCNAMEChainRecords(t *testing.T) {
+	cname := func(name, target string) requests.DNSAnswer {
+		return requests.DNSAnswer{Name: name, Type: int(dns.TypeCNAME), Data: target}
+	}
+
+	records := []requests.DNSAnswer{
+		cname("edge.example.org", "target.example.io"),
+		{Name: "target.example.io", Type: int(dns.TypeA), Data: "93.184.216.34"},
+		cname("www.example.com", "cdn.example.net"),
+		cname("cdn.example.net", "edge.example.org"),
+	}
+	chain, err := cnameChain("www.example.com", records)
+	if err != nil {
+		t.Errorf("cnameChain returned an error for a valid chain: %v", err)
+	}
+	if len(chain) != 3 || chain[0].Data != "cdn.example.net" || chain[2].Data != "target.example.io" {
+		t.Errorf("cnameChain returned %v", chain)
+	}
+
+	if chain, err := cnameChain("target.example.io", records); err != nil || len(chain) != 0 {
+		t.Errorf("cnameChain returned a chain for a name without a CNAME record: %v", chain)
+	}
+
+	// Create the loop back to the start of the chain
+	records = append(records, cname("target.example.io", "www.example.com"))
+	chain, err = cnameChain("www.example.com", records)
+	if err == nil {
+		t.Errorf("cnameChain did not detect the loop in the chain")
+	}
+	if len(chain) != 4 || chain[3].Data != "www.example.com" {
+		t.Errorf("cnameChain did not return the records up to the loop: %v", chain)
+	}
+
+	// The chains are limited to the same length as in the graph
+	records = nil
+	for i := 0; i < graph.MaxCNAMEChainLength; i++ {
+		records = append(records, cname(fmt.Sprintf("n%d.example.com", i), fmt.Sprintf("n%d.example.com", i+1)))
+	}
+	if chain, err := cnameChain("n0.example.com", records); err != nil || len(chain) != graph.MaxCNAMEChainLength {
+		t.Errorf("cnameChain rejected the chain of the maximum length: %v", err)
+	}
+
+	g := graph.NewGraph(graph.NewCayleyGraphMemory())
+	defer g.Close()
+	for _, rec := range records {
+		_ = g.InsertCNAME(rec.Name, rec.Data, "DNS", "dns", "uuid")
+	}
+	if chain, err := g.CNAMEChain("n0.example.com"); err != nil || len(chain) != graph.MaxCNAMEChainLength {
+		t.Errorf("CNAMEChain rejected the chain accepted by cnameChain: %v", err)
+	}
+
+	last := fmt.Sprintf("n%d.example.com", graph.MaxCNAMEChainLength)
+	records = append(records, cname(last, "end.example.com"))
+	chain, err = cnameChain("n0.example.com", records)
+	if err == nil || len(chain) != graph.MaxCNAMEChainLength {
+		t.Errorf("cnameChain returned %d records and the error %v for the chain that is too long", len(chain), err)
+	}
+
+	_ = g.InsertCNAME(last, "end.example.com", "DNS", "dns", "uuid")
+	if chain, err := g.CNAMEChain("n0.example.com"); err == nil || len(chain) != graph.MaxCNAMEChainLength {
+		t.Errorf("CNAMEChain accepted the chain rejected by cnameChain")
+	}
+}
+
+func TestInsertInfrastructureCDN(t *testing.T) {
+	_, e := setupTestEnum(t, "example.com")
+	dm := newDataManager(e)
+
+	dm.insertInfrastructure(13335, "CLOUDFLARENET", "104.16.1.1", "104.16.0.0/13", "RIR", requests.RIR)
+	dm.insertInfrastructure(64496, "EXAMPLE-AS", "93.184.216.34", "93.184.216.0/24", "RIR", requests.RIR)
+	if cdn := e.Graph.ReadCDN("104.16.1.1"); cdn != "Cloudflare" {
+		t.Errorf("The address was recorded with the CDN %q, expected Cloudflare", cdn)
+	}
+	if cdn := e.Graph.ReadCDN("93.184.216.34"); cdn != "" {
+		t.Errorf("The address outside of the CDNs was recorded with %s", cdn)
+	}
+
+	e.Config.NoteHiddenOrigin = true
+	o := &requests.Output{Name: "www.example.com", Addresses: []requests.AddressInfo{{CDN: "Cloudflare"}}}
+	if e.annotateCDN(o); !o.OriginHidden {
+		t.Errorf("The name only resolving into a CDN was not noted as having a hidden origin")
+	}
+	o = &requests.Output{Name: "www.example.com", Addresses: []requests.AddressInfo{{CDN: "Cloudflare"}, {}}}
+	if e.annotateCDN(o); o.OriginHidden {
+		t.Errorf("The name resolving outside of the CDNs was noted as having a hidden origin")
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/datasrcs"
+	"github.com/OWASP/Amass/v3/net/dns"
+	"github.com/caffix/stringset"
+)
+
+var suggestionRE = dns.AnySubdomainRegex()
+
+// SuggestNames returns likely candidate names for the provided discovered name without resolving them.
+// The alteration options and wordlist of the enumeration configuration are used to generate the names.
+func (e *Enumeration) SuggestNames(base string) []string {
+	name := strings.ToLower(strings.TrimSpace(base))
+
+	domain := e.Config.WhichDomain(name)
+	// Root domain names do not get altered
+	if domain == "" || len(strings.Split(name, ".")) <= len(strings.Split(domain, ".")) {
+		return []string{}
+	}
+
+	suggestions := stringset.New(datasrcs.AlteredNames(e.Config, name)...)
+	if e.Config.AddWords {
+		rest := strings.SplitN(name, ".", 2)[1]
+		// Suggest the common labels as siblings of the name
+		for _, word := range e.Config.AltWordlist {
+			suggestions.Insert(word + "." + rest)
+		}
+	}
+
+	var results []string
+	for _, n := range suggestions.Slice() {
+		if n != name && suggestionRE.FindString(n) == n && e.Config.IsDomainInScope(n) {
+			results = append(results, n)
+		}
+	}
+
+	sort.Strings(results)
+	return results
+}
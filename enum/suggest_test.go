@@ -0,0 +1,59 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package enum
+
+import (
+	"testing"
+
+	"github.com/caffix/stringset"
+)
+
+func TestSuggestNames(t *testing.T) {
+	_, e := setupTestEnum(t, "example.com")
+	e.Config.AltWordlist = []string{"dev", "api"}
+	e.Config.FlipWords = false
+	e.Config.FlipNumbers = false
+	e.Config.AddNumbers = false
+	e.Config.TransposeWords = true
+	e.Config.RemoveWords = false
+	e.Config.AddWords = true
+	e.Config.EditDistance = 0
+
+	tests := []struct {
+		name    string
+		want    []string
+		missing []string
+	}{
+		{
+			name:    "www-stage.example.com",
+			want:    []string{"stage-www.example.com", "devwww-stage.example.com", "www-stage-api.example.com", "dev.example.com"},
+			missing: []string{"www-stage.example.com"},
+		},
+		{
+			name: "example.com",
+		},
+		{
+			name: "www.example.org",
+		},
+	}
+
+	for _, test := range tests {
+		got := e.SuggestNames(test.name)
+		if len(test.want) == 0 && len(got) > 0 {
+			t.Errorf("SuggestNames(%q) returned names: %v", test.name, got)
+		}
+
+		set := stringset.New(got...)
+		for _, n := range test.want {
+			if !set.Has(n) {
+				t.Errorf("SuggestNames(%q) did not return %s", test.name, n)
+			}
+		}
+		for _, n := range test.missing {
+			if set.Has(n) {
+				t.Errorf("SuggestNames(%q) returned %s", test.name, n)
+			}
+		}
+	}
+}
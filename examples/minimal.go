@@ -24,7 +24,11 @@ func main() {
 	if err != nil {
 		return
 	}
-	sys.SetDataSources(datasrcs.GetAllSources(sys))
+	srcs, err := datasrcs.GetAllSources(sys)
+	if err != nil {
+		return
+	}
+	sys.SetDataSources(srcs)
 
 	e := enum.NewEnumeration(cfg, sys)
 	if e == nil {
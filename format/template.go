@@ -0,0 +1,67 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"text/template"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// OutputTemplateFuncs are the functions made available to output templates.
+var OutputTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseOutputTemplate parses the text of an output template and checks that it can be executed.
+func ParseOutputTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(OutputTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse the output template: %v", err)
+	}
+
+	_, cidr, _ := net.ParseCIDR("192.0.2.0/24")
+	sample := &requests.Output{
+		Name:   "www.example.com",
+		Domain: "example.com",
+		Addresses: []requests.AddressInfo{{
+			Address:     net.ParseIP("192.0.2.1"),
+			Netblock:    cidr,
+			CIDRStr:     cidr.String(),
+			ASN:         64496,
+			Description: "EXAMPLE-AS",
+		}},
+		Tag:     requests.DNS,
+		Sources: []string{"DNS"},
+	}
+	// Catch errors, such as unknown fields, that are only reported during execution
+	if err := tmpl.Execute(ioutil.Discard, sample); err != nil {
+		return nil, fmt.Errorf("Failed to execute the output template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// WriteTemplateOutput executes the output template for the provided enumeration output.
+// Each record is terminated by a newline when the template did not provide one.
+func WriteTemplateOutput(w io.Writer, tmpl *template.Template, out *requests.Output) error {
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, out); err != nil {
+		return err
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -5,6 +5,8 @@ go 1.15
 require (
 	github.com/AndreasBriese/bbloom v0.0.0-20190825152654-46b345b51c96
 	github.com/PuerkitoBio/goquery v1.6.0
+	github.com/aws/aws-sdk-go v1.34.28
+	github.com/boltdb/bolt v1.3.1
 	github.com/caffix/eventbus v0.0.0-20201229201025-4c5f3ce94295
 	github.com/caffix/pipeline v0.0.0-20210106193115-41730a0744af
 	github.com/caffix/queue v0.0.0-20210106184330-1d2e72b64fa0
@@ -19,20 +21,27 @@ require (
 	github.com/geziyor/geziyor v0.0.0-20191212210344-cfb16fe1ee0e
 	github.com/go-ini/ini v1.62.0
 	github.com/google/martian/v3 v3.0.0
-	github.com/google/uuid v1.1.3
+	github.com/google/uuid v1.3.0
+	github.com/gorilla/websocket v1.4.2
 	github.com/jmoiron/sqlx v1.2.0
 	github.com/lib/pq v1.9.0
 	github.com/miekg/dns v1.1.35
+	github.com/nats-io/nats.go v1.11.0
+	github.com/neo4j/neo4j-go-driver/v4 v4.3.3
 	github.com/rakyll/statik v0.1.7
+	github.com/segmentio/kafka-go v0.4.10
 	github.com/smartystreets/goconvey v1.6.4 // indirect
 	github.com/yl2chen/cidranger v1.0.2
 	github.com/yuin/gopher-lua v0.0.0-20200816102855-ee81675732da
+	go.mongodb.org/mongo-driver v1.4.6
 	go.uber.org/ratelimit v0.1.0
-	golang.org/x/net v0.0.0-20201224014010-6772e930b67b
+	golang.org/x/net v0.0.0-20210614182718-04defd469f4e
 	golang.org/x/oauth2 v0.0.0-20201208152858-08078c50e5b5
 	golang.org/x/sync v0.0.0-20201207232520-09787c993a3a
 	gopkg.in/ini.v1 v1.62.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 	layeh.com/gopher-json v0.0.0-20201124131017-552bb3c4c3bf
+	modernc.org/sqlite v1.14.1
 )
 
 replace github.com/knq/sysutil v1.0.0 => github.com/chromedp/sysutil v1.0.0
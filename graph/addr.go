@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/OWASP/Amass/v3/handlers"
 	"github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/caffix/stringset"
@@ -120,7 +121,7 @@ func (g *Graph) NamesToAddrs(uuid string, names ...string) ([]*NameAddrPair, err
 
 // InsertA creates FQDN, IP address and A record edge in the graph and associates them with a source and event.
 func (g *Graph) InsertA(fqdn, addr, source, tag, eventID string) error {
-	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	fqdnNode, _, err := g.insertFQDN(fqdn, source, tag, eventID)
 	if err != nil {
 		return err
 	}
@@ -140,12 +141,15 @@ func (g *Graph) InsertA(fqdn, addr, source, tag, eventID string) error {
 		return err
 	}
 
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertA(fqdn, addr, source, tag, eventID)
+	})
 	return nil
 }
 
 // InsertAAAA creates FQDN, IP address and AAAA record edge in the graph and associates them with a source and event.
 func (g *Graph) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
-	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	fqdnNode, _, err := g.insertFQDN(fqdn, source, tag, eventID)
 	if err != nil {
 		return err
 	}
@@ -165,6 +169,9 @@ func (g *Graph) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
 		return err
 	}
 
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertAAAA(fqdn, addr, source, tag, eventID)
+	})
 	return nil
 }
 
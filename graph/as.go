@@ -7,6 +7,7 @@ import (
 	"net"
 	"strconv"
 
+	"github.com/OWASP/Amass/v3/handlers"
 	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/requests"
 )
@@ -78,8 +79,14 @@ func (g *Graph) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eve
 		From:      asNode,
 		To:        cidrNode,
 	}
+	if err := g.InsertEdge(prefixEdge); err != nil {
+		return err
+	}
 
-	return g.InsertEdge(prefixEdge)
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertInfrastructure(asn, desc, addr, cidr, source, tag, eventID)
+	})
+	return nil
 }
 
 // ReadASDescription the description property of an autonomous system in the graph.
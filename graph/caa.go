@@ -0,0 +1,48 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const caaPredicate = "caa_record"
+
+// InsertCAA adds the CAA record data (e.g. issue letsencrypt.org) for the FQDN to the graph.
+func (g *Graph) InsertCAA(fqdn, data string) error {
+	data = strings.TrimSpace(data)
+	if fqdn == "" || data == "" {
+		return fmt.Errorf("%s: InsertCAA: Invalid arguments provided", g.String())
+	}
+
+	node, err := g.InsertNodeIfNotExist(fqdn, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	return g.db.InsertProperty(node, caaPredicate, data)
+}
+
+// ReadCAA returns the CAA record data that has been discovered for the FQDN.
+func (g *Graph) ReadCAA(fqdn string) []string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	properties, err := g.db.ReadProperties(node, caaPredicate)
+	if err != nil {
+		return nil
+	}
+
+	var records []string
+	for _, p := range properties {
+		records = append(records, p.Value)
+	}
+
+	sort.Strings(records)
+	return records
+}
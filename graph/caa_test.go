@@ -0,0 +1,30 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCAA(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	if err := g.InsertCAA("owasp.org", " "); err == nil {
+		t.Errorf("InsertCAA did not return an error for the empty record data")
+	}
+
+	records := []string{"issue letsencrypt.org", "iodef mailto:security@owasp.org", "issue letsencrypt.org"}
+	for _, rec := range records {
+		if err := g.InsertCAA("owasp.org", rec); err != nil {
+			t.Errorf("Failed to insert the CAA record %s: %v", rec, err)
+		}
+	}
+
+	want := []string{"iodef mailto:security@owasp.org", "issue letsencrypt.org"}
+	if got := g.ReadCAA("owasp.org"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadCAA returned %v, expected %v", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/handlers"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+const cdnPredicate = "cdn"
+
+// InsertCDN adds/updates the content delivery network that the IP address belongs to.
+func (g *Graph) InsertCDN(addr, provider, source, tag, eventID string) error {
+	provider = strings.TrimSpace(provider)
+	if addr == "" || provider == "" {
+		return fmt.Errorf("%s: InsertCDN: Invalid arguments provided", g.String())
+	}
+
+	node, err := g.InsertAddress(addr, source, tag, eventID)
+	if err != nil {
+		return err
+	}
+
+	var insert bool
+	p, err := g.db.ReadProperties(node, cdnPredicate)
+	if err == nil && len(p) > 0 {
+		if p[0].Value != provider {
+			// Update the 'cdn' property
+			if err := g.db.DeleteProperty(node, p[0].Predicate, p[0].Value); err == nil {
+				insert = true
+			}
+		}
+	} else {
+		// The provider was not found
+		insert = true
+	}
+
+	if insert {
+		if err := g.db.InsertProperty(node, cdnPredicate, provider); err != nil {
+			return err
+		}
+	}
+
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertCDN(addr, provider, source, tag, eventID)
+	})
+	return nil
+}
+
+// ReadCDN returns the content delivery network that the IP address belongs to, or an empty string.
+func (g *Graph) ReadCDN(addr string) string {
+	node, err := g.db.ReadNode(addr, "ipaddr")
+	if err != nil {
+		return ""
+	}
+
+	if p, err := g.db.ReadProperties(node, cdnPredicate); err == nil && len(p) > 0 {
+		return p[0].Value
+	}
+	return ""
+}
+
+// annotateCDN marks the output and the addresses that belong to content delivery networks.
+func (g *Graph) annotateCDN(o *requests.Output) {
+	for i, a := range o.Addresses {
+		if cdn := g.ReadCDN(a.Address.String()); cdn != "" {
+			o.Addresses[i].CDN = cdn
+			o.CDN = cdn
+		}
+	}
+}
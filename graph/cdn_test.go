@@ -0,0 +1,50 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import "testing"
+
+func TestCDN(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	uuid := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+	if err := g.InsertCDN("185.199.108.153", "", "RIR", "rir", uuid); err == nil {
+		t.Errorf("InsertCDN accepted the missing provider")
+	}
+	if cdn := g.ReadCDN("185.199.108.153"); cdn != "" {
+		t.Errorf("ReadCDN returned %s for an address without a provider", cdn)
+	}
+
+	_ = g.InsertA("www.owasp.org", "185.199.108.153", "DNS", "dns", uuid)
+	_ = g.InsertA("mail.owasp.org", "93.184.216.34", "DNS", "dns", uuid)
+	if err := g.InsertCDN("185.199.108.153", "CDN77", "RIR", "rir", uuid); err != nil {
+		t.Fatalf("InsertCDN failed: %v", err)
+	}
+	// The provider is updated when the address is found to belong to another CDN
+	if err := g.InsertCDN("185.199.108.153", "Fastly", "RIR", "rir", uuid); err != nil {
+		t.Fatalf("InsertCDN failed to update the provider: %v", err)
+	}
+	if cdn := g.ReadCDN("185.199.108.153"); cdn != "Fastly" {
+		t.Errorf("ReadCDN returned %s, expected Fastly", cdn)
+	}
+
+	var found bool
+	for _, o := range g.EventOutput(uuid, nil, false, nil) {
+		switch o.Name {
+		case "www.owasp.org":
+			found = true
+			if o.CDN != "Fastly" || len(o.Addresses) != 1 || o.Addresses[0].CDN != "Fastly" {
+				t.Errorf("The output was not annotated with the CDN: %+v", o)
+			}
+		case "mail.owasp.org":
+			if o.CDN != "" {
+				t.Errorf("The output was annotated with the CDN %s", o.CDN)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("The event output did not contain the name resolving into the CDN")
+	}
+}
@@ -6,41 +6,57 @@ package graph
 import (
 	"fmt"
 
+	"github.com/OWASP/Amass/v3/handlers"
 	"golang.org/x/net/publicsuffix"
 )
 
+// MaxCNAMEChainLength is the maximum number of CNAME records followed from a name.
+const MaxCNAMEChainLength = 16
+
 // InsertFQDN adds a fully qualified domain name to the graph.
 func (g *Graph) InsertFQDN(name, source, tag, eventID string) (Node, error) {
+	fqdnNode, created, err := g.insertFQDN(name, source, tag, eventID)
+	if err == nil && created {
+		g.forward(func(h handlers.DataHandler) error {
+			return h.InsertFQDN(name, source, tag, eventID)
+		})
+	}
+	return fqdnNode, err
+}
+
+// insertFQDN adds the FQDN to the graph without forwarding it to the data handlers, since the
+// handlers create the FQDNs of the records they receive. It returns true when the node was created.
+func (g *Graph) insertFQDN(name, source, tag, eventID string) (Node, bool, error) {
 	tld, _ := publicsuffix.PublicSuffix(name)
 
 	domain, err := publicsuffix.EffectiveTLDPlusOne(name)
 	if err != nil {
-		return nil, fmt.Errorf("InsertFQDN: Failed to obtain a valid domain name for %s", name)
+		return nil, false, fmt.Errorf("InsertFQDN: Failed to obtain a valid domain name for %s", name)
 	}
 
 	if name == "" || tld == "" || domain == "" {
-		return nil, fmt.Errorf("InsertFQDN: Failed to obtain a valid domain name for %s", name)
+		return nil, false, fmt.Errorf("InsertFQDN: Failed to obtain a valid domain name for %s", name)
 	}
 
 	// Create the graph nodes that represent the three portions of the DNS name
 	fqdnNode, err := g.db.ReadNode(name, "fqdn")
 	if err == nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
 	fqdnNode, err = g.db.InsertNode(name, "fqdn")
 	if err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
 	domainNode, err := g.InsertNodeIfNotExist(domain, "fqdn")
 	if err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
 	tldNode, err := g.InsertNodeIfNotExist(tld, "fqdn")
 	if err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
 	// Link the three nodes together
@@ -50,7 +66,7 @@ func (g *Graph) InsertFQDN(name, source, tag, eventID string) (Node, error) {
 		To:        domainNode,
 	}
 	if err := g.InsertEdge(domainEdge); err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
 	tldEdge := &Edge{
@@ -59,29 +75,29 @@ func (g *Graph) InsertFQDN(name, source, tag, eventID string) (Node, error) {
 		To:        tldNode,
 	}
 	if err := g.InsertEdge(tldEdge); err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
 	// Source and event edges for the FQDN
 	if err := g.AddNodeToEvent(fqdnNode, source, tag, eventID); err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
 	// Source and event edges for the root domain name
 	if err := g.AddNodeToEvent(domainNode, source, tag, eventID); err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 	// Add the domain edge for easy access to the DNS domains in the event
 	if err := g.addDomainEdge(domainNode, eventID); err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
 	// Source and event edges for the top-level domain name
 	if err := g.AddNodeToEvent(tldNode, source, tag, eventID); err != nil {
-		return fqdnNode, err
+		return fqdnNode, false, err
 	}
 
-	return fqdnNode, nil
+	return fqdnNode, true, nil
 }
 
 func (g *Graph) addDomainEdge(node Node, eventID string) error {
@@ -99,7 +115,14 @@ func (g *Graph) addDomainEdge(node Node, eventID string) error {
 
 // InsertCNAME adds the FQDNs and CNAME record between them to the graph.
 func (g *Graph) InsertCNAME(fqdn, target, source, tag, eventID string) error {
-	return g.insertAlias(fqdn, target, "cname_record", source, tag, eventID)
+	if err := g.insertAlias(fqdn, target, "cname_record", source, tag, eventID); err != nil {
+		return err
+	}
+
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertCNAME(fqdn, target, source, tag, eventID)
+	})
+	return nil
 }
 
 // IsCNAMENode returns true if the FQDN has a CNAME edge to another FQDN in the graph.
@@ -107,13 +130,44 @@ func (g *Graph) IsCNAMENode(fqdn string) bool {
 	return g.checkForOutEdge(fqdn, "cname_record")
 }
 
+// CNAMEChain returns the names that the FQDN is aliased to, in the order that the CNAME records
+// are followed, so the last name is the terminal target. An error is returned when the chain
+// loops back to a name already in the chain or exceeds the maximum length.
+func (g *Graph) CNAMEChain(fqdn string) ([]string, error) {
+	var chain []string
+	seen := map[string]struct{}{fqdn: {}}
+
+	for name := fqdn; ; {
+		node, err := g.db.ReadNode(name, "fqdn")
+		if err != nil {
+			return chain, nil
+		}
+
+		edges, err := g.db.ReadOutEdges(node, "cname_record")
+		if err != nil || len(edges) == 0 {
+			return chain, nil
+		}
+
+		name = g.db.NodeToID(edges[0].To)
+		if _, found := seen[name]; found {
+			return chain, fmt.Errorf("CNAMEChain: The chain for %s loops back to %s", fqdn, name)
+		}
+		if len(chain) >= MaxCNAMEChainLength {
+			return chain, fmt.Errorf("CNAMEChain: The chain for %s exceeds %d records", fqdn, MaxCNAMEChainLength)
+		}
+
+		seen[name] = struct{}{}
+		chain = append(chain, name)
+	}
+}
+
 func (g *Graph) insertAlias(fqdn, target, pred, source, tag, eventID string) error {
-	fqdnNode, err := g.InsertFQDN(fqdn, source, tag, eventID)
+	fqdnNode, _, err := g.insertFQDN(fqdn, source, tag, eventID)
 	if err != nil {
 		return err
 	}
 
-	targetNode, err := g.InsertFQDN(target, source, tag, eventID)
+	targetNode, _, err := g.insertFQDN(target, source, tag, eventID)
 	if err != nil {
 		return err
 	}
@@ -130,7 +184,14 @@ func (g *Graph) insertAlias(fqdn, target, pred, source, tag, eventID string) err
 
 // InsertPTR adds the FQDNs and PTR record between them to the graph.
 func (g *Graph) InsertPTR(fqdn, target, source, tag, eventID string) error {
-	return g.insertAlias(fqdn, target, "ptr_record", source, tag, eventID)
+	if err := g.insertAlias(fqdn, target, "ptr_record", source, tag, eventID); err != nil {
+		return err
+	}
+
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertPTR(fqdn, target, source, tag, eventID)
+	})
+	return nil
 }
 
 // IsPTRNode returns true if the FQDN has a PTR edge to another FQDN in the graph.
@@ -146,12 +207,26 @@ func (g *Graph) InsertSRV(fqdn, service, target, source, tag, eventID string) er
 	}
 
 	// Create the edge between the service and the target
-	return g.insertAlias(service, target, "srv_record", source, tag, eventID)
+	if err := g.insertAlias(service, target, "srv_record", source, tag, eventID); err != nil {
+		return err
+	}
+
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertSRV(fqdn, service, target, source, tag, eventID)
+	})
+	return nil
 }
 
 // InsertNS adds the FQDNs and NS record between them to the graph.
 func (g *Graph) InsertNS(fqdn, target, source, tag, eventID string) error {
-	return g.insertAlias(fqdn, target, "ns_record", source, tag, eventID)
+	if err := g.insertAlias(fqdn, target, "ns_record", source, tag, eventID); err != nil {
+		return err
+	}
+
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertNS(fqdn, target, source, tag, eventID)
+	})
+	return nil
 }
 
 // IsNSNode returns true if the FQDN has a NS edge pointing to it in the graph.
@@ -161,7 +236,14 @@ func (g *Graph) IsNSNode(fqdn string) bool {
 
 // InsertMX adds the FQDNs and MX record between them to the graph.
 func (g *Graph) InsertMX(fqdn, target, source, tag, eventID string) error {
-	return g.insertAlias(fqdn, target, "mx_record", source, tag, eventID)
+	if err := g.insertAlias(fqdn, target, "mx_record", source, tag, eventID); err != nil {
+		return err
+	}
+
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertMX(fqdn, target, source, tag, eventID)
+	})
+	return nil
 }
 
 // IsMXNode returns true if the FQDN has a MX edge pointing to it in the graph.
@@ -169,6 +251,30 @@ func (g *Graph) IsMXNode(fqdn string) bool {
 	return g.checkForInEdge(fqdn, "mx_record")
 }
 
+// InsertNAPTR adds the FQDNs and NAPTR record between them to the graph.
+func (g *Graph) InsertNAPTR(fqdn, target, source, tag, eventID string) error {
+	if err := g.insertAlias(fqdn, target, "naptr_record", source, tag, eventID); err != nil {
+		return err
+	}
+
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertNAPTR(fqdn, target, source, tag, eventID)
+	})
+	return nil
+}
+
+// InsertDNAME adds the FQDNs and DNAME record between them to the graph, which aliases the subtree of names.
+func (g *Graph) InsertDNAME(fqdn, target, source, tag, eventID string) error {
+	if err := g.insertAlias(fqdn, target, "dname_record", source, tag, eventID); err != nil {
+		return err
+	}
+
+	g.forward(func(h handlers.DataHandler) error {
+		return h.InsertDNAME(fqdn, target, source, tag, eventID)
+	})
+	return nil
+}
+
 // IsRootDomainNode returns true if the FQDN has a 'root' edge pointing to it in the graph.
 func (g *Graph) IsRootDomainNode(fqdn string) bool {
 	return g.checkForInEdge(fqdn, "root")
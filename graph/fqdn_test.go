@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -87,6 +88,20 @@ func TestFQDN(t *testing.T) {
 			}
 		})
 
+		t.Run("Testing InsertNAPTR...", func(t *testing.T) {
+			got := g.InsertNAPTR(tt.FQDN, "_sip._udp."+tt.FQDN, tt.Source, tt.Tag, tt.EventID)
+			if got != nil {
+				t.Errorf("Failure to insert NAPTR record.\n%v\n", got)
+			}
+		})
+
+		t.Run("Testing InsertDNAME...", func(t *testing.T) {
+			got := g.InsertDNAME(tt.FQDN, "legacy."+tt.FQDN, tt.Source, tt.Tag, tt.EventID)
+			if got != nil {
+				t.Errorf("Failure to insert DNAME record.\n%v\n", got)
+			}
+		})
+
 		t.Run("Testing IsRootDomainNode...", func(t *testing.T) {
 			got := g.IsRootDomainNode("owasp.org")
 			if got != true {
@@ -104,3 +119,30 @@ func TestFQDN(t *testing.T) {
 
 	g.Close()
 }
+
+func TestCNAMEChain(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	_ = g.InsertCNAME("www.example.com", "cdn.example.net", "DNS", "dns", "uuid")
+	_ = g.InsertCNAME("cdn.example.net", "edge.example.org", "DNS", "dns", "uuid")
+	_ = g.InsertCNAME("edge.example.org", "target.example.io", "DNS", "dns", "uuid")
+
+	chain, err := g.CNAMEChain("www.example.com")
+	if err != nil {
+		t.Errorf("CNAMEChain returned an error for a valid chain: %v", err)
+	}
+	if want := []string{"cdn.example.net", "edge.example.org", "target.example.io"}; !reflect.DeepEqual(chain, want) {
+		t.Errorf("CNAMEChain returned %v, expected %v", chain, want)
+	}
+
+	if chain, err := g.CNAMEChain("target.example.io"); err != nil || len(chain) != 0 {
+		t.Errorf("CNAMEChain returned a chain for a name without a CNAME record: %v", chain)
+	}
+
+	// Create the loop back to the start of the chain
+	_ = g.InsertCNAME("target.example.io", "www.example.com", "DNS", "dns", "uuid")
+	if _, err := g.CNAMEChain("www.example.com"); err == nil {
+		t.Errorf("CNAMEChain did not detect the loop in the chain")
+	}
+}
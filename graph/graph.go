@@ -21,6 +21,10 @@ type Graph struct {
 	// This reduces roundtrips to the graph when adding nodes to events.
 	eventFinishes   map[string]string
 	eventFinishLock sync.Mutex
+
+	// The data handlers that receive the data inserted into the graph
+	handlers    []*dataHandler
+	handlerLock sync.Mutex
 }
 
 // NewGraph accepts a graph database that stores the Graph created and maintained by the data model.
@@ -39,6 +43,7 @@ func NewGraph(database *CayleyGraph) *Graph {
 func (g *Graph) Close() {
 	if !g.alreadyClosed {
 		g.alreadyClosed = true
+		g.flushDataHandlers()
 		g.db.Close()
 	}
 }
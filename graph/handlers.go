@@ -0,0 +1,106 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"io/ioutil"
+	"log"
+	"sync/atomic"
+
+	"github.com/OWASP/Amass/v3/handlers"
+)
+
+// The number of insertions waiting for a data handler before the following insertions are dropped
+const maxHandlerQueueLen = 10000
+
+type dataHandler struct {
+	// Kept first for the alignment required by the atomic operations
+	dropped uint64
+	handler handlers.DataHandler
+	// The insertions are sent to the handler in the order they were performed on the Graph
+	queue    chan func(h handlers.DataHandler) error
+	log      *log.Logger
+	finished chan struct{}
+}
+
+// AddDataHandler forwards the data inserted into the Graph to the provided DataHandler. The data is
+// sent in the background, and failures of the handler are reported using the logger without affecting
+// the Graph. The data remaining in the queue is sent to the handler before the Graph is closed.
+func (g *Graph) AddDataHandler(h handlers.DataHandler, logger *log.Logger) {
+	if h == nil {
+		return
+	}
+	// Assign a null logger when one is not provided
+	if logger == nil {
+		logger = log.New(ioutil.Discard, "", 0)
+	}
+	// The handler reports the failures occurring in the background using the same logger
+	if ls, ok := h.(handlers.LogSetter); ok {
+		ls.SetLogger(logger)
+	}
+
+	dh := &dataHandler{
+		handler:  h,
+		queue:    make(chan func(h handlers.DataHandler) error, maxHandlerQueueLen),
+		log:      logger,
+		finished: make(chan struct{}),
+	}
+
+	g.handlerLock.Lock()
+	g.handlers = append(g.handlers, dh)
+	g.handlerLock.Unlock()
+
+	go dh.processData()
+}
+
+// forward queues the insertion performed by the callback for each of the data handlers. When a handler
+// cannot keep up with the Graph, the insertion is dropped and counted, so a slow or unreachable database
+// never blocks the Graph and the queue remains bounded.
+func (g *Graph) forward(f func(h handlers.DataHandler) error) {
+	g.handlerLock.Lock()
+	defer g.handlerLock.Unlock()
+
+	for _, dh := range g.handlers {
+		select {
+		case dh.queue <- f:
+		default:
+			dh.drop()
+		}
+	}
+}
+
+// drop counts the insertion that was not queued for the handler and reports the total periodically.
+func (dh *dataHandler) drop() {
+	if n := atomic.AddUint64(&dh.dropped, 1); n == 1 || n%maxHandlerQueueLen == 0 {
+		dh.log.Printf("%s: %d insertions were dropped, since the data handler is not keeping up", dh.handler, n)
+	}
+}
+
+// flushDataHandlers waits for the data handlers to receive all the queued data.
+func (g *Graph) flushDataHandlers() {
+	g.handlerLock.Lock()
+	hs := g.handlers
+	g.handlers = nil
+	g.handlerLock.Unlock()
+
+	// The handlers were removed, so the queues no longer receive insertions
+	for _, dh := range hs {
+		close(dh.queue)
+		<-dh.finished
+
+		if n := atomic.LoadUint64(&dh.dropped); n > 0 {
+			dh.log.Printf("%s: %d insertions were dropped in total", dh.handler, n)
+		}
+	}
+}
+
+func (dh *dataHandler) processData() {
+	defer close(dh.finished)
+
+	for f := range dh.queue {
+		if err := f(dh.handler); err != nil {
+			dh.log.Printf("%s: %v", dh.handler, err)
+		}
+	}
+}
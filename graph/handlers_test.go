@@ -0,0 +1,210 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/handlers"
+)
+
+// recordingHandler keeps a description of each insertion it receives.
+type recordingHandler struct {
+	sync.Mutex
+	inserts []string
+}
+
+func (r *recordingHandler) record(format string, args ...interface{}) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.inserts = append(r.inserts, fmt.Sprintf(format, args...))
+	return nil
+}
+
+func (r *recordingHandler) String() string { return "recording" }
+func (r *recordingHandler) Close() error   { return nil }
+
+func (r *recordingHandler) InsertFQDN(name, source, tag, eventID string) error {
+	return r.record("fqdn %s", name)
+}
+
+func (r *recordingHandler) InsertCNAME(fqdn, target, source, tag, eventID string) error {
+	return r.record("cname %s %s", fqdn, target)
+}
+
+func (r *recordingHandler) InsertA(fqdn, addr, source, tag, eventID string) error {
+	return r.record("a %s %s", fqdn, addr)
+}
+
+func (r *recordingHandler) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
+	return r.record("aaaa %s %s", fqdn, addr)
+}
+
+func (r *recordingHandler) InsertPTR(fqdn, target, source, tag, eventID string) error {
+	return r.record("ptr %s %s", fqdn, target)
+}
+
+func (r *recordingHandler) InsertSRV(fqdn, service, target, source, tag, eventID string) error {
+	return r.record("srv %s %s %s", fqdn, service, target)
+}
+
+func (r *recordingHandler) InsertNS(fqdn, target, source, tag, eventID string) error {
+	return r.record("ns %s %s", fqdn, target)
+}
+
+func (r *recordingHandler) InsertMX(fqdn, target, source, tag, eventID string) error {
+	return r.record("mx %s %s", fqdn, target)
+}
+
+func (r *recordingHandler) InsertNAPTR(fqdn, target, source, tag, eventID string) error {
+	return r.record("naptr %s %s", fqdn, target)
+}
+
+func (r *recordingHandler) InsertDNAME(fqdn, target, source, tag, eventID string) error {
+	return r.record("dname %s %s", fqdn, target)
+}
+
+func (r *recordingHandler) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eventID string) error {
+	return r.record("infra %d %s %s", asn, cidr, addr)
+}
+
+func (r *recordingHandler) InsertCDN(addr, provider, source, tag, eventID string) error {
+	return r.record("cdn %s %s", addr, provider)
+}
+
+// failingHandler returns an error for every insertion.
+type failingHandler struct {
+	recordingHandler
+}
+
+func (f *failingHandler) InsertCNAME(fqdn, target, source, tag, eventID string) error {
+	return errors.New("the database is unavailable")
+}
+
+func TestDataHandlers(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	h := &recordingHandler{}
+	g.AddDataHandler(h, nil)
+	g.AddDataHandler(&failingHandler{}, nil)
+
+	uuid := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+	if err := g.InsertCNAME("www.owasp.org", "owasp.github.io", "DNS", "dns", uuid); err != nil {
+		t.Errorf("The handler failure affected the graph insertion: %v", err)
+	}
+	_, _ = g.InsertFQDN("mail.owasp.org", "DNS", "dns", uuid)
+	_ = g.InsertA("owasp.github.io", "185.199.108.153", "DNS", "dns", uuid)
+	_ = g.InsertNAPTR("owasp.org", "_sip._udp.owasp.org", "DNS", "dns", uuid)
+	_ = g.InsertDNAME("legacy.owasp.org", "owasp.org", "DNS", "dns", uuid)
+	_ = g.InsertInfrastructure(54113, "FASTLY", "185.199.108.153", "185.199.108.0/22", "RIR", "rir", uuid)
+	_ = g.InsertCDN("185.199.108.153", "Fastly", "RIR", "rir", uuid)
+	// Closing the graph sends the remaining data to the handlers
+	g.Close()
+
+	h.Lock()
+	defer h.Unlock()
+
+	expected := map[string]bool{
+		"fqdn mail.owasp.org":                          false,
+		"cname www.owasp.org owasp.github.io":          false,
+		"a owasp.github.io 185.199.108.153":            false,
+		"naptr owasp.org _sip._udp.owasp.org":          false,
+		"dname legacy.owasp.org owasp.org":             false,
+		"infra 54113 185.199.108.0/22 185.199.108.153": false,
+		"cdn 185.199.108.153 Fastly":                   false,
+	}
+	for _, insert := range h.inserts {
+		if _, found := expected[insert]; found {
+			expected[insert] = true
+		}
+		// The handlers create the names of the records they receive
+		if insert == "fqdn www.owasp.org" || insert == "fqdn owasp.github.io" {
+			t.Errorf("The data handler received a separate insertion for the name of a record: %s", insert)
+		}
+	}
+	for insert, received := range expected {
+		if !received {
+			t.Errorf("The data handler did not receive the insertion: %s", insert)
+		}
+	}
+}
+
+// blockingHandler does not return from the insertions until it is released.
+type blockingHandler struct {
+	recordingHandler
+	release chan struct{}
+}
+
+func (b *blockingHandler) InsertFQDN(name, source, tag, eventID string) error {
+	<-b.release
+	return nil
+}
+
+func TestDataHandlerDropsInsertions(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	h := &blockingHandler{release: make(chan struct{})}
+	g.AddDataHandler(h, nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 0; i < maxHandlerQueueLen+100; i++ {
+			g.forward(func(dh handlers.DataHandler) error {
+				return dh.InsertFQDN("www.owasp.org", "DNS", "dns", "")
+			})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("The graph insertions were blocked by the data handler")
+	}
+
+	g.handlerLock.Lock()
+	dropped := atomic.LoadUint64(&g.handlers[0].dropped)
+	g.handlerLock.Unlock()
+	// The handler may have taken the first insertion from the queue before blocking
+	if dropped != 99 && dropped != 100 {
+		t.Errorf("%d insertions beyond the queue length were dropped, expected 100", dropped)
+	}
+
+	close(h.release)
+	g.Close()
+}
+
+func TestDataHandlerInsertionOrder(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	h := &recordingHandler{}
+	g.AddDataHandler(h, nil)
+
+	var expected []string
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("host%d.owasp.org", i)
+
+		expected = append(expected, "fqdn "+name)
+		g.forward(func(dh handlers.DataHandler) error {
+			return dh.InsertFQDN(name, "DNS", "dns", "")
+		})
+	}
+	g.Close()
+
+	h.Lock()
+	defer h.Unlock()
+
+	if len(h.inserts) != len(expected) {
+		t.Fatalf("The data handler received %d insertions, expected %d", len(h.inserts), len(expected))
+	}
+	for i, insert := range h.inserts {
+		if insert != expected[i] {
+			t.Fatalf("The data handler received %s at position %d, expected %s", insert, i, expected[i])
+		}
+	}
+}
@@ -53,6 +53,7 @@ func (g *Graph) EventOutput(uuid string, filter stringfilter.Filter, asninfo boo
 	output := make([]*requests.Output, 0, len(lookup))
 	if !asninfo || cache == nil {
 		for _, o := range lookup {
+			g.annotateCDN(o)
 			if !filter.Duplicate(o.Name) {
 				output = append(output, o)
 			}
@@ -80,6 +81,7 @@ func (g *Graph) EventOutput(uuid string, filter stringfilter.Filter, asninfo boo
 		}
 
 		o.Addresses = newaddrs
+		g.annotateCDN(o)
 		if len(o.Addresses) > 0 && !filter.Duplicate(o.Name) {
 			output = append(output, o)
 		}
@@ -155,6 +157,13 @@ func (g *Graph) buildNameInfo(uuid string, names []string) []*requests.Output {
 		}
 		o.Tag = g.selectTag(o.Sources, sourceTags)
 
+		// The complete chain exposes the terminal target of the aliases
+		if chain, _ := g.CNAMEChain(o.Name); len(chain) > 0 {
+			o.CNAMEChain = chain
+		}
+
+		o.Provenance = g.ReadProvenance(o.Name, uuid)
+
 		final = append(final, o)
 	}
 	return final
@@ -2,6 +2,9 @@ package graph
 
 import (
 	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
 )
 
 func TestIO(t *testing.T) {
@@ -24,3 +27,36 @@ func TestIO(t *testing.T) {
 	}
 
 }
+
+func TestEventNamesSourcesAndProvenance(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+	node, err := g.InsertFQDN("www.owasp.org", "Crtsh", "cert", event)
+	if err != nil {
+		t.Fatalf("Failed to insert the FQDN: %v", err)
+	}
+	// Another source provides the name after it was inserted into the graph
+	if err := g.AddNodeToEvent(node, "HackerTarget", "api", event); err != nil {
+		t.Fatalf("Failed to add the other source: %v", err)
+	}
+
+	now := time.Now()
+	for _, src := range []string{"Crtsh", "HackerTarget"} {
+		if err := g.InsertProvenance("www.owasp.org", event, &requests.Provenance{
+			Source:    src,
+			Timestamp: now,
+		}); err != nil {
+			t.Fatalf("Failed to insert the %s provenance: %v", src, err)
+		}
+	}
+
+	output := g.EventNames(event, nil)
+	if len(output) != 1 {
+		t.Fatalf("EventNames returned %d names, expected 1", len(output))
+	}
+	if o := output[0]; len(o.Sources) != 2 || len(o.Provenance) != 2 {
+		t.Errorf("The output has the sources %v and %d provenance entries", o.Sources, len(o.Provenance))
+	}
+}
@@ -51,6 +51,54 @@ func (g *Graph) MigrateEvents(to *Graph, uuids ...string) error {
 	return err
 }
 
+// MigrateNodes copies the nodes identified by the IDs, the edges in and out of them, the properties
+// of the adjacent nodes and the domains of the adjacent events from the receiver Graph into another.
+func (g *Graph) MigrateNodes(to *Graph, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	g.db.Lock()
+	defer g.db.Unlock()
+
+	nodes := strsToVals(ids...)
+	var quads []quad.Quad
+	var adjacent []quad.Value
+	// Build quads for the properties and edges leaving the nodes
+	p := cayley.StartPath(g.db.store, nodes...).Tag("subject").OutWithTags([]string{"predicate"}).Tag("object")
+	p.Iterate(context.Background()).TagValues(nil, func(m map[string]quad.Value) {
+		if isIRI(m["object"]) {
+			adjacent = append(adjacent, m["object"])
+		}
+		quads = append(quads, quad.Make(m["subject"], m["predicate"], m["object"], nil))
+	})
+	// Build quads for the edges entering the nodes, including the edges from the events
+	p = cayley.StartPath(g.db.store, nodes...).Tag("object").InWithTags([]string{"predicate"}).Tag("subject")
+	p.Iterate(context.Background()).TagValues(nil, func(m map[string]quad.Value) {
+		adjacent = append(adjacent, m["subject"])
+		quads = append(quads, quad.Make(m["subject"], m["predicate"], m["object"], nil))
+	})
+	// Build quads for the properties of the adjacent nodes, so the edges do not refer to untyped nodes,
+	// and for the domains of the adjacent events, so the nodes are found within the events
+	p = cayley.StartPath(g.db.store, adjacent...).Unique().Tag("subject").OutWithTags([]string{"predicate"}).Tag("object")
+	p.Iterate(context.Background()).TagValues(nil, func(m map[string]quad.Value) {
+		if !isIRI(m["object"]) || valToStr(m["predicate"]) == "domain" {
+			quads = append(quads, quad.Make(m["subject"], m["predicate"], m["object"], nil))
+		}
+	})
+
+	opts := make(graph.Options)
+	opts["ignore_missing"] = true
+	opts["ignore_duplicate"] = true
+
+	w, err := writer.NewSingleReplication(to.db.store, opts)
+	if err == nil && len(quads) > 0 {
+		err = w.AddQuadSet(quads)
+	}
+
+	return err
+}
+
 // MigrateEventsInScope copies the nodes and edges related to the Events identified by the uuids from the receiver Graph into another.
 func (g *Graph) MigrateEventsInScope(to *Graph, d []string) error {
 	if len(d) == 0 {
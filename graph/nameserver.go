@@ -0,0 +1,65 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const nsFingerprintPredicate = "ns_fingerprint"
+
+// NameserverFingerprint contains the software and configuration details obtained
+// from an authoritative nameserver during an enumeration.
+type NameserverFingerprint struct {
+	EventID   string `json:"event"`
+	Address   string `json:"address"`
+	Version   string `json:"version,omitempty"`
+	Hostname  string `json:"hostname,omitempty"`
+	ServerID  string `json:"server_id,omitempty"`
+	Software  string `json:"software,omitempty"`
+	Recursive bool   `json:"open_recursion"`
+}
+
+// InsertNameserverFingerprint adds the fingerprint obtained from the nameserver during the enumeration to the graph.
+func (g *Graph) InsertNameserverFingerprint(nameserver string, fp *NameserverFingerprint) error {
+	if nameserver == "" || fp == nil || fp.EventID == "" {
+		return fmt.Errorf("%s: InsertNameserverFingerprint: Invalid arguments provided", g.String())
+	}
+
+	node, err := g.InsertNodeIfNotExist(nameserver, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fp)
+	if err != nil {
+		return err
+	}
+
+	return g.db.InsertProperty(node, nsFingerprintPredicate, string(data))
+}
+
+// ReadNameserverFingerprints returns the fingerprints obtained from the nameserver during each enumeration.
+func (g *Graph) ReadNameserverFingerprints(nameserver string) []*NameserverFingerprint {
+	node, err := g.db.ReadNode(nameserver, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	properties, err := g.db.ReadProperties(node, nsFingerprintPredicate)
+	if err != nil {
+		return nil
+	}
+
+	var fps []*NameserverFingerprint
+	for _, p := range properties {
+		var fp NameserverFingerprint
+
+		if err := json.Unmarshal([]byte(p.Value), &fp); err == nil {
+			fps = append(fps, &fp)
+		}
+	}
+	return fps
+}
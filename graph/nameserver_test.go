@@ -0,0 +1,36 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+)
+
+func TestNameserverFingerprint(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	if err := g.InsertNameserverFingerprint("ns1.owasp.org", &NameserverFingerprint{Version: "9.16.1"}); err == nil {
+		t.Errorf("InsertNameserverFingerprint did not return an error for the missing event")
+	}
+
+	fp := &NameserverFingerprint{
+		EventID:   "enum1",
+		Address:   "192.0.2.53",
+		Version:   "9.16.1-Ubuntu",
+		Software:  "BIND",
+		Recursive: true,
+	}
+	if err := g.InsertNameserverFingerprint("ns1.owasp.org", fp); err != nil {
+		t.Errorf("Failed to insert the nameserver fingerprint: %v", err)
+	}
+
+	got := g.ReadNameserverFingerprints("ns1.owasp.org")
+	if len(got) != 1 || *got[0] != *fp {
+		t.Errorf("ReadNameserverFingerprints returned %v, expected %v", got, fp)
+	}
+	if got := g.ReadNameserverFingerprints("ns2.owasp.org"); len(got) != 0 {
+		t.Errorf("ReadNameserverFingerprints returned fingerprints for an unknown nameserver")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+const provenancePredicate = "provenance"
+
+// The provenance is kept on the FQDN node for each event, since the node is shared by the events.
+type eventProvenance struct {
+	Event string `json:"event"`
+	requests.Provenance
+}
+
+// InsertProvenance adds the discovery timestamps and evidence provided by a data source during the event to the FQDN.
+func (g *Graph) InsertProvenance(fqdn, eventID string, prov *requests.Provenance) error {
+	if fqdn == "" || eventID == "" || prov == nil || prov.Source == "" {
+		return fmt.Errorf("%s: InsertProvenance: Invalid arguments provided", g.String())
+	}
+
+	node, err := g.InsertNodeIfNotExist(fqdn, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(&eventProvenance{
+		Event:      eventID,
+		Provenance: *prov,
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.db.InsertProperty(node, provenancePredicate, string(data))
+}
+
+// ReadProvenance returns the provenance of the FQDN provided during the event, ordered by the time
+// each source provided the name.
+func (g *Graph) ReadProvenance(fqdn, eventID string) []*requests.Provenance {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	properties, err := g.db.ReadProperties(node, provenancePredicate)
+	if err != nil {
+		return nil
+	}
+
+	var provs []*requests.Provenance
+	for _, p := range properties {
+		var ep eventProvenance
+
+		if err := json.Unmarshal([]byte(p.Value), &ep); err == nil && ep.Event == eventID {
+			prov := ep.Provenance
+			provs = append(provs, &prov)
+		}
+	}
+
+	sort.Slice(provs, func(i, j int) bool {
+		return provs[i].Timestamp.Before(provs[j].Timestamp)
+	})
+	return provs
+}
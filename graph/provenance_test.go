@@ -0,0 +1,66 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestProvenance(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	event := "ef9f9475-34eb-465e-81eb-77c944822d0f"
+	if err := g.InsertProvenance("www.owasp.org", event, nil); err == nil {
+		t.Errorf("InsertProvenance did not return an error for the missing provenance")
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	provs := []*requests.Provenance{
+		{
+			Source:    "Crtsh",
+			Timestamp: now.Add(time.Minute),
+			Seen:      now.Add(-24 * time.Hour),
+			Evidence:  map[string]string{"certificate_id": "12345"},
+		},
+		{
+			Source:    "HackerTarget",
+			Timestamp: now,
+		},
+	}
+	for _, p := range provs {
+		if err := g.InsertProvenance("www.owasp.org", event, p); err != nil {
+			t.Errorf("Failed to insert the %s provenance: %v", p.Source, err)
+		}
+	}
+
+	// The provenance of another event must not be returned
+	if err := g.InsertProvenance("www.owasp.org", "other-event", &requests.Provenance{
+		Source:    "Crtsh",
+		Timestamp: now.Add(-time.Hour),
+	}); err != nil {
+		t.Errorf("Failed to insert the provenance of the other event: %v", err)
+	}
+
+	got := g.ReadProvenance("www.owasp.org", event)
+	if len(got) != 2 {
+		t.Fatalf("ReadProvenance returned %d entries, expected 2", len(got))
+	}
+	if got[0].Source != "HackerTarget" || got[1].Source != "Crtsh" {
+		t.Errorf("ReadProvenance did not order the entries by timestamp")
+	}
+	if !got[1].Seen.Equal(provs[0].Seen) || got[1].Evidence["certificate_id"] != "12345" {
+		t.Errorf("ReadProvenance returned %+v, expected %+v", got[1], provs[0])
+	}
+
+	if got := g.ReadProvenance("www.owasp.org", "other-event"); len(got) != 1 {
+		t.Errorf("ReadProvenance returned %d entries for the other event, expected 1", len(got))
+	}
+	if got := g.ReadProvenance("ftp.owasp.org", event); len(got) != 0 {
+		t.Errorf("ReadProvenance returned %v for an FQDN without provenance", got)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The predicates of the edges between FQDN nodes in the graph.
+var aliasPredicates = []string{"cname_record", "ptr_record", "srv_record", "service", "ns_record", "mx_record", "naptr_record", "dname_record"}
+
+// PrunePolicy determines the FQDN nodes that can be removed from the graph by Prune.
+type PrunePolicy struct {
+	// The UUID of the event the nodes are pruned from
+	Event string
+
+	// Returns true when the name is within the scope of the event
+	InScope func(name string) bool
+
+	// Returns true when the name has already been provided as output
+	Emitted func(name string) bool
+
+	// The event data is migrated into these graphs before any nodes are removed
+	Persist []*Graph
+}
+
+// Prune removes low-value FQDN nodes from the graph after migrating them into the persistent graphs.
+// Emitted names that no other name refers to, and out of scope names that are only referred to by emitted
+// names, are removed. Only the nodes being removed are migrated, so the graph can be pruned repeatedly
+// while the event is running. The number of nodes removed from the graph is returned.
+func (g *Graph) Prune(policy *PrunePolicy) (int, error) {
+	if policy == nil || policy.Event == "" || policy.InScope == nil || policy.Emitted == nil {
+		return 0, errors.New("Prune: Invalid policy provided")
+	}
+	if len(policy.Persist) == 0 {
+		return 0, errors.New("Prune: A persistent graph is required to retain the pruned nodes")
+	}
+
+	var names []string
+	for _, name := range g.EventFQDNs(policy.Event) {
+		if !g.IsRootDomainNode(name) && !g.IsTLDNode(name) && g.prunable(name, policy) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return 0, nil
+	}
+
+	for _, to := range policy.Persist {
+		if err := g.MigrateNodes(to, names...); err != nil {
+			return 0, fmt.Errorf("Prune: Failed to migrate the pruned nodes into %s: %v", to.String(), err)
+		}
+	}
+
+	var num int
+	for _, name := range names {
+		node, err := g.db.ReadNode(name, "fqdn")
+		if err != nil {
+			continue
+		}
+		if err := g.db.DeleteNode(node); err == nil {
+			num++
+		}
+	}
+
+	return num, nil
+}
+
+func (g *Graph) prunable(name string, policy *PrunePolicy) bool {
+	node, err := g.db.ReadNode(name, "fqdn")
+	if err != nil {
+		return false
+	}
+
+	// The names that refer to this name through DNS records
+	var referrers []string
+	if edges, err := g.db.ReadInEdges(node, aliasPredicates...); err == nil {
+		for _, edge := range edges {
+			referrers = append(referrers, g.db.NodeToID(edge.From))
+		}
+	}
+
+	if policy.InScope(name) {
+		return len(referrers) == 0 && policy.Emitted(name)
+	}
+
+	// Out of scope names, such as CNAME targets, are kept until all the names referring to them are emitted
+	for _, r := range referrers {
+		if !policy.Emitted(r) {
+			return false
+		}
+	}
+	return true
+}
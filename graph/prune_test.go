@@ -0,0 +1,88 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrune(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+	persist := NewGraph(NewCayleyGraphMemory())
+	defer persist.Close()
+
+	event := "ef9f9475-34cc-4a6f-a3a8-1a5d3cc8e0a4"
+	if _, err := g.InsertEvent(event); err != nil {
+		t.Fatalf("Failed to insert the event: %v", err)
+	}
+
+	_ = g.InsertA("www.owasp.org", "192.168.1.1", "test", "test", event)
+	_ = g.InsertA("mail.owasp.org", "192.168.1.2", "test", "test", event)
+	_ = g.InsertCNAME("cdn.owasp.org", "owasp.cdnprovider.net", "test", "test", event)
+
+	emitted := map[string]bool{"www.owasp.org": true, "cdn.owasp.org": true}
+	policy := &PrunePolicy{
+		Event:   event,
+		InScope: func(name string) bool { return strings.HasSuffix(name, "owasp.org") },
+		Emitted: func(name string) bool { return emitted[name] },
+	}
+
+	if _, err := g.Prune(policy); err == nil {
+		t.Errorf("Prune did not require a persistent graph")
+	}
+
+	policy.Persist = []*Graph{persist}
+	if _, err := g.Prune(policy); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, name := range g.EventFQDNs(event) {
+		names[name] = true
+	}
+	if names["www.owasp.org"] {
+		t.Errorf("The emitted name was not pruned from the graph")
+	}
+	if names["owasp.cdnprovider.net"] {
+		t.Errorf("The out of scope CNAME target was not pruned from the graph")
+	}
+	if !names["mail.owasp.org"] {
+		t.Errorf("Prune removed names that had not been emitted")
+	}
+
+	var found bool
+	for _, name := range persist.EventFQDNs(event) {
+		if name == "www.owasp.org" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("The pruned name was not retained by the persistent graph")
+	}
+
+	// Only the pruned names are migrated, so each run copies the names pruned since the previous run
+	for _, name := range persist.EventFQDNs(event) {
+		if name == "mail.owasp.org" {
+			t.Errorf("Prune migrated a name that was not pruned")
+		}
+	}
+
+	_ = g.InsertA("api.owasp.org", "192.168.1.3", "test", "test", event)
+	emitted["api.owasp.org"] = true
+	if num, err := g.Prune(policy); err != nil || num != 1 {
+		t.Fatalf("Prune removed %d names on the second run: %v", num, err)
+	}
+
+	found = false
+	for _, name := range persist.EventFQDNs(event) {
+		if name == "api.owasp.org" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("The name pruned on the second run was not retained by the persistent graph")
+	}
+}
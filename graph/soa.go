@@ -0,0 +1,83 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const soaPredicate = "soa_record"
+
+// SOARecord contains the SOA record data obtained for a zone during an enumeration.
+type SOARecord struct {
+	EventID   string `json:"event"`
+	PrimaryNS string `json:"primary_ns"`
+	Contact   string `json:"contact"`
+	Serial    uint32 `json:"serial"`
+}
+
+// InsertSOA adds the SOA record data obtained for the zone during the enumeration to the graph.
+func (g *Graph) InsertSOA(zone string, rec *SOARecord) error {
+	if zone == "" || rec == nil || rec.EventID == "" {
+		return fmt.Errorf("%s: InsertSOA: Invalid arguments provided", g.String())
+	}
+
+	node, err := g.InsertNodeIfNotExist(zone, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	return g.db.InsertProperty(node, soaPredicate, string(data))
+}
+
+// ReadSOARecords returns the SOA record data obtained for the zone during each enumeration.
+func (g *Graph) ReadSOARecords(zone string) []*SOARecord {
+	node, err := g.db.ReadNode(zone, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	properties, err := g.db.ReadProperties(node, soaPredicate)
+	if err != nil {
+		return nil
+	}
+
+	var records []*SOARecord
+	for _, p := range properties {
+		var rec SOARecord
+
+		if err := json.Unmarshal([]byte(p.Value), &rec); err == nil {
+			records = append(records, &rec)
+		}
+	}
+	return records
+}
+
+// SOASerial returns the highest zone serial number obtained during the enumerations identified by the uuids.
+func (g *Graph) SOASerial(zone string, uuids ...string) (uint32, bool) {
+	events := make(map[string]struct{}, len(uuids))
+	for _, uuid := range uuids {
+		events[uuid] = struct{}{}
+	}
+
+	var found bool
+	var serial uint32
+	for _, rec := range g.ReadSOARecords(zone) {
+		if _, ok := events[rec.EventID]; !ok {
+			continue
+		}
+		// Serial number arithmetic (RFC 1982) handles the wrap around of the values
+		if !found || int32(rec.Serial-serial) > 0 {
+			serial = rec.Serial
+		}
+		found = true
+	}
+	return serial, found
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"testing"
+)
+
+func TestSOA(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	if err := g.InsertSOA("owasp.org", &SOARecord{Serial: 1}); err == nil {
+		t.Errorf("InsertSOA did not return an error for the missing event")
+	}
+
+	records := []*SOARecord{
+		{EventID: "enum1", PrimaryNS: "ns1.owasp.org", Contact: "hostmaster@owasp.org", Serial: 2021010101},
+		{EventID: "enum2", PrimaryNS: "ns1.owasp.org", Contact: "hostmaster@owasp.org", Serial: 2021020301},
+		{EventID: "enum3", PrimaryNS: "ns1.owasp.org", Contact: "hostmaster@owasp.org", Serial: 4294967295},
+		{EventID: "enum4", PrimaryNS: "ns1.owasp.org", Contact: "hostmaster@owasp.org", Serial: 5},
+	}
+	for _, rec := range records {
+		if err := g.InsertSOA("owasp.org", rec); err != nil {
+			t.Errorf("Failed to insert the SOA record for %s: %v", rec.EventID, err)
+		}
+	}
+
+	if got := g.ReadSOARecords("owasp.org"); len(got) != len(records) {
+		t.Errorf("ReadSOARecords returned %d records, expected %d", len(got), len(records))
+	}
+	if serial, found := g.SOASerial("owasp.org", "enum1", "enum2"); !found || serial != 2021020301 {
+		t.Errorf("SOASerial returned %d, expected 2021020301", serial)
+	}
+	// The serial number wraps around to the lower value
+	if serial, found := g.SOASerial("owasp.org", "enum3", "enum4"); !found || serial != 5 {
+		t.Errorf("SOASerial returned %d, expected 5", serial)
+	}
+	if _, found := g.SOASerial("owasp.org", "enum5"); found {
+		t.Errorf("SOASerial returned a serial number for an enumeration without the SOA record")
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 var notDataSourceSet = stringset.New("tld", "root", "domain",
-	"cname_record", "ptr_record", "mx_record", "ns_record", "srv_record", "service")
+	"cname_record", "ptr_record", "mx_record", "ns_record", "srv_record", "service", "naptr_record", "dname_record")
 
 // InsertSource creates a data source node in the graph.
 func (g *Graph) InsertSource(source, tag string) (Node, error) {
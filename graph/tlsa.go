@@ -0,0 +1,48 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const tlsaPredicate = "tlsa_record"
+
+// InsertTLSA adds the TLSA record data (e.g. 3 1 1 <hash>) for the FQDN to the graph.
+func (g *Graph) InsertTLSA(fqdn, data string) error {
+	data = strings.TrimSpace(data)
+	if fqdn == "" || data == "" {
+		return fmt.Errorf("%s: InsertTLSA: Invalid arguments provided", g.String())
+	}
+
+	node, err := g.InsertNodeIfNotExist(fqdn, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	return g.db.InsertProperty(node, tlsaPredicate, data)
+}
+
+// ReadTLSA returns the TLSA record data that has been discovered for the FQDN.
+func (g *Graph) ReadTLSA(fqdn string) []string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	properties, err := g.db.ReadProperties(node, tlsaPredicate)
+	if err != nil {
+		return nil
+	}
+
+	var records []string
+	for _, p := range properties {
+		records = append(records, p.Value)
+	}
+
+	sort.Strings(records)
+	return records
+}
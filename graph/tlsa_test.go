@@ -0,0 +1,27 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTLSA(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	if err := g.InsertTLSA("", "3 1 1 abcdef"); err == nil {
+		t.Errorf("InsertTLSA did not return an error for the empty FQDN")
+	}
+
+	if err := g.InsertTLSA("www.owasp.org", "3 1 1 abcdef"); err != nil {
+		t.Errorf("Failed to insert the TLSA record: %v", err)
+	}
+
+	want := []string{"3 1 1 abcdef"}
+	if got := g.ReadTLSA("www.owasp.org"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadTLSA returned %v, expected %v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+const urlPathPredicate = "url_path"
+
+// InsertURLPath adds a URL path discovered for the FQDN to the graph as metadata for later web reconnaissance.
+func (g *Graph) InsertURLPath(fqdn, path string) error {
+	if fqdn == "" || path == "" {
+		return fmt.Errorf("%s: InsertURLPath: Invalid arguments provided", g.String())
+	}
+
+	node, err := g.InsertNodeIfNotExist(fqdn, "fqdn")
+	if err != nil {
+		return err
+	}
+
+	return g.db.InsertProperty(node, urlPathPredicate, path)
+}
+
+// ReadURLPaths returns the URL paths that have been discovered for the FQDN.
+func (g *Graph) ReadURLPaths(fqdn string) []string {
+	node, err := g.db.ReadNode(fqdn, "fqdn")
+	if err != nil {
+		return nil
+	}
+
+	properties, err := g.db.ReadProperties(node, urlPathPredicate)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, p := range properties {
+		paths = append(paths, p.Value)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
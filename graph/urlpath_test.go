@@ -0,0 +1,34 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestURLPaths(t *testing.T) {
+	g := NewGraph(NewCayleyGraphMemory())
+	defer g.Close()
+
+	if err := g.InsertURLPath("", "/login"); err == nil {
+		t.Errorf("InsertURLPath did not return an error for the empty FQDN")
+	}
+
+	paths := []string{"/login", "/admin/index.php", "/login"}
+	for _, p := range paths {
+		if err := g.InsertURLPath("www.owasp.org", p); err != nil {
+			t.Errorf("Failed to insert the URL path %s: %v", p, err)
+		}
+	}
+
+	want := []string{"/admin/index.php", "/login"}
+	if got := g.ReadURLPaths("www.owasp.org"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadURLPaths returned %v, expected %v", got, want)
+	}
+
+	if got := g.ReadURLPaths("ftp.owasp.org"); len(got) != 0 {
+		t.Errorf("ReadURLPaths returned %v for an FQDN without paths", got)
+	}
+}
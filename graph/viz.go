@@ -88,7 +88,7 @@ func (g *Graph) vizEdges(uuids []string, nodes []viz.Node, nodeToIdx map[string]
 
 		e, err := g.db.ReadOutEdges(node, "root", "cname_record",
 			"a_record", "aaaa_record", "ptr_record", "service",
-			"srv_record", "ns_record", "mx_record", "contains", "prefix")
+			"srv_record", "ns_record", "mx_record", "naptr_record", "dname_record", "contains", "prefix")
 		if err != nil || len(e) == 0 {
 			continue
 		}
@@ -0,0 +1,379 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	// The default number of documents indexed in each bulk request
+	defaultElasticBatchSize = 500
+	// The default prefix of the index names
+	defaultElasticIndex = "amass"
+	// How often the documents waiting in the batch are indexed
+	elasticFlushInterval = 5 * time.Second
+	// How long a request to the cluster can take before it is abandoned
+	elasticRequestTimeout = 30 * time.Second
+)
+
+type elasticBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// ElasticsearchHandler is the DataHandler that bulk indexes the names, addresses and relationships into
+// daily indices of an Elasticsearch or OpenSearch cluster, e.g. amass-names-2021.01.15, for dashboards
+// and long-term monitoring of the attack surface.
+type ElasticsearchHandler struct {
+	sync.Mutex
+	url       string
+	username  string
+	password  string
+	index     string
+	client    *http.Client
+	batchSize int
+	buf       bytes.Buffer
+	docs      int
+	done      chan struct{}
+	closed    bool
+	log       *log.Logger
+}
+
+// NewElasticsearchHandler returns an ElasticsearchHandler for the cluster at the URL, e.g. http://localhost:9200.
+// The index names start with the provided prefix, and a batchSize of zero selects the default.
+func NewElasticsearchHandler(url, username, password, index string, batchSize int) (*ElasticsearchHandler, error) {
+	if index == "" {
+		index = defaultElasticIndex
+	}
+	if batchSize <= 0 {
+		batchSize = defaultElasticBatchSize
+	}
+
+	h := &ElasticsearchHandler{
+		url:       strings.TrimRight(url, "/"),
+		username:  username,
+		password:  password,
+		index:     strings.ToLower(index),
+		client:    &http.Client{Timeout: elasticRequestTimeout},
+		batchSize: batchSize,
+		done:      make(chan struct{}),
+		log:       log.New(ioutil.Discard, "", 0),
+	}
+	// Make sure the cluster can be reached and the credentials are accepted
+	if _, err := h.request(http.MethodGet, "/", nil); err != nil {
+		return nil, fmt.Errorf("Elasticsearch: Failed to connect to %s: %v", url, err)
+	}
+
+	go h.flushPeriodically()
+	return h, nil
+}
+
+// String implements the Stringer interface.
+func (h *ElasticsearchHandler) String() string {
+	return "Elasticsearch: " + h.url
+}
+
+// SetLogger implements the LogSetter interface.
+func (h *ElasticsearchHandler) SetLogger(l *log.Logger) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.log = l
+}
+
+// Close implements the DataHandler interface.
+func (h *ElasticsearchHandler) Close() error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	close(h.done)
+
+	return h.flush()
+}
+
+// InsertFQDN implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertFQDN(name, source, tag, eventID string) error {
+	return h.add("names", map[string]interface{}{
+		"name":       name,
+		"domain":     registeredDomain(name),
+		"source":     source,
+		"tag":        tag,
+		"event_uuid": eventID,
+	}, eventID, name)
+}
+
+// InsertCNAME implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertCNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertRelation("cname_record", fqdn, target, source, tag, eventID)
+}
+
+// InsertA implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertAddrRelation("a_record", fqdn, addr, source, tag, eventID)
+}
+
+// InsertAAAA implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertAddrRelation("aaaa_record", fqdn, addr, source, tag, eventID)
+}
+
+// InsertPTR implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertRelation("ptr_record", fqdn, target, source, tag, eventID)
+}
+
+// InsertSRV implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertSRV(fqdn, service, target, source, tag, eventID string) error {
+	if err := h.insertRelation("service", service, fqdn, source, tag, eventID); err != nil {
+		return err
+	}
+	return h.insertRelation("srv_record", service, target, source, tag, eventID)
+}
+
+// InsertNS implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertNS(fqdn, target, source, tag, eventID string) error {
+	return h.insertRelation("ns_record", fqdn, target, source, tag, eventID)
+}
+
+// InsertMX implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertMX(fqdn, target, source, tag, eventID string) error {
+	return h.insertRelation("mx_record", fqdn, target, source, tag, eventID)
+}
+
+// InsertNAPTR implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertNAPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertRelation("naptr_record", fqdn, target, source, tag, eventID)
+}
+
+// InsertDNAME implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertDNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertRelation("dname_record", fqdn, target, source, tag, eventID)
+}
+
+// InsertInfrastructure implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eventID string) error {
+	return h.add("addresses", map[string]interface{}{
+		"addr":           addr,
+		"cidr":           cidr,
+		"asn":            asn,
+		"as_description": desc,
+		"source":         source,
+		"tag":            tag,
+		"event_uuid":     eventID,
+	}, eventID, addr)
+}
+
+// InsertCDN implements the DataHandler interface.
+func (h *ElasticsearchHandler) InsertCDN(addr, provider, source, tag, eventID string) error {
+	return h.add("addresses", map[string]interface{}{
+		"addr":       addr,
+		"cdn":        provider,
+		"source":     source,
+		"tag":        tag,
+		"event_uuid": eventID,
+	}, eventID, addr)
+}
+
+func (h *ElasticsearchHandler) insertRelation(rel, from, to, source, tag, eventID string) error {
+	for _, name := range []string{from, to} {
+		if err := h.InsertFQDN(name, source, tag, eventID); err != nil {
+			return err
+		}
+	}
+
+	return h.add("relations", map[string]interface{}{
+		"from":       from,
+		"type":       rel,
+		"to":         to,
+		"source":     source,
+		"tag":        tag,
+		"event_uuid": eventID,
+	}, eventID, from, rel, to)
+}
+
+func (h *ElasticsearchHandler) insertAddrRelation(rel, fqdn, addr, source, tag, eventID string) error {
+	if err := h.InsertFQDN(fqdn, source, tag, eventID); err != nil {
+		return err
+	}
+
+	if err := h.add("addresses", map[string]interface{}{
+		"addr":       addr,
+		"source":     source,
+		"tag":        tag,
+		"event_uuid": eventID,
+	}, eventID, addr); err != nil {
+		return err
+	}
+
+	return h.add("relations", map[string]interface{}{
+		"from":       fqdn,
+		"type":       rel,
+		"to":         addr,
+		"source":     source,
+		"tag":        tag,
+		"event_uuid": eventID,
+	}, eventID, fqdn, rel, addr)
+}
+
+// add places the document upsert in the batch, and submits the batch once it is full. The document ID is
+// derived from the provided values, so the same finding updates a single document within the daily index.
+func (h *ElasticsearchHandler) add(kind string, doc map[string]interface{}, values ...string) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return errors.New("The handler has been closed")
+	}
+
+	now := time.Now().UTC()
+	doc["@timestamp"] = now.Format(time.RFC3339)
+	// The fields are merged into the existing document, so the addresses keep the infrastructure details
+	body, err := json.Marshal(map[string]interface{}{
+		"doc":           doc,
+		"doc_as_upsert": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	sum := sha1.Sum([]byte(kind + "|" + strings.Join(values, "|")))
+	action, err := json.Marshal(map[string]interface{}{
+		"update": map[string]string{
+			"_index": fmt.Sprintf("%s-%s-%s", h.index, kind, now.Format("2006.01.02")),
+			"_id":    hex.EncodeToString(sum[:]),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	h.buf.Write(action)
+	h.buf.WriteByte('\n')
+	h.buf.Write(body)
+	h.buf.WriteByte('\n')
+	h.docs++
+
+	if h.docs >= h.batchSize {
+		return h.flush()
+	}
+	return nil
+}
+
+func (h *ElasticsearchHandler) flushPeriodically() {
+	t := time.NewTicker(elasticFlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-t.C:
+			h.Lock()
+			if err := h.flush(); err != nil {
+				h.log.Print(err)
+			}
+			h.Unlock()
+		}
+	}
+}
+
+// flush indexes the documents in the batch using the bulk API. The caller must hold the lock.
+func (h *ElasticsearchHandler) flush() error {
+	if h.docs == 0 {
+		return nil
+	}
+
+	body := make([]byte, h.buf.Len())
+	copy(body, h.buf.Bytes())
+	h.buf.Reset()
+	h.docs = 0
+
+	resp, err := h.request(http.MethodPost, "/_bulk", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Elasticsearch: The bulk request failed: %v", err)
+	}
+
+	var bulk elasticBulkResponse
+	if err := json.Unmarshal(resp, &bulk); err != nil {
+		return fmt.Errorf("Elasticsearch: Failed to parse the bulk response: %v", err)
+	}
+	if !bulk.Errors {
+		return nil
+	}
+
+	var failed int
+	var reason string
+	for _, item := range bulk.Items {
+		for _, result := range item {
+			if result.Status >= 300 {
+				failed++
+				if reason == "" {
+					reason = result.Error.Type + ": " + result.Error.Reason
+				}
+			}
+		}
+	}
+	return fmt.Errorf("Elasticsearch: %d documents were not indexed: %s", failed, reason)
+}
+
+func (h *ElasticsearchHandler) request(method, path string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, h.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if h.username != "" {
+		req.SetBasicAuth(h.username, h.password)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("The cluster returned status %d", resp.StatusCode)
+	}
+	return data, nil
+}
+
+// registeredDomain returns the registered domain name of the FQDN, or an empty string when it cannot be identified.
+func registeredDomain(name string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return ""
+	}
+	return domain
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestElasticsearchHandler(t *testing.T) {
+	var lock sync.Mutex
+	var bulks int
+	actions := make(map[string]int)
+	docs := make(map[string]bool)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "amass" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/_bulk" {
+			_, _ = w.Write([]byte(`{"version":{"number":"7.10.2"}}`))
+			return
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		bulks++
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action map[string]map[string]string
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			index := action["update"]["_index"]
+			actions[index[:strings.LastIndex(index, "-")]]++
+			docs[action["update"]["_id"]] = true
+		}
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewElasticsearchHandler(srv.URL, "amass", "wrong", "", 0); err == nil {
+		t.Errorf("NewElasticsearchHandler did not return an error for rejected credentials")
+	}
+
+	h, err := NewElasticsearchHandler(srv.URL, "amass", "secret", "", 4)
+	if err != nil {
+		t.Fatalf("Failed to create the Elasticsearch handler: %v", err)
+	}
+
+	// Each A record indexes the name, the address and the relationship
+	if err := h.InsertA("www.owasp.org", "192.0.2.1", "DNS", "dns", "event"); err != nil {
+		t.Errorf("InsertA failed: %v", err)
+	}
+	if err := h.InsertA("www.owasp.org", "192.0.2.1", "DNS", "dns", "event"); err != nil {
+		t.Errorf("InsertA failed: %v", err)
+	}
+	if err := h.InsertInfrastructure(64496, "EXAMPLE-AS", "192.0.2.1", "192.0.2.0/24", "RIR", "api", "event"); err != nil {
+		t.Errorf("InsertInfrastructure failed: %v", err)
+	}
+	if err := h.InsertCDN("192.0.2.1", "Fastly", "RIR", "api", "event"); err != nil {
+		t.Errorf("InsertCDN failed: %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("Failed to close the Elasticsearch handler: %v", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if bulks != 2 {
+		t.Errorf("The documents were indexed using %d bulk requests instead of 2", bulks)
+	}
+	if actions["amass-names"] != 2 || actions["amass-addresses"] != 4 || actions["amass-relations"] != 2 {
+		t.Errorf("The documents were not placed into the expected indices: %v", actions)
+	}
+	// The repeated findings and the CDN of the address update the same documents
+	if len(docs) != 3 {
+		t.Errorf("The repeated findings were indexed as %d documents instead of 3", len(docs))
+	}
+}
@@ -0,0 +1,433 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// The default number of traversals submitted to the server in each request
+	defaultGremlinBatchSize = 50
+	// How often the traversals waiting in the batch are submitted to the server
+	gremlinFlushInterval = time.Second
+	// The GraphSON version supported by JanusGraph, Neptune and Cosmos DB
+	gremlinMimeType = "application/vnd.gremlin-v2.0+json"
+	// How long the server has to accept each message and to respond to the requests
+	gremlinTimeout = 30 * time.Second
+)
+
+// The response status codes of the Gremlin Server protocol
+const (
+	gremlinStatusSuccess        = 200
+	gremlinStatusNoContent      = 204
+	gremlinStatusPartialContent = 206
+	gremlinStatusAuthenticate   = 407
+)
+
+type gremlinRequest struct {
+	RequestID string                 `json:"requestId"`
+	Op        string                 `json:"op"`
+	Processor string                 `json:"processor"`
+	Args      map[string]interface{} `json:"args"`
+}
+
+type gremlinResponse struct {
+	RequestID string `json:"requestId"`
+	Status    struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+// GremlinHandler is the DataHandler that submits the enumeration graph to a Gremlin Server, such as
+// JanusGraph, Amazon Neptune and Azure Cosmos DB. The traversals are submitted in batches for throughput.
+type GremlinHandler struct {
+	sync.Mutex
+	url       string
+	username  string
+	password  string
+	conn      *websocket.Conn
+	batchSize int
+	scripts   []string
+	bindings  map[string]interface{}
+	seq       int
+	timeout   time.Duration
+	done      chan struct{}
+	closed    bool
+	log       *log.Logger
+}
+
+// NewGremlinHandler returns a GremlinHandler connected to the server at the URL, e.g. ws://localhost:8182/gremlin.
+// The traversals are submitted once batchSize of them are waiting, and a batchSize of zero selects the default.
+func NewGremlinHandler(url, username, password string, batchSize int) (*GremlinHandler, error) {
+	if batchSize <= 0 {
+		batchSize = defaultGremlinBatchSize
+	}
+
+	h := &GremlinHandler{
+		url:       url,
+		username:  username,
+		password:  password,
+		batchSize: batchSize,
+		bindings:  make(map[string]interface{}),
+		timeout:   gremlinTimeout,
+		done:      make(chan struct{}),
+		log:       log.New(ioutil.Discard, "", 0),
+	}
+	// Make sure the server can be reached and the credentials are accepted
+	if err := h.submit("g.V().limit(1).count()", nil); err != nil {
+		return nil, fmt.Errorf("Gremlin: Failed to connect to %s: %v", url, err)
+	}
+
+	go h.flushPeriodically()
+	return h, nil
+}
+
+// String implements the Stringer interface.
+func (h *GremlinHandler) String() string {
+	return "Gremlin: " + h.url
+}
+
+// SetLogger implements the LogSetter interface.
+func (h *GremlinHandler) SetLogger(l *log.Logger) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.log = l
+}
+
+// Close implements the DataHandler interface.
+func (h *GremlinHandler) Close() error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	close(h.done)
+
+	err := h.flush()
+	if h.conn != nil {
+		h.conn.Close()
+		h.conn = nil
+	}
+	return err
+}
+
+// InsertFQDN implements the DataHandler interface.
+func (h *GremlinHandler) InsertFQDN(name, source, tag, eventID string) error {
+	return h.add(func(p string) (string, map[string]interface{}) {
+		script := upsertVertex(p+"e", "event") + ".as('e')." + nextVertex(p+"n", "fqdn") +
+			".as('n')." + upsertEdge("e", "n", "found", p)
+
+		return script, map[string]interface{}{
+			p + "e":      eventID,
+			p + "n":      name,
+			p + "source": source,
+			p + "tag":    tag,
+		}
+	})
+}
+
+// InsertCNAME implements the DataHandler interface.
+func (h *GremlinHandler) InsertCNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("cname_record", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertA implements the DataHandler interface.
+func (h *GremlinHandler) InsertA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertRecord("a_record", fqdn, "ipaddr", addr, source, tag, eventID)
+}
+
+// InsertAAAA implements the DataHandler interface.
+func (h *GremlinHandler) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertRecord("aaaa_record", fqdn, "ipaddr", addr, source, tag, eventID)
+}
+
+// InsertPTR implements the DataHandler interface.
+func (h *GremlinHandler) InsertPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("ptr_record", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertSRV implements the DataHandler interface.
+func (h *GremlinHandler) InsertSRV(fqdn, service, target, source, tag, eventID string) error {
+	if err := h.insertRecord("service", service, "fqdn", fqdn, source, tag, eventID); err != nil {
+		return err
+	}
+	return h.insertRecord("srv_record", service, "fqdn", target, source, tag, eventID)
+}
+
+// InsertNS implements the DataHandler interface.
+func (h *GremlinHandler) InsertNS(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("ns_record", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertMX implements the DataHandler interface.
+func (h *GremlinHandler) InsertMX(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("mx_record", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertNAPTR implements the DataHandler interface.
+func (h *GremlinHandler) InsertNAPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("naptr_record", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertDNAME implements the DataHandler interface.
+func (h *GremlinHandler) InsertDNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("dname_record", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertInfrastructure implements the DataHandler interface.
+func (h *GremlinHandler) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eventID string) error {
+	return h.add(func(p string) (string, map[string]interface{}) {
+		script := upsertVertex(p+"e", "event") + ".as('e')." +
+			nextVertex(p+"as", "as") + ".property('description'," + p + "desc).as('as')." +
+			nextVertex(p+"nb", "netblock") + ".as('nb')." + nextVertex(p+"ip", "ipaddr") + ".as('ip')." +
+			upsertEdge("as", "nb", "prefix", p) + "." + upsertEdge("nb", "ip", "contains", p) + "." +
+			upsertEdge("e", "as", "found", p) + "." + upsertEdge("e", "nb", "found", p)
+
+		return script, map[string]interface{}{
+			p + "e":      eventID,
+			p + "as":     fmt.Sprintf("%d", asn),
+			p + "desc":   desc,
+			p + "nb":     cidr,
+			p + "ip":     addr,
+			p + "source": source,
+			p + "tag":    tag,
+		}
+	})
+}
+
+// InsertCDN implements the DataHandler interface.
+func (h *GremlinHandler) InsertCDN(addr, provider, source, tag, eventID string) error {
+	return h.add(func(p string) (string, map[string]interface{}) {
+		script := upsertVertex(p+"e", "event") + ".as('e')." +
+			nextVertex(p+"ip", "ipaddr") + ".property('cdn'," + p + "cdn).as('ip')." +
+			upsertEdge("e", "ip", "found", p)
+
+		return script, map[string]interface{}{
+			p + "e":      eventID,
+			p + "ip":     addr,
+			p + "cdn":    provider,
+			p + "source": source,
+			p + "tag":    tag,
+		}
+	})
+}
+
+// insertRecord upserts the FQDN, the target vertex with the provided label and the edge between them.
+// Both vertices are linked to the event that found them.
+func (h *GremlinHandler) insertRecord(label, fqdn, vlabel, target, source, tag, eventID string) error {
+	return h.add(func(p string) (string, map[string]interface{}) {
+		script := upsertVertex(p+"e", "event") + ".as('e')." + nextVertex(p+"f", "fqdn") + ".as('f')." +
+			nextVertex(p+"t", vlabel) + ".as('t')." + upsertEdge("f", "t", label, p) + "." +
+			upsertEdge("e", "f", "found", p) + "." + upsertEdge("e", "t", "found", p)
+
+		return script, map[string]interface{}{
+			p + "e":      eventID,
+			p + "f":      fqdn,
+			p + "t":      target,
+			p + "source": source,
+			p + "tag":    tag,
+		}
+	})
+}
+
+// upsertVertex returns the traversal that finds or creates the vertex with the name provided by the binding.
+func upsertVertex(binding, label string) string {
+	return fmt.Sprintf("g.V().has('%s','name',%s).fold().coalesce(unfold(),addV('%s').property('name',%s))",
+		label, binding, label, binding)
+}
+
+// nextVertex returns the traversal step that finds or creates another vertex without a reducing barrier,
+// so the step labels of the vertices found earlier in the traversal remain available.
+func nextVertex(binding, label string) string {
+	return fmt.Sprintf("coalesce(__.V().has('%s','name',%s),__.addV('%s').property('name',%s))",
+		label, binding, label, binding)
+}
+
+// upsertEdge returns the traversal step that finds or creates the edge between the labeled steps.
+func upsertEdge(from, to, label, p string) string {
+	return fmt.Sprintf("coalesce(__.select('%s').outE('%s').where(__.inV().as('%s')),"+
+		"__.addE('%s').from('%s').to('%s')).property('source',%ssource).property('tag',%stag)",
+		from, label, to, label, from, to, p, p)
+}
+
+// add places the traversal in the batch, and submits the batch once it is full.
+func (h *GremlinHandler) add(build func(prefix string) (string, map[string]interface{})) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return errors.New("The handler has been closed")
+	}
+
+	// The bindings of each traversal receive a unique prefix within the batch
+	h.seq++
+	script, bindings := build(fmt.Sprintf("b%d_", h.seq))
+
+	h.scripts = append(h.scripts, script+".iterate()")
+	for k, v := range bindings {
+		h.bindings[k] = v
+	}
+
+	if len(h.scripts) >= h.batchSize {
+		return h.flush()
+	}
+	return nil
+}
+
+func (h *GremlinHandler) flushPeriodically() {
+	t := time.NewTicker(gremlinFlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-t.C:
+			h.Lock()
+			if err := h.flush(); err != nil {
+				h.log.Print(err)
+			}
+			h.Unlock()
+		}
+	}
+}
+
+// flush submits the traversals in the batch. The caller must hold the lock.
+func (h *GremlinHandler) flush() error {
+	if len(h.scripts) == 0 {
+		return nil
+	}
+
+	n := len(h.scripts)
+	script := strings.Join(h.scripts, ";")
+	bindings := h.bindings
+
+	h.scripts = nil
+	h.bindings = make(map[string]interface{})
+	h.seq = 0
+	if err := h.submit(script, bindings); err != nil {
+		return fmt.Errorf("Gremlin: %d traversals were not submitted: %v", n, err)
+	}
+	return nil
+}
+
+// submit sends the script to the server and waits for the response. The caller must hold the lock.
+func (h *GremlinHandler) submit(script string, bindings map[string]interface{}) error {
+	if h.conn == nil {
+		dialer := &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: h.timeout,
+		}
+
+		conn, _, err := dialer.Dial(h.url, nil)
+		if err != nil {
+			return err
+		}
+		h.conn = conn
+	}
+
+	id := uuid.New().String()
+	err := h.send(&gremlinRequest{
+		RequestID: id,
+		Op:        "eval",
+		Args: map[string]interface{}{
+			"gremlin":  script,
+			"bindings": bindings,
+			"language": "gremlin-groovy",
+		},
+	})
+	if err == nil {
+		err = h.readResponse(id)
+	}
+	// Start with a new connection when the protocol exchange fails
+	if err != nil && h.conn != nil {
+		h.conn.Close()
+		h.conn = nil
+	}
+	return err
+}
+
+func (h *GremlinHandler) send(req *gremlinRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	// The message starts with the length of the mime type followed by the mime type
+	msg := append([]byte{byte(len(gremlinMimeType))}, []byte(gremlinMimeType)...)
+	if err := h.conn.SetWriteDeadline(time.Now().Add(h.timeout)); err != nil {
+		return err
+	}
+	return h.conn.WriteMessage(websocket.BinaryMessage, append(msg, body...))
+}
+
+// readResponse waits for the final response to the request. A server that stalls
+// for longer than the timeout causes an error, so the handler lock is released.
+func (h *GremlinHandler) readResponse(id string) error {
+	for {
+		if err := h.conn.SetReadDeadline(time.Now().Add(h.timeout)); err != nil {
+			return err
+		}
+
+		_, body, err := h.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var resp gremlinResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return err
+		}
+		if resp.RequestID != id {
+			continue
+		}
+
+		switch resp.Status.Code {
+		case gremlinStatusSuccess, gremlinStatusNoContent:
+			return nil
+		case gremlinStatusPartialContent:
+			continue
+		case gremlinStatusAuthenticate:
+			if err := h.authenticate(id); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("The server returned status %d: %s", resp.Status.Code, resp.Status.Message)
+		}
+	}
+}
+
+// authenticate responds to the server challenge using SASL PLAIN.
+func (h *GremlinHandler) authenticate(id string) error {
+	if h.username == "" {
+		return errors.New("The server requires authentication and no credentials were provided")
+	}
+
+	creds := "\x00" + h.username + "\x00" + h.password
+	return h.send(&gremlinRequest{
+		RequestID: id,
+		Op:        "authentication",
+		Args: map[string]interface{}{
+			"sasl":          base64.StdEncoding.EncodeToString([]byte(creds)),
+			"saslMechanism": "PLAIN",
+		},
+	})
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeGremlinServer requires authentication and records the scripts submitted for evaluation.
+type fakeGremlinServer struct {
+	sync.Mutex
+	scripts  []string
+	bindings []map[string]interface{}
+	pending  *gremlinRequest
+	authed   bool
+}
+
+func (f *fakeGremlinServer) evaluate(req *gremlinRequest) {
+	f.scripts = append(f.scripts, req.Args["gremlin"].(string))
+	if b, ok := req.Args["bindings"].(map[string]interface{}); ok {
+		f.bindings = append(f.bindings, b)
+	} else {
+		f.bindings = append(f.bindings, nil)
+	}
+}
+
+func (f *fakeGremlinServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req gremlinRequest
+		if err := json.Unmarshal(msg[int(msg[0])+1:], &req); err != nil {
+			return
+		}
+
+		code := gremlinStatusNoContent
+		f.Lock()
+		switch req.Op {
+		case "authentication":
+			// The request that caused the challenge is evaluated once authenticated
+			if f.authed = req.Args["saslMechanism"] == "PLAIN"; f.authed && f.pending != nil {
+				f.evaluate(f.pending)
+			} else {
+				code = 401
+			}
+			f.pending = nil
+		case "eval":
+			if !f.authed {
+				code = gremlinStatusAuthenticate
+				f.pending = &req
+			} else {
+				f.evaluate(&req)
+			}
+		}
+		f.Unlock()
+
+		resp, _ := json.Marshal(map[string]interface{}{
+			"requestId": req.RequestID,
+			"status":    map[string]interface{}{"code": code},
+		})
+		_ = conn.WriteMessage(websocket.TextMessage, resp)
+	}
+}
+
+func TestGremlinHandler(t *testing.T) {
+	fake := &fakeGremlinServer{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	if _, err := NewGremlinHandler(url, "", "", 2); err == nil {
+		t.Errorf("NewGremlinHandler connected without the required credentials")
+	}
+
+	h, err := NewGremlinHandler(url, "amass", "secret", 2)
+	if err != nil {
+		t.Fatalf("NewGremlinHandler failed to connect: %v", err)
+	}
+
+	// The first two traversals are submitted together once the batch is full
+	_ = h.InsertCNAME("www.owasp.org", "owasp.github.io", "DNS", "dns", "uuid")
+	_ = h.InsertA("owasp.github.io", "185.199.108.153", "DNS", "dns", "uuid")
+	// The remaining traversal is submitted when the handler is closed
+	_ = h.InsertInfrastructure(54113, "FASTLY", "185.199.108.153", "185.199.108.0/22", "RIR", "rir", "uuid")
+	if err := h.Close(); err != nil {
+		t.Errorf("Failed to submit the remaining traversals: %v", err)
+	}
+
+	fake.Lock()
+	defer fake.Unlock()
+	// The first script was the connectivity check
+	if len(fake.scripts) != 3 {
+		t.Fatalf("Expected the traversals to be submitted in two batches, got %d scripts", len(fake.scripts)-1)
+	}
+	if n := strings.Count(fake.scripts[1], ".iterate()"); n != 2 {
+		t.Errorf("The first batch contained %d traversals, expected 2", n)
+	}
+	if fake.bindings[1]["b1_f"] != "www.owasp.org" || fake.bindings[1]["b2_t"] != "185.199.108.153" {
+		t.Errorf("The bindings of the batched traversals were not unique: %v", fake.bindings[1])
+	}
+	if fake.bindings[1]["b1_e"] != "uuid" || strings.Count(fake.scripts[1], "addE('found')") != 4 {
+		t.Errorf("The records were not linked to the event: %s", fake.scripts[1])
+	}
+	if !strings.Contains(fake.scripts[2], "'prefix'") || !strings.Contains(fake.scripts[2], "'contains'") {
+		t.Errorf("The infrastructure traversal did not create the expected edges: %s", fake.scripts[2])
+	}
+	if fake.bindings[2]["b1_e"] != "uuid" || strings.Count(fake.scripts[2], "addE('found')") != 2 {
+		t.Errorf("The infrastructure was not linked to the event: %s", fake.scripts[2])
+	}
+}
+
+func TestGremlinHandlerStalledServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Receive the requests without ever responding
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	h := &GremlinHandler{
+		url:      "ws" + strings.TrimPrefix(srv.URL, "http"),
+		bindings: make(map[string]interface{}),
+		timeout:  100 * time.Millisecond,
+	}
+
+	done := make(chan error)
+	go func() {
+		h.Lock()
+		defer h.Unlock()
+
+		done <- h.submit("g.V().limit(1).count()", nil)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("The submission succeeded without a response from the server")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The handler waited for the stalled server without a deadline")
+	}
+}
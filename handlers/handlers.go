@@ -0,0 +1,106 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+// DataHandler is the interface implemented by the databases that receive
+// the data as it is inserted into the enumeration graph.
+type DataHandler interface {
+	fmt.Stringer
+
+	// InsertFQDN adds a fully qualified domain name discovered during the event
+	InsertFQDN(name, source, tag, eventID string) error
+
+	// InsertCNAME adds the FQDNs and the CNAME record between them
+	InsertCNAME(fqdn, target, source, tag, eventID string) error
+
+	// InsertA adds the FQDN, the IPv4 address and the A record between them
+	InsertA(fqdn, addr, source, tag, eventID string) error
+
+	// InsertAAAA adds the FQDN, the IPv6 address and the AAAA record between them
+	InsertAAAA(fqdn, addr, source, tag, eventID string) error
+
+	// InsertPTR adds the reverse DNS name, the target FQDN and the PTR record between them
+	InsertPTR(fqdn, target, source, tag, eventID string) error
+
+	// InsertSRV adds the FQDN, the service name, the target and the SRV record between them
+	InsertSRV(fqdn, service, target, source, tag, eventID string) error
+
+	// InsertNS adds the FQDN, the nameserver and the NS record between them
+	InsertNS(fqdn, target, source, tag, eventID string) error
+
+	// InsertMX adds the FQDN, the mail server and the MX record between them
+	InsertMX(fqdn, target, source, tag, eventID string) error
+
+	// InsertNAPTR adds the FQDN, the replacement name and the NAPTR record between them
+	InsertNAPTR(fqdn, target, source, tag, eventID string) error
+
+	// InsertDNAME adds the owner name, the target name and the DNAME record that aliases the subtree
+	InsertDNAME(fqdn, target, source, tag, eventID string) error
+
+	// InsertInfrastructure adds the autonomous system, the netblock and the address contained in the netblock
+	InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eventID string) error
+
+	// InsertCDN records the content delivery network that the IP address belongs to
+	InsertCDN(addr, provider, source, tag, eventID string) error
+
+	// Close releases the connections to the database
+	Close() error
+}
+
+// LogSetter is implemented by the data handlers that report the failures occurring in the background,
+// such as the periodic execution of the batched statements.
+type LogSetter interface {
+	SetLogger(l *log.Logger)
+}
+
+// NewDataHandler returns the DataHandler for the database system identified in the settings.
+func NewDataHandler(db *config.Database) (DataHandler, error) {
+	if db == nil {
+		return nil, fmt.Errorf("NewDataHandler: The database settings were not provided")
+	}
+
+	switch strings.ToLower(db.System) {
+	case "neo4j":
+		size, _ := strconv.Atoi(parseOptions(db.Options)["batch_size"])
+		return NewNeo4jHandler(db.URL, db.Username, db.Password, db.DBName, size)
+	case "mongodb":
+		return NewMongoHandler(db.URL, db.Username, db.Password, db.DBName)
+	case "postgres":
+		size, _ := strconv.Atoi(parseOptions(db.Options)["batch_size"])
+		return NewPostgresHandler(db.URL, size)
+	case "sqlite":
+		size, _ := strconv.Atoi(parseOptions(db.Options)["batch_size"])
+		return NewSQLiteHandler(db.URL, size)
+	case "elasticsearch", "opensearch":
+		opts := parseOptions(db.Options)
+		size, _ := strconv.Atoi(opts["batch_size"])
+		return NewElasticsearchHandler(db.URL, db.Username, db.Password, opts["index"], size)
+	case "gremlin":
+		size, _ := strconv.Atoi(parseOptions(db.Options)["batch_size"])
+		return NewGremlinHandler(db.URL, db.Username, db.Password, size)
+	}
+	return nil, fmt.Errorf("NewDataHandler: The %s database system is not supported", db.System)
+}
+
+// parseOptions extracts the key/value pairs from options such as "batch_size=100,timeout=10".
+func parseOptions(options string) map[string]string {
+	opts := make(map[string]string)
+
+	for _, opt := range strings.Split(options, ",") {
+		kv := strings.SplitN(strings.TrimSpace(opt), "=", 2)
+		if len(kv) == 2 {
+			opts[strings.ToLower(kv[0])] = kv[1]
+		}
+	}
+	return opts
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"testing"
+
+	"github.com/OWASP/Amass/v3/config"
+)
+
+func TestNewDataHandler(t *testing.T) {
+	if _, err := NewDataHandler(nil); err == nil {
+		t.Errorf("NewDataHandler did not return an error without the database settings")
+	}
+	if _, err := NewDataHandler(&config.Database{System: "unknown", URL: "unknown://localhost"}); err == nil {
+		t.Errorf("NewDataHandler did not return an error for an unsupported database system")
+	}
+	// The connectivity is verified before the handler is returned
+	if _, err := NewDataHandler(&config.Database{System: "neo4j", URL: "bolt://127.0.0.1:1"}); err == nil {
+		t.Errorf("NewDataHandler returned a Neo4j handler that is not connected")
+	}
+}
+
+func TestNewOutputStream(t *testing.T) {
+	if _, err := NewOutputStream(nil); err == nil {
+		t.Errorf("NewOutputStream did not return an error without the broker settings")
+	}
+	if _, err := NewOutputStream(&config.Database{System: "unknown", URL: "unknown://localhost"}); err == nil {
+		t.Errorf("NewOutputStream did not return an error for an unsupported message broker")
+	}
+	// The connectivity is verified before the stream is returned
+	if _, err := NewOutputStream(&config.Database{System: "kafka", URL: "kafka://127.0.0.1:1"}); err == nil {
+		t.Errorf("NewOutputStream returned a Kafka stream that is not connected")
+	}
+	if _, err := NewOutputStream(&config.Database{System: "nats", URL: "nats://127.0.0.1:1"}); err == nil {
+		t.Errorf("NewOutputStream returned a NATS stream that is not connected")
+	}
+
+	if topic := streamTopic(" "); topic != defaultStreamTopic {
+		t.Errorf("The default topic was not selected: %s", topic)
+	}
+	if topic := streamTopic("recon.names"); topic != "recon.names" {
+		t.Errorf("The provided topic was not selected: %s", topic)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+const (
+	// How long the connection to a broker can take before it is abandoned
+	kafkaDialTimeout = 10 * time.Second
+	// How long the messages wait to be sent in a batch
+	kafkaBatchTimeout = 100 * time.Millisecond
+)
+
+// KafkaStream is the OutputStream that publishes the outputs to a Kafka topic. The messages
+// are keyed by the name, so the outputs for a name are kept within the same partition.
+type KafkaStream struct {
+	sync.Mutex
+	writer  *kafka.Writer
+	brokers []string
+	err     error
+}
+
+// NewKafkaStream returns a KafkaStream for the comma-separated brokers in the URL,
+// e.g. kafka://broker1:9092,broker2:9092. SASL PLAIN is used when the username is provided.
+func NewKafkaStream(url, username, password, topic string) (*KafkaStream, error) {
+	var brokers []string
+	for _, b := range strings.Split(strings.TrimPrefix(url, "kafka://"), ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("Kafka: The brokers were not provided")
+	}
+
+	dialer := &kafka.Dialer{Timeout: kafkaDialTimeout}
+	transport := &kafka.Transport{DialTimeout: kafkaDialTimeout}
+	if username != "" {
+		mechanism := plain.Mechanism{
+			Username: username,
+			Password: password,
+		}
+
+		dialer.SASLMechanism = mechanism
+		transport.SASL = mechanism
+	}
+
+	// Make sure a broker can be reached and the credentials are accepted
+	conn, err := dialer.DialContext(context.Background(), "tcp", brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("Kafka: Failed to connect to %s: %v", brokers[0], err)
+	}
+	conn.Close()
+
+	s := &KafkaStream{brokers: brokers}
+	s.writer = &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: kafkaBatchTimeout,
+		RequiredAcks: kafka.RequireOne,
+		Transport:    transport,
+		// The outputs are delivered in the background, and the failures are returned by later calls
+		Async:      true,
+		Completion: s.completion,
+	}
+	return s, nil
+}
+
+// String implements the Stringer interface.
+func (s *KafkaStream) String() string {
+	return "Kafka: " + strings.Join(s.brokers, ",")
+}
+
+// Publish implements the OutputStream interface.
+func (s *KafkaStream) Publish(out *requests.Output) error {
+	if err := s.lastError(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	return s.writer.WriteMessages(context.Background(), kafka.Message{
+		Key:   []byte(out.Name),
+		Value: data,
+	})
+}
+
+// Close implements the OutputStream interface.
+func (s *KafkaStream) Close() error {
+	if err := s.writer.Close(); err != nil {
+		return err
+	}
+	return s.lastError()
+}
+
+func (s *KafkaStream) completion(msgs []kafka.Message, err error) {
+	if err == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.err = fmt.Errorf("Kafka: Failed to deliver %d outputs: %v", len(msgs), err)
+}
+
+// lastError returns the delivery failure not yet reported, if any.
+func (s *KafkaStream) lastError() error {
+	s.Lock()
+	defer s.Unlock()
+
+	err := s.err
+	s.err = nil
+	return err
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// The database used when a database name is not provided
+	defaultMongoDatabase = "amass"
+	// The collection that holds a document for each discovered asset
+	mongoAssetCollection = "assets"
+	// How long an operation on the database can take before it is abandoned
+	mongoTimeout = 10 * time.Second
+)
+
+// MongoHandler is the DataHandler that stores a document for each discovered asset in MongoDB. The DNS
+// records and the sources that discovered the asset are embedded in the document as arrays.
+type MongoHandler struct {
+	client *mongo.Client
+	assets *mongo.Collection
+	uri    string
+}
+
+// NewMongoHandler returns a MongoHandler connected to the server at the URI, e.g. mongodb://localhost:27017.
+// The amass database is used when a database name is not provided.
+func NewMongoHandler(uri, username, password, database string) (*MongoHandler, error) {
+	if database == "" {
+		database = defaultMongoDatabase
+	}
+
+	opts := options.Client().ApplyURI(uri)
+	if username != "" {
+		opts.SetAuth(options.Credential{
+			Username: username,
+			Password: password,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("MongoDB: Failed to create the client for %s: %v", uri, err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("MongoDB: Failed to connect to %s: %v", uri, err)
+	}
+
+	h := &MongoHandler{
+		client: client,
+		assets: client.Database(database).Collection(mongoAssetCollection),
+		uri:    uri,
+	}
+
+	if _, err := h.assets.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "domain", Value: 1}}},
+		{Keys: bson.D{{Key: "sources.event_uuid", Value: 1}}},
+	}); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("MongoDB: Failed to create the indexes: %v", err)
+	}
+	return h, nil
+}
+
+// String implements the Stringer interface.
+func (h *MongoHandler) String() string {
+	return "MongoDB: " + h.uri
+}
+
+// Close implements the DataHandler interface.
+func (h *MongoHandler) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	return h.client.Disconnect(ctx)
+}
+
+// InsertFQDN implements the DataHandler interface.
+func (h *MongoHandler) InsertFQDN(name, source, tag, eventID string) error {
+	return h.upsert(assetUpdate("fqdn", name, nil, nil, source, tag, eventID))
+}
+
+// InsertCNAME implements the DataHandler interface.
+func (h *MongoHandler) InsertCNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("CNAME", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertA implements the DataHandler interface.
+func (h *MongoHandler) InsertA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertRecord("A", fqdn, "ipaddr", addr, source, tag, eventID)
+}
+
+// InsertAAAA implements the DataHandler interface.
+func (h *MongoHandler) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertRecord("AAAA", fqdn, "ipaddr", addr, source, tag, eventID)
+}
+
+// InsertPTR implements the DataHandler interface.
+func (h *MongoHandler) InsertPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("PTR", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertSRV implements the DataHandler interface.
+func (h *MongoHandler) InsertSRV(fqdn, service, target, source, tag, eventID string) error {
+	if err := h.insertRecord("SERVICE", fqdn, "fqdn", service, source, tag, eventID); err != nil {
+		return err
+	}
+	return h.insertRecord("SRV", service, "fqdn", target, source, tag, eventID)
+}
+
+// InsertNS implements the DataHandler interface.
+func (h *MongoHandler) InsertNS(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("NS", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertMX implements the DataHandler interface.
+func (h *MongoHandler) InsertMX(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("MX", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertNAPTR implements the DataHandler interface.
+func (h *MongoHandler) InsertNAPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("NAPTR", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertDNAME implements the DataHandler interface.
+func (h *MongoHandler) InsertDNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("DNAME", fqdn, "fqdn", target, source, tag, eventID)
+}
+
+// InsertInfrastructure implements the DataHandler interface.
+func (h *MongoHandler) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eventID string) error {
+	as := strconv.Itoa(asn)
+
+	if err := h.upsert(assetUpdate("as", as, bson.M{"description": desc},
+		mongoRecord("prefix", cidr), source, tag, eventID)); err != nil {
+		return err
+	}
+	if err := h.upsert(assetUpdate("netblock", cidr, bson.M{"asn": asn},
+		mongoRecord("contains", addr), source, tag, eventID)); err != nil {
+		return err
+	}
+	return h.upsert(assetUpdate("ipaddr", addr, bson.M{"netblock": cidr, "asn": asn, "as_description": desc},
+		nil, source, tag, eventID))
+}
+
+// InsertCDN implements the DataHandler interface.
+func (h *MongoHandler) InsertCDN(addr, provider, source, tag, eventID string) error {
+	return h.upsert(assetUpdate("ipaddr", addr, bson.M{"cdn": provider}, nil, source, tag, eventID))
+}
+
+// insertRecord embeds the DNS record in the document of the FQDN and upserts the document of the target.
+func (h *MongoHandler) insertRecord(rrtype, fqdn, ttype, target, source, tag, eventID string) error {
+	if err := h.upsert(assetUpdate("fqdn", fqdn, nil,
+		mongoRecord(rrtype, target), source, tag, eventID)); err != nil {
+		return err
+	}
+	return h.upsert(assetUpdate(ttype, target, nil, nil, source, tag, eventID))
+}
+
+func (h *MongoHandler) upsert(filter, update bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mongoTimeout)
+	defer cancel()
+
+	_, err := h.assets.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// assetUpdate returns the filter that selects the asset document, and the update that sets the fields
+// and adds the DNS record and the source to the embedded arrays when they are not already present.
+func assetUpdate(atype, name string, fields bson.M, record bson.D, source, tag, eventID string) (bson.M, bson.M) {
+	now := time.Now().UTC()
+
+	set := bson.M{"last_seen": now}
+	if atype == "fqdn" {
+		set["domain"] = registeredDomain(name)
+	}
+	for k, v := range fields {
+		set[k] = v
+	}
+
+	// The embedded documents keep the order of the fields, so $addToSet can identify the duplicates
+	add := bson.M{
+		"sources": bson.D{
+			{Key: "source", Value: source},
+			{Key: "tag", Value: tag},
+			{Key: "event_uuid", Value: eventID},
+		},
+	}
+	if record != nil {
+		add["records"] = record
+	}
+
+	return bson.M{"_id": atype + ":" + name}, bson.M{
+		"$setOnInsert": bson.M{
+			"type":       atype,
+			"name":       name,
+			"first_seen": now,
+		},
+		"$set":      set,
+		"$addToSet": add,
+	}
+}
+
+// mongoRecord returns the embedded document for the DNS record or relationship with the target.
+func mongoRecord(rtype, target string) bson.D {
+	return bson.D{{Key: "type", Value: rtype}, {Key: "target", Value: target}}
+}
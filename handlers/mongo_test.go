@@ -0,0 +1,39 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMongoAssetUpdate(t *testing.T) {
+	filter, update := assetUpdate("fqdn", "www.owasp.org", nil,
+		mongoRecord("A", "192.0.2.1"), "DNS", "dns", "event")
+
+	if filter["_id"] != "fqdn:www.owasp.org" {
+		t.Errorf("The asset document was not selected by the type and name: %v", filter)
+	}
+	if set := update["$set"].(bson.M); set["domain"] != "owasp.org" {
+		t.Errorf("The registered domain was not set for the FQDN: %v", set)
+	}
+
+	add := update["$addToSet"].(bson.M)
+	if rec, ok := add["records"].(bson.D); !ok || rec.Map()["type"] != "A" || rec.Map()["target"] != "192.0.2.1" {
+		t.Errorf("The DNS record was not added to the embedded array: %v", add)
+	}
+	if src := add["sources"].(bson.D); src[0].Key != "source" || src.Map()["event_uuid"] != "event" {
+		t.Errorf("The source was not added to the embedded array: %v", src)
+	}
+
+	// The documents without a record only add the source
+	_, update = assetUpdate("ipaddr", "192.0.2.1", bson.M{"asn": 64496}, nil, "RIR", "api", "event")
+	if _, found := update["$addToSet"].(bson.M)["records"]; found {
+		t.Errorf("A record was added to the document without a record being provided")
+	}
+	if update["$set"].(bson.M)["asn"] != 64496 {
+		t.Errorf("The fields provided for the asset were not set")
+	}
+}
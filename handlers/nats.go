@@ -0,0 +1,64 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/OWASP/Amass/v3/requests"
+	"github.com/nats-io/nats.go"
+)
+
+// How long the connection to the server can take before it is abandoned
+const natsTimeout = 10 * time.Second
+
+// NATSStream is the OutputStream that publishes the outputs to a NATS subject.
+type NATSStream struct {
+	conn    *nats.Conn
+	url     string
+	subject string
+}
+
+// NewNATSStream returns a NATSStream connected to the server at the URL, e.g. nats://localhost:4222.
+func NewNATSStream(url, username, password, subject string) (*NATSStream, error) {
+	opts := []nats.Option{nats.Name("amass"), nats.Timeout(natsTimeout)}
+	if username != "" {
+		opts = append(opts, nats.UserInfo(username, password))
+	}
+
+	conn, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("NATS: Failed to connect to %s: %v", url, err)
+	}
+
+	return &NATSStream{
+		conn:    conn,
+		url:     url,
+		subject: subject,
+	}, nil
+}
+
+// String implements the Stringer interface.
+func (s *NATSStream) String() string {
+	return "NATS: " + s.url
+}
+
+// Publish implements the OutputStream interface.
+func (s *NATSStream) Publish(out *requests.Output) error {
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	return s.conn.Publish(s.subject, data)
+}
+
+// Close implements the OutputStream interface.
+func (s *NATSStream) Close() error {
+	defer s.conn.Close()
+
+	return s.conn.FlushTimeout(natsTimeout)
+}
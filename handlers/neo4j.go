@@ -0,0 +1,324 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+)
+
+const (
+	// The default number of statements executed in each transaction
+	defaultNeo4jBatchSize = 100
+	// How often the statements waiting in the batch are executed
+	neo4jFlushInterval = 5 * time.Second
+)
+
+type neo4jStatement struct {
+	stmt   string
+	params map[string]interface{}
+}
+
+// Neo4jHandler is the DataHandler that stores the enumeration graph in a Neo4j database using the Bolt protocol.
+// The statements are executed in batches, each within a single transaction, for throughput.
+type Neo4jHandler struct {
+	sync.Mutex
+	driver    neo4j.Driver
+	uri       string
+	database  string
+	batchSize int
+	batch     []*neo4jStatement
+	done      chan struct{}
+	closed    bool
+	log       *log.Logger
+}
+
+// NewNeo4jHandler returns a Neo4jHandler connected to the database at the URI, e.g. bolt://localhost:7687.
+// The default database of the server is used when a database name is not provided, and a batchSize
+// of zero selects the default.
+func NewNeo4jHandler(uri, username, password, database string, batchSize int) (*Neo4jHandler, error) {
+	if batchSize <= 0 {
+		batchSize = defaultNeo4jBatchSize
+	}
+
+	auth := neo4j.NoAuth()
+	if username != "" {
+		auth = neo4j.BasicAuth(username, password, "")
+	}
+
+	driver, err := neo4j.NewDriver(uri, auth)
+	if err != nil {
+		return nil, fmt.Errorf("Neo4j: Failed to create the driver for %s: %v", uri, err)
+	}
+	if err := driver.VerifyConnectivity(); err != nil {
+		driver.Close()
+		return nil, fmt.Errorf("Neo4j: Failed to connect to %s: %v", uri, err)
+	}
+
+	h := &Neo4jHandler{
+		driver:    driver,
+		uri:       uri,
+		database:  database,
+		batchSize: batchSize,
+		done:      make(chan struct{}),
+		log:       log.New(ioutil.Discard, "", 0),
+	}
+
+	for _, label := range []string{"FQDN", "IPAddress", "Netblock", "AS", "Event"} {
+		key := neo4jNodeKeys[label]
+		stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS FOR (n:%s) ON (n.%s)", label, key)
+
+		if err := h.write([]*neo4jStatement{{stmt: stmt}}); err != nil {
+			driver.Close()
+			return nil, fmt.Errorf("Neo4j: Failed to create the index for %s nodes: %v", label, err)
+		}
+	}
+
+	go h.flushPeriodically()
+	return h, nil
+}
+
+// The property that identifies each type of node
+var neo4jNodeKeys = map[string]string{
+	"FQDN":      "name",
+	"IPAddress": "addr",
+	"Netblock":  "cidr",
+	"AS":        "asn",
+	"Event":     "uuid",
+}
+
+// String implements the Stringer interface.
+func (h *Neo4jHandler) String() string {
+	return "Neo4j: " + h.uri
+}
+
+// SetLogger implements the LogSetter interface.
+func (h *Neo4jHandler) SetLogger(l *log.Logger) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.log = l
+}
+
+// Close implements the DataHandler interface.
+func (h *Neo4jHandler) Close() error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	close(h.done)
+
+	err := h.flush()
+	if e := h.driver.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// InsertFQDN implements the DataHandler interface.
+func (h *Neo4jHandler) InsertFQDN(name, source, tag, eventID string) error {
+	return h.add(`
+		MERGE (e:Event {uuid: $event})
+		MERGE (n:FQDN {name: $name})
+		MERGE (e)-[r:FOUND]->(n)
+		SET r.source = $source, r.tag = $tag`,
+		map[string]interface{}{
+			"event":  eventID,
+			"name":   name,
+			"source": source,
+			"tag":    tag,
+		})
+}
+
+// InsertCNAME implements the DataHandler interface.
+func (h *Neo4jHandler) InsertCNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("CNAME_RECORD", fqdn, "FQDN", target, source, tag, eventID)
+}
+
+// InsertA implements the DataHandler interface.
+func (h *Neo4jHandler) InsertA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertRecord("A_RECORD", fqdn, "IPAddress", addr, source, tag, eventID)
+}
+
+// InsertAAAA implements the DataHandler interface.
+func (h *Neo4jHandler) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertRecord("AAAA_RECORD", fqdn, "IPAddress", addr, source, tag, eventID)
+}
+
+// InsertPTR implements the DataHandler interface.
+func (h *Neo4jHandler) InsertPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("PTR_RECORD", fqdn, "FQDN", target, source, tag, eventID)
+}
+
+// InsertSRV implements the DataHandler interface.
+func (h *Neo4jHandler) InsertSRV(fqdn, service, target, source, tag, eventID string) error {
+	if err := h.insertRecord("SERVICE", fqdn, "FQDN", service, source, tag, eventID); err != nil {
+		return err
+	}
+	return h.insertRecord("SRV_RECORD", service, "FQDN", target, source, tag, eventID)
+}
+
+// InsertNS implements the DataHandler interface.
+func (h *Neo4jHandler) InsertNS(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("NS_RECORD", fqdn, "FQDN", target, source, tag, eventID)
+}
+
+// InsertMX implements the DataHandler interface.
+func (h *Neo4jHandler) InsertMX(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("MX_RECORD", fqdn, "FQDN", target, source, tag, eventID)
+}
+
+// InsertNAPTR implements the DataHandler interface.
+func (h *Neo4jHandler) InsertNAPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("NAPTR_RECORD", fqdn, "FQDN", target, source, tag, eventID)
+}
+
+// InsertDNAME implements the DataHandler interface.
+func (h *Neo4jHandler) InsertDNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertRecord("DNAME_RECORD", fqdn, "FQDN", target, source, tag, eventID)
+}
+
+// InsertInfrastructure implements the DataHandler interface.
+func (h *Neo4jHandler) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eventID string) error {
+	return h.add(`
+		MERGE (e:Event {uuid: $event})
+		MERGE (sys:AS {asn: $asn})
+		SET sys.description = $desc
+		MERGE (nb:Netblock {cidr: $cidr})
+		MERGE (ip:IPAddress {addr: $addr})
+		MERGE (sys)-[:PREFIX]->(nb)
+		MERGE (nb)-[:CONTAINS]->(ip)
+		MERGE (e)-[r1:FOUND]->(sys)
+		SET r1.source = $source, r1.tag = $tag
+		MERGE (e)-[r2:FOUND]->(nb)
+		SET r2.source = $source, r2.tag = $tag`,
+		map[string]interface{}{
+			"event":  eventID,
+			"asn":    asn,
+			"desc":   desc,
+			"cidr":   cidr,
+			"addr":   addr,
+			"source": source,
+			"tag":    tag,
+		})
+}
+
+// InsertCDN implements the DataHandler interface.
+func (h *Neo4jHandler) InsertCDN(addr, provider, source, tag, eventID string) error {
+	return h.add(`
+		MERGE (e:Event {uuid: $event})
+		MERGE (ip:IPAddress {addr: $addr})
+		SET ip.cdn = $cdn
+		MERGE (e)-[r:FOUND]->(ip)
+		SET r.source = $source, r.tag = $tag`,
+		map[string]interface{}{
+			"event":  eventID,
+			"addr":   addr,
+			"cdn":    provider,
+			"source": source,
+			"tag":    tag,
+		})
+}
+
+// insertRecord merges the FQDN, the target node of the provided label and the relationship between them.
+func (h *Neo4jHandler) insertRecord(rel, fqdn, label, target, source, tag, eventID string) error {
+	stmt := fmt.Sprintf(`
+		MERGE (e:Event {uuid: $event})
+		MERGE (n:FQDN {name: $fqdn})
+		MERGE (t:%s {%s: $target})
+		MERGE (n)-[r:%s]->(t)
+		SET r.source = $source, r.tag = $tag
+		MERGE (e)-[f1:FOUND]->(n)
+		SET f1.source = $source, f1.tag = $tag
+		MERGE (e)-[f2:FOUND]->(t)
+		SET f2.source = $source, f2.tag = $tag`, label, neo4jNodeKeys[label], rel)
+
+	return h.add(stmt, map[string]interface{}{
+		"event":  eventID,
+		"fqdn":   fqdn,
+		"target": target,
+		"source": source,
+		"tag":    tag,
+	})
+}
+
+// add places the statement in the batch, and executes the batch once it is full.
+func (h *Neo4jHandler) add(stmt string, params map[string]interface{}) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return errors.New("The handler has been closed")
+	}
+
+	h.batch = append(h.batch, &neo4jStatement{stmt: stmt, params: params})
+	if len(h.batch) >= h.batchSize {
+		return h.flush()
+	}
+	return nil
+}
+
+func (h *Neo4jHandler) flushPeriodically() {
+	t := time.NewTicker(neo4jFlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-t.C:
+			h.Lock()
+			if err := h.flush(); err != nil {
+				h.log.Print(err)
+			}
+			h.Unlock()
+		}
+	}
+}
+
+// flush executes the statements in the batch. The caller must hold the lock.
+func (h *Neo4jHandler) flush() error {
+	if len(h.batch) == 0 {
+		return nil
+	}
+
+	batch := h.batch
+	h.batch = nil
+	if err := h.write(batch); err != nil {
+		return fmt.Errorf("Neo4j: %d statements were not executed: %v", len(batch), err)
+	}
+	return nil
+}
+
+// write executes the statements within a single transaction.
+func (h *Neo4jHandler) write(stmts []*neo4jStatement) error {
+	session := h.driver.NewSession(neo4j.SessionConfig{
+		AccessMode:   neo4j.AccessModeWrite,
+		DatabaseName: h.database,
+	})
+	defer session.Close()
+
+	_, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		for _, s := range stmts {
+			result, err := tx.Run(s.stmt, s.params)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := result.Consume(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
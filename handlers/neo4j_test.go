@@ -0,0 +1,94 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+)
+
+// TestNeo4jHandler executes the Cypher statements against the server identified by AMASS_TEST_NEO4J_URL,
+// e.g. bolt://localhost:7687, using the AMASS_TEST_NEO4J_USERNAME and AMASS_TEST_NEO4J_PASSWORD credentials.
+func TestNeo4jHandler(t *testing.T) {
+	uri := os.Getenv("AMASS_TEST_NEO4J_URL")
+	if uri == "" {
+		t.Skip("AMASS_TEST_NEO4J_URL was not provided")
+	}
+	username := os.Getenv("AMASS_TEST_NEO4J_USERNAME")
+	password := os.Getenv("AMASS_TEST_NEO4J_PASSWORD")
+
+	h, err := NewNeo4jHandler(uri, username, password, "", 2)
+	if err != nil {
+		t.Fatalf("Failed to create the Neo4j handler: %v", err)
+	}
+
+	const event = "a4f4d5e2-0f4b-4c8d-9b3e-2c1d7f6a1b10"
+	inserts := map[string]error{
+		"FQDN":           h.InsertFQDN("neo4j-test.owasp.org", "DNS", "dns", event),
+		"CNAME":          h.InsertCNAME("www.neo4j-test.owasp.org", "neo4j-test.owasp.org", "DNS", "dns", event),
+		"A":              h.InsertA("neo4j-test.owasp.org", "192.0.2.1", "DNS", "dns", event),
+		"AAAA":           h.InsertAAAA("neo4j-test.owasp.org", "2001:db8::1", "DNS", "dns", event),
+		"PTR":            h.InsertPTR("1.2.0.192.in-addr.arpa", "neo4j-test.owasp.org", "DNS", "dns", event),
+		"SRV":            h.InsertSRV("neo4j-test.owasp.org", "_sip._tcp.neo4j-test.owasp.org", "sip.neo4j-test.owasp.org", "DNS", "dns", event),
+		"NS":             h.InsertNS("neo4j-test.owasp.org", "ns1.neo4j-test.owasp.org", "DNS", "dns", event),
+		"MX":             h.InsertMX("neo4j-test.owasp.org", "mx.neo4j-test.owasp.org", "DNS", "dns", event),
+		"NAPTR":          h.InsertNAPTR("neo4j-test.owasp.org", "_sip._udp.neo4j-test.owasp.org", "DNS", "dns", event),
+		"DNAME":          h.InsertDNAME("legacy.neo4j-test.owasp.org", "neo4j-test.owasp.org", "DNS", "dns", event),
+		"Infrastructure": h.InsertInfrastructure(64496, "EXAMPLE-AS", "192.0.2.1", "192.0.2.0/24", "RIR", "api", event),
+		"CDN":            h.InsertCDN("192.0.2.1", "Fastly", "RIR", "api", event),
+	}
+	for name, err := range inserts {
+		if err != nil {
+			t.Errorf("Insert%s failed: %v", name, err)
+		}
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Failed to execute the remaining statements: %v", err)
+	}
+
+	auth := neo4j.NoAuth()
+	if username != "" {
+		auth = neo4j.BasicAuth(username, password, "")
+	}
+	driver, err := neo4j.NewDriver(uri, auth)
+	if err != nil {
+		t.Fatalf("Failed to create the driver: %v", err)
+	}
+	defer driver.Close()
+
+	session := driver.NewSession(neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close()
+
+	checks := map[string]string{
+		"the DNS records": `MATCH (:Event {uuid: $event})-[:FOUND]->(n:FQDN {name: 'neo4j-test.owasp.org'})
+			MATCH (n)-[r]->() RETURN count(DISTINCT type(r))`,
+		"the infrastructure": `MATCH (:AS {asn: 64496, description: 'EXAMPLE-AS'})-[:PREFIX]->(:Netblock {cidr: '192.0.2.0/24'})
+			-[:CONTAINS]->(:IPAddress {addr: '192.0.2.1'}) RETURN count(*)`,
+	}
+	expected := map[string]int64{
+		// A, AAAA, SERVICE, NS, MX and NAPTR records
+		"the DNS records":    6,
+		"the infrastructure": 1,
+	}
+	for desc, query := range checks {
+		result, err := session.Run(query, map[string]interface{}{"event": event})
+		if err != nil {
+			t.Errorf("Failed to query %s: %v", desc, err)
+			continue
+		}
+
+		record, err := result.Single()
+		if err != nil {
+			t.Errorf("Failed to read %s: %v", desc, err)
+			continue
+		}
+		if count, _ := record.Values[0].(int64); count != expected[desc] {
+			t.Errorf("The query for %s returned %d, expected %d", desc, count, expected[desc])
+		}
+	}
+
+	_, _ = session.Run(`MATCH (e:Event {uuid: $event}) DETACH DELETE e`, map[string]interface{}{"event": event})
+}
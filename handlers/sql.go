@@ -0,0 +1,465 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// The normalized schema that allows SQL reporting across many enumerations. The {{ID}} placeholder is replaced
+// by the auto-incrementing primary key type of the database, so the reports work on all of them.
+var sqlSchemaStmts = []string{
+	`CREATE TABLE IF NOT EXISTS events (
+		uuid TEXT PRIMARY KEY,
+		first_seen TIMESTAMP NOT NULL,
+		last_seen TIMESTAMP NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS domains (
+		id {{ID}},
+		name TEXT NOT NULL UNIQUE
+	)`,
+	`CREATE TABLE IF NOT EXISTS names (
+		id {{ID}},
+		name TEXT NOT NULL UNIQUE,
+		domain_id INTEGER REFERENCES domains (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS autonomous_systems (
+		asn INTEGER PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE TABLE IF NOT EXISTS netblocks (
+		id {{ID}},
+		cidr TEXT NOT NULL UNIQUE,
+		asn INTEGER REFERENCES autonomous_systems (asn)
+	)`,
+	`CREATE TABLE IF NOT EXISTS addresses (
+		id {{ID}},
+		addr TEXT NOT NULL UNIQUE,
+		netblock_id INTEGER REFERENCES netblocks (id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS address_cdns (
+		address_id INTEGER PRIMARY KEY REFERENCES addresses (id),
+		provider TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS name_records (
+		name_id INTEGER NOT NULL REFERENCES names (id),
+		type TEXT NOT NULL,
+		target_id INTEGER NOT NULL REFERENCES names (id),
+		first_seen TIMESTAMP NOT NULL,
+		last_seen TIMESTAMP NOT NULL,
+		PRIMARY KEY (name_id, type, target_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS address_records (
+		name_id INTEGER NOT NULL REFERENCES names (id),
+		type TEXT NOT NULL,
+		address_id INTEGER NOT NULL REFERENCES addresses (id),
+		first_seen TIMESTAMP NOT NULL,
+		last_seen TIMESTAMP NOT NULL,
+		PRIMARY KEY (name_id, type, address_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS event_names (
+		event_uuid TEXT NOT NULL REFERENCES events (uuid),
+		name_id INTEGER NOT NULL REFERENCES names (id),
+		source TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (event_uuid, name_id, source)
+	)`,
+	`CREATE TABLE IF NOT EXISTS event_netblocks (
+		event_uuid TEXT NOT NULL REFERENCES events (uuid),
+		netblock_id INTEGER NOT NULL REFERENCES netblocks (id),
+		source TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (event_uuid, netblock_id, source)
+	)`,
+}
+
+// sqlSchema returns the schema statements using the provided definition of the auto-incrementing primary keys.
+func sqlSchema(idType string) []string {
+	var stmts []string
+
+	for _, stmt := range sqlSchemaStmts {
+		stmts = append(stmts, strings.ReplaceAll(stmt, "{{ID}}", idType))
+	}
+	return stmts
+}
+
+const (
+	// The default number of inserts executed in each transaction
+	defaultSQLBatchSize = 100
+	// How often the inserts waiting in the batch are executed
+	sqlFlushInterval = 5 * time.Second
+)
+
+// sqlInsert executes the statements of an insert within the transaction of the batch.
+type sqlInsert func(tx *sql.Tx, now time.Time) error
+
+// sqlHandler stores the enumeration findings in a normalized relational schema. The statements are
+// written with ? placeholders and rebound for databases that use numbered placeholders. The inserts
+// are executed in batches, each within a single transaction, for throughput.
+type sqlHandler struct {
+	sync.Mutex
+	db        *sql.DB
+	name      string
+	numbered  bool
+	batchSize int
+	batch     []sqlInsert
+	done      chan struct{}
+	closed    bool
+	log       *log.Logger
+}
+
+// newSQLHandler returns a sqlHandler for the database, and a batchSize of zero selects the default.
+func newSQLHandler(db *sql.DB, name string, numbered bool, batchSize int) *sqlHandler {
+	if batchSize <= 0 {
+		batchSize = defaultSQLBatchSize
+	}
+
+	h := &sqlHandler{
+		db:        db,
+		name:      name,
+		numbered:  numbered,
+		batchSize: batchSize,
+		done:      make(chan struct{}),
+		log:       log.New(ioutil.Discard, "", 0),
+	}
+
+	go h.flushPeriodically()
+	return h
+}
+
+// String implements the Stringer interface.
+func (h *sqlHandler) String() string {
+	return h.name
+}
+
+// SetLogger implements the LogSetter interface.
+func (h *sqlHandler) SetLogger(l *log.Logger) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.log = l
+}
+
+// Close implements the DataHandler interface.
+func (h *sqlHandler) Close() error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	close(h.done)
+
+	err := h.flush()
+	if e := h.db.Close(); e != nil && err == nil {
+		err = e
+	}
+	return err
+}
+
+// InsertFQDN implements the DataHandler interface.
+func (h *sqlHandler) InsertFQDN(name, source, tag, eventID string) error {
+	return h.add(func(tx *sql.Tx, now time.Time) error {
+		_, err := h.eventName(tx, now, name, source, tag, eventID)
+		return err
+	})
+}
+
+// InsertCNAME implements the DataHandler interface.
+func (h *sqlHandler) InsertCNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertNameRecord("CNAME", fqdn, target, source, tag, eventID)
+}
+
+// InsertA implements the DataHandler interface.
+func (h *sqlHandler) InsertA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertAddrRecord("A", fqdn, addr, source, tag, eventID)
+}
+
+// InsertAAAA implements the DataHandler interface.
+func (h *sqlHandler) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
+	return h.insertAddrRecord("AAAA", fqdn, addr, source, tag, eventID)
+}
+
+// InsertPTR implements the DataHandler interface.
+func (h *sqlHandler) InsertPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertNameRecord("PTR", fqdn, target, source, tag, eventID)
+}
+
+// InsertSRV implements the DataHandler interface.
+func (h *sqlHandler) InsertSRV(fqdn, service, target, source, tag, eventID string) error {
+	if err := h.insertNameRecord("SERVICE", fqdn, service, source, tag, eventID); err != nil {
+		return err
+	}
+	return h.insertNameRecord("SRV", service, target, source, tag, eventID)
+}
+
+// InsertNS implements the DataHandler interface.
+func (h *sqlHandler) InsertNS(fqdn, target, source, tag, eventID string) error {
+	return h.insertNameRecord("NS", fqdn, target, source, tag, eventID)
+}
+
+// InsertMX implements the DataHandler interface.
+func (h *sqlHandler) InsertMX(fqdn, target, source, tag, eventID string) error {
+	return h.insertNameRecord("MX", fqdn, target, source, tag, eventID)
+}
+
+// InsertNAPTR implements the DataHandler interface.
+func (h *sqlHandler) InsertNAPTR(fqdn, target, source, tag, eventID string) error {
+	return h.insertNameRecord("NAPTR", fqdn, target, source, tag, eventID)
+}
+
+// InsertDNAME implements the DataHandler interface.
+func (h *sqlHandler) InsertDNAME(fqdn, target, source, tag, eventID string) error {
+	return h.insertNameRecord("DNAME", fqdn, target, source, tag, eventID)
+}
+
+// InsertInfrastructure implements the DataHandler interface.
+func (h *sqlHandler) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eventID string) error {
+	return h.add(func(tx *sql.Tx, now time.Time) error {
+		if err := h.event(tx, now, eventID); err != nil {
+			return err
+		}
+
+		if _, err := h.exec(tx, `INSERT INTO autonomous_systems (asn, description) VALUES (?, ?)
+			ON CONFLICT (asn) DO UPDATE SET description = excluded.description`, asn, desc); err != nil {
+			return err
+		}
+
+		if _, err := h.exec(tx, `INSERT INTO netblocks (cidr, asn) VALUES (?, ?)
+			ON CONFLICT (cidr) DO UPDATE SET asn = excluded.asn`, cidr, asn); err != nil {
+			return err
+		}
+		netblockID, err := h.id(tx, "SELECT id FROM netblocks WHERE cidr = ?", cidr)
+		if err != nil {
+			return err
+		}
+
+		if _, err := h.exec(tx, `INSERT INTO addresses (addr, netblock_id) VALUES (?, ?)
+			ON CONFLICT (addr) DO UPDATE SET netblock_id = excluded.netblock_id`, addr, netblockID); err != nil {
+			return err
+		}
+
+		_, err = h.exec(tx, `INSERT INTO event_netblocks (event_uuid, netblock_id, source, tag) VALUES (?, ?, ?, ?)
+			ON CONFLICT (event_uuid, netblock_id, source) DO NOTHING`, eventID, netblockID, source, tag)
+		return err
+	})
+}
+
+// InsertCDN implements the DataHandler interface.
+func (h *sqlHandler) InsertCDN(addr, provider, source, tag, eventID string) error {
+	return h.add(func(tx *sql.Tx, now time.Time) error {
+		if err := h.event(tx, now, eventID); err != nil {
+			return err
+		}
+
+		if _, err := h.exec(tx, "INSERT INTO addresses (addr) VALUES (?) ON CONFLICT (addr) DO NOTHING", addr); err != nil {
+			return err
+		}
+		addrID, err := h.id(tx, "SELECT id FROM addresses WHERE addr = ?", addr)
+		if err != nil {
+			return err
+		}
+
+		_, err = h.exec(tx, `INSERT INTO address_cdns (address_id, provider) VALUES (?, ?)
+			ON CONFLICT (address_id) DO UPDATE SET provider = excluded.provider`, addrID, provider)
+		return err
+	})
+}
+
+func (h *sqlHandler) insertNameRecord(rrtype, fqdn, target, source, tag, eventID string) error {
+	return h.add(func(tx *sql.Tx, now time.Time) error {
+		nameID, err := h.eventName(tx, now, fqdn, source, tag, eventID)
+		if err != nil {
+			return err
+		}
+
+		targetID, err := h.eventName(tx, now, target, source, tag, eventID)
+		if err != nil {
+			return err
+		}
+
+		_, err = h.exec(tx, `INSERT INTO name_records (name_id, type, target_id, first_seen, last_seen)
+			VALUES (?, ?, ?, ?, ?) ON CONFLICT (name_id, type, target_id) DO UPDATE SET last_seen = excluded.last_seen`,
+			nameID, rrtype, targetID, now, now)
+		return err
+	})
+}
+
+func (h *sqlHandler) insertAddrRecord(rrtype, fqdn, addr, source, tag, eventID string) error {
+	return h.add(func(tx *sql.Tx, now time.Time) error {
+		nameID, err := h.eventName(tx, now, fqdn, source, tag, eventID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := h.exec(tx, "INSERT INTO addresses (addr) VALUES (?) ON CONFLICT (addr) DO NOTHING", addr); err != nil {
+			return err
+		}
+		addrID, err := h.id(tx, "SELECT id FROM addresses WHERE addr = ?", addr)
+		if err != nil {
+			return err
+		}
+
+		_, err = h.exec(tx, `INSERT INTO address_records (name_id, type, address_id, first_seen, last_seen)
+			VALUES (?, ?, ?, ?, ?) ON CONFLICT (name_id, type, address_id) DO UPDATE SET last_seen = excluded.last_seen`,
+			nameID, rrtype, addrID, now, now)
+		return err
+	})
+}
+
+// eventName upserts the name, its domain and the association with the event, and returns the ID of the name.
+func (h *sqlHandler) eventName(tx *sql.Tx, now time.Time, name, source, tag, eventID string) (int64, error) {
+	if err := h.event(tx, now, eventID); err != nil {
+		return 0, err
+	}
+
+	var domainID interface{}
+	if domain, err := publicsuffix.EffectiveTLDPlusOne(name); err == nil {
+		if _, err := h.exec(tx, "INSERT INTO domains (name) VALUES (?) ON CONFLICT (name) DO NOTHING", domain); err != nil {
+			return 0, err
+		}
+
+		id, err := h.id(tx, "SELECT id FROM domains WHERE name = ?", domain)
+		if err != nil {
+			return 0, err
+		}
+		domainID = id
+	}
+
+	if _, err := h.exec(tx, "INSERT INTO names (name, domain_id) VALUES (?, ?) ON CONFLICT (name) DO NOTHING",
+		name, domainID); err != nil {
+		return 0, err
+	}
+	nameID, err := h.id(tx, "SELECT id FROM names WHERE name = ?", name)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = h.exec(tx, `INSERT INTO event_names (event_uuid, name_id, source, tag) VALUES (?, ?, ?, ?)
+		ON CONFLICT (event_uuid, name_id, source) DO NOTHING`, eventID, nameID, source, tag)
+	return nameID, err
+}
+
+func (h *sqlHandler) event(tx *sql.Tx, now time.Time, eventID string) error {
+	_, err := h.exec(tx, `INSERT INTO events (uuid, first_seen, last_seen) VALUES (?, ?, ?)
+		ON CONFLICT (uuid) DO UPDATE SET last_seen = excluded.last_seen`, eventID, now, now)
+	return err
+}
+
+// add places the insert in the batch, and executes the batch once it is full.
+func (h *sqlHandler) add(insert sqlInsert) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.closed {
+		return errors.New("The handler has been closed")
+	}
+
+	h.batch = append(h.batch, insert)
+	if len(h.batch) >= h.batchSize {
+		return h.flush()
+	}
+	return nil
+}
+
+func (h *sqlHandler) flushPeriodically() {
+	t := time.NewTicker(sqlFlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-t.C:
+			h.Lock()
+			if err := h.flush(); err != nil {
+				h.log.Print(err)
+			}
+			h.Unlock()
+		}
+	}
+}
+
+// flush executes the inserts in the batch. The caller must hold the lock.
+func (h *sqlHandler) flush() error {
+	if len(h.batch) == 0 {
+		return nil
+	}
+
+	batch := h.batch
+	h.batch = nil
+	if err := h.write(batch); err != nil {
+		return fmt.Errorf("%s: %d inserts were not executed: %v", h.name, len(batch), err)
+	}
+	return nil
+}
+
+// write executes the inserts within a single transaction.
+func (h *sqlHandler) write(batch []sqlInsert) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, insert := range batch {
+		if err := insert(tx, now); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (h *sqlHandler) exec(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return tx.Exec(h.rebind(query), args...)
+}
+
+func (h *sqlHandler) id(tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	var id int64
+
+	err := tx.QueryRow(h.rebind(query), args...).Scan(&id)
+	return id, err
+}
+
+// rebind replaces the ? placeholders with numbered placeholders when required by the database.
+func (h *sqlHandler) rebind(query string) string {
+	if !h.numbered {
+		return query
+	}
+
+	var n int
+	var b strings.Builder
+	for _, c := range query {
+		if c != '?' {
+			b.WriteRune(c)
+			continue
+		}
+
+		n++
+		b.WriteString("$" + strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+// createSchema executes the statements that create the tables when they do not exist.
+func (h *sqlHandler) createSchema(stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := h.db.Exec(stmt); err != nil {
+			return fmt.Errorf("Failed to create the schema: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSQLRebind(t *testing.T) {
+	query := "INSERT INTO names (name, domain_id) VALUES (?, ?)"
+
+	h := &sqlHandler{numbered: true}
+	if got := h.rebind(query); got != "INSERT INTO names (name, domain_id) VALUES ($1, $2)" {
+		t.Errorf("The placeholders were not numbered: %s", got)
+	}
+
+	h.numbered = false
+	if got := h.rebind(query); got != query {
+		t.Errorf("The placeholders were changed for a database that accepts them: %s", got)
+	}
+}
+
+func TestSQLSchema(t *testing.T) {
+	for _, stmt := range sqlSchema("SERIAL PRIMARY KEY") {
+		if strings.Contains(stmt, "{{ID}}") || strings.Contains(stmt, "AUTOINCREMENT") {
+			t.Errorf("The primary key type was not substituted: %s", stmt)
+		}
+	}
+
+	var found bool
+	for _, stmt := range sqlSchema("INTEGER PRIMARY KEY AUTOINCREMENT") {
+		if strings.Contains(stmt, "SERIAL") {
+			t.Errorf("The PostgreSQL primary key type was used: %s", stmt)
+		}
+		if strings.Contains(stmt, "id INTEGER PRIMARY KEY AUTOINCREMENT") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("The statements did not define the auto-incrementing primary keys")
+	}
+}
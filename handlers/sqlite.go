@@ -0,0 +1,274 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// SQLiteHandler is the DataHandler that writes the findings of each enumeration
+// into a separate SQLite database file, which can be shared and queried without a server.
+type SQLiteHandler struct {
+	sync.Mutex
+	dir       string
+	batchSize int
+	dbs       map[string]*sqlHandler
+	log       *log.Logger
+}
+
+// NewSQLiteHandler returns a SQLiteHandler that creates the database files within the directory
+// identified by the URL, e.g. sqlite:///home/user/amass_results. Each file is named amass_<uuid>.db.
+// A batchSize of zero selects the default number of inserts executed in each transaction.
+func NewSQLiteHandler(url string, batchSize int) (*SQLiteHandler, error) {
+	if !sqliteSupported {
+		return nil, fmt.Errorf("SQLite: The handler is not supported on %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+
+	dir := strings.TrimPrefix(strings.TrimPrefix(url, "sqlite://"), "file:")
+	if dir == "" {
+		return nil, fmt.Errorf("SQLite: The directory for the database files was not provided")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("SQLite: Failed to create the directory %s: %v", dir, err)
+	}
+	// The database files are created as the events are seen, so make sure they can be written now
+	if err := checkSQLiteDirectory(dir); err != nil {
+		return nil, fmt.Errorf("SQLite: Failed to create a database in %s: %v", dir, err)
+	}
+
+	return &SQLiteHandler{
+		dir:       dir,
+		batchSize: batchSize,
+		dbs:       make(map[string]*sqlHandler),
+		log:       log.New(ioutil.Discard, "", 0),
+	}, nil
+}
+
+// String implements the Stringer interface.
+func (h *SQLiteHandler) String() string {
+	return "SQLite"
+}
+
+// SetLogger implements the LogSetter interface.
+func (h *SQLiteHandler) SetLogger(l *log.Logger) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.log = l
+	for _, db := range h.dbs {
+		db.SetLogger(l)
+	}
+}
+
+// DatabaseFile returns the path of the database file that contains the findings of the event.
+func (h *SQLiteHandler) DatabaseFile(eventID string) string {
+	return filepath.Join(h.dir, "amass_"+eventID+".db")
+}
+
+// Close implements the DataHandler interface.
+func (h *SQLiteHandler) Close() error {
+	h.Lock()
+	defer h.Unlock()
+
+	var err error
+	for id, db := range h.dbs {
+		if e := db.Close(); e != nil && err == nil {
+			err = e
+		}
+		delete(h.dbs, id)
+	}
+	return err
+}
+
+// InsertFQDN implements the DataHandler interface.
+func (h *SQLiteHandler) InsertFQDN(name, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertFQDN(name, source, tag, eventID)
+}
+
+// InsertCNAME implements the DataHandler interface.
+func (h *SQLiteHandler) InsertCNAME(fqdn, target, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertCNAME(fqdn, target, source, tag, eventID)
+}
+
+// InsertA implements the DataHandler interface.
+func (h *SQLiteHandler) InsertA(fqdn, addr, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertA(fqdn, addr, source, tag, eventID)
+}
+
+// InsertAAAA implements the DataHandler interface.
+func (h *SQLiteHandler) InsertAAAA(fqdn, addr, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertAAAA(fqdn, addr, source, tag, eventID)
+}
+
+// InsertPTR implements the DataHandler interface.
+func (h *SQLiteHandler) InsertPTR(fqdn, target, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertPTR(fqdn, target, source, tag, eventID)
+}
+
+// InsertSRV implements the DataHandler interface.
+func (h *SQLiteHandler) InsertSRV(fqdn, service, target, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertSRV(fqdn, service, target, source, tag, eventID)
+}
+
+// InsertNS implements the DataHandler interface.
+func (h *SQLiteHandler) InsertNS(fqdn, target, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertNS(fqdn, target, source, tag, eventID)
+}
+
+// InsertMX implements the DataHandler interface.
+func (h *SQLiteHandler) InsertMX(fqdn, target, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertMX(fqdn, target, source, tag, eventID)
+}
+
+// InsertNAPTR implements the DataHandler interface.
+func (h *SQLiteHandler) InsertNAPTR(fqdn, target, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertNAPTR(fqdn, target, source, tag, eventID)
+}
+
+// InsertDNAME implements the DataHandler interface.
+func (h *SQLiteHandler) InsertDNAME(fqdn, target, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertDNAME(fqdn, target, source, tag, eventID)
+}
+
+// InsertInfrastructure implements the DataHandler interface.
+func (h *SQLiteHandler) InsertInfrastructure(asn int, desc, addr, cidr, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertInfrastructure(asn, desc, addr, cidr, source, tag, eventID)
+}
+
+// InsertCDN implements the DataHandler interface.
+func (h *SQLiteHandler) InsertCDN(addr, provider, source, tag, eventID string) error {
+	db, err := h.eventDB(eventID)
+	if err != nil {
+		return err
+	}
+	return db.InsertCDN(addr, provider, source, tag, eventID)
+}
+
+// eventDB returns the database for the event, and creates the file the first time the event is seen.
+func (h *SQLiteHandler) eventDB(eventID string) (*sqlHandler, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	if db, found := h.dbs[eventID]; found {
+		return db, nil
+	}
+	if eventID == "" || strings.ContainsAny(eventID, `/\`) {
+		return nil, fmt.Errorf("SQLite: The event ID %q cannot be used as a file name", eventID)
+	}
+
+	db, err := openSQLite(h.DatabaseFile(eventID))
+	if err != nil {
+		return nil, fmt.Errorf("SQLite: Failed to open the database for event %s: %v", eventID, err)
+	}
+
+	s := newSQLHandler(db, "SQLite", false, h.batchSize)
+	s.SetLogger(h.log)
+	if err := s.createSchema(sqlSchema("INTEGER PRIMARY KEY AUTOINCREMENT")); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("SQLite: %v", err)
+	}
+
+	h.dbs[eventID] = s
+	return s, nil
+}
+
+// openSQLite opens the database file and configures the connection used by the handler.
+func openSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, so the connection is shared by the transactions
+	// and keeps the settings applied by the pragmas below
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	// The write-ahead log avoids syncing the database file for every transaction
+	for _, pragma := range []string{
+		"PRAGMA foreign_keys = ON",
+		"PRAGMA busy_timeout = 5000",
+		"PRAGMA journal_mode = WAL",
+		"PRAGMA synchronous = NORMAL",
+	} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// checkSQLiteDirectory creates a database file in the directory to verify the driver and the permissions.
+func checkSQLiteDirectory(dir string) error {
+	f, err := ioutil.TempFile(dir, ".amass_check_*.db")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	db, err := openSQLite(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS amass_check (id INTEGER PRIMARY KEY)")
+	return err
+}
@@ -0,0 +1,15 @@
+//go:build (darwin && amd64) || (darwin && arm64) || (freebsd && amd64) || (linux && 386) || (linux && amd64) || (linux && arm) || (linux && arm64) || (linux && s390x) || (netbsd && amd64) || (windows && 386) || (windows && amd64)
+// +build darwin,amd64 darwin,arm64 freebsd,amd64 linux,386 linux,amd64 linux,arm linux,arm64 linux,s390x netbsd,amd64 windows,386 windows,amd64
+
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	// The pure Go SQLite driver, so the releases built without cgo support the handler
+	_ "modernc.org/sqlite"
+)
+
+// The SQLite driver is available on the platforms supported by modernc.org/sqlite.
+const sqliteSupported = true
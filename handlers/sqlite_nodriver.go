@@ -0,0 +1,20 @@
+//go:build (!darwin || !amd64) && (!darwin || !arm64) && (!freebsd || !amd64) && (!linux || !386) && (!linux || !amd64) && (!linux || !arm) && (!linux || !arm64) && (!linux || !s390x) && (!netbsd || !amd64) && (!windows || !386) && (!windows || !amd64)
+// +build !darwin !amd64
+// +build !darwin !arm64
+// +build !freebsd !amd64
+// +build !linux !386
+// +build !linux !amd64
+// +build !linux !arm
+// +build !linux !arm64
+// +build !linux !s390x
+// +build !netbsd !amd64
+// +build !windows !386
+// +build !windows !amd64
+
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+// The pure Go SQLite driver does not support this platform.
+const sqliteSupported = false
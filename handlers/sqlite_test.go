@@ -0,0 +1,174 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteHandler(t *testing.T) {
+	h, err := NewSQLiteHandler("sqlite://"+t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the SQLite handler: %v", err)
+	}
+
+	const event = "a4f4d5e2-0f4b-4c8d-9b3e-2c1d7f6a1b10"
+	if err := h.InsertA("www.owasp.org", "192.0.2.1", "DNS", "dns", event); err != nil {
+		t.Errorf("InsertA failed: %v", err)
+	}
+	if err := h.InsertCNAME("owasp.org", "www.owasp.org", "DNS", "dns", event); err != nil {
+		t.Errorf("InsertCNAME failed: %v", err)
+	}
+	// Inserting the same data again must not fail the upserts
+	if err := h.InsertA("www.owasp.org", "192.0.2.1", "DNS", "dns", event); err != nil {
+		t.Errorf("InsertA failed for data already in the database: %v", err)
+	}
+	if err := h.InsertInfrastructure(64496, "EXAMPLE-AS", "192.0.2.1", "192.0.2.0/24", "RIR", "api", event); err != nil {
+		t.Errorf("InsertInfrastructure failed: %v", err)
+	}
+	if err := h.InsertFQDN("mail.owasp.org", "DNS", "dns", "other-event"); err != nil {
+		t.Errorf("InsertFQDN failed: %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Errorf("Failed to close the SQLite handler: %v", err)
+	}
+
+	path := h.DatabaseFile(event)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("The database file was not created for the event: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open the database file: %v", err)
+	}
+	defer db.Close()
+
+	var names, records int
+	_ = db.QueryRow("SELECT COUNT(*) FROM names").Scan(&names)
+	_ = db.QueryRow("SELECT COUNT(*) FROM address_records").Scan(&records)
+	// The names from the other event are written to a separate file
+	if names != 2 || records != 1 {
+		t.Errorf("The database file contained %d names and %d address records", names, records)
+	}
+
+	var asn int
+	if err := db.QueryRow(`SELECT n.asn FROM addresses a JOIN netblocks n ON a.netblock_id = n.id
+		WHERE a.addr = ?`, "192.0.2.1").Scan(&asn); err != nil || asn != 64496 {
+		t.Errorf("The address was not associated with the netblock and autonomous system")
+	}
+
+	if _, err := h.eventDB("../escape"); err == nil {
+		t.Errorf("An event ID containing a path separator was accepted")
+	}
+}
+
+func TestNewSQLiteHandlerChecksDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewSQLiteHandler("sqlite://"+dir, 0); err != nil {
+		t.Fatalf("Failed to create the SQLite handler: %v", err)
+	}
+	// The database created to check the directory must not be left behind
+	if entries, err := ioutil.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("The directory was not left empty by the check: %v", entries)
+	}
+
+	file := filepath.Join(dir, "results")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("Failed to create the file: %v", err)
+	}
+	if _, err := NewSQLiteHandler("sqlite://"+file, 0); err == nil {
+		t.Errorf("The handler was created for a path that cannot hold the database files")
+	}
+}
+
+func TestOpenSQLiteSettings(t *testing.T) {
+	db, err := openSQLite(filepath.Join(t.TempDir(), "amass.db"))
+	if err != nil {
+		t.Fatalf("Failed to open the database: %v", err)
+	}
+	defer db.Close()
+
+	var mode string
+	var sync int
+	_ = db.QueryRow("PRAGMA journal_mode").Scan(&mode)
+	_ = db.QueryRow("PRAGMA synchronous").Scan(&sync)
+	// The NORMAL synchronous setting has the value 1
+	if mode != "wal" || sync != 1 {
+		t.Errorf("The database uses the %s journal mode and synchronous setting %d", mode, sync)
+	}
+}
+
+func TestSQLiteHandlerBatches(t *testing.T) {
+	h, err := NewSQLiteHandler("sqlite://"+t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("Failed to create the SQLite handler: %v", err)
+	}
+	defer h.Close()
+
+	const event = "a4f4d5e2-0f4b-4c8d-9b3e-2c1d7f6a1b10"
+	if err := h.InsertFQDN("www.owasp.org", "DNS", "dns", event); err != nil {
+		t.Fatalf("InsertFQDN failed: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", h.DatabaseFile(event))
+	if err != nil {
+		t.Fatalf("Failed to open the database file: %v", err)
+	}
+	defer db.Close()
+
+	var names int
+	_ = db.QueryRow("SELECT COUNT(*) FROM names").Scan(&names)
+	if names != 0 {
+		t.Errorf("The insert was executed before the batch was full")
+	}
+
+	if err := h.InsertFQDN("mail.owasp.org", "DNS", "dns", event); err != nil {
+		t.Fatalf("InsertFQDN failed: %v", err)
+	}
+	_ = db.QueryRow("SELECT COUNT(*) FROM names").Scan(&names)
+	if names != 2 {
+		t.Errorf("The full batch was not executed, the database contained %d names", names)
+	}
+
+	if err := h.Close(); err != nil {
+		t.Errorf("Failed to close the SQLite handler: %v", err)
+	}
+	if _, found := h.dbs[event]; found {
+		t.Errorf("The database for the event was not released")
+	}
+}
+
+func TestSQLiteHandlerCDN(t *testing.T) {
+	h, err := NewSQLiteHandler("sqlite://"+t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Failed to create the SQLite handler: %v", err)
+	}
+
+	const event = "a4f4d5e2-0f4b-4c8d-9b3e-2c1d7f6a1b10"
+	for _, cdn := range []string{"CDN77", "Fastly"} {
+		if err := h.InsertCDN("192.0.2.1", cdn, "RIR", "rir", event); err != nil {
+			t.Errorf("InsertCDN failed: %v", err)
+		}
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Failed to close the SQLite handler: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", h.DatabaseFile(event))
+	if err != nil {
+		t.Fatalf("Failed to open the database file: %v", err)
+	}
+	defer db.Close()
+
+	var provider string
+	if err := db.QueryRow(`SELECT c.provider FROM address_cdns c JOIN addresses a ON c.address_id = a.id
+		WHERE a.addr = ?`, "192.0.2.1").Scan(&provider); err != nil || provider != "Fastly" {
+		t.Errorf("The address was recorded with the CDN %q: %v", provider, err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OWASP/Amass/v3/config"
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+// The topic or subject used when one is not provided in the options
+const defaultStreamTopic = "amass.output"
+
+// OutputStream is the interface implemented by the message brokers that receive
+// each finalized output as JSON, enabling real-time downstream pipelines.
+type OutputStream interface {
+	fmt.Stringer
+
+	// Publish sends the output to the topic or subject of the broker
+	Publish(out *requests.Output) error
+
+	// Close delivers the outputs still waiting to be sent and releases the connections
+	Close() error
+}
+
+// NewOutputStream returns the OutputStream for the message broker identified in the settings.
+// The topic or subject is provided by the options, e.g. topic=amass.output.
+func NewOutputStream(db *config.Database) (OutputStream, error) {
+	if db == nil {
+		return nil, fmt.Errorf("NewOutputStream: The broker settings were not provided")
+	}
+
+	opts := parseOptions(db.Options)
+	switch strings.ToLower(db.System) {
+	case "kafka":
+		return NewKafkaStream(db.URL, db.Username, db.Password, streamTopic(opts["topic"]))
+	case "nats":
+		return NewNATSStream(db.URL, db.Username, db.Password, streamTopic(opts["subject"]))
+	}
+	return nil, fmt.Errorf("NewOutputStream: The %s message broker is not supported", db.System)
+}
+
+func streamTopic(topic string) string {
+	if topic = strings.TrimSpace(topic); topic == "" {
+		return defaultStreamTopic
+	}
+	return topic
+}
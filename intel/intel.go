@@ -77,6 +77,9 @@ func (c *Collection) HostedDomains(ctx context.Context) error {
 	ctx, cancel = context.WithCancel(ctx)
 	ctx = context.WithValue(ctx, requests.ContextConfig, c.Config)
 	ctx = context.WithValue(ctx, requests.ContextEventBus, c.Bus)
+	if cache := datasrcs.NewResponseCache(c.Config, c.Sys.GraphDatabases()); cache != nil {
+		ctx = context.WithValue(ctx, requests.ContextResponseCache, cache)
+	}
 	c.ctx = ctx
 	defer cancel()
 
@@ -262,7 +265,11 @@ func (c *Collection) ReverseWhois() error {
 
 	// Setup the context used throughout the collection
 	ctx := context.WithValue(context.Background(), requests.ContextConfig, c.Config)
-	c.ctx = context.WithValue(ctx, requests.ContextEventBus, c.Bus)
+	ctx = context.WithValue(ctx, requests.ContextEventBus, c.Bus)
+	if cache := datasrcs.NewResponseCache(c.Config, c.Sys.GraphDatabases()); cache != nil {
+		ctx = context.WithValue(ctx, requests.ContextResponseCache, cache)
+	}
+	c.ctx = ctx
 
 	// Send the whois requests to the data sources
 	for _, src := range c.srcs {
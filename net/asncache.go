@@ -71,6 +71,15 @@ func (c *ASNCache) Update(req *requests.ASNRequest) {
 	} else {
 		as.Netblocks.Union(req.Netblocks)
 	}
+loop:
+	for _, up := range req.Upstreams {
+		for _, known := range as.Upstreams {
+			if up == known {
+				continue loop
+			}
+		}
+		as.Upstreams = append(as.Upstreams, up)
+	}
 }
 
 // ASNSearch return the cached ASN / netblock info associated with the provided asn parameter,
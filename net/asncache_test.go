@@ -0,0 +1,40 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package net
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/requests"
+)
+
+func TestASNCacheUpstreams(t *testing.T) {
+	c := NewASNCache()
+
+	c.Update(&requests.ASNRequest{
+		Address:     "192.0.2.1",
+		ASN:         64496,
+		Prefix:      "192.0.2.0/24",
+		Description: "EXAMPLE-ORG",
+		Upstreams:   []int{64497},
+	})
+	c.Update(&requests.ASNRequest{
+		Address:   "198.51.100.1",
+		ASN:       64496,
+		Prefix:    "198.51.100.0/24",
+		Upstreams: []int{64497, 64498},
+	})
+
+	as := c.ASNSearch(64496)
+	if as == nil {
+		t.Fatalf("The ASN was not cached")
+	}
+	if want := []int{64497, 64498}; !reflect.DeepEqual(as.Upstreams, want) {
+		t.Errorf("The cached upstreams were %v, expected %v", as.Upstreams, want)
+	}
+	if as.Description != "EXAMPLE-ORG" {
+		t.Errorf("The description was changed to %s", as.Description)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// BrowserProfile contains the headers sent by a web browser.
+type BrowserProfile struct {
+	UserAgent  string
+	Accept     string
+	AcceptLang string
+	Headers    map[string]string
+}
+
+// BrowserProfiles is the pool of realistic web browser headers rotated across the web sites.
+var BrowserProfiles = []*BrowserProfile{
+	{
+		UserAgent:  UserAgent,
+		Accept:     "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9",
+		AcceptLang: "en-US,en;q=0.9",
+		Headers: map[string]string{
+			"Sec-Fetch-Dest":            "document",
+			"Sec-Fetch-Mode":            "navigate",
+			"Sec-Fetch-Site":            "none",
+			"Sec-Fetch-User":            "?1",
+			"Upgrade-Insecure-Requests": "1",
+		},
+	},
+	{
+		UserAgent:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 11_2_1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/88.0.4324.150 Safari/537.36",
+		Accept:     "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9",
+		AcceptLang: "en-US,en;q=0.9",
+		Headers: map[string]string{
+			"Sec-Fetch-Dest":            "document",
+			"Sec-Fetch-Mode":            "navigate",
+			"Sec-Fetch-Site":            "none",
+			"Sec-Fetch-User":            "?1",
+			"Upgrade-Insecure-Requests": "1",
+		},
+	},
+	{
+		UserAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:85.0) Gecko/20100101 Firefox/85.0",
+		Accept:     "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+		AcceptLang: "en-US,en;q=0.5",
+		Headers: map[string]string{
+			"DNT":                       "1",
+			"Upgrade-Insecure-Requests": "1",
+		},
+	},
+	{
+		UserAgent:  "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:85.0) Gecko/20100101 Firefox/85.0",
+		Accept:     "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
+		AcceptLang: "en-US,en;q=0.5",
+		Headers: map[string]string{
+			"Upgrade-Insecure-Requests": "1",
+		},
+	},
+	{
+		UserAgent:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.0.3 Safari/605.1.15",
+		Accept:     "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		AcceptLang: "en-us",
+	},
+	{
+		UserAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/88.0.4324.150 Safari/537.36 Edg/88.0.705.63",
+		Accept:     "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.9",
+		AcceptLang: "en-US,en;q=0.9",
+		Headers: map[string]string{
+			"Sec-Fetch-Dest":            "document",
+			"Sec-Fetch-Mode":            "navigate",
+			"Sec-Fetch-Site":            "none",
+			"Sec-Fetch-User":            "?1",
+			"Upgrade-Insecure-Requests": "1",
+		},
+	},
+}
+
+// Each web site continues to receive the same browser profile, so sessions remain consistent.
+var hostProfiles = struct {
+	sync.Mutex
+	profiles map[string]*BrowserProfile
+}{profiles: make(map[string]*BrowserProfile)}
+
+type userAgentContextKey struct{}
+
+// WithUserAgent returns a copy of the context that sends the HTTP requests with the user agent.
+func WithUserAgent(ctx context.Context, ua string) context.Context {
+	return context.WithValue(ctx, userAgentContextKey{}, ua)
+}
+
+// HostBrowserProfile returns the browser profile randomly assigned to the host.
+func HostBrowserProfile(host string) *BrowserProfile {
+	host = strings.ToLower(host)
+
+	hostProfiles.Lock()
+	defer hostProfiles.Unlock()
+
+	p, found := hostProfiles.profiles[host]
+	if !found {
+		p = BrowserProfiles[rand.Intn(len(BrowserProfiles))]
+		hostProfiles.profiles[host] = p
+	}
+	return p
+}
+
+// SetBrowserHeaders emulates a web browser by setting the headers of the profile assigned to the request host.
+// The user agent provided with the request context takes precedence over the profile.
+func SetBrowserHeaders(req *http.Request) {
+	p := HostBrowserProfile(req.URL.Hostname())
+
+	ua := p.UserAgent
+	if v, ok := req.Context().Value(userAgentContextKey{}).(string); ok && v != "" {
+		ua = v
+	}
+
+	req.Header.Set("User-Agent", ua)
+	req.Header.Set("Accept", p.Accept)
+	req.Header.Set("Accept-Language", p.AcceptLang)
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+}
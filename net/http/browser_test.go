@@ -0,0 +1,37 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSetBrowserHeaders(t *testing.T) {
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "https://www.owasp.org", nil)
+	SetBrowserHeaders(req)
+
+	ua := req.Header.Get("User-Agent")
+	if ua == "" || req.Header.Get("Accept") == "" || req.Header.Get("Accept-Language") == "" {
+		t.Errorf("Failed to set the browser headers")
+	}
+
+	for i := 0; i < 10; i++ {
+		req, _ = http.NewRequestWithContext(context.Background(), "GET", "https://WWW.owasp.org/index.html", nil)
+		SetBrowserHeaders(req)
+
+		if req.Header.Get("User-Agent") != ua {
+			t.Errorf("The browser profile changed for the same host")
+			break
+		}
+	}
+
+	ctx := WithUserAgent(context.Background(), "Custom/1.0")
+	req, _ = http.NewRequestWithContext(ctx, "GET", "https://www.owasp.org", nil)
+	SetBrowserHeaders(req)
+	if req.Header.Get("User-Agent") != "Custom/1.0" {
+		t.Errorf("Failed to use the user agent provided with the context")
+	}
+}
@@ -55,6 +55,17 @@ var (
 // DefaultClient is the same HTTP client used by the package methods.
 var DefaultClient *http.Client
 
+// StatusError is returned when the HTTP response status code indicates the request was not successful.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return e.Status
+}
+
 // BasicAuth contains the data used for HTTP basic authentication.
 type BasicAuth struct {
 	Username string
@@ -66,7 +77,7 @@ func init() {
 	DefaultClient = &http.Client{
 		Timeout: httpTimeout,
 		Transport: &http.Transport{
-			Proxy:                 http.ProxyFromEnvironment,
+			Proxy:                 proxyFromContext,
 			DialContext:           amassnet.DialContext,
 			MaxIdleConns:          200,
 			MaxConnsPerHost:       50,
@@ -79,6 +90,46 @@ func init() {
 	}
 }
 
+type proxyContextKey struct{}
+
+// WithProxy returns a copy of the context that sends the HTTP requests through the proxy.
+// The HTTP, HTTPS and SOCKS5 proxy schemes are supported, and credentials can be provided in the URL.
+func WithProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyContextKey{}, proxy)
+}
+
+// proxyFromContext selects the proxy provided with the request context before the environment variables.
+func proxyFromContext(req *http.Request) (*url.URL, error) {
+	if proxy := contextProxy(req.Context()); proxy != nil {
+		return proxy, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// contextProxy returns the proxy provided with the context, or nil when none was provided.
+func contextProxy(ctx context.Context) *url.URL {
+	if proxy, ok := ctx.Value(proxyContextKey{}).(*url.URL); ok {
+		return proxy
+	}
+	return nil
+}
+
+// ClientWithProxy returns an HTTP client with the DefaultClient settings that sends every request through the proxy.
+// The DefaultClient is returned when the proxy is nil.
+func ClientWithProxy(proxy *url.URL) *http.Client {
+	if proxy == nil {
+		return DefaultClient
+	}
+
+	t := DefaultClient.Transport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyURL(proxy)
+	return &http.Client{
+		Timeout:   DefaultClient.Timeout,
+		Transport: t,
+		Jar:       DefaultClient.Jar,
+	}
+}
+
 // CopyCookies copies cookies from one domain to another. Some of our data
 // sources rely on shared auth tokens and this avoids sending extra requests
 // to have the site reissue cookies for the other domains.
@@ -114,9 +165,7 @@ func RequestWebPage(ctx context.Context, u string, body io.Reader, hvals map[str
 	if auth != nil && auth.Username != "" && auth.Password != "" {
 		req.SetBasicAuth(auth.Username, auth.Password)
 	}
-	req.Header.Set("User-Agent", UserAgent)
-	req.Header.Set("Accept", Accept)
-	req.Header.Set("Accept-Language", AcceptLang)
+	SetBrowserHeaders(req)
 
 	for k, v := range hvals {
 		req.Header.Set(k, v)
@@ -131,12 +180,16 @@ func RequestWebPage(ctx context.Context, u string, body io.Reader, hvals map[str
 	resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		err = errors.New(resp.Status)
+		err = &StatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
 	}
 	return string(in), err
 }
 
 // Crawl will spider the web page at the URL argument looking for DNS names within the scope argument.
+// The requests are sent through the proxy provided with the context by WithProxy.
 func Crawl(ctx context.Context, u string, scope []string, max int, filter stringfilter.Filter) ([]string, error) {
 	newScope := append([]string{}, scope...)
 
@@ -166,7 +219,7 @@ func Crawl(ctx context.Context, u string, scope []string, max int, filter string
 		StartURLs:             []string{u},
 		Timeout:               5 * time.Minute,
 		RobotsTxtDisabled:     true,
-		UserAgent:             UserAgent,
+		UserAgent:             HostBrowserProfile(target).UserAgent,
 		LogDisabled:           true,
 		ConcurrentRequests:    5,
 		RequestDelay:          750 * time.Millisecond,
@@ -243,7 +296,8 @@ func Crawl(ctx context.Context, u string, scope []string, max int, filter string
 		RetryHTTPCodes: []int{408, 500, 502, 503, 504, 522, 524},
 	}
 	g.Client = client.NewClient(options)
-	g.Client.Client = http.DefaultClient
+	// The crawl requests are sent through the proxy provided with the context
+	g.Client.Client = ClientWithProxy(contextProxy(ctx))
 
 	done := make(chan struct{}, 2)
 	go func() {
@@ -0,0 +1,161 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	amassnet "github.com/OWASP/Amass/v3/net"
+	"golang.org/x/net/proxy"
+	"golang.org/x/net/websocket"
+)
+
+// contextDialer forwards the connections made by the SOCKS5 dialer using the Amass network settings.
+type contextDialer struct{}
+
+func (d contextDialer) Dial(network, addr string) (net.Conn, error) {
+	return amassnet.DialContext(context.Background(), network, addr)
+}
+
+func (d contextDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return amassnet.DialContext(ctx, network, addr)
+}
+
+// ProxyDialContext connects to the address through the proxy, or directly when the proxy is nil.
+// HTTP and HTTPS proxies are asked to open a tunnel using the CONNECT method.
+func ProxyDialContext(ctx context.Context, p *url.URL, network, addr string) (net.Conn, error) {
+	if p == nil {
+		return amassnet.DialContext(ctx, network, addr)
+	}
+
+	switch strings.ToLower(p.Scheme) {
+	case "socks5":
+		d, err := proxy.FromURL(p, contextDialer{})
+		if err != nil {
+			return nil, err
+		}
+		if cd, ok := d.(proxy.ContextDialer); ok {
+			return cd.DialContext(ctx, network, addr)
+		}
+		return d.Dial(network, addr)
+	case "http", "https":
+		return connectTunnel(ctx, p, addr)
+	}
+	return nil, fmt.Errorf("The proxy scheme %q is not supported", p.Scheme)
+}
+
+func connectTunnel(ctx context.Context, p *url.URL, addr string) (net.Conn, error) {
+	host := p.Host
+	if p.Port() == "" {
+		port := "80"
+		if strings.EqualFold(p.Scheme, "https") {
+			port = "443"
+		}
+		host = net.JoinHostPort(p.Hostname(), port)
+	}
+
+	conn, err := amassnet.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(p.Scheme, "https") {
+		if conn, err = tlsHandshake(ctx, conn, p.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if p.User != nil {
+		pass, _ := p.User.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(p.User.Username() + ":" + pass))
+
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	// The deadline of the context also applies to the tunnel being established
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("The proxy refused the tunnel to %s: %s", addr, resp.Status)
+	}
+	return conn, nil
+}
+
+// tlsHandshake secures the connection within the deadline of the context, and closes it on failure.
+func tlsHandshake(ctx context.Context, conn net.Conn, name string) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	tconn := tls.Client(conn, &tls.Config{ServerName: name})
+	if err := tconn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tconn, nil
+}
+
+// DialWebsocket opens the websocket connection through the proxy, or directly when the proxy is nil.
+func DialWebsocket(ctx context.Context, u, origin string, p *url.URL) (*websocket.Conn, error) {
+	cfg, err := websocket.NewConfig(u, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	host := cfg.Location.Host
+	if cfg.Location.Port() == "" {
+		port := "80"
+		if cfg.Location.Scheme == "wss" {
+			port = "443"
+		}
+		host = net.JoinHostPort(cfg.Location.Hostname(), port)
+	}
+
+	conn, err := ProxyDialContext(ctx, p, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Location.Scheme == "wss" {
+		if conn, err = tlsHandshake(ctx, conn, cfg.Location.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	ws, err := websocket.NewClient(cfg, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// testProxy tunnels the CONNECT requests and answers the plain HTTP requests with the page.
+type testProxy struct {
+	sync.Mutex
+	auth     string
+	page     string
+	requests []string
+}
+
+func (p *testProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.Lock()
+	p.requests = append(p.requests, r.Method+" "+r.RequestURI)
+	p.Unlock()
+
+	if p.auth != "" && r.Header.Get("Proxy-Authorization") != "Basic "+p.auth {
+		w.WriteHeader(http.StatusProxyAuthRequired)
+		return
+	}
+	if r.Method != http.MethodConnect {
+		fmt.Fprint(w, p.page)
+		return
+	}
+
+	dst, err := net.Dial("tcp", r.Host)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	defer dst.Close()
+
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		return
+	}
+
+	go func() { _, _ = io.Copy(dst, rw) }()
+	_, _ = io.Copy(conn, dst)
+}
+
+func (p *testProxy) seen() []string {
+	p.Lock()
+	defer p.Unlock()
+
+	return append([]string(nil), p.requests...)
+}
+
+func TestDialWebsocketProxy(t *testing.T) {
+	ws := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		_, _ = io.Copy(conn, conn)
+	}))
+	defer ws.Close()
+	wsURL := "ws" + ws.URL[len("http"):] + "/"
+
+	p := &testProxy{auth: base64.StdEncoding.EncodeToString([]byte("user:secret"))}
+	ps := httptest.NewServer(p)
+	defer ps.Close()
+
+	proxy, _ := url.Parse(ps.URL)
+	proxy.User = url.UserPassword("user", "secret")
+
+	conn, err := DialWebsocket(context.Background(), wsURL, "http://localhost/", proxy)
+	if err != nil {
+		t.Fatalf("Failed to open the websocket through the proxy: %v", err)
+	}
+	defer conn.Close()
+
+	if err := websocket.Message.Send(conn, "hello"); err != nil {
+		t.Fatalf("Failed to send the message: %v", err)
+	}
+	var msg string
+	if err := websocket.Message.Receive(conn, &msg); err != nil || msg != "hello" {
+		t.Errorf("The message was not echoed through the tunnel: %q %v", msg, err)
+	}
+
+	host := ws.Listener.Addr().String()
+	if seen := p.seen(); len(seen) != 1 || seen[0] != "CONNECT "+host {
+		t.Errorf("The proxy did not tunnel the websocket connection: %v", seen)
+	}
+
+	proxy.User = url.UserPassword("user", "wrong")
+	if _, err := DialWebsocket(context.Background(), wsURL, "http://localhost/", proxy); err == nil {
+		t.Errorf("The tunnel was opened with the wrong proxy credentials")
+	}
+}
+
+func TestCrawlProxy(t *testing.T) {
+	p := &testProxy{page: `<html><body>www.example.com<a href="http://api.example.com/">API</a></body></html>`}
+	ps := httptest.NewServer(p)
+	defer ps.Close()
+
+	proxy, _ := url.Parse(ps.URL)
+	ctx := WithProxy(context.Background(), proxy)
+
+	names, err := Crawl(ctx, "http://www.example.com/", []string{"example.com"}, 1, nil)
+	if err != nil {
+		t.Fatalf("The crawl failed: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, n := range names {
+		got[n] = true
+	}
+	if !got["www.example.com"] || !got["api.example.com"] {
+		t.Errorf("The crawl did not return the names from the page sent by the proxy: %v", names)
+	}
+	if seen := p.seen(); len(seen) == 0 || seen[0] != "GET http://www.example.com/" {
+		t.Errorf("The crawl requests were not sent through the proxy: %v", seen)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"math/big"
 	"net"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -15,6 +16,19 @@ import (
 // IPv4RE is a regular expression that will match an IPv4 address.
 const IPv4RE = "((25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)[.]){3}(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)"
 
+// IPv6RE is a regular expression that will match an IPv6 address, including the compressed forms.
+// The alternatives with the most groups following the "::" are attempted first to match entire addresses.
+const IPv6RE = "(" +
+	"([0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}|" +
+	"[0-9a-fA-F]{1,4}:(:[0-9a-fA-F]{1,4}){1,6}|" +
+	"([0-9a-fA-F]{1,4}:){1,2}(:[0-9a-fA-F]{1,4}){1,5}|" +
+	"([0-9a-fA-F]{1,4}:){1,3}(:[0-9a-fA-F]{1,4}){1,4}|" +
+	"([0-9a-fA-F]{1,4}:){1,4}(:[0-9a-fA-F]{1,4}){1,3}|" +
+	"([0-9a-fA-F]{1,4}:){1,5}(:[0-9a-fA-F]{1,4}){1,2}|" +
+	"([0-9a-fA-F]{1,4}:){1,6}:[0-9a-fA-F]{1,4}|" +
+	"([0-9a-fA-F]{1,4}:){1,7}:|" +
+	":(:[0-9a-fA-F]{1,4}){1,7})"
+
 // ReservedCIDRDescription is the description used for reserved address ranges.
 const ReservedCIDRDescription = "Reserved Network Address Blocks"
 
@@ -41,11 +55,21 @@ var ReservedCIDRs = []string{
 	"192.12.109.0/24",
 	"192.31.196.0/24",
 	"192.0.0.0/29",
+	"::1/128",
+	"100::/64",
+	"2001:db8::/32",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
 }
 
 // The reserved network address ranges
 var reservedAddrRanges []*net.IPNet
 
+// Matches start at the beginning of the data or after a character that cannot be part of the address,
+// so the "ip6:" mechanism in SPF records is not taken as the first group of an address
+var ipv6AddrRE = regexp.MustCompile("(?:^|[^0-9a-zA-Z])" + IPv6RE)
+
 func init() {
 	for _, cidr := range ReservedCIDRs {
 		if _, ipnet, err := net.ParseCIDR(cidr); err == nil {
@@ -118,6 +142,18 @@ func IsReservedAddress(addr string) (bool, string) {
 	return false, ""
 }
 
+// FindIPv6Addrs returns the valid IPv6 addresses found in the data, in their canonical form.
+func FindIPv6Addrs(data string) []string {
+	var addrs []string
+
+	for _, match := range ipv6AddrRE.FindAllStringSubmatch(data, -1) {
+		if ip := net.ParseIP(match[1]); ip != nil && IsIPv6(ip) {
+			addrs = append(addrs, ip.String())
+		}
+	}
+	return addrs
+}
+
 // FirstLast return the first and last IP address of the provided CIDR/netblock.
 func FirstLast(cidr *net.IPNet) (net.IP, net.IP) {
 	firstIP := cidr.IP
@@ -252,3 +252,46 @@ func TestIPDec(t *testing.T) {
 		}
 	}
 }
+
+func TestFindIPv6Addrs(t *testing.T) {
+	tests := []struct {
+		Data     string
+		Expected string
+	}{
+		{"v=spf1 ip6:2620:0:860:2:ffff:ffff:ffff:ffff -all", "2620:0:860:2:ffff:ffff:ffff:ffff"},
+		{"ip6:2001:db8::1 ~all", "2001:db8::1"},
+		{"2001:db8::1:2 is the address", "2001:db8::1:2"},
+		{"a:b:c:d:e:f::1", "a:b:c:d:e:f:0:1"},
+		{"2001:0DB8:0000:0000:0000:0000:0000:0001", "2001:db8::1"},
+		{"2606:4700::/32", "2606:4700::"},
+		{"loopback ::1", "::1"},
+		{"ip4:192.0.2.1 -all", ""},
+	}
+
+	for _, test := range tests {
+		addrs := FindIPv6Addrs(test.Data)
+
+		if test.Expected == "" {
+			if len(addrs) != 0 {
+				t.Errorf("Found %v in %s, expected no addresses", addrs, test.Data)
+			}
+			continue
+		}
+		if len(addrs) != 1 || addrs[0] != test.Expected {
+			t.Errorf("Found %v in %s, expected %s", addrs, test.Data, test.Expected)
+		}
+	}
+}
+
+func TestIsReservedIPv6Address(t *testing.T) {
+	for _, addr := range []string{"::1", "fe80::1", "fd00::1", "2001:db8::1"} {
+		if yes, _ := IsReservedAddress(addr); !yes {
+			t.Errorf("The address %s was not identified as reserved", addr)
+		}
+	}
+	for _, addr := range []string{"2620:0:860:2::1", "72.237.4.1"} {
+		if yes, _ := IsReservedAddress(addr); yes {
+			t.Errorf("The address %s was identified as reserved", addr)
+		}
+	}
+}
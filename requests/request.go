@@ -38,6 +38,9 @@ type ContextKey int
 const (
 	ContextConfig ContextKey = iota
 	ContextEventBus
+	ContextSourceBudgets
+	ContextResponseCache
+	ContextGraph
 )
 
 // Request Pub/Sub topics used across Amass.
@@ -49,6 +52,7 @@ const (
 	NewASNTopic        = "amass:newasn"
 	WhoisRequestTopic  = "amass:whoisreq"
 	NewWhoisTopic      = "amass:whoisinfo"
+	RelatedDomainTopic = "amass:relateddomain"
 	LogTopic           = "amass:log"
 	OutputTopic        = "amass:output"
 )
@@ -61,23 +65,56 @@ type DNSAnswer struct {
 	Data string `json:"data"`
 }
 
+// Provenance describes when and how a data source discovered a DNS name.
+type Provenance struct {
+	Source string `json:"source"`
+	// The time when the data source provided the name
+	Timestamp time.Time `json:"timestamp"`
+	// The time when the data source observed the name, e.g. the certificate NotBefore
+	Seen time.Time `json:"seen"`
+	// The raw evidence supporting the discovery, e.g. the certificate ID
+	Evidence map[string]string `json:"evidence,omitempty"`
+}
+
+// Clone returns a deep copy of the receiver.
+func (p *Provenance) Clone() *Provenance {
+	if p == nil {
+		return nil
+	}
+
+	c := &Provenance{
+		Source:    p.Source,
+		Timestamp: p.Timestamp,
+		Seen:      p.Seen,
+	}
+	if p.Evidence != nil {
+		c.Evidence = make(map[string]string, len(p.Evidence))
+		for k, v := range p.Evidence {
+			c.Evidence[k] = v
+		}
+	}
+	return c
+}
+
 // DNSRequest handles data needed throughout Service processing of a DNS name.
 type DNSRequest struct {
-	Name    string
-	Domain  string
-	Records []DNSAnswer
-	Tag     string
-	Source  string
+	Name       string
+	Domain     string
+	Records    []DNSAnswer
+	Tag        string
+	Source     string
+	Provenance *Provenance
 }
 
 // Clone implements pipeline Data.
 func (d *DNSRequest) Clone() pipeline.Data {
 	return &DNSRequest{
-		Name:    d.Name,
-		Domain:  d.Domain,
-		Records: append([]DNSAnswer(nil), d.Records...),
-		Tag:     d.Tag,
-		Source:  d.Source,
+		Name:       d.Name,
+		Domain:     d.Domain,
+		Records:    append([]DNSAnswer(nil), d.Records...),
+		Tag:        d.Tag,
+		Source:     d.Source,
+		Provenance: d.Provenance.Clone(),
 	}
 }
 
@@ -246,6 +283,8 @@ type ASNRequest struct {
 	AllocationDate time.Time
 	Description    string
 	Netblocks      stringset.Set
+	Upstreams      []int
+	Organization   string
 	Tag            string
 	Source         string
 }
@@ -261,6 +300,8 @@ func (a *ASNRequest) Clone() pipeline.Data {
 		AllocationDate: a.AllocationDate,
 		Description:    a.Description,
 		Netblocks:      stringset.New(a.Netblocks.Slice()...),
+		Upstreams:      append([]int(nil), a.Upstreams...),
+		Organization:   a.Organization,
 		Tag:            a.Tag,
 		Source:         a.Source,
 	}
@@ -295,23 +336,52 @@ type WhoisRequest struct {
 	Source     string
 }
 
+// RelatedDomain describes a domain name that appears to belong to the same organization as the
+// target, learned from PTR records in netblocks announced by the organization's ASN.
+type RelatedDomain struct {
+	Domain      string
+	Name        string
+	Address     string
+	ASN         int
+	Description string
+	Tag         string
+	Source      string
+}
+
 // Output contains all the output data for an enumerated DNS name.
 type Output struct {
-	Name      string        `json:"name"`
-	Domain    string        `json:"domain"`
-	Addresses []AddressInfo `json:"addresses"`
-	Tag       string        `json:"tag"`
-	Sources   []string      `json:"sources"`
+	Name         string        `json:"name"`
+	Domain       string        `json:"domain"`
+	Addresses    []AddressInfo `json:"addresses"`
+	Tag          string        `json:"tag"`
+	Sources      []string      `json:"sources"`
+	CDN          string        `json:"cdn,omitempty"`
+	OriginHidden bool          `json:"origin_hidden,omitempty"`
+	CNAMEChain   []string      `json:"cname_chain,omitempty"`
+	Provenance   []*Provenance `json:"provenance,omitempty"`
+}
+
+func clonedProvenance(provs []*Provenance) []*Provenance {
+	var results []*Provenance
+
+	for _, p := range provs {
+		results = append(results, p.Clone())
+	}
+	return results
 }
 
 // Clone implements pipeline Data.
 func (o *Output) Clone() pipeline.Data {
 	return &Output{
-		Name:      o.Name,
-		Domain:    o.Domain,
-		Addresses: append([]AddressInfo(nil), o.Addresses...),
-		Tag:       o.Tag,
-		Sources:   append([]string(nil), o.Sources...),
+		Name:         o.Name,
+		Domain:       o.Domain,
+		Addresses:    append([]AddressInfo(nil), o.Addresses...),
+		Tag:          o.Tag,
+		Sources:      append([]string(nil), o.Sources...),
+		CDN:          o.CDN,
+		OriginHidden: o.OriginHidden,
+		CNAMEChain:   append([]string(nil), o.CNAMEChain...),
+		Provenance:   clonedProvenance(o.Provenance),
 	}
 }
 
@@ -325,6 +395,7 @@ type AddressInfo struct {
 	CIDRStr     string     `json:"cidr"`
 	ASN         int        `json:"asn"`
 	Description string     `json:"desc"`
+	CDN         string     `json:"cdn,omitempty"`
 }
 
 // TrustedTag returns true when the tag parameter is of a type that should be trusted even
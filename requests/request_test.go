@@ -31,3 +31,23 @@ func TestTrustedTag(t *testing.T) {
 		}
 	}
 }
+
+func TestProvenanceClone(t *testing.T) {
+	req := &DNSRequest{
+		Name:   "www.owasp.org",
+		Domain: "owasp.org",
+		Provenance: &Provenance{
+			Source:   "Crtsh",
+			Evidence: map[string]string{"certificate_id": "12345"},
+		},
+	}
+
+	c := req.Clone().(*DNSRequest)
+	c.Provenance.Evidence["certificate_id"] = "54321"
+	if req.Provenance.Evidence["certificate_id"] != "12345" {
+		t.Errorf("Clone did not copy the provenance evidence")
+	}
+	if c.Provenance.Source != "Crtsh" {
+		t.Errorf("Clone returned the provenance source %s, expected Crtsh", c.Provenance.Source)
+	}
+}
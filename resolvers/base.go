@@ -172,11 +172,12 @@ func (r *baseResolver) queueQuery(ctx context.Context, msg *dns.Msg, p int) *res
 	}
 
 	req := &resolveRequest{
-		ID:     msg.Id,
-		Name:   RemoveLastDot(msg.Question[0].Name),
-		Qtype:  msg.Question[0].Qtype,
-		Msg:    msg,
-		Result: resultChan,
+		ID:       msg.Id,
+		Name:     RemoveLastDot(msg.Question[0].Name),
+		Qtype:    msg.Question[0].Qtype,
+		Msg:      msg,
+		Priority: p,
+		Result:   resultChan,
 	}
 
 	if err := r.xchgs.add(req); err != nil {
@@ -202,8 +203,13 @@ func (r *baseResolver) sendQueries() {
 			return
 		case <-r.xchgQueue.Signal():
 			if element, ok := r.xchgQueue.Next(); ok {
+				req := element.(*resolveRequest)
+				// Every packet sent is paced by the governor across all the resolvers
+				if !currentGovernor().take(req.Priority, r.done) {
+					return
+				}
 				r.rlimit.Take()
-				r.writeMessage(element.(*resolveRequest))
+				r.writeMessage(req)
 			}
 		}
 	}
@@ -338,6 +344,12 @@ func (r *baseResolver) tcpExchange(req *resolveRequest) {
 		Timeout: time.Minute,
 	}
 
+	if !currentGovernor().take(req.Priority, r.done) {
+		estr := fmt.Sprintf("DNS resolver %s has stopped", r.address)
+		r.returnRequest(req, makeResolveResult(nil, false, estr, ResolverErrRcode))
+		return
+	}
+
 	m, _, err := client.Exchange(req.Msg, r.address)
 	if err != nil {
 		estr := fmt.Sprintf("DNS: Failed to perform the exchange via TCP to %s: %v", r.address, err)
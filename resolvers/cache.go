@@ -0,0 +1,382 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// The maximum duration a response is cached, regardless of the record TTLs
+	maxCacheTTL = time.Hour
+	// How often the expired responses are removed from the cache
+	cachePurgeInterval = time.Minute
+	// The default number of responses kept before the least recently used are evicted
+	defaultMaxCacheEntries = 100000
+)
+
+type cacheEntry struct {
+	Msg     *dns.Msg
+	Rcode   int
+	Expires time.Time
+	// The position of the key in the least recently used list
+	elem *list.Element
+}
+
+// CacheResolver is a Resolver that caches the DNS responses obtained from another Resolver,
+// so the same query is not sent repeatedly during the TTL of the records in the response.
+type CacheResolver struct {
+	sync.Mutex
+	resolver       Resolver
+	entries        map[string]*cacheEntry
+	lru            *list.List
+	maxEntries     int
+	negativeTTL    time.Duration
+	path           string
+	done           chan struct{}
+	hasBeenStopped bool
+}
+
+// NewCacheResolver returns a CacheResolver that caches the responses obtained from the provided Resolver.
+// When a path is provided, unexpired responses are loaded from the file and saved to it by the Save method.
+func NewCacheResolver(r Resolver, path string) *CacheResolver {
+	c := &CacheResolver{
+		resolver:    r,
+		entries:     make(map[string]*cacheEntry),
+		lru:         list.New(),
+		maxEntries:  defaultMaxCacheEntries,
+		negativeTTL: maxCacheTTL,
+		path:        path,
+		done:        make(chan struct{}, 2),
+	}
+
+	if path != "" {
+		_ = c.load()
+	}
+
+	go c.purgeExpired()
+	return c
+}
+
+// SetNegativeTTL assigns the maximum duration that NXDOMAIN responses are cached.
+// Providing zero disables the caching of negative responses.
+func (c *CacheResolver) SetNegativeTTL(ttl time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	if ttl < 0 {
+		ttl = 0
+	}
+	c.negativeTTL = ttl
+}
+
+// SetMaxEntries assigns the maximum number of responses kept in the cache, and the least recently
+// used responses are evicted once the size is exceeded. Providing zero restores the default.
+func (c *CacheResolver) SetMaxEntries(max int) {
+	c.Lock()
+	defer c.Unlock()
+
+	if max <= 0 {
+		max = defaultMaxCacheEntries
+	}
+	c.maxEntries = max
+	c.trim()
+}
+
+// Stop implements the Resolver interface.
+func (c *CacheResolver) Stop() {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.hasBeenStopped {
+		return
+	}
+	c.hasBeenStopped = true
+
+	close(c.done)
+	c.resolver.Stop()
+}
+
+// Stopped implements the Resolver interface.
+func (c *CacheResolver) Stopped() bool {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.hasBeenStopped
+}
+
+// String implements the Stringer interface.
+func (c *CacheResolver) String() string {
+	return "CacheResolver"
+}
+
+// Query implements the Resolver interface.
+func (c *CacheResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry Retry) (*dns.Msg, error) {
+	key := cacheKey(msg)
+	if key == "" {
+		return c.resolver.Query(ctx, msg, priority, retry)
+	}
+
+	if resp, err, found := c.lookup(key, msg); found {
+		return resp, err
+	}
+
+	resp, err := c.resolver.Query(ctx, msg, priority, retry)
+	c.insert(key, resp, err)
+	return resp, err
+}
+
+// WildcardType implements the Resolver interface.
+func (c *CacheResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return c.resolver.WildcardType(ctx, msg, domain)
+}
+
+// Len returns the number of responses in the cache.
+func (c *CacheResolver) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return len(c.entries)
+}
+
+func cacheKey(msg *dns.Msg) string {
+	if msg == nil || len(msg.Question) == 0 {
+		return ""
+	}
+
+	q := msg.Question[0]
+	key := strings.ToLower(RemoveLastDot(q.Name)) + ":" + strconv.Itoa(int(q.Qtype))
+	// Responses to DNSSEC queries include additional records
+	if opt := msg.IsEdns0(); opt != nil && opt.Do() {
+		key += ":do"
+	}
+	return key
+}
+
+func (c *CacheResolver) lookup(key string, msg *dns.Msg) (*dns.Msg, error, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry, found := c.entries[key]
+	if !found {
+		return nil, nil, false
+	}
+	if time.Now().After(entry.Expires) {
+		c.remove(key)
+		return nil, nil, false
+	}
+	c.lru.MoveToFront(entry.elem)
+
+	resp := entry.Msg.Copy()
+	resp.Id = msg.Id
+	if entry.Rcode != dns.RcodeSuccess {
+		return resp, &ResolveError{
+			Err:   "The cached response was " + dns.RcodeToString[entry.Rcode],
+			Rcode: entry.Rcode,
+		}, true
+	}
+	return resp, nil, true
+}
+
+func (c *CacheResolver) insert(key string, resp *dns.Msg, err error) {
+	if resp == nil {
+		return
+	}
+
+	var ttl time.Duration
+	rcode := dns.RcodeSuccess
+	if err == nil && resp.Rcode == dns.RcodeSuccess && len(resp.Answer) > 0 {
+		ttl = answerTTL(resp.Answer)
+	} else if e, ok := err.(*ResolveError); ok && e.Rcode == dns.RcodeNameError {
+		// Negative responses are cached for the duration provided by the SOA record
+		rcode = dns.RcodeNameError
+		ttl = negativeTTL(resp)
+
+		c.Lock()
+		if ttl > c.negativeTTL {
+			ttl = c.negativeTTL
+		}
+		c.Unlock()
+	}
+	if ttl <= 0 {
+		return
+	}
+	if ttl > maxCacheTTL {
+		ttl = maxCacheTTL
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.add(key, &cacheEntry{
+		Msg:     resp.Copy(),
+		Rcode:   rcode,
+		Expires: time.Now().Add(ttl),
+	})
+}
+
+// add stores the entry as the most recently used and evicts the least recently used entries
+// beyond the size of the cache. The lock must be held by the caller.
+func (c *CacheResolver) add(key string, entry *cacheEntry) {
+	if old, found := c.entries[key]; found {
+		entry.elem = old.elem
+		c.lru.MoveToFront(entry.elem)
+	} else {
+		entry.elem = c.lru.PushFront(key)
+	}
+
+	c.entries[key] = entry
+	c.trim()
+}
+
+// trim evicts the least recently used entries beyond the size of the cache. The lock must be held by the caller.
+func (c *CacheResolver) trim() {
+	for len(c.entries) > c.maxEntries {
+		back := c.lru.Back()
+		if back == nil {
+			return
+		}
+		c.remove(back.Value.(string))
+	}
+}
+
+// remove deletes the entry from the cache. The lock must be held by the caller.
+func (c *CacheResolver) remove(key string) {
+	if entry, found := c.entries[key]; found {
+		c.lru.Remove(entry.elem)
+		delete(c.entries, key)
+	}
+}
+
+func answerTTL(rrs []dns.RR) time.Duration {
+	var min uint32
+
+	for i, rr := range rrs {
+		if ttl := rr.Header().Ttl; i == 0 || ttl < min {
+			min = ttl
+		}
+	}
+	return time.Duration(min) * time.Second
+}
+
+func negativeTTL(resp *dns.Msg) time.Duration {
+	for _, rr := range resp.Ns {
+		if soa, ok := rr.(*dns.SOA); ok {
+			ttl := soa.Hdr.Ttl
+			if soa.Minttl < ttl {
+				ttl = soa.Minttl
+			}
+			return time.Duration(ttl) * time.Second
+		}
+	}
+	return 0
+}
+
+func (c *CacheResolver) purgeExpired() {
+	t := time.NewTicker(cachePurgeInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+			now := time.Now()
+
+			c.Lock()
+			for key, entry := range c.entries {
+				if now.After(entry.Expires) {
+					c.remove(key)
+				}
+			}
+			c.Unlock()
+		}
+	}
+}
+
+type cacheFileEntry struct {
+	Key     string    `json:"key"`
+	Msg     []byte    `json:"msg"`
+	Rcode   int       `json:"rcode"`
+	Expires time.Time `json:"expires"`
+}
+
+// Save writes the unexpired responses to the file provided when the CacheResolver was created.
+func (c *CacheResolver) Save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	now := time.Now()
+	var entries []*cacheFileEntry
+	c.Lock()
+	for key, entry := range c.entries {
+		if now.After(entry.Expires) {
+			continue
+		}
+
+		if data, err := entry.Msg.Pack(); err == nil {
+			entries = append(entries, &cacheFileEntry{
+				Key:     key,
+				Msg:     data,
+				Rcode:   entry.Rcode,
+				Expires: entry.Expires,
+			})
+		}
+	}
+	c.Unlock()
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, data, 0644)
+}
+
+func (c *CacheResolver) load() error {
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	var entries []*cacheFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	c.Lock()
+	defer c.Unlock()
+
+	for _, entry := range entries {
+		if now.After(entry.Expires) {
+			continue
+		}
+
+		msg := new(dns.Msg)
+		if err := msg.Unpack(entry.Msg); err != nil {
+			continue
+		}
+		c.add(entry.Key, &cacheEntry{
+			Msg:     msg,
+			Rcode:   entry.Rcode,
+			Expires: entry.Expires,
+		})
+	}
+	return nil
+}
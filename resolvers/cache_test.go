@@ -0,0 +1,164 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// countingResolver answers with the provided TTL and counts the queries it receives.
+type countingResolver struct {
+	answerResolver
+	ttl     uint32
+	queries int
+}
+
+func (c *countingResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry Retry) (*dns.Msg, error) {
+	c.queries++
+
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+	if msg.Question[0].Name == "missing.example.com." {
+		resp.Rcode = dns.RcodeNameError
+		resp.Ns = append(resp.Ns, &dns.SOA{
+			Hdr:    dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+			Minttl: c.ttl,
+		})
+		return resp, &ResolveError{Err: "NXDOMAIN", Rcode: dns.RcodeNameError}
+	}
+
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: c.ttl},
+		A:   net.ParseIP("192.0.2.1"),
+	})
+	return resp, nil
+}
+
+func TestCacheResolver(t *testing.T) {
+	r := &countingResolver{ttl: 300}
+	c := NewCacheResolver(r, "")
+	defer c.Stop()
+
+	for i := 0; i < 3; i++ {
+		msg := QueryMsg("WWW.example.com", dns.TypeA)
+		resp, err := c.Query(context.Background(), msg, PriorityNormal, nil)
+		if err != nil || len(resp.Answer) != 1 {
+			t.Errorf("The cache resolver did not return the answer: %v", err)
+		} else if resp.Id != msg.Id {
+			t.Errorf("The cached response did not match the query message ID")
+		}
+	}
+	if r.queries != 1 {
+		t.Errorf("The cache resolver sent %d queries for the same name, expected 1", r.queries)
+	}
+
+	_, _ = c.Query(context.Background(), QueryMsg("www.example.com", dns.TypeAAAA), PriorityNormal, nil)
+	if r.queries != 2 {
+		t.Errorf("The cache resolver did not send the query for another record type")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Query(context.Background(), QueryMsg("missing.example.com", dns.TypeA), PriorityNormal, nil)
+		if e, ok := err.(*ResolveError); !ok || e.Rcode != dns.RcodeNameError {
+			t.Errorf("The cache resolver did not return the NXDOMAIN response")
+		}
+	}
+	if r.queries != 3 {
+		t.Errorf("The cache resolver did not cache the negative response")
+	}
+
+	// Responses with a zero TTL are never cached
+	zero := &countingResolver{ttl: 0}
+	zc := NewCacheResolver(zero, "")
+	defer zc.Stop()
+
+	for i := 0; i < 2; i++ {
+		_, _ = zc.Query(context.Background(), QueryMsg("www.example.com", dns.TypeA), PriorityNormal, nil)
+	}
+	if zero.queries != 2 || zc.Len() != 0 {
+		t.Errorf("The cache resolver cached a response with a zero TTL")
+	}
+}
+
+func TestCacheResolverNegativeTTL(t *testing.T) {
+	r := &countingResolver{ttl: 300}
+	c := NewCacheResolver(r, "")
+	defer c.Stop()
+
+	// Negative caching is disabled by a zero duration
+	c.SetNegativeTTL(0)
+	for i := 0; i < 2; i++ {
+		_, _ = c.Query(context.Background(), QueryMsg("missing.example.com", dns.TypeA), PriorityNormal, nil)
+	}
+	if r.queries != 2 || c.Len() != 0 {
+		t.Errorf("The cache resolver cached the negative response after it was disabled")
+	}
+
+	c.SetNegativeTTL(time.Minute)
+	_, _ = c.Query(context.Background(), QueryMsg("missing.example.com", dns.TypeA), PriorityNormal, nil)
+
+	c.Lock()
+	entry := c.entries[cacheKey(QueryMsg("missing.example.com", dns.TypeA))]
+	c.Unlock()
+	if entry == nil || time.Until(entry.Expires) > time.Minute {
+		t.Errorf("The negative response was not cached for the configured duration")
+	}
+}
+
+func TestCacheResolverMaxEntries(t *testing.T) {
+	r := &countingResolver{ttl: 300}
+	c := NewCacheResolver(r, "")
+	defer c.Stop()
+	c.SetMaxEntries(2)
+
+	query := func(name string) {
+		_, _ = c.Query(context.Background(), QueryMsg(name, dns.TypeA), PriorityNormal, nil)
+	}
+
+	query("first.example.com")
+	query("second.example.com")
+	// The first response becomes the most recently used
+	query("first.example.com")
+	query("third.example.com")
+	if c.Len() != 2 {
+		t.Errorf("The cache contains %d responses, expected the maximum of 2", c.Len())
+	}
+
+	r.queries = 0
+	query("first.example.com")
+	query("third.example.com")
+	if r.queries != 0 {
+		t.Errorf("The recently used responses were evicted from the cache")
+	}
+	query("second.example.com")
+	if r.queries != 1 {
+		t.Errorf("The least recently used response was not evicted from the cache")
+	}
+}
+
+func TestCacheResolverPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dns_cache.json")
+
+	first := NewCacheResolver(&countingResolver{ttl: 300}, path)
+	_, _ = first.Query(context.Background(), QueryMsg("www.example.com", dns.TypeA), PriorityNormal, nil)
+	if err := first.Save(); err != nil {
+		t.Fatalf("Failed to save the cached responses: %v", err)
+	}
+	first.Stop()
+
+	r := &countingResolver{ttl: 300}
+	second := NewCacheResolver(r, path)
+	defer second.Stop()
+
+	resp, err := second.Query(context.Background(), QueryMsg("www.example.com", dns.TypeA), PriorityNormal, nil)
+	if err != nil || len(resp.Answer) != 1 || r.queries != 0 {
+		t.Errorf("The saved response was not loaded from the cache file")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// How long a resolver returning wrong data for a canary name is not used
+	quarantineDuration time.Duration = 10 * time.Minute
+	// The number of failed canary checks that causes the resolver to be considered poisoned
+	maxCanaryFailures int = 3
+)
+
+// DefaultCanaryRecords are names with well-known addresses that rarely change,
+// which are resolved to detect resolvers that are poisoned or intercepted.
+var DefaultCanaryRecords = map[string][]string{
+	"a.root-servers.net": {"198.41.0.4"},
+	"c.root-servers.net": {"192.33.4.12"},
+	"k.root-servers.net": {"193.0.14.129"},
+	"m.root-servers.net": {"202.12.27.33"},
+}
+
+var canaryRecords = struct {
+	sync.Mutex
+	records map[string][]string
+}{records: DefaultCanaryRecords}
+
+// SetCanaryRecords assigns the names and known addresses resolved through each resolver to detect
+// poisoning and interception. Providing no records restores the defaults.
+func SetCanaryRecords(records map[string][]string) error {
+	recs := make(map[string][]string, len(records))
+
+	for name, addrs := range records {
+		name = strings.ToLower(RemoveLastDot(strings.TrimSpace(name)))
+		if _, ok := dns.IsDomainName(name); !ok || name == "" {
+			return fmt.Errorf("The canary name %s is not valid", name)
+		}
+
+		for _, addr := range addrs {
+			ip := net.ParseIP(strings.TrimSpace(addr))
+			if ip == nil {
+				return fmt.Errorf("The canary address %s for %s is not valid", addr, name)
+			}
+
+			recs[name] = append(recs[name], ip.String())
+		}
+		if len(recs[name]) == 0 {
+			return fmt.Errorf("The canary name %s has no known addresses", name)
+		}
+	}
+	if len(recs) == 0 {
+		recs = DefaultCanaryRecords
+	}
+
+	canaryRecords.Lock()
+	defer canaryRecords.Unlock()
+
+	canaryRecords.records = recs
+	return nil
+}
+
+// AddInterceptionHandler implements the InterceptionReporter interface.
+func (rp *resolverPool) AddInterceptionHandler(h func(msg string)) func() {
+	rp.Lock()
+	id := rp.nextHandlerID
+	rp.nextHandlerID++
+	rp.interceptHandlers[id] = h
+	rp.Unlock()
+
+	// The baseline resolvers are checked for interception as well
+	var removeBaseline func()
+	if ir, ok := rp.baseline.(InterceptionReporter); ok {
+		removeBaseline = ir.AddInterceptionHandler(h)
+	}
+
+	return func() {
+		rp.Lock()
+		delete(rp.interceptHandlers, id)
+		rp.Unlock()
+
+		if removeBaseline != nil {
+			removeBaseline()
+		}
+	}
+}
+
+// reportInterception delivers the message to the registered interception handlers, or the pool log when none are registered.
+func (rp *resolverPool) reportInterception(msg string) {
+	rp.Lock()
+	handlers := make([]func(msg string), 0, len(rp.interceptHandlers))
+	for _, h := range rp.interceptHandlers {
+		handlers = append(handlers, h)
+	}
+	rp.Unlock()
+
+	if len(handlers) == 0 {
+		rp.log.Print(msg)
+		return
+	}
+	for _, h := range handlers {
+		h(msg)
+	}
+}
+
+// randomCanary returns a canary name and the known addresses for the name.
+func randomCanary() (string, []string) {
+	canaryRecords.Lock()
+	defer canaryRecords.Unlock()
+
+	names := make([]string, 0, len(canaryRecords.records))
+	for name := range canaryRecords.records {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	name := names[rand.Intn(len(names))]
+	return name, canaryRecords.records[name]
+}
+
+// canaryCheck resolves a canary name through the resolver and quarantines the resolver when the
+// known addresses are missing from the answers. Repeated failures mark the resolver as poisoned.
+func (rp *resolverPool) canaryCheck(ctx context.Context, r Resolver) {
+	name, known := randomCanary()
+	if name == "" || r.Stopped() {
+		return
+	}
+
+	qtype := dns.TypeA
+	if ip := net.ParseIP(known[0]); ip.To4() == nil {
+		qtype = dns.TypeAAAA
+	}
+
+	resp, err := r.Query(ctx, QueryMsg(name, qtype), PriorityCritical, nil)
+	if err != nil {
+		// Failures to respond are handled by the resolver score, but a resolver
+		// claiming the name does not exist is providing wrong data
+		if e, ok := err.(*ResolveError); !ok || e.Rcode != dns.RcodeNameError {
+			return
+		}
+	} else if resp == nil || canaryAnswered(resp, qtype, known) {
+		rp.Lock()
+		delete(rp.canaryFails, r.String())
+		rp.Unlock()
+		return
+	}
+
+	k := r.String()
+	rp.Lock()
+	rp.canaryFails[k]++
+	fails := rp.canaryFails[k]
+	rp.Unlock()
+
+	rp.updateWait(k, quarantineDuration)
+	rp.reportInterception(fmt.Sprintf("Resolver %s returned wrong data for the canary name %s and was quarantined: suspected DNS interception", k, name))
+	if fails >= maxCanaryFailures {
+		rp.scores.poisoned(k)
+	}
+}
+
+// canaryAnswered returns true when the response includes one of the known addresses.
+func canaryAnswered(resp *dns.Msg, qtype uint16, known []string) bool {
+	for _, a := range AnswersByType(ExtractAnswers(resp), qtype) {
+		ip := net.ParseIP(a.Data)
+
+		for _, addr := range known {
+			if ip != nil && ip.Equal(net.ParseIP(addr)) {
+				return true
+			}
+		}
+	}
+	return false
+}
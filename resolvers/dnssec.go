@@ -0,0 +1,61 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// ZoneDenialType returns the record type used by the DNSSEC-signed zone to deny the existence of names.
+// The returned type is dns.TypeNSEC or dns.TypeNSEC3, or zero when the zone is not signed.
+func ZoneDenialType(ctx context.Context, r Resolver, domain string, priority int) (uint16, error) {
+	if r.Stopped() {
+		return 0, errors.New("Resolver: The resolver has been stopped")
+	}
+
+	domain = strings.ToLower(RemoveLastDot(domain))
+	// Signed zones publish the DNSKEY records at the apex
+	resp, err := r.Query(ctx, WalkMsg(domain, dns.TypeDNSKEY), priority, RetryPolicy)
+	if err != nil {
+		return 0, fmt.Errorf("ZoneDenialType: Resolver %s: %v", r.String(), err)
+	}
+
+	var signed bool
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype == dns.TypeDNSKEY {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return 0, nil
+	}
+
+	// The authority section of a negative response reveals the denial of existence records
+	resp, err = r.Query(ctx, WalkMsg(randomLabel()+"."+domain, dns.TypeA), priority, RetryPolicy)
+	if !negativeResponse(resp, err) {
+		return 0, fmt.Errorf("ZoneDenialType: Resolver %s: %v", r.String(), err)
+	}
+
+	for _, rr := range resp.Ns {
+		switch rr.Header().Rrtype {
+		case dns.TypeNSEC, dns.TypeNSEC3:
+			return rr.Header().Rrtype, nil
+		}
+	}
+	return 0, fmt.Errorf("ZoneDenialType: Resolver %s: %s is signed, but NSEC records were not returned", r.String(), domain)
+}
+
+// negativeResponse returns true when the response can contain the denial of existence records.
+func negativeResponse(resp *dns.Msg, err error) bool {
+	if resp == nil {
+		return false
+	}
+	return err == nil || resp.Rcode == dns.RcodeNameError
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// zoneResolver answers the queries for a zone using the provided denial of existence record type.
+type zoneResolver struct {
+	signed bool
+	denial uint16
+}
+
+func (z *zoneResolver) String() string { return "zone" }
+func (z *zoneResolver) Stop()          {}
+func (z *zoneResolver) Stopped() bool  { return false }
+func (z *zoneResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return WildcardTypeNone
+}
+
+func (z *zoneResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	name := msg.Question[0].Name
+	if msg.Question[0].Qtype == dns.TypeDNSKEY {
+		if z.signed {
+			resp.Answer = append(resp.Answer, &dns.DNSKEY{
+				Hdr:       dns.RR_Header{Name: name, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET},
+				Flags:     257,
+				Protocol:  3,
+				Algorithm: dns.RSASHA256,
+			})
+		}
+		return resp, nil
+	}
+
+	resp.Rcode = dns.RcodeNameError
+	switch z.denial {
+	case dns.TypeNSEC:
+		resp.Ns = append(resp.Ns, &dns.NSEC{
+			Hdr:        dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeNSEC, Class: dns.ClassINET},
+			NextDomain: "www.example.com.",
+		})
+	case dns.TypeNSEC3:
+		resp.Ns = append(resp.Ns, &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: "2vptu5timamqttgl4luu9kg21e0aor3s.example.com.", Rrtype: dns.TypeNSEC3, Class: dns.ClassINET},
+			Hash:       dns.SHA1,
+			NextDomain: "35mthgpgcu1qg68fab165klnsnk3dpvl",
+		})
+	}
+	return resp, &ResolveError{Err: "NXDOMAIN", Rcode: dns.RcodeNameError}
+}
+
+func TestZoneDenialType(t *testing.T) {
+	tests := []struct {
+		r        *zoneResolver
+		expected uint16
+		err      bool
+	}{
+		{&zoneResolver{}, 0, false},
+		{&zoneResolver{signed: true, denial: dns.TypeNSEC}, dns.TypeNSEC, false},
+		{&zoneResolver{signed: true, denial: dns.TypeNSEC3}, dns.TypeNSEC3, false},
+		{&zoneResolver{signed: true}, 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := ZoneDenialType(context.Background(), test.r, "example.com", PriorityHigh)
+		if (err != nil) != test.err {
+			t.Errorf("ZoneDenialType returned the unexpected error result: %v", err)
+		}
+		if got != test.expected {
+			t.Errorf("ZoneDenialType returned %d, expected %d", got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// The name queried to check if a nameserver provides recursion for names outside of its zones
+const recursionTestName = "example.com"
+
+// NameserverFingerprint contains the information obtained about the software and configuration of a nameserver.
+type NameserverFingerprint struct {
+	Version   string
+	Hostname  string
+	ServerID  string
+	Software  string
+	Recursive bool
+}
+
+// The software identified by keywords in the version.bind responses
+var nameserverSoftware = []struct {
+	Keyword  string
+	Software string
+}{
+	{"powerdns", "PowerDNS"},
+	{"unbound", "Unbound"},
+	{"nsd", "NSD"},
+	{"knot", "Knot DNS"},
+	{"dnsmasq", "dnsmasq"},
+	{"microsoft", "Microsoft DNS"},
+	{"windows", "Microsoft DNS"},
+	{"yadifa", "YADIFA"},
+	{"coredns", "CoreDNS"},
+	{"gdnsd", "gdnsd"},
+	{"bind", "BIND"},
+}
+
+// FingerprintNameserver queries the nameserver for the CHAOS class identification names
+// (version.bind, hostname.bind and id.server) and checks if it provides open recursion.
+func FingerprintNameserver(ctx context.Context, r Resolver, priority int) *NameserverFingerprint {
+	fp := &NameserverFingerprint{
+		Version:  chaosTXT(ctx, r, "version.bind", priority),
+		Hostname: chaosTXT(ctx, r, "hostname.bind", priority),
+		ServerID: chaosTXT(ctx, r, "id.server", priority),
+	}
+
+	fp.Software = NameserverSoftware(fp.Version)
+	fp.Recursive = openRecursion(ctx, r, priority)
+	return fp
+}
+
+// NameserverSoftware returns the name of the nameserver software identified by the version string.
+func NameserverSoftware(version string) string {
+	v := strings.ToLower(strings.TrimSpace(version))
+	if v == "" {
+		return ""
+	}
+
+	for _, s := range nameserverSoftware {
+		if strings.Contains(v, s.Keyword) {
+			return s.Software
+		}
+	}
+	// BIND reports only the version number by default, e.g. 9.16.1-Ubuntu
+	if strings.HasPrefix(v, "9.") {
+		return "BIND"
+	}
+	return ""
+}
+
+func chaosTXT(ctx context.Context, r Resolver, name string, priority int) string {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), dns.TypeTXT)
+	msg.Question[0].Qclass = dns.ClassCHAOS
+	msg.RecursionDesired = false
+
+	resp, err := r.Query(ctx, msg, priority, nil)
+	if err != nil || resp == nil {
+		return ""
+	}
+
+	for _, rr := range resp.Answer {
+		if t, ok := rr.(*dns.TXT); ok {
+			return strings.TrimSpace(strings.Join(t.Txt, " "))
+		}
+	}
+	return ""
+}
+
+func openRecursion(ctx context.Context, r Resolver, priority int) bool {
+	resp, err := r.Query(ctx, QueryMsg(recursionTestName, dns.TypeA), priority, nil)
+	if err != nil || resp == nil {
+		return false
+	}
+	// An authoritative answer is not the result of recursion
+	return resp.RecursionAvailable && !resp.Authoritative && len(resp.Answer) > 0
+}
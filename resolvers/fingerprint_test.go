@@ -0,0 +1,84 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// chaosResolver answers the CHAOS class identification queries and provides open recursion.
+type chaosResolver struct {
+	answerResolver
+}
+
+func (c *chaosResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+	resp.RecursionAvailable = true
+
+	q := msg.Question[0]
+	if q.Qclass == dns.ClassCHAOS {
+		if q.Name != "version.bind." {
+			resp.Rcode = dns.RcodeRefused
+			return resp, &ResolveError{Err: "REFUSED", Rcode: dns.RcodeRefused}
+		}
+
+		resp.Answer = append(resp.Answer, &dns.TXT{
+			Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeTXT, Class: dns.ClassCHAOS},
+			Txt: []string{"9.16.1-Ubuntu"},
+		})
+		return resp, nil
+	}
+
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET},
+		A:   net.ParseIP("192.0.2.1"),
+	})
+	return resp, nil
+}
+
+func TestFingerprintNameserver(t *testing.T) {
+	fp := FingerprintNameserver(context.Background(), &chaosResolver{}, PriorityNormal)
+
+	if fp.Version != "9.16.1-Ubuntu" || fp.Software != "BIND" {
+		t.Errorf("The nameserver version was not identified: %+v", fp)
+	}
+	if fp.Hostname != "" || fp.ServerID != "" {
+		t.Errorf("The refused identification queries provided data: %+v", fp)
+	}
+	if !fp.Recursive {
+		t.Errorf("The open recursion was not detected")
+	}
+
+	closed := FingerprintNameserver(context.Background(), &answerResolver{}, PriorityNormal)
+	if closed.Recursive || closed.Version != "" || closed.Software != "" {
+		t.Errorf("The nameserver without answers was fingerprinted: %+v", closed)
+	}
+}
+
+func TestNameserverSoftware(t *testing.T) {
+	tests := []struct {
+		Version  string
+		Expected string
+	}{
+		{"PowerDNS Authoritative Server 4.4.0", "PowerDNS"},
+		{"unbound 1.13.1", "Unbound"},
+		{"NSD 4.3.5", "NSD"},
+		{"Knot DNS 3.0.5", "Knot DNS"},
+		{"9.11.4-P2-RedHat-9.11.4-26.P2.el7", "BIND"},
+		{"Microsoft DNS 6.1.7601 (1DB15D39)", "Microsoft DNS"},
+		{"none of your business", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if s := NameserverSoftware(test.Version); s != test.Expected {
+			t.Errorf("Identified %s as %s, expected %s", test.Version, s, test.Expected)
+		}
+	}
+}
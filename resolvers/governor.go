@@ -0,0 +1,244 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"io/ioutil"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// How often the governor evaluates the outcomes of the queries and adjusts the rate
+	governorInterval = 5 * time.Second
+	// The fraction of failed queries that causes the governor to back off
+	governorFailureThreshold = 0.25
+	// The minimum number of queries required before the failure rate is evaluated
+	governorMinQueries = 20
+	// The rate is never reduced below this fraction of the maximum rate
+	governorMinFraction = 0.1
+)
+
+var (
+	governorLock  sync.Mutex
+	queryGovernor *Governor
+)
+
+// SetGovernor assigns the Governor that paces every DNS query packet sent by the resolvers,
+// including the retries and the queries sent to the baseline resolvers. Nil removes the limit.
+func SetGovernor(g *Governor) {
+	governorLock.Lock()
+	defer governorLock.Unlock()
+
+	queryGovernor = g
+}
+
+func currentGovernor() *Governor {
+	governorLock.Lock()
+	defer governorLock.Unlock()
+
+	return queryGovernor
+}
+
+// Governor limits the total queries per second sent by the resolvers using a token bucket.
+// The rate backs off when the timeout and SERVFAIL rates spike, and recovers toward the
+// max rate once the failures subside. Queries with higher priorities are served first.
+type Governor struct {
+	sync.Mutex
+	// Logger for error messages
+	log      *log.Logger
+	max      float64
+	rate     float64
+	tokens   float64
+	last     time.Time
+	queries  int
+	failures int
+	waiting  [PriorityCritical + 1]int
+	done     chan struct{}
+	stopped  bool
+}
+
+// NewGovernor returns a Governor that permits at most max queries per second.
+// Nil is returned when max does not set a limit.
+func NewGovernor(max int, logger *log.Logger) *Governor {
+	if max <= 0 {
+		return nil
+	}
+
+	g := &Governor{
+		log:    logger,
+		max:    float64(max),
+		rate:   float64(max),
+		tokens: 1,
+		last:   time.Now(),
+		done:   make(chan struct{}, 2),
+	}
+
+	// Assign a null logger when one is not provided
+	if g.log == nil {
+		g.log = log.New(ioutil.Discard, "", 0)
+	}
+
+	go g.adjustRate()
+	return g
+}
+
+// Stop releases the queries waiting on the Governor and ends the rate adjustments.
+func (g *Governor) Stop() {
+	if g == nil {
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if g.stopped {
+		return
+	}
+	g.stopped = true
+	close(g.done)
+}
+
+// take blocks until a token is available for the priority, or the done channel is closed.
+// Tokens are not provided while queries with a higher priority are waiting.
+func (g *Governor) take(priority int, done <-chan struct{}) bool {
+	if g == nil {
+		return true
+	}
+	if priority < PriorityLow || priority > PriorityCritical {
+		priority = PriorityNormal
+	}
+
+	var registered bool
+	defer func() {
+		if registered {
+			g.Lock()
+			g.waiting[priority]--
+			g.Unlock()
+		}
+	}()
+
+	for {
+		g.Lock()
+		if g.stopped {
+			g.Unlock()
+			return true
+		}
+
+		now := time.Now()
+		// The bucket holds at most a tenth of a second worth of tokens
+		burst := g.rate / 10
+		if burst < 1 {
+			burst = 1
+		}
+
+		g.tokens += now.Sub(g.last).Seconds() * g.rate
+		if g.tokens > burst {
+			g.tokens = burst
+		}
+		g.last = now
+
+		var higher bool
+		for p := priority + 1; p <= PriorityCritical; p++ {
+			if g.waiting[p] > 0 {
+				higher = true
+				break
+			}
+		}
+
+		if g.tokens >= 1 && !higher {
+			g.tokens--
+			g.Unlock()
+			return true
+		}
+		if !registered {
+			registered = true
+			g.waiting[priority]++
+		}
+
+		wait := time.Millisecond
+		if g.tokens < 1 {
+			if d := time.Duration((1 - g.tokens) / g.rate * float64(time.Second)); d > wait {
+				wait = d
+			}
+		}
+		g.Unlock()
+
+		t := time.NewTimer(wait)
+		select {
+		case <-done:
+			t.Stop()
+			return false
+		case <-g.done:
+			t.Stop()
+			return true
+		case <-t.C:
+		}
+	}
+}
+
+// record counts the outcome of a query sent after obtaining a token.
+func (g *Governor) record(err error) {
+	if g == nil {
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	g.queries++
+	if e, ok := err.(*ResolveError); ok && (e.Rcode == TimeoutRcode || e.Rcode == dns.RcodeServerFailure) {
+		g.failures++
+	}
+}
+
+func (g *Governor) adjustRate() {
+	t := time.NewTicker(governorInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-g.done:
+			return
+		case <-t.C:
+			g.adjust()
+		}
+	}
+}
+
+// adjust performs a multiplicative decrease of the rate when the failures spike,
+// and an additive increase toward the maximum rate otherwise.
+func (g *Governor) adjust() {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.queries < governorMinQueries {
+		return
+	}
+
+	prev := g.rate
+	if float64(g.failures)/float64(g.queries) > governorFailureThreshold {
+		g.rate /= 2
+		if min := g.max * governorMinFraction; g.rate < min {
+			g.rate = min
+		}
+		if g.rate < 1 {
+			g.rate = 1
+		}
+	} else if g.rate < g.max {
+		g.rate += g.max * governorMinFraction
+		if g.rate > g.max {
+			g.rate = g.max
+		}
+	}
+	g.queries = 0
+	g.failures = 0
+
+	if g.rate < prev {
+		g.log.Printf("The DNS query rate was reduced to %d queries per second due to failures", int(g.rate))
+	}
+}
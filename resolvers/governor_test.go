@@ -0,0 +1,137 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestGovernorRate(t *testing.T) {
+	g := NewGovernor(50, nil)
+	defer g.Stop()
+
+	start := time.Now()
+	for i := 0; i < 15; i++ {
+		g.take(PriorityNormal, nil)
+	}
+	// The bucket provides five tokens, and the remaining queries are sent at fifty per second
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("The governor permitted 15 queries in %v, exceeding the rate", elapsed)
+	}
+
+	done := make(chan struct{})
+	close(done)
+	time.Sleep(100 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		if !g.take(PriorityNormal, done) {
+			return
+		}
+	}
+	t.Errorf("The governor did not give up after the done channel was closed")
+}
+
+func TestGovernorPriority(t *testing.T) {
+	g := NewGovernor(20, nil)
+	defer g.Stop()
+	// Empty the bucket
+	g.take(PriorityNormal, nil)
+
+	var lock sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	take := func(p int) {
+		defer wg.Done()
+
+		g.take(p, nil)
+		lock.Lock()
+		order = append(order, p)
+		lock.Unlock()
+	}
+
+	wg.Add(2)
+	go take(PriorityLow)
+	time.Sleep(10 * time.Millisecond)
+	go take(PriorityCritical)
+	wg.Wait()
+
+	if len(order) != 2 || order[0] != PriorityCritical {
+		t.Errorf("The governor did not serve the higher priority first: %v", order)
+	}
+}
+
+func TestGovernorBackoff(t *testing.T) {
+	g := NewGovernor(1000, nil)
+	defer g.Stop()
+
+	record := func(n int, err error) {
+		for i := 0; i < n; i++ {
+			g.record(err)
+		}
+	}
+	timeout := &ResolveError{Err: "Timeout", Rcode: TimeoutRcode}
+
+	record(governorMinQueries, timeout)
+	g.adjust()
+	if g.rate != 500 {
+		t.Errorf("The governor did not back off after the failures: %f", g.rate)
+	}
+
+	for i := 0; i < 10; i++ {
+		record(governorMinQueries, timeout)
+		g.adjust()
+	}
+	if g.rate != 100 {
+		t.Errorf("The governor reduced the rate below the minimum: %f", g.rate)
+	}
+
+	record(governorMinQueries, nil)
+	g.adjust()
+	if g.rate != 200 {
+		t.Errorf("The governor did not increase the rate after the failures subsided: %f", g.rate)
+	}
+}
+
+func TestGovernorEveryPacket(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen for the DNS queries: %v", err)
+	}
+
+	srv := &dns.Server{
+		PacketConn: pc,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetRcode(req, dns.RcodeServerFailure)
+			_ = w.WriteMsg(m)
+		}),
+	}
+	go func() { _ = srv.ActivateAndServe() }()
+	defer func() { _ = srv.Shutdown() }()
+
+	g := NewGovernor(1000, nil)
+	defer g.Stop()
+	SetGovernor(g)
+	defer SetGovernor(nil)
+
+	r := NewBaseResolver(pc.LocalAddr().String(), 100, nil)
+	if r == nil {
+		t.Fatalf("Failed to create the resolver")
+	}
+	defer r.Stop()
+
+	retry := func(times, priority int, msg *dns.Msg) bool { return times < 3 }
+	_, _ = r.Query(context.Background(), QueryMsg("www.example.com", dns.TypeA), PriorityNormal, retry)
+
+	g.Lock()
+	defer g.Unlock()
+	if g.queries != 3 || g.failures != 3 {
+		t.Errorf("The governor counted %d queries and %d failures, expected 3 of each", g.queries, g.failures)
+	}
+}
@@ -34,3 +34,10 @@ type Resolver interface {
 	// WildcardType returns the DNS wildcard type for the FQDN in the provided message.
 	WildcardType(ctx context.Context, msg *dns.Msg, domain string) int
 }
+
+// InterceptionReporter is implemented by the Resolvers that detect resolvers suspected of DNS interception.
+type InterceptionReporter interface {
+	// AddInterceptionHandler registers a function receiving the messages about resolvers
+	// suspected of DNS interception, and returns the function removing the registration.
+	AddInterceptionHandler(h func(msg string)) func()
+}
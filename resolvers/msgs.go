@@ -4,8 +4,11 @@
 package resolvers
 
 import (
+	"fmt"
 	"net"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/miekg/dns"
@@ -46,15 +49,63 @@ func WalkMsg(name string, qtype uint16) *dns.Msg {
 	return m
 }
 
-// SetupOptions returns the EDNS0_SUBNET option for hiding our location.
+// The client subnet provided in the EDNS0_SUBNET option of the queries
+var clientSubnet struct {
+	sync.Mutex
+	family  uint16
+	netmask uint8
+	addr    net.IP
+}
+
+func init() {
+	_ = SetClientSubnet("")
+}
+
+// SetClientSubnet assigns the netblock provided in the EDNS0_SUBNET option of the queries.
+// CDNs can use the netblock to provide geo-targeted answers, and an empty string
+// zeroes out the option so our network location is not revealed.
+func SetClientSubnet(cidr string) error {
+	family := uint16(1)
+	var netmask uint8
+	addr := net.IPv4zero.To4()
+
+	if cidr != "" {
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("Failed to parse the client subnet %s: %v", cidr, err)
+		}
+
+		ones, _ := ipnet.Mask.Size()
+		netmask = uint8(ones)
+		addr = ipnet.IP
+		if ip4 := ipnet.IP.To4(); ip4 != nil {
+			addr = ip4
+		} else {
+			family = 2
+		}
+	}
+
+	clientSubnet.Lock()
+	defer clientSubnet.Unlock()
+
+	clientSubnet.family = family
+	clientSubnet.netmask = netmask
+	clientSubnet.addr = addr
+	return nil
+}
+
+// SetupOptions returns the EDNS0_SUBNET option providing the configured client subnet,
+// which hides our location by default.
 func SetupOptions() *dns.OPT {
+	clientSubnet.Lock()
 	e := &dns.EDNS0_SUBNET{
 		Code:          dns.EDNS0SUBNET,
-		Family:        1,
-		SourceNetmask: 0,
+		Family:        clientSubnet.family,
+		SourceNetmask: clientSubnet.netmask,
 		SourceScope:   0,
-		Address:       net.ParseIP("0.0.0.0").To4(),
+		Address:       clientSubnet.addr,
 	}
+	clientSubnet.Unlock()
 
 	return &dns.OPT{
 		Hdr: dns.RR_Header{
@@ -144,7 +195,7 @@ func ExtractAnswers(msg *dns.Msg) []*ExtractedAnswer {
 			}
 		case dns.TypeSOA:
 			if t, ok := a.(*dns.SOA); ok {
-				value = t.Ns + "," + t.Mbox
+				value = soaData(t)
 			}
 		case dns.TypeSPF:
 			if t, ok := a.(*dns.SPF); ok {
@@ -158,6 +209,30 @@ func ExtractAnswers(msg *dns.Msg) []*ExtractedAnswer {
 					value = name
 				}
 			}
+		case dns.TypeDNAME:
+			if t, ok := a.(*dns.DNAME); ok {
+				name := RemoveLastDot(t.Target)
+
+				if _, ok := dns.IsDomainName(name); ok {
+					value = name
+				}
+			}
+		case dns.TypeNAPTR:
+			if t, ok := a.(*dns.NAPTR); ok {
+				name := RemoveLastDot(t.Replacement)
+				// Replacement fields set to the root provide the result using the regular expression
+				if _, ok := dns.IsDomainName(name); ok && name != "" {
+					value = name
+				}
+			}
+		case dns.TypeTLSA:
+			if t, ok := a.(*dns.TLSA); ok {
+				value = tlsaData(t)
+			}
+		case dns.TypeCAA:
+			if t, ok := a.(*dns.CAA); ok && t.Tag != "" {
+				value = strings.ToLower(t.Tag) + " " + t.Value
+			}
 		}
 
 		if value != "" {
@@ -172,6 +247,65 @@ func ExtractAnswers(msg *dns.Msg) []*ExtractedAnswer {
 	return data
 }
 
+func tlsaData(tlsa *dns.TLSA) string {
+	return fmt.Sprintf("%d %d %d %s", tlsa.Usage, tlsa.Selector, tlsa.MatchingType, strings.ToLower(tlsa.Certificate))
+}
+
+func soaData(soa *dns.SOA) string {
+	return soa.Ns + "," + soa.Mbox + "," + strconv.FormatUint(uint64(soa.Serial), 10)
+}
+
+// ParseSOAData returns the primary nameserver, the responsible mailbox and the serial number
+// from the SOA record data extracted from the answers.
+func ParseSOAData(data string) (string, string, uint32, error) {
+	pieces := strings.Split(strings.TrimSpace(data), ",")
+	if len(pieces) != 3 {
+		return "", "", 0, fmt.Errorf("ParseSOAData: %s is not valid SOA record data", data)
+	}
+
+	serial, err := strconv.ParseUint(pieces[2], 10, 32)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("ParseSOAData: %s contains an invalid serial number", data)
+	}
+
+	ns := strings.ToLower(RemoveLastDot(pieces[0]))
+	mbox := strings.ToLower(RemoveLastDot(pieces[1]))
+	return ns, mbox, uint32(serial), nil
+}
+
+// IsServiceName returns true when the name identifies a service using the underscore labels, e.g. _sip._udp.example.com.
+func IsServiceName(name string) bool {
+	labels := strings.SplitN(RemoveLastDot(name), ".", 3)
+
+	return len(labels) == 3 && strings.HasPrefix(labels[0], "_") && strings.HasPrefix(labels[1], "_")
+}
+
+// TLSAHost returns the host name from the owner name of a TLSA record, e.g. _443._tcp.www.example.com.
+func TLSAHost(name string) string {
+	if !IsServiceName(name) {
+		return ""
+	}
+	return strings.SplitN(RemoveLastDot(name), ".", 3)[2]
+}
+
+// CAAIssuers returns the certificate authority domain names authorized by the CAA record data extracted from the answers.
+func CAAIssuers(records []string) []string {
+	var issuers []string
+
+	for _, rec := range records {
+		parts := strings.SplitN(strings.TrimSpace(rec), " ", 2)
+		if len(parts) != 2 || (parts[0] != "issue" && parts[0] != "issuewild") {
+			continue
+		}
+		// Parameters follow the issuer domain name, e.g. letsencrypt.org; validationmethods=dns-01
+		issuer := strings.ToLower(strings.TrimSpace(strings.SplitN(parts[1], ";", 2)[0]))
+		if issuer != "" {
+			issuers = append(issuers, issuer)
+		}
+	}
+	return issuers
+}
+
 func realName(hdr dns.RR_Header) string {
 	pieces := strings.Split(hdr.Name, " ")
 
@@ -221,7 +355,7 @@ func getXfrRequests(en *dns.Envelope, domain string) []*requests.DNSRequest {
 		case *dns.SOA:
 			record.Name = RemoveLastDot(v.Hdr.Name)
 			record.Type = int(dns.TypeSOA)
-			record.Data = v.Ns + " " + v.Mbox
+			record.Data = soaData(v)
 		case *dns.SPF:
 			record.Name = RemoveLastDot(v.Hdr.Name)
 			record.Type = int(dns.TypeSPF)
@@ -232,6 +366,25 @@ func getXfrRequests(en *dns.Envelope, domain string) []*requests.DNSRequest {
 			record.Name = RemoveLastDot(v.Hdr.Name)
 			record.Type = int(dns.TypeSRV)
 			record.Data = RemoveLastDot(v.Target)
+		case *dns.DNAME:
+			record.Name = RemoveLastDot(v.Hdr.Name)
+			record.Type = int(dns.TypeDNAME)
+			record.Data = RemoveLastDot(v.Target)
+		case *dns.NAPTR:
+			record.Name = RemoveLastDot(v.Hdr.Name)
+			record.Type = int(dns.TypeNAPTR)
+			record.Data = RemoveLastDot(v.Replacement)
+			if record.Data == "" {
+				continue
+			}
+		case *dns.TLSA:
+			record.Name = RemoveLastDot(v.Hdr.Name)
+			record.Type = int(dns.TypeTLSA)
+			record.Data = tlsaData(v)
+		case *dns.CAA:
+			record.Name = RemoveLastDot(v.Hdr.Name)
+			record.Type = int(dns.TypeCAA)
+			record.Data = strings.ToLower(v.Tag) + " " + v.Value
 		default:
 			continue
 		}
@@ -0,0 +1,103 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestCAAIssuers(t *testing.T) {
+	records := []string{
+		"issue letsencrypt.org",
+		"issuewild DigiCert.com; cansignhttpexchanges=yes",
+		"iodef mailto:security@example.com",
+		"issue ;",
+	}
+
+	want := []string{"letsencrypt.org", "digicert.com"}
+	if got := CAAIssuers(records); !reflect.DeepEqual(got, want) {
+		t.Errorf("CAAIssuers returned %v, expected %v", got, want)
+	}
+}
+
+func TestParseSOAData(t *testing.T) {
+	ns, mbox, serial, err := ParseSOAData("ns1.owasp.org.,Hostmaster.owasp.org.,2021020301")
+	if err != nil {
+		t.Fatalf("ParseSOAData returned an error: %v", err)
+	}
+	if ns != "ns1.owasp.org" || mbox != "hostmaster.owasp.org" || serial != 2021020301 {
+		t.Errorf("ParseSOAData returned %s, %s and %d", ns, mbox, serial)
+	}
+
+	for _, data := range []string{"ns1.owasp.org.,hostmaster.owasp.org.", "ns1.owasp.org.,hostmaster.owasp.org.,serial"} {
+		if _, _, _, err := ParseSOAData(data); err == nil {
+			t.Errorf("ParseSOAData did not return an error for %s", data)
+		}
+	}
+}
+
+func TestTLSAHost(t *testing.T) {
+	tests := map[string]string{
+		"_443._tcp.www.owasp.org.": "www.owasp.org",
+		"_25._tcp.mail.owasp.org":  "mail.owasp.org",
+		"www.owasp.org":            "",
+		"_443.www.owasp.org":       "",
+	}
+
+	for name, want := range tests {
+		if got := TLSAHost(name); got != want {
+			t.Errorf("TLSAHost returned %s for %s, expected %s", got, name, want)
+		}
+	}
+}
+
+func TestIsServiceName(t *testing.T) {
+	tests := map[string]bool{
+		"_sip._udp.owasp.org.":    true,
+		"_443._tcp.www.owasp.org": true,
+		"_dmarc.owasp.org":        false,
+		"_sip._udp":               false,
+		"www.owasp.org":           false,
+	}
+
+	for name, want := range tests {
+		if got := IsServiceName(name); got != want {
+			t.Errorf("IsServiceName returned %t for %s, expected %t", got, name, want)
+		}
+	}
+}
+
+func TestSetClientSubnet(t *testing.T) {
+	defer func() { _ = SetClientSubnet("") }()
+
+	subnet := func() *dns.EDNS0_SUBNET {
+		return QueryMsg("www.example.com", dns.TypeA).IsEdns0().Option[0].(*dns.EDNS0_SUBNET)
+	}
+
+	if e := subnet(); e.Family != 1 || e.SourceNetmask != 0 || !e.Address.Equal(net.IPv4zero) {
+		t.Errorf("The client subnet was not zeroed out by default: %v", e)
+	}
+
+	if err := SetClientSubnet("203.0.113.77/24"); err != nil {
+		t.Errorf("Failed to set the IPv4 client subnet: %v", err)
+	}
+	if e := subnet(); e.Family != 1 || e.SourceNetmask != 24 || e.Address.String() != "203.0.113.0" {
+		t.Errorf("The IPv4 client subnet was not provided in the queries: %v", e)
+	}
+
+	if err := SetClientSubnet("2001:db8:1::/48"); err != nil {
+		t.Errorf("Failed to set the IPv6 client subnet: %v", err)
+	}
+	if e := subnet(); e.Family != 2 || e.SourceNetmask != 48 || e.Address.String() != "2001:db8:1::" {
+		t.Errorf("The IPv6 client subnet was not provided in the queries: %v", e)
+	}
+
+	if err := SetClientSubnet("203.0.113.77"); err == nil {
+		t.Errorf("An address without a prefix length was accepted as the client subnet")
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	nibbleChars = "0123456789abcdef"
+	// The number of nibbles in the ip6.arpa name of an IPv6 address
+	numOfNibbles = 32
+)
+
+// NibbleWalk discovers the IPv6 addresses with PTR records within the provided netblock by walking
+// the ip6.arpa tree. Only the nibbles that do not return NXDOMAIN are followed, since servers
+// return NXDOMAIN when no names exist below an empty non-terminal (RFC 8020). The walk stops
+// after max queries have been sent, and a max value of zero does not bound the walk.
+func NibbleWalk(ctx context.Context, r Resolver, cidr *net.IPNet, priority, max int) ([]string, error) {
+	if cidr == nil || cidr.IP.To4() != nil || len(cidr.IP) != net.IPv6len {
+		return []string{}, errors.New("NibbleWalk: The netblock must be an IPv6 netblock")
+	}
+	if r.Stopped() {
+		return []string{}, errors.New("Resolver: The resolver has been stopped")
+	}
+
+	ones, _ := cidr.Mask.Size()
+	// Only complete nibbles of the prefix can be used as the starting point
+	prefix := nibblePrefix(cidr.IP, ones/4)
+
+	w := &nibbleWalker{
+		ctx:      ctx,
+		resolver: r,
+		priority: priority,
+		max:      max,
+	}
+	if !w.exists(nibbleName(prefix)) {
+		return []string{}, w.err
+	}
+
+	w.walk(prefix)
+	return w.addrs, w.err
+}
+
+type nibbleWalker struct {
+	ctx      context.Context
+	resolver Resolver
+	priority int
+	max      int
+	queries  int
+	addrs    []string
+	err      error
+}
+
+func (w *nibbleWalker) walk(nibbles string) {
+	var found []string
+
+	for _, c := range nibbleChars {
+		if w.err != nil {
+			return
+		}
+
+		if next := nibbles + string(c); w.exists(nibbleName(next)) {
+			found = append(found, next)
+		}
+	}
+	// Every nibble existing indicates that the names are synthesized by the server
+	if len(found) == len(nibbleChars) {
+		w.err = errors.New("NibbleWalk: The reverse zone appears to synthesize the PTR records")
+		return
+	}
+
+	for _, next := range found {
+		if len(next) == numOfNibbles {
+			w.addrs = append(w.addrs, nibbleAddr(next))
+			continue
+		}
+
+		w.walk(next)
+	}
+}
+
+func (w *nibbleWalker) exists(name string) bool {
+	select {
+	case <-w.ctx.Done():
+		w.err = errors.New("NibbleWalk: The context expired during the walk")
+		return false
+	default:
+	}
+
+	if w.max > 0 && w.queries >= w.max {
+		w.err = errors.New("NibbleWalk: The maximum number of queries was reached")
+		return false
+	}
+	w.queries++
+
+	resp, err := w.resolver.Query(w.ctx, QueryMsg(name, dns.TypePTR), w.priority, RetryPolicy)
+	if err != nil || resp == nil {
+		return false
+	}
+	return resp.Rcode == dns.RcodeSuccess
+}
+
+// nibblePrefix returns the first num hexadecimal nibbles of the IPv6 address.
+func nibblePrefix(ip net.IP, num int) string {
+	var b strings.Builder
+
+	for _, octet := range ip.To16() {
+		b.WriteByte(nibbleChars[octet>>4])
+		b.WriteByte(nibbleChars[octet&0xf])
+	}
+	return b.String()[:num]
+}
+
+// nibbleName returns the ip6.arpa name for the provided nibbles, which are in address order.
+func nibbleName(nibbles string) string {
+	labels := make([]string, 0, len(nibbles)+2)
+
+	for i := len(nibbles) - 1; i >= 0; i-- {
+		labels = append(labels, string(nibbles[i]))
+	}
+	return strings.Join(append(labels, "ip6", "arpa"), ".")
+}
+
+// nibbleAddr returns the IPv6 address represented by all the nibbles of the address.
+func nibbleAddr(nibbles string) string {
+	var groups []string
+
+	for i := 0; i < numOfNibbles; i += 4 {
+		groups = append(groups, nibbles[i:i+4])
+	}
+	return net.ParseIP(strings.Join(groups, ":")).String()
+}
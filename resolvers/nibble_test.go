@@ -0,0 +1,88 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// reverseZoneResolver serves the PTR records of the provided addresses, following RFC 8020.
+type reverseZoneResolver struct {
+	answerResolver
+	names      []string
+	synthesize bool
+}
+
+func (z *reverseZoneResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	qname := strings.ToLower(msg.Question[0].Name)
+	for _, name := range z.names {
+		if name == qname {
+			resp.Answer = append(resp.Answer, &dns.PTR{
+				Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypePTR, Class: dns.ClassINET},
+				Ptr: "host.example.com.",
+			})
+			return resp, nil
+		}
+		// Empty non-terminals exist
+		if strings.HasSuffix(name, "."+qname) {
+			return resp, nil
+		}
+	}
+	if z.synthesize {
+		return resp, nil
+	}
+
+	resp.Rcode = dns.RcodeNameError
+	return resp, &ResolveError{Err: "NXDOMAIN", Rcode: dns.RcodeNameError}
+}
+
+func TestNibbleWalk(t *testing.T) {
+	addrs := []string{"2001:db8:1:2::1", "2001:db8:1:2::53", "2001:db8:1:2:a::1"}
+
+	var names []string
+	for _, addr := range addrs {
+		name, _ := dns.ReverseAddr(addr)
+		names = append(names, name)
+	}
+	// An address outside of the walked netblock
+	other, _ := dns.ReverseAddr("2001:db8:1:3::1")
+
+	r := &reverseZoneResolver{names: append(names, other)}
+	_, cidr, _ := net.ParseCIDR("2001:db8:1:2::/64")
+
+	found, err := NibbleWalk(context.Background(), r, cidr, PriorityLow, 0)
+	if err != nil {
+		t.Errorf("The nibble walk returned an error: %v", err)
+	}
+	if len(found) != len(addrs) {
+		t.Errorf("The nibble walk found %v, expected %v", found, addrs)
+	}
+	for i, addr := range addrs {
+		if i < len(found) && !strings.EqualFold(found[i], addr) {
+			t.Errorf("The nibble walk found %s, expected %s", found[i], addr)
+		}
+	}
+
+	if _, err := NibbleWalk(context.Background(), r, cidr, PriorityLow, 10); err == nil {
+		t.Errorf("The nibble walk did not stop at the maximum number of queries")
+	}
+
+	synth := &reverseZoneResolver{synthesize: true}
+	if found, err := NibbleWalk(context.Background(), synth, cidr, PriorityLow, 0); err == nil || len(found) != 0 {
+		t.Errorf("The nibble walk did not detect the synthesized PTR records")
+	}
+
+	_, v4, _ := net.ParseCIDR("192.0.2.0/24")
+	if _, err := NibbleWalk(context.Background(), r, v4, PriorityLow, 0); err == nil {
+		t.Errorf("The nibble walk accepted an IPv4 netblock")
+	}
+}
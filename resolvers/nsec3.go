@@ -0,0 +1,221 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// NSEC3Zone contains the NSEC3 hashes and parameters collected from a DNS zone.
+type NSEC3Zone struct {
+	Domain     string
+	Hash       uint8
+	Iterations uint16
+	Salt       string
+	Hashes     map[string]struct{}
+}
+
+// nsec3ZoneJSON is the format used to save the collected hashes for offline cracking.
+type nsec3ZoneJSON struct {
+	Domain     string   `json:"domain"`
+	Hash       uint8    `json:"hash"`
+	Iterations uint16   `json:"iterations"`
+	Salt       string   `json:"salt"`
+	Hashes     []string `json:"hashes"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (z *NSEC3Zone) MarshalJSON() ([]byte, error) {
+	zj := &nsec3ZoneJSON{
+		Domain:     z.Domain,
+		Hash:       z.Hash,
+		Iterations: z.Iterations,
+		Salt:       z.Salt,
+	}
+
+	for hash := range z.Hashes {
+		zj.Hashes = append(zj.Hashes, hash)
+	}
+	sort.Strings(zj.Hashes)
+
+	return json.Marshal(zj)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (z *NSEC3Zone) UnmarshalJSON(data []byte) error {
+	var zj nsec3ZoneJSON
+
+	if err := json.Unmarshal(data, &zj); err != nil {
+		return err
+	}
+	if zj.Domain == "" {
+		return errors.New("The NSEC3 zone does not provide the domain name")
+	}
+
+	z.Domain = strings.ToLower(zj.Domain)
+	z.Hash = zj.Hash
+	z.Iterations = zj.Iterations
+	z.Salt = zj.Salt
+	z.Hashes = make(map[string]struct{}, len(zj.Hashes))
+	for _, hash := range zj.Hashes {
+		z.Hashes[strings.ToUpper(hash)] = struct{}{}
+	}
+	return nil
+}
+
+// maxStaleNsec3Queries is the number of queries returning no new hashes before the collection stops.
+const maxStaleNsec3Queries = 25
+
+// Nsec3Hashes collects the NSEC3 hashes for a zone by querying for names that do not exist.
+// The collection stops after max queries have been performed.
+func Nsec3Hashes(ctx context.Context, r Resolver, domain string, priority, max int) (*NSEC3Zone, error) {
+	if r.Stopped() {
+		return nil, errors.New("Resolver: The resolver has been stopped")
+	}
+
+	zone := &NSEC3Zone{
+		Domain: strings.ToLower(domain),
+		Hashes: make(map[string]struct{}),
+	}
+
+	var found bool
+	var stale int
+	for i := 0; i < max && stale < maxStaleNsec3Queries; i++ {
+		select {
+		case <-ctx.Done():
+			return zone, errors.New("Nsec3Hashes: The context expired during the hash collection")
+		default:
+		}
+
+		name := randomLabel() + "." + zone.Domain
+		resp, err := r.Query(ctx, WalkMsg(name, dns.TypeA), priority, RetryPolicy)
+		if !negativeResponse(resp, err) {
+			stale++
+			continue
+		}
+
+		before := len(zone.Hashes)
+		for _, rr := range resp.Ns {
+			nsec3, ok := rr.(*dns.NSEC3)
+			if !ok {
+				continue
+			}
+
+			found = true
+			zone.Hash = nsec3.Hash
+			zone.Iterations = nsec3.Iterations
+			zone.Salt = nsec3.Salt
+
+			owner := strings.Split(nsec3.Hdr.Name, ".")[0]
+			zone.Hashes[strings.ToUpper(owner)] = struct{}{}
+			zone.Hashes[strings.ToUpper(nsec3.NextDomain)] = struct{}{}
+		}
+
+		if len(zone.Hashes) == before {
+			stale++
+		} else {
+			stale = 0
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("Nsec3Hashes: Resolver %s: NSEC3 records not found for %s", r.String(), domain)
+	}
+	return zone, nil
+}
+
+// Crack returns the names, built from the provided labels, that match the hashes collected for the zone.
+func (z *NSEC3Zone) Crack(labels []string) []string {
+	var names []string
+
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label == "" {
+			continue
+		}
+
+		name := label + "." + z.Domain
+		hash := dns.HashName(dns.Fqdn(name), z.Hash, z.Iterations, z.Salt)
+		if hash == "" {
+			continue
+		}
+		if _, found := z.Hashes[hash]; found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// MaxNSEC3BruteLength is the longest label tried while brute forcing the hashes, since each
+// additional character multiplies the number of hashes computed by 37.
+const MaxNSEC3BruteLength = 6
+
+// nsec3BruteChars are the characters used to build the labels while brute forcing the hashes.
+const nsec3BruteChars = "abcdefghijklmnopqrstuvwxyz0123456789-"
+
+// BruteForce returns the names matching the hashes collected for the zone by trying each label of up
+// to max characters, which is capped at MaxNSEC3BruteLength. The search stops early once all the hashes
+// have been cracked or the context expires.
+func (z *NSEC3Zone) BruteForce(ctx context.Context, max int) []string {
+	var names []string
+
+	if max > MaxNSEC3BruteLength {
+		max = MaxNSEC3BruteLength
+	}
+
+	label := make([]byte, 0, max)
+	var search func() bool
+	search = func() bool {
+		if l := len(label); l > 0 && label[0] != '-' && label[l-1] != '-' {
+			name := string(label) + "." + z.Domain
+
+			if _, found := z.Hashes[dns.HashName(dns.Fqdn(name), z.Hash, z.Iterations, z.Salt)]; found {
+				names = append(names, name)
+				if len(names) >= len(z.Hashes) {
+					return false
+				}
+			}
+		}
+		if len(label) >= max {
+			return true
+		}
+
+		for i := 0; i < len(nsec3BruteChars); i++ {
+			select {
+			case <-ctx.Done():
+				return false
+			default:
+			}
+
+			label = append(label, nsec3BruteChars[i])
+			cont := search()
+			label = label[:len(label)-1]
+			if !cont {
+				return false
+			}
+		}
+		return true
+	}
+
+	search()
+	return names
+}
+
+func randomLabel() string {
+	const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	b := make([]byte, 12)
+	for i := range b {
+		b[i] = chars[rand.Intn(len(chars))]
+	}
+	return string(b)
+}
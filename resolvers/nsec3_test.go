@@ -0,0 +1,116 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestNSEC3ZoneCrack(t *testing.T) {
+	zone := &NSEC3Zone{
+		Domain:     "example.com",
+		Hash:       dns.SHA1,
+		Iterations: 10,
+		Salt:       "AABBCCDD",
+		Hashes:     make(map[string]struct{}),
+	}
+
+	for _, name := range []string{"www.example.com", "mail.example.com"} {
+		zone.Hashes[dns.HashName(dns.Fqdn(name), zone.Hash, zone.Iterations, zone.Salt)] = struct{}{}
+	}
+
+	names := zone.Crack([]string{"www", "ftp", "MAIL", ""})
+	if len(names) != 2 {
+		t.Errorf("Crack returned %d names instead of the expected 2: %v", len(names), names)
+	}
+	for _, name := range names {
+		if name != "www.example.com" && name != "mail.example.com" {
+			t.Errorf("Crack returned an unexpected name: %s", name)
+		}
+	}
+}
+
+func TestNSEC3ZoneBruteForce(t *testing.T) {
+	zone := &NSEC3Zone{
+		Domain:     "example.com",
+		Hash:       dns.SHA1,
+		Iterations: 1,
+		Salt:       "AABBCCDD",
+		Hashes:     make(map[string]struct{}),
+	}
+
+	for _, name := range []string{"a1.example.com", "vpn.example.com", "longer.example.com"} {
+		zone.Hashes[dns.HashName(dns.Fqdn(name), zone.Hash, zone.Iterations, zone.Salt)] = struct{}{}
+	}
+
+	names := zone.BruteForce(context.Background(), 3)
+	if len(names) != 2 {
+		t.Errorf("BruteForce returned %d names instead of the expected 2: %v", len(names), names)
+	}
+	for _, name := range names {
+		if name != "a1.example.com" && name != "vpn.example.com" {
+			t.Errorf("BruteForce returned an unexpected name: %s", name)
+		}
+	}
+}
+
+func TestNSEC3ZoneBruteForceCancel(t *testing.T) {
+	zone := &NSEC3Zone{
+		Domain:     "example.com",
+		Hash:       dns.SHA1,
+		Iterations: 1,
+		Salt:       "AABBCCDD",
+		Hashes:     map[string]struct{}{"unknown": {}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	done := make(chan []string, 1)
+	// The length is capped, and the search stops once the context has been cancelled
+	go func() { done <- zone.BruteForce(ctx, 100) }()
+
+	select {
+	case names := <-done:
+		if len(names) != 0 {
+			t.Errorf("BruteForce returned names for the unknown hash: %v", names)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("BruteForce did not stop when the context was cancelled")
+	}
+}
+
+func TestNSEC3ZoneJSON(t *testing.T) {
+	zone := &NSEC3Zone{
+		Domain:     "example.com",
+		Hash:       dns.SHA1,
+		Iterations: 10,
+		Salt:       "AABBCCDD",
+		Hashes:     map[string]struct{}{"2VPTU5TIMAMQTTGL4LUU9KG21E0AOR3S": {}},
+	}
+
+	data, err := json.Marshal(zone)
+	if err != nil {
+		t.Fatalf("Failed to marshal the NSEC3 zone: %v", err)
+	}
+
+	var got NSEC3Zone
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to unmarshal the NSEC3 zone: %v", err)
+	}
+	if got.Domain != zone.Domain || got.Iterations != zone.Iterations || got.Salt != zone.Salt {
+		t.Errorf("Unmarshal returned %+v, expected %+v", got, zone)
+	}
+	if _, found := got.Hashes["2VPTU5TIMAMQTTGL4LUU9KG21E0AOR3S"]; !found || len(got.Hashes) != 1 {
+		t.Errorf("Unmarshal returned the hashes %v", got.Hashes)
+	}
+	if err := json.Unmarshal([]byte(`{"hashes": []}`), &got); err == nil {
+		t.Errorf("Unmarshal did not return an error for the missing domain name")
+	}
+}
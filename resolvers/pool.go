@@ -7,6 +7,7 @@ import (
 	"context"
 	"io/ioutil"
 	"log"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -17,14 +18,19 @@ type resolverPool struct {
 	sync.Mutex
 	done chan struct{}
 	// Logger for error messages
-	log            *log.Logger
-	baseline       Resolver
-	resolvers      []Resolver
-	curIdx         int
-	avgs           *slidingWindowTimeouts
-	waits          map[string]time.Time
-	delay          time.Duration
-	hasBeenStopped bool
+	log         *log.Logger
+	baseline    Resolver
+	resolvers   []Resolver
+	avgs        *slidingWindowTimeouts
+	scores      *resolverScores
+	waits       map[string]time.Time
+	canaryFails map[string]int
+	// Functions receiving the messages about resolvers suspected of DNS interception
+	interceptHandlers map[int]func(msg string)
+	nextHandlerID     int
+	delay             time.Duration
+	scoring           bool
+	hasBeenStopped    bool
 }
 
 // NewResolverPool initializes a ResolverPool that uses the provided Resolvers.
@@ -34,13 +40,16 @@ func NewResolverPool(resolvers []Resolver, delay time.Duration, baseline Resolve
 	}
 
 	rp := &resolverPool{
-		baseline:  baseline,
-		resolvers: resolvers,
-		avgs:      newSlidingWindowTimeouts(),
-		waits:     make(map[string]time.Time),
-		delay:     delay,
-		done:      make(chan struct{}, 2),
-		log:       logger,
+		baseline:          baseline,
+		resolvers:         resolvers,
+		avgs:              newSlidingWindowTimeouts(),
+		scores:            newResolverScores(),
+		waits:             make(map[string]time.Time),
+		canaryFails:       make(map[string]int),
+		interceptHandlers: make(map[int]func(msg string)),
+		delay:             delay,
+		done:              make(chan struct{}, 2),
+		log:               logger,
 	}
 
 	// Assign a null logger when one is not provided
@@ -48,6 +57,23 @@ func NewResolverPool(resolvers []Resolver, delay time.Duration, baseline Resolve
 		rp.log = log.New(ioutil.Discard, "", 0)
 	}
 
+	// The canary checks detect interception for every pool, even when the resolvers are not scored
+	go rp.manageResolvers()
+	return rp
+}
+
+// NewScoredResolverPool initializes a ResolverPool that continuously scores the provided Resolvers
+// on latency, failure rate and poisoning checks, and evicts the Resolvers that score poorly.
+func NewScoredResolverPool(resolvers []Resolver, delay time.Duration, baseline Resolver, logger *log.Logger) Resolver {
+	r := NewResolverPool(resolvers, delay, baseline, logger)
+	if r == nil {
+		return nil
+	}
+
+	rp := r.(*resolverPool)
+	rp.Lock()
+	rp.scoring = true
+	rp.Unlock()
 	return rp
 }
 
@@ -59,15 +85,16 @@ func (rp *resolverPool) Stop() {
 	rp.hasBeenStopped = true
 	close(rp.done)
 
+	rp.Lock()
 	for _, r := range rp.resolvers {
 		r.Stop()
 	}
+	rp.resolvers = []Resolver{}
+	rp.Unlock()
 
 	if rp.baseline != nil {
 		rp.baseline.Stop()
 	}
-
-	rp.resolvers = []Resolver{}
 	return
 }
 
@@ -81,35 +108,35 @@ func (rp *resolverPool) String() string {
 	return "ResolverPool"
 }
 
+// nextResolver selects a random resolver, and the better scoring of two randomly chosen resolvers
+// when scoring is enabled, which rebalances the query load toward the fast and reliable resolvers.
 func (rp *resolverPool) nextResolver(ctx context.Context) Resolver {
-	var count int
-	var r Resolver
-
 	for {
 		if checkContext(ctx) != nil {
-			break
+			return nil
 		}
 
-		rp.Lock()
-		idx := rp.curIdx
-		rp.curIdx++
-		rp.curIdx = rp.curIdx % len(rp.resolvers)
-		r = rp.resolvers[idx]
-		t, found := rp.waits[r.String()]
-		rp.Unlock()
+		usable := rp.usableResolvers()
+		if len(usable) == 0 {
+			rp.Lock()
+			num := len(rp.resolvers)
+			rp.Unlock()
 
-		if (!found || t.IsZero() || time.Now().After(t)) && !r.Stopped() {
-			break
+			if num == 0 {
+				return nil
+			}
+			time.Sleep(5 * time.Second)
+			continue
 		}
 
-		count++
-		count = count % len(rp.resolvers)
-		if count == 0 {
-			time.Sleep(5 * time.Second)
+		r := usable[rand.Intn(len(usable))]
+		if rp.scoring && len(usable) > 1 {
+			if o := usable[rand.Intn(len(usable))]; rp.scores.score(o.String()) > rp.scores.score(r.String()) {
+				r = o
+			}
 		}
+		return r
 	}
-
-	return r
 }
 
 func (rp *resolverPool) updateWait(key string, d time.Duration) {
@@ -119,21 +146,25 @@ func (rp *resolverPool) updateWait(key string, d time.Duration) {
 	rp.waits[key] = time.Now().Add(d)
 }
 
-func (rp *resolverPool) numUsableResolvers() int {
+func (rp *resolverPool) usableResolvers() []Resolver {
 	rp.Lock()
 	defer rp.Unlock()
 
-	var num int
+	var usable []Resolver
 	now := time.Now()
 	for _, r := range rp.resolvers {
 		t, found := rp.waits[r.String()]
 
 		if (!found || t.IsZero() || now.After(t)) && !r.Stopped() {
-			num++
+			usable = append(usable, r)
 		}
 	}
 
-	return num
+	return usable
+}
+
+func (rp *resolverPool) numUsableResolvers() int {
+	return len(rp.usableResolvers())
 }
 
 // Query implements the Stringer interface.
@@ -155,10 +186,16 @@ func (rp *resolverPool) Query(ctx context.Context, msg *dns.Msg, priority int, r
 
 		r = rp.nextResolver(ctx)
 		if r == nil {
+			err = &ResolveError{
+				Err:   "The resolver pool has no usable resolvers",
+				Rcode: ResolverErrRcode,
+			}
 			break
 		}
 
+		start := time.Now()
 		resp, err = r.Query(ctx, msg, priority, nil)
+		rp.scores.update(r.String(), time.Since(start), err)
 
 		var timeout bool
 		// Check if the response is considered a resolver failure to be tracked
@@ -192,11 +229,12 @@ func (rp *resolverPool) Query(ctx context.Context, msg *dns.Msg, priority int, r
 	}
 
 	if rp.baseline != nil && err == nil && len(resp.Answer) > 0 {
-		// Validate findings from an untrusted resolver
+		// Validate findings from an untrusted resolver, so only the trusted answers are returned
 		resp, err = rp.baseline.Query(ctx, msg, priority, retry)
-		// False positives result in stopping the untrusted resolver
-		if err == nil && resp != nil && len(resp.Answer) == 0 {
-			r.Stop()
+		// False positives result in evicting the untrusted resolver
+		if falsePositive(resp, err) {
+			rp.scores.poisoned(r.String())
+			rp.evict(r)
 		}
 	}
 
@@ -208,5 +246,123 @@ func (rp *resolverPool) WildcardType(ctx context.Context, msg *dns.Msg, domain s
 	if rp.baseline != nil {
 		return rp.baseline.WildcardType(ctx, msg, domain)
 	}
-	return rp.resolvers[0].WildcardType(ctx, msg, domain)
+
+	rp.Lock()
+	r := rp.resolvers[0]
+	rp.Unlock()
+
+	return r.WildcardType(ctx, msg, domain)
+}
+
+// falsePositive returns true when the trusted response shows the name does not exist or lacks the records.
+func falsePositive(resp *dns.Msg, err error) bool {
+	if err == nil {
+		return resp != nil && len(resp.Answer) == 0
+	}
+	if e, ok := err.(*ResolveError); ok && e.Rcode == dns.RcodeNameError {
+		return true
+	}
+	return false
+}
+
+func (rp *resolverPool) manageResolvers() {
+	t := time.NewTicker(healthCheckInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-rp.done:
+			return
+		case <-t.C:
+			ctx, cancel := context.WithTimeout(context.Background(), healthCheckInterval)
+			rp.healthChecks(ctx)
+			cancel()
+		}
+	}
+}
+
+// healthChecks sends each resolver a query for a canary name with known addresses, and the
+// resolvers returning wrong data are quarantined. When scoring is enabled, each resolver is also
+// sent a query for a name that does not exist, since resolvers providing answers are hijacking
+// or poisoned, and then the poorly scoring resolvers are evicted.
+func (rp *resolverPool) healthChecks(ctx context.Context) {
+	rp.Lock()
+	all := make([]Resolver, len(rp.resolvers))
+	copy(all, rp.resolvers)
+	scoring := rp.scoring
+	rp.Unlock()
+
+	var wg sync.WaitGroup
+	for _, r := range all {
+		wg.Add(1)
+		go func(r Resolver) {
+			defer wg.Done()
+
+			rp.canaryCheck(ctx, r)
+		}(r)
+
+		if !scoring {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(r Resolver) {
+			defer wg.Done()
+
+			name := UnlikelyName(poisonCheckDomain)
+			if name == "" || r.Stopped() {
+				return
+			}
+
+			start := time.Now()
+			resp, err := r.Query(ctx, QueryMsg(name, dns.TypeA), PriorityCritical, nil)
+			rp.scores.update(r.String(), time.Since(start), err)
+			if err != nil || resp == nil || len(resp.Answer) == 0 {
+				return
+			}
+			// Confirm the name does not exist before considering the resolver poisoned
+			if rp.baseline != nil {
+				if bresp, berr := rp.baseline.Query(ctx, QueryMsg(name, dns.TypeA),
+					PriorityCritical, nil); berr == nil && bresp != nil && len(bresp.Answer) > 0 {
+					return
+				}
+			}
+			rp.scores.poisoned(r.String())
+		}(r)
+	}
+	wg.Wait()
+
+	if !scoring {
+		return
+	}
+	for _, r := range all {
+		if rp.scores.evict(r.String()) {
+			rp.evict(r)
+		}
+	}
+}
+
+// evict stops the resolver and removes it from the pool, while keeping at least one resolver.
+func (rp *resolverPool) evict(r Resolver) {
+	rp.Lock()
+	defer rp.Unlock()
+
+	if len(rp.resolvers) <= 1 {
+		return
+	}
+
+	for i, res := range rp.resolvers {
+		if res != r {
+			continue
+		}
+
+		rp.resolvers = append(rp.resolvers[:i], rp.resolvers[i+1:]...)
+		delete(rp.waits, r.String())
+		delete(rp.canaryFails, r.String())
+		rp.scores.remove(r.String())
+		r.Stop()
+		rp.log.Printf("Resolver %s has a low score and was evicted from the pool", r.String())
+		return
+	}
 }
@@ -4,6 +4,8 @@
 package resolvers
 
 import (
+	"sync"
+
 	"github.com/miekg/dns"
 )
 
@@ -31,6 +33,24 @@ var PoolRetryCodes = []int{
 	dns.RcodeNotImplemented,
 }
 
+var maxAttempts = struct {
+	sync.Mutex
+	attempts int
+}{}
+
+// SetMaxAttempts assigns the number of times a query is attempted before the retry policies
+// give up. Providing zero restores the default attempts, which are based on the query priority.
+func SetMaxAttempts(attempts int) {
+	if attempts < 0 {
+		attempts = 0
+	}
+
+	maxAttempts.Lock()
+	defer maxAttempts.Unlock()
+
+	maxAttempts.attempts = attempts
+}
+
 // RetryPolicy is the default policy used throughout Amass
 // to determine if a DNS query should be performed again.
 func RetryPolicy(times, priority int, msg *dns.Msg) bool {
@@ -61,7 +81,13 @@ func checkPolicy(times, priority int, msg *dns.Msg, codes []int) bool {
 }
 
 func attemptsExceeded(times, priority int) bool {
-	var attempts int
+	maxAttempts.Lock()
+	attempts := maxAttempts.attempts
+	maxAttempts.Unlock()
+
+	if attempts > 0 {
+		return times > attempts
+	}
 
 	switch priority {
 	case PriorityCritical:
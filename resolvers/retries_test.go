@@ -0,0 +1,27 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestSetMaxAttempts(t *testing.T) {
+	defer SetMaxAttempts(0)
+
+	msg := new(dns.Msg)
+	msg.Rcode = TimeoutRcode
+
+	SetMaxAttempts(3)
+	if !RetryPolicy(3, PriorityLow, msg) || RetryPolicy(4, PriorityCritical, msg) {
+		t.Errorf("The retry policy did not honor the configured attempts")
+	}
+
+	SetMaxAttempts(0)
+	if !RetryPolicy(50, PriorityLow, msg) || RetryPolicy(51, PriorityLow, msg) {
+		t.Errorf("The retry policy did not restore the attempts based on priority")
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// The number of queries required before a resolver score can lead to eviction
+	minScoredQueries int = 25
+	// Resolvers scoring below this fraction of the median score across the pool are evicted,
+	// so failures caused by the target, e.g. a zone that SERVFAILs everywhere, do not shrink the pool
+	evictionScore float64 = 0.2
+	// The weight given to the newest latency measurement in the moving average
+	latencyWeight float64 = 0.1
+	// How often the resolver pool performs the health checks
+	healthCheckInterval time.Duration = 30 * time.Second
+	// The domain name used to detect resolvers that answer for names that do not exist
+	poisonCheckDomain string = "example.com"
+)
+
+// ResolverScore contains the measurements used to rank a resolver within the pool.
+type ResolverScore struct {
+	Queries  int
+	Failures int
+	Latency  time.Duration
+	Poisoned bool
+}
+
+// Score returns a value between zero and one, where higher values indicate a better resolver.
+func (s *ResolverScore) Score() float64 {
+	if s.Poisoned {
+		return 0
+	}
+	if s.Queries == 0 {
+		return 1
+	}
+
+	success := 1 - (float64(s.Failures) / float64(s.Queries))
+	// Latency approaching the query timeout reduces the score by half
+	speed := 1 / (1 + (float64(s.Latency) / float64(QueryTimeout)))
+	return success * speed
+}
+
+type resolverScores struct {
+	sync.Mutex
+	scores map[string]*ResolverScore
+}
+
+func newResolverScores() *resolverScores {
+	return &resolverScores{scores: make(map[string]*ResolverScore)}
+}
+
+func (rs *resolverScores) get(key string) *ResolverScore {
+	s, found := rs.scores[key]
+	if !found {
+		s = new(ResolverScore)
+		rs.scores[key] = s
+	}
+	return s
+}
+
+// update records the outcome of a query sent to the resolver identified by key.
+func (rs *resolverScores) update(key string, rtt time.Duration, err error) {
+	rs.Lock()
+	defer rs.Unlock()
+
+	s := rs.get(key)
+	s.Queries++
+	if e, ok := err.(*ResolveError); ok && (e.Rcode == TimeoutRcode ||
+		e.Rcode == dns.RcodeServerFailure || e.Rcode == dns.RcodeRefused) {
+		s.Failures++
+		rtt = QueryTimeout
+	}
+
+	if s.Latency == 0 {
+		s.Latency = rtt
+	} else {
+		s.Latency = time.Duration((latencyWeight * float64(rtt)) + ((1 - latencyWeight) * float64(s.Latency)))
+	}
+}
+
+func (rs *resolverScores) poisoned(key string) {
+	rs.Lock()
+	defer rs.Unlock()
+
+	rs.get(key).Poisoned = true
+}
+
+func (rs *resolverScores) score(key string) float64 {
+	rs.Lock()
+	defer rs.Unlock()
+
+	return rs.get(key).Score()
+}
+
+// evict returns true when the resolver identified by key should be removed from the pool.
+func (rs *resolverScores) evict(key string) bool {
+	rs.Lock()
+	defer rs.Unlock()
+
+	s := rs.get(key)
+	if s.Poisoned {
+		return true
+	}
+	return s.Queries >= minScoredQueries && s.Score() < evictionScore*rs.median()
+}
+
+// median returns the median score of the resolvers with enough queries to be scored.
+func (rs *resolverScores) median() float64 {
+	var scores []float64
+
+	for _, s := range rs.scores {
+		if !s.Poisoned && s.Queries >= minScoredQueries {
+			scores = append(scores, s.Score())
+		}
+	}
+	if len(scores) == 0 {
+		return 1
+	}
+
+	sort.Float64s(scores)
+	return scores[len(scores)/2]
+}
+
+func (rs *resolverScores) remove(key string) {
+	rs.Lock()
+	defer rs.Unlock()
+
+	delete(rs.scores, key)
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// answerResolver provides an answer for every name when poisoned, and otherwise
+// only provides the known addresses of the canary names.
+type answerResolver struct {
+	name     string
+	poisoned bool
+	stopped  bool
+}
+
+func (a *answerResolver) String() string { return a.name }
+func (a *answerResolver) Stop()          { a.stopped = true }
+func (a *answerResolver) Stopped() bool  { return a.stopped }
+func (a *answerResolver) WildcardType(ctx context.Context, msg *dns.Msg, domain string) int {
+	return WildcardTypeNone
+}
+
+func (a *answerResolver) Query(ctx context.Context, msg *dns.Msg, priority int, retry Retry) (*dns.Msg, error) {
+	resp := new(dns.Msg)
+	resp.SetReply(msg)
+
+	if !a.poisoned {
+		name := RemoveLastDot(msg.Question[0].Name)
+		if addrs, found := DefaultCanaryRecords[name]; found {
+			resp.Answer = append(resp.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET},
+				A:   net.ParseIP(addrs[0]),
+			})
+			return resp, nil
+		}
+
+		resp.Rcode = dns.RcodeNameError
+		return resp, &ResolveError{Err: "NXDOMAIN", Rcode: dns.RcodeNameError}
+	}
+
+	resp.Answer = append(resp.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET},
+		A:   net.ParseIP("192.0.2.1"),
+	})
+	return resp, nil
+}
+
+func TestResolverScore(t *testing.T) {
+	rs := newResolverScores()
+
+	for i := 0; i < minScoredQueries; i++ {
+		rs.update("fast", 10*time.Millisecond, nil)
+		rs.update("slow", QueryTimeout/2, nil)
+		rs.update("failing", 10*time.Millisecond, &ResolveError{Err: "SERVFAIL", Rcode: dns.RcodeServerFailure})
+		rs.update("nxdomain", 10*time.Millisecond, &ResolveError{Err: "NXDOMAIN", Rcode: dns.RcodeNameError})
+	}
+
+	if rs.score("unused") != 1 {
+		t.Errorf("Resolvers without queries did not receive a neutral score")
+	}
+	if rs.score("fast") <= rs.score("slow") {
+		t.Errorf("The fast resolver did not score higher than the slow resolver")
+	}
+	if rs.score("nxdomain") != rs.score("fast") {
+		t.Errorf("NXDOMAIN responses were considered resolver failures")
+	}
+	if rs.score("failing") != 0 || !rs.evict("failing") {
+		t.Errorf("The resolver returning SERVFAIL was not marked for eviction")
+	}
+	if rs.evict("fast") || rs.evict("slow") {
+		t.Errorf("A healthy resolver was marked for eviction")
+	}
+
+	rs.poisoned("fast")
+	if rs.score("fast") != 0 || !rs.evict("fast") {
+		t.Errorf("The poisoned resolver was not marked for eviction")
+	}
+}
+
+func TestResolverScoreRelativeToPeers(t *testing.T) {
+	rs := newResolverScores()
+	servfail := &ResolveError{Err: "SERVFAIL", Rcode: dns.RcodeServerFailure}
+
+	// The target zone causes most of the queries to fail on every resolver
+	for i := 0; i < minScoredQueries*10; i++ {
+		var err error
+		if i%10 != 0 {
+			err = servfail
+		}
+
+		for _, key := range []string{"first", "second", "third"} {
+			rs.update(key, 10*time.Millisecond, err)
+		}
+	}
+
+	for _, key := range []string{"first", "second", "third"} {
+		if rs.evict(key) {
+			t.Errorf("The resolver %s was evicted for failures shared by the whole pool", key)
+		}
+	}
+}
+
+func TestResolverPoolVerification(t *testing.T) {
+	good := &answerResolver{name: "good"}
+	bad := &answerResolver{name: "bad", poisoned: true}
+	baseline := &answerResolver{name: "baseline"}
+
+	rp := NewResolverPool([]Resolver{good, bad}, time.Second, baseline, nil)
+	defer rp.Stop()
+
+	for i := 0; i < 10 && !bad.Stopped(); i++ {
+		resp, err := rp.Query(context.Background(), QueryMsg("www.example.com", dns.TypeA), PriorityNormal, nil)
+		if err == nil && resp != nil && len(resp.Answer) > 0 {
+			t.Errorf("The resolver pool returned an answer that was not verified by the trusted resolvers")
+		}
+	}
+	if !bad.Stopped() {
+		t.Errorf("The resolver providing answers for a name that does not exist was not evicted")
+	}
+}
+
+func TestResolverPoolHealthChecks(t *testing.T) {
+	good := &answerResolver{name: "good"}
+	bad := &answerResolver{name: "bad", poisoned: true}
+	baseline := &answerResolver{name: "baseline"}
+
+	rp := NewScoredResolverPool([]Resolver{good, bad}, time.Second, baseline, nil)
+	defer rp.Stop()
+
+	pool := rp.(*resolverPool)
+	pool.healthChecks(context.Background())
+
+	if !bad.Stopped() || good.Stopped() {
+		t.Errorf("The health checks did not evict the poisoned resolver")
+	}
+	if num := pool.numUsableResolvers(); num != 1 {
+		t.Errorf("The resolver pool contains %d usable resolvers, expected 1", num)
+	}
+
+	// The last resolver is never evicted
+	good.poisoned = true
+	pool.healthChecks(context.Background())
+	if good.Stopped() {
+		t.Errorf("The health checks evicted the last resolver in the pool")
+	}
+}
+
+func TestResolverPoolCanaryChecks(t *testing.T) {
+	good := &answerResolver{name: "good"}
+	bad := &answerResolver{name: "bad", poisoned: true}
+
+	rp := NewScoredResolverPool([]Resolver{good, bad}, time.Second, nil, nil)
+	defer rp.Stop()
+
+	pool := rp.(*resolverPool)
+	pool.canaryCheck(context.Background(), good)
+	pool.canaryCheck(context.Background(), bad)
+
+	usable := pool.usableResolvers()
+	if len(usable) != 1 || usable[0] != good {
+		t.Errorf("The resolver returning wrong data for the canary name was not quarantined")
+	}
+	if pool.scores.score("bad") == 0 {
+		t.Errorf("The resolver was considered poisoned after a single failed canary check")
+	}
+
+	for i := 1; i < maxCanaryFailures; i++ {
+		pool.canaryCheck(context.Background(), bad)
+	}
+	if pool.scores.score("bad") != 0 || pool.scores.score("good") == 0 {
+		t.Errorf("The repeated canary check failures did not mark the resolver as poisoned")
+	}
+}
+
+func TestSetCanaryRecords(t *testing.T) {
+	defer func() { _ = SetCanaryRecords(nil) }()
+
+	if err := SetCanaryRecords(map[string][]string{"canary.example.com.": {"192.0.2.53"}}); err != nil {
+		t.Errorf("Failed to set the canary records: %v", err)
+	}
+	if name, addrs := randomCanary(); name != "canary.example.com" || len(addrs) != 1 || addrs[0] != "192.0.2.53" {
+		t.Errorf("The canary records were not assigned: %s %v", name, addrs)
+	}
+
+	if err := SetCanaryRecords(map[string][]string{"canary.example.com": {"not an address"}}); err == nil {
+		t.Errorf("A canary record with an invalid address was accepted")
+	}
+}
+
+func TestUnscoredResolverPoolCanaryChecks(t *testing.T) {
+	good := &answerResolver{name: "good"}
+	bad := &answerResolver{name: "bad", poisoned: true}
+
+	rp := NewResolverPool([]Resolver{good, bad}, time.Second, nil, nil)
+	defer rp.Stop()
+
+	var msgs []string
+	remove := rp.(InterceptionReporter).AddInterceptionHandler(func(msg string) { msgs = append(msgs, msg) })
+	defer remove()
+
+	pool := rp.(*resolverPool)
+	pool.healthChecks(context.Background())
+
+	usable := pool.usableResolvers()
+	if len(usable) != 1 || usable[0] != good {
+		t.Errorf("The pool without scoring did not quarantine the resolver returning wrong data")
+	}
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "bad") || !strings.Contains(msgs[0], "suspected DNS interception") {
+		t.Errorf("The suspected interception was not reported: %v", msgs)
+	}
+	if bad.Stopped() {
+		t.Errorf("The pool without scoring evicted a resolver")
+	}
+}
+
+func TestInterceptionHandlersPerPool(t *testing.T) {
+	bad := &answerResolver{name: "bad", poisoned: true}
+	baseline := NewResolverPool([]Resolver{bad}, time.Second, nil, nil)
+	rp := NewResolverPool([]Resolver{&answerResolver{name: "good"}}, time.Second, baseline, nil)
+	defer rp.Stop()
+	other := NewResolverPool([]Resolver{&answerResolver{name: "other", poisoned: true}}, time.Second, nil, nil)
+	defer other.Stop()
+
+	var first, second, unrelated []string
+	removeFirst := rp.(InterceptionReporter).AddInterceptionHandler(func(msg string) { first = append(first, msg) })
+	removeSecond := rp.(InterceptionReporter).AddInterceptionHandler(func(msg string) { second = append(second, msg) })
+	defer removeSecond()
+	removeUnrelated := other.(InterceptionReporter).AddInterceptionHandler(func(msg string) { unrelated = append(unrelated, msg) })
+	defer removeUnrelated()
+
+	// The baseline resolvers report to the handlers registered with the pool using them
+	baseline.(*resolverPool).healthChecks(context.Background())
+	if len(first) != 1 || len(second) != 1 || !strings.Contains(first[0], "bad") {
+		t.Errorf("The handlers registered with the pool did not receive the report: %v %v", first, second)
+	}
+	if len(unrelated) != 0 {
+		t.Errorf("The handler registered with another pool received the report: %v", unrelated)
+	}
+
+	removeFirst()
+	baseline.(*resolverPool).healthChecks(context.Background())
+	if len(first) != 1 {
+		t.Errorf("The removed handler continued to receive reports: %v", first)
+	}
+	if len(second) != 2 {
+		t.Errorf("The remaining handler did not receive the report: %v", second)
+	}
+}
@@ -32,6 +32,7 @@ type resolveRequest struct {
 	Name      string
 	Qtype     uint16
 	Msg       *dns.Msg
+	Priority  int
 	Result    chan *resolveResult
 }
 
@@ -42,6 +43,7 @@ type resolveResult struct {
 }
 
 func (r *baseResolver) returnRequest(req *resolveRequest, res *resolveResult) {
+	currentGovernor().record(res.Err)
 	req.Result <- res
 }
 
@@ -16,6 +16,12 @@ import (
 
 // NsecTraversal attempts to retrieve a DNS zone using NSEC-walking.
 func NsecTraversal(ctx context.Context, r Resolver, domain string, priority int) ([]string, bool, error) {
+	return BoundedNsecTraversal(ctx, r, domain, priority, 0)
+}
+
+// BoundedNsecTraversal attempts to retrieve a DNS zone using NSEC-walking, and stops
+// after max names have been discovered. A max value of zero does not bound the walk.
+func BoundedNsecTraversal(ctx context.Context, r Resolver, domain string, priority, max int) ([]string, bool, error) {
 	if priority != PriorityCritical && priority != PriorityHigh && priority != PriorityLow {
 		return []string{}, false, &ResolveError{
 			Err:   fmt.Sprintf("Resolver: Invalid priority parameter: %d", priority),
@@ -30,6 +36,14 @@ func NsecTraversal(ctx context.Context, r Resolver, domain string, priority int)
 	var err error
 	var results []string
 	for next := "0"; next != ""; {
+		select {
+		case <-ctx.Done():
+			return results, false, errors.New("NsecTraversal: The context expired during the zone walk")
+		default:
+		}
+		if max > 0 && len(results) >= max {
+			break
+		}
 		query := next
 
 		for _, qtype := range []uint16{dns.TypeNSEC, dns.TypeA} {
@@ -127,7 +141,7 @@ func firstIsLess(prev, next string) bool {
 func walkMsgRequest(ctx context.Context, r Resolver, name string, qt uint16, priority int) *dns.Msg {
 	for i := 0; i < 100; i++ {
 		resp, err := r.Query(ctx, WalkMsg(name, qt), priority, RetryPolicy)
-		if err == nil && resp != nil {
+		if negativeResponse(resp, err) {
 			return resp
 		}
 	}
@@ -25,6 +25,9 @@ const (
 
 const numOfWildcardTests = 3
 
+// The number of additional queries used to detect wildcards returning rotating sets of records
+const numOfRotationTests = 5
+
 // Names for the different types of wildcards that can be detected.
 const (
 	WildcardTypeNone = iota
@@ -96,7 +99,7 @@ func (r *baseResolver) wildcard(ctx context.Context, msg *dns.Msg, domain string
 			}
 
 			set := stringset.New()
-			insertRecordData(set, ExtractAnswers(msg))
+			insertRecordData(set, wildcardAnswers(name, ExtractAnswers(msg)))
 			intersectRecordData(set, w.Answers)
 			if set.Len() > 0 {
 				return w.WildcardType
@@ -227,47 +230,54 @@ func (r *baseResolver) testIPsAcrossLevels(wildcards map[string]*wildcard, req *
 func (r *baseResolver) wildcardTest(ctx context.Context, sub string) {
 	var retRecords bool
 	set := stringset.New()
-	var answers []*ExtractedAnswer
+	var tests [][]*ExtractedAnswer
 
 	// Query multiple times with unlikely names against this subdomain
 	for i := 0; i < numOfWildcardTests; i++ {
-		var name string
-
-		// Generate the unlikely label / name
-		for j := 0; j < 10; j++ {
-			name = UnlikelyName(sub)
-			if name != "" {
-				break
-			}
+		ans, ok := r.unlikelyNameTest(ctx, sub)
+		if !ok {
+			continue
 		}
-
-		var ans []*ExtractedAnswer
-		for _, t := range wildcardQueryTypes {
-			msg := QueryMsg(name, t)
-
-			if resp, err := r.Query(ctx, msg, PriorityCritical, RetryPolicy); err == nil && len(resp.Answer) > 0 {
-				retRecords = true
-				ans = append(ans, ExtractAnswers(resp)...)
-			}
+		if len(ans) > 0 {
+			retRecords = true
 		}
 
-		if i == 0 {
+		if len(tests) == 0 {
 			insertRecordData(set, ans)
 		} else {
 			intersectRecordData(set, ans)
 		}
-		answers = append(answers, ans...)
+		tests = append(tests, ans)
+	}
+
+	// Wildcards returning rotating sets of records do not provide answers common across all the
+	// queries, so additional queries check whether the answers are selected from a limited set
+	if retRecords && set.Len() == 0 {
+		for i := 0; i < numOfRotationTests; i++ {
+			if ans, ok := r.unlikelyNameTest(ctx, sub); ok {
+				tests = append(tests, ans)
+			}
+		}
+
+		if rotating := rotatingRecordData(tests); rotating.Len() > 0 {
+			set = stringset.New()
+			for _, ans := range tests {
+				insertRecordData(set, ans)
+			}
+		}
 	}
 
 	already := stringset.New()
 	var final []*ExtractedAnswer
 	// Create the slice of answers common across all the unlikely name queries
-	for _, a := range answers {
-		a.Data = strings.Trim(a.Data, ".")
+	for _, ans := range tests {
+		for _, a := range ans {
+			a.Data = strings.Trim(a.Data, ".")
 
-		if set.Has(a.Data) && !already.Has(a.Data) {
-			final = append(final, a)
-			already.Insert(a.Data)
+			if set.Has(a.Data) && !already.Has(a.Data) {
+				final = append(final, a)
+				already.Insert(a.Data)
+			}
 		}
 	}
 
@@ -293,6 +303,80 @@ func (r *baseResolver) wildcardTest(ctx context.Context, sub string) {
 	}
 }
 
+// unlikelyNameTest queries an unlikely name within the subdomain and returns the answers,
+// with CNAME targets that include the queried label made comparable across the queries.
+func (r *baseResolver) unlikelyNameTest(ctx context.Context, sub string) ([]*ExtractedAnswer, bool) {
+	var name string
+
+	// Generate the unlikely label / name
+	for j := 0; j < 10; j++ {
+		name = UnlikelyName(sub)
+		if name != "" {
+			break
+		}
+	}
+	if name == "" {
+		return nil, false
+	}
+
+	var ans []*ExtractedAnswer
+	for _, t := range wildcardQueryTypes {
+		msg := QueryMsg(name, t)
+
+		if resp, err := r.Query(ctx, msg, PriorityCritical, RetryPolicy); err == nil && len(resp.Answer) > 0 {
+			ans = append(ans, ExtractAnswers(resp)...)
+		}
+	}
+
+	return wildcardAnswers(name, ans), true
+}
+
+// wildcardAnswers replaces the first label of the name within the CNAME targets, so the targets
+// generated by a CNAME wildcard can be compared across names, e.g. foo.example.com.cdn.net.
+func wildcardAnswers(name string, ans []*ExtractedAnswer) []*ExtractedAnswer {
+	label := strings.ToLower(strings.Split(RemoveLastDot(name), ".")[0])
+	if label == "" {
+		return ans
+	}
+
+	var results []*ExtractedAnswer
+	for _, a := range ans {
+		data := strings.ToLower(strings.Trim(a.Data, "."))
+
+		if a.Type == dns.TypeCNAME && strings.HasPrefix(data, label+".") {
+			data = "*" + strings.TrimPrefix(data, label)
+		}
+		results = append(results, &ExtractedAnswer{
+			Name: a.Name,
+			Type: a.Type,
+			Data: data,
+		})
+	}
+	return results
+}
+
+// rotatingRecordData returns the records provided by more than one of the query answers.
+func rotatingRecordData(tests [][]*ExtractedAnswer) stringset.Set {
+	counts := make(map[string]int)
+
+	for _, ans := range tests {
+		set := stringset.New()
+		insertRecordData(set, ans)
+
+		for _, rec := range set.Slice() {
+			counts[rec]++
+		}
+	}
+
+	rotating := stringset.New()
+	for rec, count := range counts {
+		if count > 1 {
+			rotating.Insert(rec)
+		}
+	}
+	return rotating
+}
+
 // UnlikelyName takes a subdomain name and returns an unlikely DNS name within that subdomain.
 func UnlikelyName(sub string) string {
 	ldh := []rune(LDHChars)
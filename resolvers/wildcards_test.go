@@ -0,0 +1,62 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package resolvers
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestWildcardAnswers(t *testing.T) {
+	first := wildcardAnswers("abc123.dev.example.com", []*ExtractedAnswer{
+		{Name: "abc123.dev.example.com", Type: dns.TypeCNAME, Data: "abc123.dev.example.com.edgekey.net."},
+		{Name: "abc123.dev.example.com.edgekey.net", Type: dns.TypeA, Data: "192.0.2.1"},
+	})
+	second := wildcardAnswers("xyz789.dev.example.com.", []*ExtractedAnswer{
+		{Name: "xyz789.dev.example.com", Type: dns.TypeCNAME, Data: "xyz789.dev.example.com.edgekey.net"},
+	})
+
+	if first[0].Data != second[0].Data || first[0].Data != "*.dev.example.com.edgekey.net" {
+		t.Errorf("The CNAME wildcard targets were not made comparable: %s and %s", first[0].Data, second[0].Data)
+	}
+	if first[1].Data != "192.0.2.1" {
+		t.Errorf("The address record data was modified: %s", first[1].Data)
+	}
+
+	other := wildcardAnswers("www.example.com", []*ExtractedAnswer{
+		{Name: "www.example.com", Type: dns.TypeCNAME, Data: "web.example.net"},
+	})
+	if other[0].Data != "web.example.net" {
+		t.Errorf("A CNAME target without the queried label was modified: %s", other[0].Data)
+	}
+}
+
+func TestRotatingRecordData(t *testing.T) {
+	a := func(addrs ...string) []*ExtractedAnswer {
+		var ans []*ExtractedAnswer
+		for _, addr := range addrs {
+			ans = append(ans, &ExtractedAnswer{Type: dns.TypeA, Data: addr})
+		}
+		return ans
+	}
+
+	rotating := rotatingRecordData([][]*ExtractedAnswer{
+		a("192.0.2.1", "192.0.2.2"),
+		a("192.0.2.3", "192.0.2.4"),
+		a("192.0.2.2", "192.0.2.3"),
+	})
+	if rotating.Len() != 2 || !rotating.Has("192.0.2.2") || !rotating.Has("192.0.2.3") {
+		t.Errorf("The records selected from a rotating set were not identified: %v", rotating.Slice())
+	}
+
+	dynamic := rotatingRecordData([][]*ExtractedAnswer{
+		a("192.0.2.1"),
+		a("192.0.2.2"),
+		a("192.0.2.3"),
+	})
+	if dynamic.Len() != 0 {
+		t.Errorf("Unique answers were considered a rotating set: %v", dynamic.Slice())
+	}
+}
@@ -14,8 +14,28 @@ import (
 )
 
 // ZoneTransfer attempts a DNS zone transfer using the provided server.
+// An incremental zone transfer (IXFR) is attempted when the server refuses the AXFR request.
 // The returned slice contains all the records discovered from the zone transfer.
 func ZoneTransfer(sub, domain, server string) ([]*requests.DNSRequest, error) {
+	m := &dns.Msg{}
+	m.SetAxfr(dns.Fqdn(sub))
+
+	results, err := transferZone(m, domain, server)
+	if err == nil && len(results) > 0 {
+		return results, nil
+	}
+
+	// A serial number of zero requests all the changes, which servers provide as the full zone
+	m = &dns.Msg{}
+	m.SetIxfr(dns.Fqdn(sub), 0, "", "")
+
+	if ixfr, ierr := transferZone(m, domain, server); ierr == nil && len(ixfr) > 0 {
+		return ixfr, nil
+	}
+	return results, err
+}
+
+func transferZone(m *dns.Msg, domain, server string) ([]*requests.DNSRequest, error) {
 	var results []*requests.DNSRequest
 
 	// Set the maximum time allowed for making the connection
@@ -33,15 +53,16 @@ func ZoneTransfer(sub, domain, server string) ([]*requests.DNSRequest, error) {
 		ReadTimeout: 30 * time.Second,
 	}
 
-	m := &dns.Msg{}
-	m.SetAxfr(dns.Fqdn(sub))
-
 	in, err := xfr.In(m, "")
 	if err != nil {
 		return results, fmt.Errorf("DNS zone transfer error: %s: %v", server+":53", err)
 	}
 
 	for en := range in {
+		if en.Error != nil {
+			err = fmt.Errorf("DNS zone transfer error: %s: %v", server+":53", en.Error)
+		}
+
 		reqs := getXfrRequests(en, domain)
 		if reqs == nil {
 			continue
@@ -49,5 +70,5 @@ func ZoneTransfer(sub, domain, server string) ([]*requests.DNSRequest, error) {
 
 		results = append(results, reqs...)
 	}
-	return results, nil
+	return results, err
 }
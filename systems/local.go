@@ -7,18 +7,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/handlers"
 	"github.com/OWASP/Amass/v3/limits"
 	amassnet "github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
+	"github.com/boltdb/bolt"
 	"github.com/caffix/service"
 	"github.com/miekg/dns"
 )
@@ -27,7 +31,10 @@ import (
 type LocalSystem struct {
 	cfg               *config.Config
 	pool              resolvers.Resolver
+	dnsCache          *resolvers.CacheResolver
+	governor          *resolvers.Governor
 	graphs            []*graph.Graph
+	handlers          []handlers.DataHandler
 	cache             *amassnet.ASNCache
 	done              chan struct{}
 	doneAlreadyClosed bool
@@ -41,6 +48,24 @@ func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 		return nil, err
 	}
 
+	if err := resolvers.SetClientSubnet(c.ClientSubnet); err != nil {
+		return nil, err
+	}
+	if err := resolvers.SetCanaryRecords(c.CanaryRecords); err != nil {
+		return nil, err
+	}
+	resolvers.SetMaxAttempts(c.QueryRetries)
+	if c.QueryTimeout > 0 {
+		resolvers.QueryTimeout = c.QueryTimeout
+	}
+
+	// The total queries per second are governed across all the resolvers, including the baselines
+	var gov *resolvers.Governor
+	if c.AdaptiveQueryRate {
+		gov = resolvers.NewGovernor(c.MaxDNSQueries, c.Log)
+	}
+	resolvers.SetGovernor(gov)
+
 	max := int(float64(limits.GetFileLimit()) * 0.7)
 
 	var pool resolvers.Resolver
@@ -50,12 +75,23 @@ func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 		pool = customResolverSetup(c, max)
 	}
 	if pool == nil {
+		gov.Stop()
 		return nil, errors.New("The system was unable to build the pool of resolvers")
 	}
 
+	var dnsCache *resolvers.CacheResolver
+	if c.DNSCache {
+		dnsCache = resolvers.NewCacheResolver(pool, c.DNSCacheFile())
+		dnsCache.SetNegativeTTL(c.NegativeCacheTTL)
+		dnsCache.SetMaxEntries(c.DNSCacheSize)
+		pool = dnsCache
+	}
+
 	sys := &LocalSystem{
 		cfg:        c,
 		pool:       pool,
+		dnsCache:   dnsCache,
+		governor:   gov,
 		cache:      amassnet.NewASNCache(),
 		done:       make(chan struct{}, 2),
 		addSource:  make(chan service.Service),
@@ -77,6 +113,11 @@ func NewLocalSystem(c *config.Config) (*LocalSystem, error) {
 		sys.Shutdown()
 		return nil, err
 	}
+	if err := sys.setupDataHandlers(); err != nil {
+		sys.Shutdown()
+		return nil, err
+	}
+	sys.recoverEnumGraphs()
 
 	go sys.manageDataSources()
 	return sys, nil
@@ -148,6 +189,11 @@ func (l *LocalSystem) GraphDatabases() []*graph.Graph {
 	return l.graphs
 }
 
+// DataHandlers implements the System interface.
+func (l *LocalSystem) DataHandlers() []handlers.DataHandler {
+	return l.handlers
+}
+
 // Shutdown implements the System interface.
 func (l *LocalSystem) Shutdown() error {
 	if l.doneAlreadyClosed {
@@ -163,6 +209,14 @@ func (l *LocalSystem) Shutdown() error {
 	for _, g := range l.GraphDatabases() {
 		g.Close()
 	}
+	for _, h := range l.DataHandlers() {
+		_ = h.Close()
+	}
+
+	if l.dnsCache != nil {
+		_ = l.dnsCache.Save()
+	}
+	l.governor.Stop()
 
 	//go l.pool.Stop()
 	return nil
@@ -223,6 +277,90 @@ func (l *LocalSystem) setupGraphDBs() error {
 	return nil
 }
 
+// Migrate the enumeration graphs left in the embedded stores by processes that did not
+// finish, so the findings are not lost when the process crashes during an enumeration.
+func (l *LocalSystem) recoverEnumGraphs() {
+	recoverEnumGraphs(l.Config().EnumGraphDirectory(), l.graphs, l.Config().Log)
+}
+
+// recoverEnumGraphs migrates the embedded stores left behind by enumerations that did not finish into the
+// graph databases, and removes each store once every migration succeeded. The stores are removed without
+// a migration when there are no graph databases, since the findings would never be recovered.
+func recoverEnumGraphs(dir string, graphs []*graph.Graph, logger *log.Logger) {
+	if dir == "" {
+		return
+	}
+	if logger == nil {
+		logger = log.New(ioutil.Discard, "", 0)
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		// The stores of enumerations still running are locked by the other process
+		if !entry.IsDir() || !enumGraphAvailable(path) {
+			continue
+		}
+
+		var failed bool
+		if len(graphs) > 0 {
+			db := graph.NewCayleyGraph("local", path, "nosync=true")
+			if db == nil {
+				continue
+			}
+
+			g := graph.NewGraph(db)
+			events := g.EventList()
+			for _, sg := range graphs {
+				if err := g.MigrateEvents(sg, events...); err != nil {
+					logger.Printf("Failed to recover the enumeration graph at %s: %v", path, err)
+					failed = true
+				}
+			}
+			g.Close()
+
+			if !failed {
+				logger.Printf("Recovered the findings of %d enumerations from %s", len(events), path)
+			}
+		}
+
+		if !failed {
+			os.RemoveAll(path)
+		}
+	}
+}
+
+// enumGraphAvailable returns true when the embedded store is not locked by another process.
+func enumGraphAvailable(path string) bool {
+	db, err := bolt.Open(filepath.Join(path, "indexes.bolt"), 0600, &bolt.Options{
+		Timeout:  time.Second,
+		ReadOnly: true,
+	})
+	if err != nil {
+		return false
+	}
+
+	db.Close()
+	return true
+}
+
+// Connect to the databases that receive the data as it is inserted into the enumeration graph.
+func (l *LocalSystem) setupDataHandlers() error {
+	for _, db := range l.Config().DataHandlers {
+		h, err := handlers.NewDataHandler(db)
+		if err != nil {
+			return fmt.Errorf("System: Failed to create the %s data handler: %v", db.System, err)
+		}
+
+		l.handlers = append(l.handlers, h)
+	}
+	return nil
+}
+
 // GetMemoryUsage returns the number bytes allocated to heap objects on this system.
 func (l *LocalSystem) GetMemoryUsage() uint64 {
 	var m runtime.MemStats
@@ -282,20 +420,21 @@ func customResolverSetup(cfg *config.Config, max int) resolvers.Resolver {
 		num = max
 	}
 
-	if cfg.MaxDNSQueries == 0 {
-		cfg.MaxDNSQueries = num * config.DefaultQueriesPerBaselineResolver
-	} else if cfg.MaxDNSQueries < num {
-		cfg.MaxDNSQueries = num
-	}
-
-	rate := cfg.MaxDNSQueries / num
+	// Each resolver receives the rate provided for it or a share of the maximum
+	rates := cfg.ResolverRates()
+	var total int
 	var trusted []resolvers.Resolver
-	for _, addr := range cfg.Resolvers {
-		if r := resolvers.NewBaseResolver(addr, rate, cfg.Log); r != nil {
+	for _, addr := range cfg.Resolvers[:num] {
+		if r := resolvers.NewBaseResolver(addr, rates[addr], cfg.Log); r != nil {
 			trusted = append(trusted, r)
+			total += rates[addr]
 		}
 	}
 
+	if cfg.MaxDNSQueries == 0 || cfg.MaxDNSQueries > total {
+		cfg.MaxDNSQueries = total
+	}
+
 	return resolvers.NewResolverPool(trusted, 2*time.Second, nil, cfg.Log)
 }
 
@@ -311,8 +450,14 @@ func publicResolverSetup(cfg *config.Config, max int) resolvers.Resolver {
 		cfg.MaxDNSQueries = num
 	}
 
+	// The trusted resolvers verify every answer provided by the public resolvers
+	addrs := config.DefaultBaselineResolvers
+	if len(cfg.TrustedResolvers) > 0 {
+		addrs = cfg.TrustedResolvers
+	}
+
 	var trusted []resolvers.Resolver
-	for _, addr := range config.DefaultBaselineResolvers {
+	for _, addr := range addrs {
 		if r := resolvers.NewBaseResolver(addr, config.DefaultQueriesPerBaselineResolver, cfg.Log); r != nil {
 			trusted = append(trusted, r)
 		}
@@ -321,6 +466,9 @@ func publicResolverSetup(cfg *config.Config, max int) resolvers.Resolver {
 	baseline := resolvers.NewResolverPool(trusted, 2*time.Second, nil, cfg.Log)
 	r := setupResolvers(config.PublicResolvers, max, config.DefaultQueriesPerPublicResolver, cfg.Log)
 
+	if cfg.ScoreResolvers {
+		return resolvers.NewScoredResolverPool(r, 5*time.Second, baseline, cfg.Log)
+	}
 	return resolvers.NewResolverPool(r, 5*time.Second, baseline, cfg.Log)
 }
 
@@ -0,0 +1,94 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package systems
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OWASP/Amass/v3/graph"
+)
+
+const testEventID = "ef9f9475-34eb-465e-81eb-77c944822d0f"
+
+// newTestEnumGraph creates an embedded store holding a single finding, as left behind by an interrupted enumeration.
+func newTestEnumGraph(t *testing.T, dir string) string {
+	path := filepath.Join(dir, testEventID)
+
+	db := graph.NewCayleyGraph("local", path, "nosync=true")
+	if db == nil {
+		t.Fatalf("Failed to create the enumeration graph at %s", path)
+	}
+
+	g := graph.NewGraph(db)
+	defer g.Close()
+
+	if _, err := g.InsertFQDN("www.owasp.org", "testsource", "testtag", testEventID); err != nil {
+		t.Fatalf("Failed to insert the FQDN: %v", err)
+	}
+	return path
+}
+
+func TestRecoverEnumGraphs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-enum-graphs")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := newTestEnumGraph(t, dir)
+	g := graph.NewGraph(graph.NewCayleyGraphMemory())
+	defer g.Close()
+
+	recoverEnumGraphs(dir, []*graph.Graph{g}, nil)
+
+	if names := g.EventFQDNs(testEventID); len(names) != 1 || names[0] != "www.owasp.org" {
+		t.Errorf("The findings were not migrated into the graph database: %v", names)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("The enumeration graph was not removed after the findings were recovered")
+	}
+}
+
+func TestRecoverEnumGraphsWithoutGraphDatabases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-enum-graphs")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := newTestEnumGraph(t, dir)
+	recoverEnumGraphs(dir, nil, nil)
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("The enumeration graph was not removed when no graph databases were available")
+	}
+}
+
+func TestRecoverEnumGraphsSkipsLockedStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "amass-enum-graphs")
+	if err != nil {
+		t.Fatalf("Failed to create the temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := newTestEnumGraph(t, dir)
+	// The store remains locked while the enumeration is running
+	running := graph.NewGraph(graph.NewCayleyGraph("local", path, "nosync=true"))
+	defer running.Close()
+
+	g := graph.NewGraph(graph.NewCayleyGraphMemory())
+	defer g.Close()
+
+	recoverEnumGraphs(dir, []*graph.Graph{g}, nil)
+
+	if names := g.EventFQDNs(testEventID); len(names) != 0 {
+		t.Errorf("The findings of a running enumeration were migrated: %v", names)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("The enumeration graph of a running enumeration was removed: %v", err)
+	}
+}
@@ -5,10 +5,12 @@ package systems
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/OWASP/Amass/v3/config"
 	"github.com/OWASP/Amass/v3/graph"
+	"github.com/OWASP/Amass/v3/handlers"
 	"github.com/OWASP/Amass/v3/net"
 	"github.com/OWASP/Amass/v3/requests"
 	"github.com/OWASP/Amass/v3/resolvers"
@@ -42,6 +44,9 @@ type System interface {
 	// GraphDatabases return the Graphs used by the System
 	GraphDatabases() []*graph.Graph
 
+	// DataHandlers returns the databases that receive the data as it is inserted into the graph
+	DataHandlers() []handlers.DataHandler
+
 	// GetMemoryUsage() returns the number bytes allocated to heap objects on this system
 	GetMemoryUsage() uint64
 
@@ -51,19 +56,43 @@ type System interface {
 
 // PopulateCache updates the provided System cache with ASN information from the System data sources.
 func PopulateCache(ctx context.Context, asn int, sys System) {
+	populateCache(ctx, &requests.ASNRequest{ASN: asn}, sys)
+}
+
+// PopulateCacheByOrganization updates the provided System cache with information from the System data sources
+// for the autonomous systems registered to the organization, and returns the AS numbers that were discovered.
+func PopulateCacheByOrganization(ctx context.Context, org string, sys System) []int {
+	return populateCache(ctx, &requests.ASNRequest{Organization: org}, sys)
+}
+
+func populateCache(ctx context.Context, req *requests.ASNRequest, sys System) []int {
 	bus := eb.NewEventBus()
 	defer bus.Stop()
 
+	var lock sync.Mutex
+	var asns []int
 	cache := sys.Cache()
-	bus.Subscribe(requests.NewASNTopic, cache.Update)
-	defer bus.Unsubscribe(requests.NewASNTopic, cache.Update)
+	fn := func(resp *requests.ASNRequest) {
+		cache.Update(resp)
+
+		lock.Lock()
+		defer lock.Unlock()
+		for _, asn := range asns {
+			if asn == resp.ASN {
+				return
+			}
+		}
+		asns = append(asns, resp.ASN)
+	}
+	bus.Subscribe(requests.NewASNTopic, fn)
+	defer bus.Unsubscribe(requests.NewASNTopic, fn)
 
 	ctx = context.WithValue(ctx, requests.ContextConfig, sys.Config())
 	ctx = context.WithValue(ctx, requests.ContextEventBus, bus)
 
 	// Send the ASN requests to the data sources
 	for _, src := range sys.DataSources() {
-		src.Request(ctx, &requests.ASNRequest{ASN: asn})
+		src.Request(ctx, req.Clone().(*requests.ASNRequest))
 	}
 
 	// Wait for the ASN requests to return responses
@@ -73,4 +102,8 @@ func PopulateCache(ctx context.Context, asn int, sys System) {
 	case <-ctx.Done():
 	case <-t.C:
 	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	return asns
 }
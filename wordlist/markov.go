@@ -0,0 +1,180 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package wordlist
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	markovStart     = '^'
+	markovEnd       = '$'
+	markovMaxLen    = 63
+	markovLDHChars  = "abcdefghijklmnopqrstuvwxyz0123456789-"
+	markovSamplings = 20
+)
+
+// MarkovModel is a character-level Markov chain trained on DNS labels that generates likely new labels.
+type MarkovModel struct {
+	sync.Mutex
+	order  int
+	counts map[string]map[byte]int
+	totals map[string]int
+	words  map[string]struct{}
+}
+
+// NewMarkovModel returns a MarkovModel using the provided number of preceding characters as the state.
+func NewMarkovModel(order int) *MarkovModel {
+	if order < 1 {
+		order = 1
+	}
+
+	return &MarkovModel{
+		order:  order,
+		counts: make(map[string]map[byte]int),
+		totals: make(map[string]int),
+		words:  make(map[string]struct{}),
+	}
+}
+
+// Train updates the model with the provided label and returns false if the label was not used.
+func (m *MarkovModel) Train(label string) bool {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" || len(label) > markovMaxLen || strings.Trim(label, markovLDHChars) != "" {
+		return false
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	if _, found := m.words[label]; found {
+		return false
+	}
+	m.words[label] = struct{}{}
+
+	state := strings.Repeat(string(markovStart), m.order)
+	for _, c := range []byte(label + string(markovEnd)) {
+		if _, found := m.counts[state]; !found {
+			m.counts[state] = make(map[byte]int)
+		}
+
+		m.counts[state][c]++
+		m.totals[state]++
+		state = state[1:] + string(c)
+	}
+	return true
+}
+
+// Len returns the number of labels the model has been trained on.
+func (m *MarkovModel) Len() int {
+	m.Lock()
+	defer m.Unlock()
+
+	return len(m.words)
+}
+
+// Probability returns the likelihood of the model generating the provided label.
+func (m *MarkovModel) Probability(label string) float64 {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.probability(strings.ToLower(label))
+}
+
+func (m *MarkovModel) probability(label string) float64 {
+	prob := 1.0
+	state := strings.Repeat(string(markovStart), m.order)
+
+	for _, c := range []byte(label + string(markovEnd)) {
+		total := m.totals[state]
+		if total == 0 {
+			return 0
+		}
+
+		prob *= float64(m.counts[state][c]) / float64(total)
+		if prob == 0 {
+			return 0
+		}
+		state = state[1:] + string(c)
+	}
+	return prob
+}
+
+// Generate returns up to num labels, not used to train the model, in order of decreasing probability.
+func (m *MarkovModel) Generate(num int) []string {
+	m.Lock()
+	defer m.Unlock()
+
+	if num <= 0 || len(m.words) == 0 {
+		return []string{}
+	}
+
+	probs := make(map[string]float64)
+	for i := 0; i < num*markovSamplings; i++ {
+		label, ok := m.sample()
+		if !ok {
+			continue
+		}
+		if _, found := m.words[label]; found {
+			continue
+		}
+		if _, found := probs[label]; !found {
+			probs[label] = m.probability(label)
+		}
+	}
+
+	var labels []string
+	for label := range probs {
+		labels = append(labels, label)
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		if probs[labels[i]] == probs[labels[j]] {
+			return labels[i] < labels[j]
+		}
+		return probs[labels[i]] > probs[labels[j]]
+	})
+
+	if len(labels) > num {
+		labels = labels[:num]
+	}
+	return labels
+}
+
+// sample performs a random walk through the chain, selecting each character based on its frequency.
+func (m *MarkovModel) sample() (string, bool) {
+	var label []byte
+	state := strings.Repeat(string(markovStart), m.order)
+
+	for len(label) <= markovMaxLen {
+		total := m.totals[state]
+		if total == 0 {
+			return "", false
+		}
+
+		n := rand.Intn(total)
+		for c, count := range m.counts[state] {
+			if n -= count; n >= 0 {
+				continue
+			}
+
+			if c == markovEnd {
+				l := string(label)
+				// DNS labels cannot start or end with a hyphen
+				if l == "" || strings.HasPrefix(l, "-") || strings.HasSuffix(l, "-") {
+					return "", false
+				}
+				return l, true
+			}
+
+			label = append(label, c)
+			state = state[1:] + string(c)
+			break
+		}
+	}
+	return "", false
+}
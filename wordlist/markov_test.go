@@ -0,0 +1,52 @@
+// Copyright 2021 Jeff Foley. All rights reserved.
+// Use of this source code is governed by Apache 2 LICENSE that can be found in the LICENSE file.
+
+package wordlist
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkovModel(t *testing.T) {
+	m := NewMarkovModel(1)
+
+	for _, label := range []string{"app01", "app02", "app03", "app04", "app-dev", "app-prod", "web01", "web02"} {
+		if !m.Train(label) {
+			t.Errorf("The model rejected the label %s", label)
+		}
+	}
+	if m.Train("APP01") || m.Train("bad_label") || m.Train("") {
+		t.Errorf("The model accepted a duplicate or invalid label")
+	}
+	if m.Len() != 8 {
+		t.Errorf("The model was trained on %d labels, expected 8", m.Len())
+	}
+
+	if m.Probability("web03") <= 0 {
+		t.Errorf("A label following the observed patterns received a zero probability")
+	}
+	if m.Probability("zzz") != 0 {
+		t.Errorf("A label using unobserved transitions received a nonzero probability")
+	}
+
+	guesses := m.Generate(5)
+	if len(guesses) == 0 || len(guesses) > 5 {
+		t.Errorf("The model generated %d labels, expected between one and five", len(guesses))
+	}
+	for i, label := range guesses {
+		if label == "app01" || label == "web02" {
+			t.Errorf("The model generated the training label %s", label)
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			t.Errorf("The model generated the invalid label %s", label)
+		}
+		if i > 0 && m.Probability(label) > m.Probability(guesses[i-1]) {
+			t.Errorf("The generated labels were not sorted by probability")
+		}
+	}
+
+	if empty := NewMarkovModel(3).Generate(10); len(empty) != 0 {
+		t.Errorf("The untrained model generated labels: %v", empty)
+	}
+}
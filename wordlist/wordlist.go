@@ -13,9 +13,13 @@ import (
 const (
 	maskLetters = "abcdefghijklmnopqrstuvwxyz"
 	maskDigits  = "0123456789"
+	maskHex     = "0123456789abcdef"
 	maskSpecial = "-"
 )
 
+// MaxMaskExpansion is the maximum number of words a single "hashcat-style" mask can generate.
+const MaxMaskExpansion = 1000000
+
 func getWordList(reader io.Reader) []string {
 	var words []string
 
@@ -31,29 +35,19 @@ func getWordList(reader io.Reader) []string {
 }
 
 // ExpandMask will return a slice of words that a "hashcat-style" mask matches.
+// The ?l, ?u, ?d, ?h, ?s and ?a placeholders are supported, e.g. app?d?d or ?l?l?l-prod.
 func ExpandMask(word string) ([]string, error) {
 	var expanded []string
-	var chars string
 
-	if strings.Count(word, "?") > 3 {
-		return expanded, fmt.Errorf("Exceeded maximum mask size (3): %s", word)
+	if err := CheckMask(word); err != nil {
+		return expanded, err
 	}
 
 	parts := strings.SplitN(word, "?", 2)
 	if len(parts) > 1 {
 		if len(parts[1]) > 0 {
-			switch parts[1][0] {
-			case 'a':
-				chars = maskLetters + maskDigits + maskSpecial
-			case 'd':
-				chars = maskDigits
-			case 'u':
-				fallthrough
-			case 'l':
-				chars = maskLetters
-			case 's':
-				chars = maskSpecial
-			default:
+			chars := maskCharset(parts[1][0])
+			if chars == "" {
 				return expanded, fmt.Errorf("Improper mask used: %s", word)
 			}
 			for _, ch := range chars {
@@ -71,14 +65,66 @@ func ExpandMask(word string) ([]string, error) {
 	return expanded, nil
 }
 
+// CheckMask returns an error when the "hashcat-style" mask is improper or generates more than MaxMaskExpansion words.
+func CheckMask(word string) error {
+	size, err := MaskSize(word)
+	if err != nil {
+		return err
+	}
+	if size > MaxMaskExpansion {
+		return fmt.Errorf("Exceeded maximum mask expansion (%d): %s", MaxMaskExpansion, word)
+	}
+	return nil
+}
+
+// MaskSize returns the number of words the "hashcat-style" mask will generate.
+func MaskSize(word string) (int, error) {
+	size := 1
+
+	parts := strings.Split(word, "?")
+	for _, part := range parts[1:] {
+		if len(part) == 0 {
+			continue
+		}
+
+		chars := maskCharset(part[0])
+		if chars == "" {
+			return 0, fmt.Errorf("Improper mask used: %s", word)
+		}
+		// Stop multiplying once the maximum has been exceeded
+		if size <= MaxMaskExpansion {
+			size *= len(chars)
+		}
+	}
+	return size, nil
+}
+
+func maskCharset(placeholder byte) string {
+	switch placeholder {
+	case 'a':
+		return maskLetters + maskDigits + maskSpecial
+	case 'd':
+		return maskDigits
+	case 'h':
+		return maskHex
+	case 'u', 'l':
+		return maskLetters
+	case 's':
+		return maskSpecial
+	}
+	return ""
+}
+
 // ExpandMaskWordlist performs ExpandMask on a slice of words.
 func ExpandMaskWordlist(wordlist []string) ([]string, error) {
 	var newWordlist []string
 
 	for _, word := range wordlist {
-		if words, err := ExpandMask(word); err == nil {
-			newWordlist = append(newWordlist, words...)
+		words, err := ExpandMask(word)
+		if err != nil {
+			return nil, err
 		}
+		newWordlist = append(newWordlist, words...)
 	}
 
 	return newWordlist, nil